@@ -0,0 +1,192 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RepoInventory is the inventory of the metrics of a repository.
+type RepoInventory struct {
+	Repo      string
+	Inventory *Inventory
+}
+
+// MergeInventories merges the inventories of several repositories into one,
+// setting the Repo of their metrics, so that platform teams can check the
+// names across their services. The metrics of an already merged inventory
+// keep their repository.
+func MergeInventories(invs []RepoInventory) *Inventory {
+	merged := &Inventory{Metrics: []InventoryMetric{}}
+	for _, ri := range invs {
+		for _, m := range ri.Inventory.Metrics {
+			if m.Repo == "" {
+				m.Repo = ri.Repo
+			}
+			merged.Metrics = append(merged.Metrics, m)
+		}
+	}
+	sort.SliceStable(merged.Metrics, func(i, j int) bool {
+		a, b := merged.Metrics[i], merged.Metrics[j]
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Name < b.Name
+	})
+	return merged
+}
+
+// CrossRepoIssues checks the metrics of a merged inventory across their
+// repositories: the series names exposed by the metrics of several
+// repositories, and, if h is not nil, the metrics defined by a repository not
+// allowed to use their namespace or subsystem.
+func CrossRepoIssues(inv *Inventory, h *Hierarchy) []Issue {
+	issues := crossRepoCollisions(inv)
+	if h != nil {
+		issues = append(issues, namespaceOwnership(inv, h)...)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Repo != issues[j].Repo {
+			return issues[i].Repo < issues[j].Repo
+		}
+		return positionLess(issues[i].Pos, issues[j].Pos)
+	})
+	return issues
+}
+
+// crossRepoCollisions reports the metrics exposing a series already exposed
+// by a metric of another repository. The first definition, by repository then
+// position, is kept; the others are reported.
+func crossRepoCollisions(inv *Inventory) []Issue {
+	metrics := append([]InventoryMetric(nil), inv.Metrics...)
+	sort.SliceStable(metrics, func(i, j int) bool {
+		if metrics[i].Repo != metrics[j].Repo {
+			return metrics[i].Repo < metrics[j].Repo
+		}
+		return positionLess(parsePosition(metrics[i].Position), parsePosition(metrics[j].Position))
+	})
+
+	var (
+		issues []Issue
+		series = make(map[string]InventoryMetric)
+	)
+	for _, m := range metrics {
+		reported := false
+		for _, name := range exposedNames(m) {
+			first, ok := series[name]
+			if !ok {
+				series[name] = m
+				continue
+			}
+			if reported || first.Repo == m.Repo {
+				continue
+			}
+			reported = true
+
+			issue := inventoryIssue(m, RuleCrossRepoCollision)
+			if name == m.Name && name == first.Name {
+				issue.Text = fmt.Sprintf("metric is also defined in repository %s at %s", first.Repo, first.Position)
+				switch {
+				case first.Type != m.Type:
+					issue.Text += " as a " + first.Type
+					issue.Severity = SeverityError
+				case strings.Join(first.Labels, ",") != strings.Join(m.Labels, ","):
+					issue.Text += " with the labels " + formatLabels(first.Labels)
+				}
+			} else {
+				issue.Text = fmt.Sprintf("series %s of the metric is also exposed by the %s %s of repository %s at %s", name, first.Type, first.Name, first.Repo, first.Position)
+				issue.Severity = SeverityError
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return "(none)"
+	}
+	return strings.Join(labels, ", ")
+}
+
+// namespaceOwnership reports the metrics whose repository is not among the
+// repos of their namespace or subsystem in h.
+func namespaceOwnership(inv *Inventory, h *Hierarchy) []Issue {
+	var issues []Issue
+	for _, m := range inv.Metrics {
+		prefix, team, repos := h.owner(m.Name)
+		if len(repos) == 0 || m.Repo == "" || matchRepo(repos, m.Repo) {
+			continue
+		}
+		owner := ""
+		if team != "" {
+			owner = fmt.Sprintf(" (owned by %s)", team)
+		}
+		issue := inventoryIssue(m, RuleNamespaceOwnership)
+		issue.Text = fmt.Sprintf("metric is in %s%s, which repository %s may not use, expected one of the repositories %s", prefix, owner, m.Repo, strings.Join(repos, ", "))
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func matchRepo(patterns []string, repo string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, repo); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inventoryIssue returns an issue of rule about a metric of an inventory. Its
+// position is the one of the inventory, prefixed by the repository of the
+// metric if it was merged.
+func inventoryIssue(m InventoryMetric, rule string) Issue {
+	pos := parsePosition(m.Position)
+	if pos.Filename != "" && m.Repo != "" && !path.IsAbs(pos.Filename) {
+		pos.Filename = path.Join(m.Repo, pos.Filename)
+	}
+	return Issue{
+		Pos:        pos,
+		Metric:     m.Name,
+		RuleID:     rule,
+		Severity:   ruleSeverity(rule),
+		End:        pos,
+		MetricType: m.Type,
+		Labels:     m.Labels,
+		Repo:       m.Repo,
+	}
+}
+
+// parsePosition parses a position formatted by token.Position.String, e.g.
+// main.go:12:2.
+func parsePosition(s string) token.Position {
+	var pos token.Position
+	if s == "" || s == "-" {
+		return pos
+	}
+	// The line and column are parsed from the end, as the file name may hold
+	// colons.
+	parts := strings.Split(s, ":")
+	var numbers []int
+	for len(parts) > 1 && len(numbers) < 2 {
+		n, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		numbers = append([]int{n}, numbers...)
+		parts = parts[:len(parts)-1]
+	}
+	pos.Filename = strings.Join(parts, ":")
+	if len(numbers) > 0 {
+		pos.Line = numbers[0]
+	}
+	if len(numbers) > 1 {
+		pos.Column = numbers[1]
+	}
+	return pos
+}
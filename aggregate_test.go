@@ -0,0 +1,82 @@
+package promlinter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readTestInventories(t *testing.T, repos ...string) []RepoInventory {
+	var invs []RepoInventory
+	for _, repo := range repos {
+		f, err := os.Open(filepath.Join("testdata", "aggregate", filepath.Base(repo)+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		inv, err := ReadInventory(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		invs = append(invs, RepoInventory{Repo: repo, Inventory: inv})
+	}
+	return invs
+}
+
+func TestMergeInventories(t *testing.T) {
+	merged := MergeInventories(readTestInventories(t, "acme/worker", "acme/api"))
+	if len(merged.Metrics) != 7 || merged.Metrics[0].Repo != "acme/api" || merged.Metrics[6].Repo != "acme/worker" {
+		t.Fatalf("expected the metrics of both repositories ordered by repository, got %+v", merged.Metrics)
+	}
+
+	again := MergeInventories([]RepoInventory{{Repo: "all", Inventory: merged}})
+	if again.Metrics[0].Repo != "acme/api" {
+		t.Errorf("expected merged metrics to keep their repository, got %s", again.Metrics[0].Repo)
+	}
+}
+
+func TestCrossRepoIssues(t *testing.T) {
+	h, err := LoadHierarchy(filepath.Join("testdata", "aggregate", "hierarchy.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := MergeInventories(readTestInventories(t, "acme/api", "acme/worker"))
+	issues := CrossRepoIssues(merged, h)
+
+	expected := []struct {
+		pos, rule string
+		severity  Severity
+		text      string
+	}{
+		{"acme/api/pkg/billing/metrics.go:5:2", RuleNamespaceOwnership, SeverityWarning, "metric is in acme_billing_ (owned by payments), which repository acme/api may not use, expected one of the repositories acme/billing"},
+		{"acme/worker/internal/http.go:7:2", RuleCrossRepoCollision, SeverityError, "metric is also defined in repository acme/api at pkg/server/metrics.go:10:2 as a counter"},
+		{"acme/worker/internal/metrics.go:12:2", RuleCrossRepoCollision, SeverityWarning, "metric is also defined in repository acme/api at pkg/jobs/metrics.go:8:2 with the labels (none)"},
+		{"acme/worker/internal/metrics.go:20:2", RuleCrossRepoCollision, SeverityError, "series queue_latency_seconds_count of the metric is also exposed by the histogram queue_latency_seconds of repository acme/api at pkg/jobs/metrics.go:14:2"},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("expected %d issues, got %d: %+v", len(expected), len(issues), issues)
+	}
+	for i, e := range expected {
+		iss := issues[i]
+		if iss.Pos.String() != e.pos || iss.RuleID != e.rule || iss.Severity != e.severity || iss.Text != e.text {
+			t.Errorf("expected %s %s %s %q, got %s %s %s %q", e.pos, e.rule, e.severity, e.text, iss.Pos, iss.RuleID, iss.Severity, iss.Text)
+		}
+	}
+
+	if issues := CrossRepoIssues(merged, nil); len(issues) != 3 {
+		t.Errorf("expected no ownership issues without hierarchy, got %+v", issues)
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	for s, want := range map[string]string{
+		"main.go:12:2":       "main.go:12:2",
+		"main.go:12":         "main.go:12",
+		"C:/src/main.go:3:1": "C:/src/main.go:3:1",
+		"-":                  "-",
+	} {
+		if got := parsePosition(s).String(); got != want {
+			t.Errorf("expected %s for %s, got %s", want, s, got)
+		}
+	}
+}
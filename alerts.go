@@ -0,0 +1,147 @@
+package promlinter
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// AlertConfig tells which alerts NewAlertRules generates. The patterns match
+// metric names with the syntax of path.Match, e.g. "*_errors_total".
+type AlertConfig struct {
+	// Group is the name of the rule group.
+	Group string
+	// Critical holds the patterns of the metrics alerted on when they are
+	// absent, e.g. because the job stopped exposing them.
+	Critical []string
+	// Errors holds the patterns of the error counters alerted on when their
+	// ratio to the matching total counter is above ErrorRatio. The total
+	// counter of foo_errors_total is foo_total or foo_requests_total.
+	// Without one, the alert fires on any error.
+	Errors     []string
+	ErrorRatio float64
+	// For is how long the conditions must hold before the alerts fire.
+	For string
+}
+
+// DefaultAlertConfig alerts on the ratio of the error counters.
+var DefaultAlertConfig = AlertConfig{
+	Group:      "promlinter",
+	Errors:     []string{"*_errors_total"},
+	ErrorRatio: 0.05,
+	For:        "5m",
+}
+
+// AlertRules is a Prometheus rule file.
+type AlertRules struct {
+	Groups []AlertGroup `yaml:"groups"`
+}
+
+// AlertGroup is a group of alerting rules.
+type AlertGroup struct {
+	Name  string      `yaml:"name"`
+	Rules []AlertRule `yaml:"rules"`
+}
+
+// AlertRule is an alerting rule.
+type AlertRule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// NewAlertRules generates alerting rule stubs for the metrics of inv, to
+// adapt before deploying them: an absent() alert for each critical metric,
+// and an alert on the ratio of each error counter.
+func NewAlertRules(inv *Inventory, config AlertConfig) (*AlertRules, error) {
+	byName := inv.byName()
+	group := AlertGroup{Name: config.Group, Rules: make([]AlertRule, 0)}
+
+	seen := make(map[string]bool)
+	for _, m := range inv.Metrics {
+		if seen[m.Name] {
+			continue
+		}
+		seen[m.Name] = true
+
+		critical, err := matchAny(config.Critical, m.Name)
+		if err != nil {
+			return nil, err
+		}
+		if critical {
+			group.Rules = append(group.Rules, AlertRule{
+				Alert:       alertName(m.Name) + "Absent",
+				Expr:        fmt.Sprintf("absent(%s)", m.Name),
+				For:         config.For,
+				Labels:      map[string]string{"severity": "critical"},
+				Annotations: map[string]string{"summary": fmt.Sprintf("%s is not exposed by any target.", m.Name)},
+			})
+		}
+
+		errors, err := matchAny(config.Errors, m.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !errors || m.Type != "counter" {
+			continue
+		}
+		prefix := strings.TrimSuffix(strings.TrimSuffix(m.Name, "_total"), "_errors")
+		rule := AlertRule{
+			Alert:       alertName(prefix) + "Errors",
+			Expr:        fmt.Sprintf("sum(rate(%s[5m])) > 0", m.Name),
+			For:         config.For,
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: map[string]string{"summary": fmt.Sprintf("%s is increasing.", m.Name)},
+		}
+		for _, total := range []string{prefix + "_total", prefix + "_requests_total"} {
+			if t, ok := byName[total]; ok && t.Type == "counter" && total != m.Name {
+				rule.Alert = alertName(prefix) + "ErrorRatioHigh"
+				rule.Expr = fmt.Sprintf("sum(rate(%s[5m])) / sum(rate(%s[5m])) > %v", m.Name, total, config.ErrorRatio)
+				rule.Annotations = map[string]string{"summary": fmt.Sprintf("More than %v%% of %s are errors.", config.ErrorRatio*100, total)}
+				break
+			}
+		}
+		group.Rules = append(group.Rules, rule)
+	}
+	return &AlertRules{Groups: []AlertGroup{group}}, nil
+}
+
+func matchAny(patterns []string, name string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// alertName turns a metric name into an alert name, e.g. http_requests into
+// HttpRequests.
+func alertName(metric string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(metric, "_") {
+		if part != "" {
+			sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return sb.String()
+}
+
+// Write encodes the rules as a Prometheus rule file.
+func (r *AlertRules) Write(w io.Writer) error {
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
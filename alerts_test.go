@@ -0,0 +1,62 @@
+package promlinter
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAlertRules(t *testing.T) {
+	inv := &Inventory{Metrics: []InventoryMetric{
+		{Name: "http_requests_total", Type: "counter"},
+		{Name: "http_errors_total", Type: "counter"},
+		{Name: "jobs_errors_total", Type: "counter"},
+		{Name: "queue_length", Type: "gauge"},
+		{Name: "cache_errors_total", Type: "gauge"},
+	}}
+	config := DefaultAlertConfig
+	config.Critical = []string{"queue_*"}
+
+	rules, err := NewAlertRules(inv, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := rules.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `groups:
+- name: promlinter
+  rules:
+  - alert: HttpErrorRatioHigh
+    expr: sum(rate(http_errors_total[5m])) / sum(rate(http_requests_total[5m])) >
+      0.05
+    for: 5m
+    labels:
+      severity: warning
+    annotations:
+      summary: More than 5% of http_requests_total are errors.
+  - alert: JobsErrors
+    expr: sum(rate(jobs_errors_total[5m])) > 0
+    for: 5m
+    labels:
+      severity: warning
+    annotations:
+      summary: jobs_errors_total is increasing.
+  - alert: QueueLengthAbsent
+    expr: absent(queue_length)
+    for: 5m
+    labels:
+      severity: critical
+    annotations:
+      summary: queue_length is not exposed by any target.
+`
+	if got := buf.String(); got != expected {
+		t.Fatalf("expected rules:\n%s\ngot:\n%s", expected, got)
+	}
+
+	config.Critical = []string{"["}
+	if _, err := NewAlertRules(inv, config); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+}
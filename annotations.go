@@ -0,0 +1,129 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// labelValuesDirective is the prefix of the comments declaring the values a
+// label may have, e.g.
+//
+//	// promlinter:label-values status=ok,error,timeout
+//	requests = prometheus.NewCounterVec(opts, []string{"status"})
+const labelValuesDirective = "promlinter:label-values"
+
+// parseDirectives records the label-values comments of file by line.
+func (v *visitor) parseDirectives(file *ast.File) {
+	v.directives = make(map[int]map[string][]string)
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+			rest, ok := strings.CutPrefix(text, labelValuesDirective)
+			if !ok {
+				continue
+			}
+			values := parseLabelValuesDirective(rest)
+			if values == nil {
+				v.debug(c, "malformed label-values directive", "comment", c.Text)
+				continue
+			}
+			v.directives[v.fs.Position(c.Pos()).Line] = values
+		}
+	}
+}
+
+// parseLabelValuesDirective parses the labels and values of a directive,
+// e.g. "status=ok,error code=200,404", or returns nil if it is malformed.
+func parseLabelValuesDirective(s string) map[string][]string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil
+	}
+	values := make(map[string][]string)
+	for _, f := range fields {
+		label, list, ok := strings.Cut(f, "=")
+		if !ok || label == "" || list == "" {
+			return nil
+		}
+		values[label] = append(values[label], strings.Split(list, ",")...)
+	}
+	return values
+}
+
+// annotatedValues returns the label values declared by the directives on the
+// line before the constructor call or within it.
+func (v *visitor) annotatedValues(call *ast.CallExpr) map[string][]string {
+	var annotated map[string][]string
+	for line := v.fs.Position(call.Pos()).Line - 1; line <= v.fs.Position(call.End()).Line; line++ {
+		for label, values := range v.directives[line] {
+			if annotated == nil {
+				annotated = make(map[string][]string)
+			}
+			annotated[label] = append(annotated[label], values...)
+		}
+	}
+	return annotated
+}
+
+// undeclaredLabelValues reports the label values passed to the Vecs which
+// are not among the values declared by their label-values directive, e.g. a
+// typo such as "eror", and the directives naming labels the Vec does not
+// have. Only the values which are resolved statically are checked.
+func undeclaredLabelValues(res *partialResult) []Issue {
+	byHolder := make(map[string][]labelSite)
+	for _, s := range res.labels {
+		byHolder[s.Holder] = append(byHolder[s.Holder], s)
+	}
+
+	var issues []Issue
+	for _, m := range res.metrics {
+		if len(m.allowedValues) == 0 {
+			continue
+		}
+		report := func(site labelSite, text string) {
+			issues = append(issues, Issue{
+				Pos:        site.Pos,
+				Metric:     m.MetricFamily.GetName(),
+				Text:       text,
+				RuleID:     RuleUndeclaredLabelValue,
+				Severity:   ruleSeverity(RuleUndeclaredLabelValue),
+				MetricType: metricTypeName(m.MetricFamily.GetType()),
+				Labels:     m.Labels(),
+			})
+		}
+
+		labels := m.Labels()
+		var unknown []string
+		for label := range m.allowedValues {
+			if !contains(labels, label) {
+				unknown = append(unknown, label)
+			}
+		}
+		sort.Strings(unknown)
+		for _, label := range unknown {
+			report(labelSite{Pos: m.Pos}, fmt.Sprintf("label-values directive names the unknown label %q", label))
+		}
+		if m.holder == "" {
+			continue
+		}
+		for _, site := range byHolder[m.holder] {
+			if site.Unknown {
+				continue
+			}
+			for i, label := range labels {
+				allowed, ok := m.allowedValues[label]
+				if !ok {
+					continue
+				}
+				for _, value := range site.value(label, i) {
+					if !contains(allowed, value) {
+						report(site, fmt.Sprintf("label %q has the value %q, which is not one of %s", label, value, strings.Join(allowed, ", ")))
+					}
+				}
+			}
+		}
+	}
+	return issues
+}
@@ -0,0 +1,41 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUndeclaredLabelValues(t *testing.T) {
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "annotations", "annotations.go")}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleUndeclaredLabelValue {
+			got = append(got, iss.Pos.String()+": "+iss.Text)
+		}
+	}
+	expected := []string{
+		`testdata/annotations/annotations.go:12:34: label-values directive names the unknown label "kind"`,
+		`testdata/annotations/annotations.go:23:2: label "status" has the value "eror", which is not one of ok, error, timeout`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestParseLabelValuesDirective(t *testing.T) {
+	expected := map[string][]string{"status": {"ok", "error"}, "code": {"200"}}
+	if got := parseLabelValuesDirective(" status=ok,error code=200"); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for _, s := range []string{"", "status", "status="} {
+		if got := parseLabelValuesDirective(s); got != nil {
+			t.Errorf("expected %q to be malformed, got %v", s, got)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package promlinter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ArchiveTree is a source tree extracted from a tar or zip archive, e.g.
+// written by git archive or kept as a CI artifact, so that a scanning
+// service can analyze it without a checkout.
+type ArchiveTree struct {
+	// Dir is the temporary directory holding the extracted files.
+	Dir string
+	// Files holds the paths of the extracted Go files, relative to the root
+	// of the archive.
+	Files []string
+}
+
+// archiveFiles are the files extracted along with the Go files, for the
+// analyses loading the module.
+var archiveFiles = map[string]bool{"go.mod": true, "go.sum": true, "go.work": true}
+
+// ExtractArchive extracts the Go files of the tar, gzipped tar or zip archive
+// at path to a temporary directory. The format is detected from the content
+// of the archive. Entries escaping the root of the archive, links and other
+// special files are skipped. Close removes the directory.
+func ExtractArchive(path string) (*ArchiveTree, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dir, err := os.MkdirTemp("", "promlinter-archive-")
+	if err != nil {
+		return nil, err
+	}
+	t := &ArchiveTree{Dir: dir}
+	if err := t.extract(f); err != nil {
+		t.Close()
+		return nil, fmt.Errorf("extracting %s: %v", path, err)
+	}
+	sort.Strings(t.Files)
+	return t, nil
+}
+
+func (t *ArchiveTree) extract(f *os.File) error {
+	r := bufio.NewReader(f)
+	magic, _ := r.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")), bytes.HasPrefix(magic, []byte("PK\x05\x06")):
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return t.extractZip(f, info.Size())
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return t.extractTar(gz)
+	}
+	return t.extractTar(r)
+}
+
+func (t *ArchiveTree) extractTar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := t.write(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func (t *ArchiveTree) extractZip(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, zf := range zr.File {
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		err = t.write(zf.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write extracts the entry name if it is a Go file or a file of the module
+// within the root of the archive.
+func (t *ArchiveTree) write(name string, r io.Reader) error {
+	name = path.Clean(strings.TrimPrefix(filepath.ToSlash(name), "./"))
+	if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return nil
+	}
+	isGo := strings.HasSuffix(name, ".go")
+	if !isGo && !archiveFiles[path.Base(name)] {
+		return nil
+	}
+
+	dst := filepath.Join(t.Dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if isGo {
+		t.Files = append(t.Files, name)
+	}
+	return nil
+}
+
+// Paths returns the paths of the extracted Go files.
+func (t *ArchiveTree) Paths() []string {
+	paths := make([]string, 0, len(t.Files))
+	for _, f := range t.Files {
+		paths = append(paths, filepath.Join(t.Dir, filepath.FromSlash(f)))
+	}
+	return paths
+}
+
+// Restore returns the issues with the paths of the extracted files replaced
+// by their paths in the archive, see RestorePath.
+func (t *ArchiveTree) Restore(issues []Issue) []Issue {
+	restore := func(filename string) string {
+		path, _ := t.RestorePath(filename)
+		return path
+	}
+	res := make([]Issue, 0, len(issues))
+	for _, iss := range issues {
+		res = append(res, iss.MapFilenames(restore))
+	}
+	return res
+}
+
+// RestorePath returns the path in the archive of the extracted file
+// filename, and whether it was extracted. The other paths are returned
+// unchanged.
+func (t *ArchiveTree) RestorePath(filename string) (string, bool) {
+	rel, err := filepath.Rel(t.Dir, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filename, false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// Close removes the extracted files.
+func (t *ArchiveTree) Close() error {
+	return os.RemoveAll(t.Dir)
+}
@@ -0,0 +1,113 @@
+package promlinter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var archiveEntries = []struct{ name, src string }{
+	{"api/go.mod", "module example.com/api\n"},
+	{"api/main.go", "package main\n\nimport \"github.com/prometheus/client_golang/prometheus\"\n\nvar c = prometheus.NewCounter(prometheus.CounterOpts{Name: \"archive_requests\", Help: \"Help.\"})\n"},
+	{"api/README.md", "not extracted\n"},
+	{"../escape.go", "package escape\n"},
+}
+
+func writeTarGz(t *testing.T, path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "api/", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "api/link.go", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range archiveEntries {
+		if err := tw.WriteHeader(&tar.Header{Name: e.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(e.src))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(tw, e.src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, e := range archiveEntries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, e.src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	dir := t.TempDir()
+	for name, write := range map[string]func(*testing.T, string){
+		"src.tar.gz": writeTarGz,
+		"src.zip":    writeZip,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			write(t, path)
+
+			tree, err := ExtractArchive(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tree.Close()
+
+			if expected := []string{"api/main.go"}; !reflect.DeepEqual(tree.Files, expected) {
+				t.Errorf("expected the files %v, got %v", expected, tree.Files)
+			}
+			if _, err := os.Stat(filepath.Join(tree.Dir, "api", "go.mod")); err != nil {
+				t.Errorf("expected go.mod to be extracted: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(tree.Dir), "escape.go")); err == nil {
+				t.Error("expected the entry escaping the archive to be skipped")
+			}
+
+			res, err := AnalyzeFiles(token.NewFileSet(), tree.Paths(), Setting{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			issues := tree.Restore(res.Issues)
+			if len(issues) != 1 || issues[0].Pos.Filename != "api/main.go" || issues[0].Metric != "archive_requests" {
+				t.Errorf("expected the counter issue in api/main.go, got %v", issues)
+			}
+		})
+	}
+
+	if _, err := ExtractArchive(filepath.Join(dir, "missing.tar")); err == nil {
+		t.Error("expected an error for a missing archive")
+	}
+}
@@ -0,0 +1,117 @@
+package promlinter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Blame is the git blame attribution of the line an issue points to.
+type Blame struct {
+	Commit      string    `json:"commit"`
+	Author      string    `json:"author"`
+	AuthorEmail string    `json:"author_email"`
+	AuthorTime  time.Time `json:"author_time"`
+}
+
+// AddBlame runs git blame on the files of the issues and attributes each issue
+// to the last commit which changed its line. git is run once per file.
+func AddBlame(issues []Issue) error {
+	files := make(map[string]map[int]*Blame)
+	for i := range issues {
+		filename := issues[i].Pos.Filename
+		if filename == "" {
+			continue
+		}
+
+		lines, ok := files[filename]
+		if !ok {
+			var err error
+			if lines, err = blameFile(filename); err != nil {
+				return err
+			}
+			files[filename] = lines
+		}
+		issues[i].Blame = lines[issues[i].Pos.Line]
+	}
+	return nil
+}
+
+func blameFile(filename string) (map[int]*Blame, error) {
+	cmd := exec.Command("git", "blame", "--porcelain", "--", filepath.Base(filename))
+	cmd.Dir = filepath.Dir(filename)
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("git blame %s: %v: %s", filename, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git blame %s: %v", filename, err)
+	}
+	return parseBlamePorcelain(strings.NewReader(string(out)))
+}
+
+// parseBlamePorcelain parses the output of git blame --porcelain into the
+// attribution of each line. The commit details are only printed the first
+// time a commit appears.
+func parseBlamePorcelain(r io.Reader) (map[int]*Blame, error) {
+	var (
+		lines   = make(map[int]*Blame)
+		commits = make(map[string]*Blame)
+		current *Blame
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	header := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\t") {
+			// Content of the line, the next line is a header.
+			header = true
+			continue
+		}
+
+		if header {
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("unexpected git blame header %q", line)
+			}
+			final, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected git blame header %q: %v", line, err)
+			}
+
+			current = commits[fields[0]]
+			if current == nil {
+				current = &Blame{Commit: fields[0]}
+				commits[fields[0]] = current
+			}
+			lines[final] = current
+			header = false
+			continue
+		}
+
+		key, value := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			key, value = line[:i], line[i+1:]
+		}
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-mail":
+			current.AuthorEmail = strings.Trim(value, "<>")
+		case "author-time":
+			sec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected git blame author time %q: %v", value, err)
+			}
+			current.AuthorTime = time.Unix(sec, 0).UTC()
+		}
+	}
+	return lines, scanner.Err()
+}
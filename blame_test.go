@@ -0,0 +1,40 @@
+package promlinter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBlamePorcelain(t *testing.T) {
+	out := `9ffc02c140d09c2121422a9cf80a504353a865f0 1 1 2
+author X
+author-mail <x@example.com>
+author-time 1600000000
+summary first
+filename f.go
+	a
+9ffc02c140d09c2121422a9cf80a504353a865f0 2 2
+	b
+4c19a7a50765486ca059e094a4d2f05e3c951556 3 3 1
+author Y
+author-mail <y@example.com>
+author-time 1600000100
+previous 9ffc02c140d09c2121422a9cf80a504353a865f0 f.go
+filename f.go
+	c
+`
+	lines, err := parseBlamePorcelain(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	if b := lines[2]; b.Author != "X" || b.AuthorEmail != "x@example.com" || b.AuthorTime.Unix() != 1600000000 {
+		t.Fatalf("unexpected blame for line 2: %+v", b)
+	}
+	if b := lines[3]; b.Author != "Y" || b.Commit != "4c19a7a50765486ca059e094a4d2f05e3c951556" {
+		t.Fatalf("unexpected blame for line 3: %+v", b)
+	}
+}
@@ -0,0 +1,93 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/build"
+	"path/filepath"
+	"strings"
+)
+
+// BuildConfig is a build configuration: a target platform and build tags.
+type BuildConfig struct {
+	// GOOS and GOARCH default to the host platform.
+	GOOS   string
+	GOARCH string
+	Tags   []string
+}
+
+// ParseBuildConfig parses a build configuration written as
+// "GOOS/GOARCH:tag1,tag2". The platform and the tags are both optional, e.g.
+// "linux/arm64" or ":integration".
+func ParseBuildConfig(s string) (BuildConfig, error) {
+	var c BuildConfig
+	platform, tags, hasTags := strings.Cut(s, ":")
+	if platform != "" {
+		var ok bool
+		c.GOOS, c.GOARCH, ok = strings.Cut(platform, "/")
+		if !ok || c.GOOS == "" || c.GOARCH == "" {
+			return BuildConfig{}, fmt.Errorf("invalid platform %q in build configuration %q, expected GOOS/GOARCH", platform, s)
+		}
+	}
+	if hasTags {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				c.Tags = append(c.Tags, tag)
+			}
+		}
+	}
+	return c, nil
+}
+
+// String formats the configuration like ParseBuildConfig expects it.
+func (c BuildConfig) String() string {
+	var s string
+	if c.GOOS != "" {
+		s = c.GOOS + "/" + c.GOARCH
+	}
+	if len(c.Tags) > 0 {
+		s += ":" + strings.Join(c.Tags, ",")
+	}
+	return s
+}
+
+func (c BuildConfig) context() build.Context {
+	ctx := build.Default
+	if c.GOOS != "" {
+		ctx.GOOS, ctx.GOARCH = c.GOOS, c.GOARCH
+	}
+	ctx.BuildTags = c.Tags
+	return ctx
+}
+
+// Match reports whether the Go file at path is built with the configuration,
+// according to its file name suffixes (_linux.go, _arm64.go, ...) and its
+// //go:build constraints.
+func (c BuildConfig) Match(path string) (bool, error) {
+	ctx := c.context()
+	return ctx.MatchFile(filepath.Dir(path), filepath.Base(path))
+}
+
+// FilterFiles returns the paths built with at least one of the
+// configurations, so that a file only built on linux is analyzed as long as
+// one of the configurations targets linux. Without configurations, all the
+// paths are returned: build constraints are ignored.
+func FilterFiles(paths []string, configs []BuildConfig) ([]string, error) {
+	if len(configs) == 0 {
+		return paths, nil
+	}
+
+	var files []string
+	for _, path := range paths {
+		for _, c := range configs {
+			ok, err := c.Match(path)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				files = append(files, path)
+				break
+			}
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,55 @@
+package promlinter
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseBuildConfig(t *testing.T) {
+	for s, expected := range map[string]BuildConfig{
+		"":                        {},
+		"linux/arm64":             {GOOS: "linux", GOARCH: "arm64"},
+		":integration":            {Tags: []string{"integration"}},
+		"windows/amd64:e2e, race": {GOOS: "windows", GOARCH: "amd64", Tags: []string{"e2e", "race"}},
+	} {
+		c, err := ParseBuildConfig(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(c, expected) {
+			t.Fatalf("expected %+v for %q, got %+v", expected, s, c)
+		}
+	}
+
+	for _, s := range []string{"linux", "linux/", "/amd64:foo"} {
+		if _, err := ParseBuildConfig(s); err == nil {
+			t.Fatalf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestFilterFiles(t *testing.T) {
+	paths := []string{
+		filepath.Join("testdata", "build", "integration.go"),
+		filepath.Join("testdata", "build", "metrics_linux.go"),
+		filepath.Join("testdata", "build", "metrics_windows.go"),
+	}
+
+	for _, tc := range []struct {
+		configs  []BuildConfig
+		expected []string
+	}{
+		{configs: nil, expected: paths},
+		{configs: []BuildConfig{{GOOS: "linux", GOARCH: "amd64"}}, expected: paths[1:2]},
+		{configs: []BuildConfig{{GOOS: "linux", GOARCH: "amd64"}, {GOOS: "windows", GOARCH: "amd64", Tags: []string{"integration"}}}, expected: paths},
+	} {
+		files, err := FilterFiles(paths, tc.configs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(files, tc.expected) {
+			t.Fatalf("expected %v for %v, got %v", tc.expected, tc.configs, files)
+		}
+	}
+}
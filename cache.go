@@ -0,0 +1,224 @@
+package promlinter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// cacheVersion must be bumped whenever a change to the analysis makes
+// previously cached results invalid.
+const cacheVersion = "33"
+
+// Cache stores per-package analysis results in a directory or in memory,
+// keyed by the paths and contents of the files of the package and by the
+// setting of the analysis.
+type Cache struct {
+	dir string
+
+	mu      sync.Mutex
+	entries map[string]*partialResult
+	// keys holds the key of the last entry of each package directory, to
+	// evict entries of previous versions of a package from memory.
+	keys map[string]string
+}
+
+// NewCache returns a cache storing its entries in dir, creating it if needed.
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// NewMemoryCache returns a cache keeping its entries in memory, for long
+// running processes.
+func NewMemoryCache() *Cache {
+	return &Cache{entries: make(map[string]*partialResult), keys: make(map[string]string)}
+}
+
+type cacheEntry struct {
+	Files   int            `json:"files"`
+	Metrics []cachedMetric `json:"metrics"`
+	Issues  []Issue        `json:"issues"`
+	Skipped int            `json:"skipped"`
+
+	SyntaxErrors []SyntaxError `json:"syntax_errors,omitempty"`
+
+	References    []string                    `json:"references,omitempty"`
+	Registrations map[string][]token.Position `json:"registrations,omitempty"`
+	Writes        []writeSite                 `json:"writes,omitempty"`
+	Labels        []labelSite                 `json:"labels,omitempty"`
+	Instruments   []otelInstrument            `json:"instruments,omitempty"`
+	Generated     []string                    `json:"generated,omitempty"`
+}
+
+type cachedMetric struct {
+	Name   string         `json:"name"`
+	Type   dto.MetricType `json:"type"`
+	Help   *string        `json:"help"`
+	Labels []string       `json:"labels"`
+	Pos    token.Position `json:"pos"`
+	End    token.Position `json:"end"`
+
+	AutoRegistered bool      `json:"auto_registered,omitempty"`
+	Constructor    string    `json:"constructor,omitempty"`
+	Holder         string    `json:"holder,omitempty"`
+	Buckets        []float64 `json:"buckets,omitempty"`
+	ConstLabels    bool      `json:"const_labels,omitempty"`
+
+	AllowedValues map[string][]string `json:"allowed_values,omitempty"`
+}
+
+// key returns the cache key of the files of a package for the given setting.
+// Only the settings which change the result of the analysis are part of the
+// key.
+func (c *Cache) key(paths []string, srcs [][]byte, setting Setting) string {
+	disabled := append([]string(nil), setting.DisabledRules...)
+	sort.Strings(disabled)
+	enabled := append([]string(nil), setting.EnabledRules...)
+	sort.Strings(enabled)
+	var dictionary string
+	if setting.Dictionary != nil {
+		dictionary = setting.Dictionary.digest
+	}
+	config, _ := json.Marshal(struct {
+		Strict        bool
+		DisabledRules []string
+		EnabledRules  []string
+		Severities    map[string]Severity
+		Generated     GeneratedPolicy
+		Packages      []string
+		Dictionary    string
+		Reserved      []string
+		Allowed       []string
+		Hierarchy     *Hierarchy
+		Profiles      []Profile
+		Overrides     []RuleOverride
+		Validation    NameValidation
+		Checks        []string
+		Scripts       []*ScriptRule
+		GoVersion     string
+	}{setting.Strict, disabled, enabled, setting.Severities, setting.Generated, setting.PrometheusPackages, dictionary, setting.ReservedLabels, setting.AllowedConstLabels, setting.Hierarchy, setting.Profiles, setting.RuleOverrides, setting.NameValidation, checkIDs(), setting.ScriptRules, setting.GoVersion})
+
+	h := sha256.New()
+	for _, b := range [][]byte{[]byte(cacheVersion), config} {
+		h.Write(b)
+		h.Write([]byte{0})
+	}
+	for i, path := range paths {
+		for _, b := range [][]byte{[]byte(path), srcs[i]} {
+			h.Write(b)
+			h.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) get(key string) (*partialResult, bool) {
+	if c.entries != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		res, ok := c.entries[key]
+		return res, ok
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	res := &partialResult{files: entry.Files, issues: entry.Issues, skipped: entry.Skipped, syntaxErrors: entry.SyntaxErrors, writes: entry.Writes, labels: entry.Labels, instruments: entry.Instruments, references: make(map[string]bool), registrations: make(map[string][]token.Position), generated: make(map[string]bool)}
+	for _, k := range entry.References {
+		res.references[k] = true
+	}
+	for k, sites := range entry.Registrations {
+		res.registrations[k] = sites
+	}
+	for _, f := range entry.Generated {
+		res.generated[f] = true
+	}
+	for _, m := range entry.Metrics {
+		m := m
+		mf := &dto.MetricFamily{Name: &m.Name, Type: &m.Type, Help: m.Help}
+		setLabels(mf, m.Labels)
+		res.metrics = append(res.metrics, MetricFamilyWithPos{MetricFamily: mf, Pos: m.Pos, End: m.End, AutoRegistered: m.AutoRegistered, Constructor: m.Constructor, holder: m.Holder, buckets: m.Buckets, allowedValues: m.AllowedValues, constLabels: m.ConstLabels})
+	}
+	return res, true
+}
+
+func (c *Cache) put(dir, key string, res *partialResult) error {
+	if c.entries != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.entries, c.keys[dir])
+		c.entries[key] = res
+		c.keys[dir] = key
+		return nil
+	}
+
+	entry := cacheEntry{
+		Files:         res.files,
+		Metrics:       make([]cachedMetric, 0, len(res.metrics)),
+		Issues:        res.issues,
+		Skipped:       res.skipped,
+		SyntaxErrors:  res.syntaxErrors,
+		References:    sortedSet(res.references),
+		Registrations: res.registrations,
+		Writes:        res.writes,
+		Labels:        res.labels,
+		Instruments:   res.instruments,
+		Generated:     sortedSet(res.generated),
+	}
+	for _, m := range res.metrics {
+		entry.Metrics = append(entry.Metrics, cachedMetric{
+			Name:   m.MetricFamily.GetName(),
+			Type:   m.MetricFamily.GetType(),
+			Help:   m.MetricFamily.Help,
+			Labels: m.Labels(),
+			Pos:    m.Pos,
+			End:    m.End,
+
+			AutoRegistered: m.AutoRegistered,
+			Constructor:    m.Constructor,
+			Holder:         m.holder,
+			Buckets:        m.buckets,
+			ConstLabels:    m.constLabels,
+			AllowedValues:  m.allowedValues,
+		})
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that concurrent runs never read a
+	// partially written entry.
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(c.dir, key+".json"))
+}
@@ -0,0 +1,63 @@
+package promlinter
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "foo.go")
+	src := `package foo
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var c = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "foo"}, []string{"code"})
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	setting := Setting{Cache: cache}
+	first, err := AnalyzeFiles(token.NewFileSet(), []string{path}, setting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Issues) != 2 {
+		t.Fatalf("expected 2 issues, got %+v", first.Issues)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 cache entry, got %d", len(entries))
+	}
+
+	second, err := AnalyzeFiles(token.NewFileSet(), []string{path}, setting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first.Issues, second.Issues) || !reflect.DeepEqual(first.Metrics[0].Labels(), second.Metrics[0].Labels()) {
+		t.Fatalf("cached result differs: %+v, %+v", first, second)
+	}
+
+	// A different setting must not reuse the cached entry.
+	setting.DisabledRules = []string{RuleHelp}
+	third, err := AnalyzeFiles(token.NewFileSet(), []string{path}, setting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(third.Issues) != 1 {
+		t.Fatalf("expected 1 issue, got %+v", third.Issues)
+	}
+}
@@ -0,0 +1,123 @@
+package promlinter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CatalogFormat is the format of a service catalog export.
+type CatalogFormat string
+
+const (
+	// CatalogBackstage is a Backstage Component entity, to merge into the
+	// catalog-info.yaml of the component.
+	CatalogBackstage CatalogFormat = "backstage"
+	// CatalogGeneric is a plain YAML document listing the metrics of the
+	// component, for other developer portals.
+	CatalogGeneric CatalogFormat = "generic"
+)
+
+// CatalogConfig describes the component exposing the metrics.
+type CatalogConfig struct {
+	Format CatalogFormat
+	// Component is the name of the component, and Owner and System its
+	// owning team and system, if known.
+	Component string
+	Owner     string
+	System    string
+}
+
+// CatalogMetric is a metric in a catalog export.
+type CatalogMetric struct {
+	Name   string   `yaml:"name"`
+	Type   string   `yaml:"type"`
+	Help   string   `yaml:"help,omitempty"`
+	Labels []string `yaml:"labels,omitempty"`
+}
+
+// backstageEntity is a Backstage catalog entity. The metrics are stored in
+// an extra field of its metadata, which Backstage keeps as is, and their
+// names in an annotation for plugins which only read annotations.
+type backstageEntity struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   backstageMetadata `yaml:"metadata"`
+	Spec       backstageSpec     `yaml:"spec"`
+}
+
+type backstageMetadata struct {
+	Name        string            `yaml:"name"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Metrics     []CatalogMetric   `yaml:"metrics"`
+}
+
+type backstageSpec struct {
+	Type   string `yaml:"type"`
+	Owner  string `yaml:"owner"`
+	System string `yaml:"system,omitempty"`
+}
+
+// genericCatalog is the generic catalog export.
+type genericCatalog struct {
+	Component string          `yaml:"component"`
+	Owner     string          `yaml:"owner,omitempty"`
+	System    string          `yaml:"system,omitempty"`
+	Metrics   []CatalogMetric `yaml:"metrics"`
+}
+
+// metricsAnnotation is the annotation listing the names of the metrics of a
+// Backstage entity.
+const metricsAnnotation = "promlinter.io/metrics"
+
+// WriteCatalog writes the metrics of inv as a catalog fragment describing
+// the component which exposes them.
+func WriteCatalog(w io.Writer, inv *Inventory, config CatalogConfig) error {
+	if config.Component == "" {
+		return fmt.Errorf("the component name is required")
+	}
+
+	metrics := make([]CatalogMetric, 0, len(inv.Metrics))
+	names := make([]string, 0, len(inv.Metrics))
+	seen := make(map[string]bool)
+	for _, m := range inv.Metrics {
+		if seen[m.Name] {
+			continue
+		}
+		seen[m.Name] = true
+		metrics = append(metrics, CatalogMetric{Name: m.Name, Type: m.Type, Help: m.Help, Labels: m.Labels})
+		names = append(names, m.Name)
+	}
+
+	var doc interface{}
+	switch config.Format {
+	case CatalogBackstage, "":
+		owner := config.Owner
+		if owner == "" {
+			owner = "unknown"
+		}
+		doc = backstageEntity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       "Component",
+			Metadata: backstageMetadata{
+				Name:        config.Component,
+				Annotations: map[string]string{metricsAnnotation: strings.Join(names, ",")},
+				Metrics:     metrics,
+			},
+			Spec: backstageSpec{Type: "service", Owner: owner, System: config.System},
+		}
+	case CatalogGeneric:
+		doc = genericCatalog{Component: config.Component, Owner: config.Owner, System: config.System, Metrics: metrics}
+	default:
+		return fmt.Errorf("unknown catalog format %q", config.Format)
+	}
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
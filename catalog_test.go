@@ -0,0 +1,67 @@
+package promlinter
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCatalog(t *testing.T) {
+	inv := &Inventory{Metrics: []InventoryMetric{
+		{Name: "requests_total", Type: "counter", Help: "Requests.", Labels: []string{"code"}},
+		{Name: "queue_length", Type: "gauge"},
+		{Name: "requests_total", Type: "counter", Help: "Requests.", Labels: []string{"code"}},
+	}}
+
+	for _, tc := range []struct {
+		config   CatalogConfig
+		expected string
+	}{
+		{
+			config: CatalogConfig{Component: "api", Owner: "team-a"},
+			expected: `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: api
+  annotations:
+    promlinter.io/metrics: requests_total,queue_length
+  metrics:
+  - name: requests_total
+    type: counter
+    help: Requests.
+    labels:
+    - code
+  - name: queue_length
+    type: gauge
+spec:
+  type: service
+  owner: team-a
+`,
+		},
+		{
+			config: CatalogConfig{Format: CatalogGeneric, Component: "api", System: "shop"},
+			expected: `component: api
+system: shop
+metrics:
+- name: requests_total
+  type: counter
+  help: Requests.
+  labels:
+  - code
+- name: queue_length
+  type: gauge
+`,
+		},
+	} {
+		var buf bytes.Buffer
+		if err := WriteCatalog(&buf, inv, tc.config); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tc.expected {
+			t.Errorf("expected catalog:\n%s\ngot:\n%s", tc.expected, got)
+		}
+	}
+
+	if err := WriteCatalog(&bytes.Buffer{}, inv, CatalogConfig{}); err == nil {
+		t.Fatal("expected an error without component name")
+	}
+}
@@ -0,0 +1,166 @@
+package promlinter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is the kind of a change between two inventories.
+type ChangeKind string
+
+const (
+	MetricAdded   ChangeKind = "added"
+	MetricRemoved ChangeKind = "removed"
+	MetricRenamed ChangeKind = "renamed"
+	TypeChanged   ChangeKind = "type_changed"
+	HelpChanged   ChangeKind = "help_changed"
+	LabelAdded    ChangeKind = "label_added"
+	LabelRemoved  ChangeKind = "label_removed"
+)
+
+// Change is a single entry of a metrics changelog.
+type Change struct {
+	Kind ChangeKind
+	// Metric is the name of the metric in the new inventory, or in the old
+	// one if the metric was removed.
+	Metric string
+	// OldMetric is the previous name of a renamed metric.
+	OldMetric string
+	// Type is the metric type, or the new type if it changed.
+	Type    string
+	OldType string
+	// Label is the label added or removed.
+	Label string
+}
+
+// String returns the human-readable changelog line of the change.
+func (c Change) String() string {
+	switch c.Kind {
+	case MetricAdded:
+		return fmt.Sprintf("Added %s %s", c.Metric, c.Type)
+	case MetricRemoved:
+		return fmt.Sprintf("Removed %s %s", c.Metric, c.Type)
+	case MetricRenamed:
+		return fmt.Sprintf("Renamed %s → %s", c.OldMetric, c.Metric)
+	case TypeChanged:
+		return fmt.Sprintf("Changed type of %s from %s to %s", c.Metric, c.OldType, c.Type)
+	case HelpChanged:
+		return fmt.Sprintf("Changed help of %s", c.Metric)
+	case LabelAdded:
+		return fmt.Sprintf("Added label %s to %s", c.Label, c.Metric)
+	case LabelRemoved:
+		return fmt.Sprintf("Removed label %s from %s", c.Label, c.Metric)
+	}
+	return string(c.Kind) + " " + c.Metric
+}
+
+// Changelog compares two inventories and returns the changes made between
+// them, sorted by metric name.
+//
+// A metric which disappeared and a metric which appeared with the same type,
+// help text and labels are reported as a rename.
+func Changelog(from, to *Inventory) []Change {
+	var (
+		changes        []Change
+		added, removed []InventoryMetric
+	)
+	oldMetrics, newMetrics := from.byName(), to.byName()
+
+	for name, n := range newMetrics {
+		o, ok := oldMetrics[name]
+		if !ok {
+			added = append(added, n)
+			continue
+		}
+
+		if o.Type != n.Type {
+			changes = append(changes, Change{Kind: TypeChanged, Metric: name, Type: n.Type, OldType: o.Type})
+		}
+		if o.Help != n.Help {
+			changes = append(changes, Change{Kind: HelpChanged, Metric: name, Type: n.Type})
+		}
+		for _, l := range subtract(n.Labels, o.Labels) {
+			changes = append(changes, Change{Kind: LabelAdded, Metric: name, Type: n.Type, Label: l})
+		}
+		for _, l := range subtract(o.Labels, n.Labels) {
+			changes = append(changes, Change{Kind: LabelRemoved, Metric: name, Type: n.Type, Label: l})
+		}
+	}
+	for name, o := range oldMetrics {
+		if _, ok := newMetrics[name]; !ok {
+			removed = append(removed, o)
+		}
+	}
+
+	sortByName := func(metrics []InventoryMetric) {
+		sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	}
+	sortByName(added)
+	sortByName(removed)
+
+	renamed := make(map[string]bool)
+	for _, a := range added {
+		for _, r := range removed {
+			if renamed[r.Name] || a.Type != r.Type || a.Help != r.Help || !sameLabels(a.Labels, r.Labels) {
+				continue
+			}
+			renamed[r.Name], renamed[a.Name] = true, true
+			changes = append(changes, Change{Kind: MetricRenamed, Metric: a.Name, OldMetric: r.Name, Type: a.Type})
+			break
+		}
+	}
+	for _, a := range added {
+		if !renamed[a.Name] {
+			changes = append(changes, Change{Kind: MetricAdded, Metric: a.Name, Type: a.Type})
+		}
+	}
+	for _, r := range removed {
+		if !renamed[r.Name] {
+			changes = append(changes, Change{Kind: MetricRemoved, Metric: r.Name, Type: r.Type})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Metric == changes[j].Metric {
+			return changes[i].String() < changes[j].String()
+		}
+		return changes[i].Metric < changes[j].Metric
+	})
+	return changes
+}
+
+// FormatChangelog renders changes as a markdown list suitable for release notes.
+func FormatChangelog(changes []Change) string {
+	var sb strings.Builder
+	for _, c := range changes {
+		sb.WriteString("- ")
+		sb.WriteString(c.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// subtract returns the elements of a which are not in b.
+func subtract(a, b []string) []string {
+	var res []string
+	for _, x := range a {
+		if !contains(b, x) {
+			res = append(res, x)
+		}
+	}
+	return res
+}
+
+func sameLabels(a, b []string) bool {
+	return len(subtract(a, b)) == 0 && len(subtract(b, a)) == 0
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
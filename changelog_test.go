@@ -0,0 +1,26 @@
+package promlinter
+
+import (
+	"testing"
+)
+
+func TestChangelog(t *testing.T) {
+	from := &Inventory{Metrics: []InventoryMetric{
+		{Name: "bar_total", Type: "counter", Help: "Bar events."},
+		{Name: "qux", Type: "gauge", Help: "Qux.", Labels: []string{"region", "zone"}},
+		{Name: "old_bytes", Type: "gauge", Help: "Old."},
+	}}
+	to := &Inventory{Metrics: []InventoryMetric{
+		{Name: "baz_total", Type: "counter", Help: "Bar events."},
+		{Name: "qux", Type: "gauge", Help: "Qux.", Labels: []string{"zone"}},
+		{Name: "foo_seconds", Type: "histogram", Help: "Foo."},
+	}}
+
+	expected := "- Renamed bar_total → baz_total\n" +
+		"- Added foo_seconds histogram\n" +
+		"- Removed old_bytes gauge\n" +
+		"- Removed label region from qux\n"
+	if got := FormatChangelog(Changelog(from, to)); got != expected {
+		t.Fatalf("expected changelog:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/yeya24/promlinter"
+)
+
+// aggregateCommand holds the flags of the aggregate command.
+type aggregateCommand struct {
+	cmd *kingpin.CmdClause
+
+	inventories *[]string
+	hierarchy   *string
+	merged      *string
+	output      *string
+	failOn      *string
+}
+
+func registerAggregate(app *kingpin.Application) *aggregateCommand {
+	c := &aggregateCommand{cmd: app.Command("aggregate", "Merge the inventories of several repositories and check the metrics across them: the names exposed by several repositories, and the namespaces used by repositories not allowed to.")}
+	c.inventories = c.cmd.Arg("inventories", "Inventories written by the list command, as REPO=FILE, e.g. acme/api=api.json. The repository defaults to the file name without extension; the metrics of a merged inventory keep their repository.").Required().Strings()
+	c.hierarchy = c.cmd.Flag("hierarchy", "YAML policy of the namespaces, whose repos are the repositories allowed to define their metrics.").ExistingFile()
+	c.merged = c.cmd.Flag("merged", "Write the merged inventory to this file, with the repository of each metric.").String()
+	c.output = c.cmd.Flag("output", "Print the issues in a registered format: "+strings.Join(promlinter.FormatterNames(), ", ")+".").Short('o').Default("text").Enum(promlinter.FormatterNames()...)
+	c.failOn = c.cmd.Flag("fail-on", "Exit with code 1 if an issue of at least this severity is reported.").Default("none").Enum("error", "warning", "info", "none")
+	return c
+}
+
+func (c *aggregateCommand) run() {
+	var invs []promlinter.RepoInventory
+	for _, arg := range *c.inventories {
+		repo, path, ok := strings.Cut(arg, "=")
+		if !ok {
+			path = arg
+			repo = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		invs = append(invs, promlinter.RepoInventory{Repo: repo, Inventory: readInventory(path)})
+	}
+	merged := promlinter.MergeInventories(invs)
+
+	var h *promlinter.Hierarchy
+	if *c.hierarchy != "" {
+		var err error
+		if h, err = promlinter.LoadHierarchy(*c.hierarchy); err != nil {
+			fatalf("loading hierarchy: %v", err)
+		}
+	}
+
+	if *c.merged != "" {
+		f, err := os.Create(*c.merged)
+		if err != nil {
+			fatalf("writing merged inventory: %v", err)
+		}
+		if err := merged.Write(f); err != nil {
+			fatalf("writing merged inventory: %v", err)
+		}
+		if err := f.Close(); err != nil {
+			fatalf("writing merged inventory: %v", err)
+		}
+	}
+
+	issues := promlinter.CrossRepoIssues(merged, h)
+	f, _ := promlinter.LookupFormatter(*c.output)
+	if err := f.Format(os.Stdout, issues); err != nil {
+		fatalf("writing %s output: %v", *c.output, err)
+	}
+	for _, iss := range issues {
+		if *c.failOn != "none" && iss.Severity.AtLeast(promlinter.Severity(*c.failOn)) {
+			os.Exit(exitIssues)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/yeya24/promlinter"
+)
+
+// historyCommand holds the subcommands of the history command and their
+// flags.
+type historyCommand struct {
+	record, log, diff *kingpin.CmdClause
+
+	recordDB        *string
+	recordCommit    *string
+	recordInventory *string
+	recordPaths     *[]string
+	recordFilter    *fileFilter
+	recordPackages  *[]string
+
+	logDB     *string
+	logMetric *string
+	logLabel  *string
+
+	diffDB   *string
+	diffFrom *string
+	diffTo   *string
+}
+
+func registerHistory(app *kingpin.Application) *historyCommand {
+	cmd := app.Command("history", "Record the inventory of each commit in a local database and query the changes of the metrics across commits.")
+	c := &historyCommand{}
+
+	c.record = cmd.Command("record", "Analyze the files and record their inventory as the one of a commit.")
+	c.recordDB = c.record.Flag("db", "Path of the history database, created if it does not exist.").Required().String()
+	c.recordCommit = c.record.Flag("commit", "Revision whose inventory is recorded, resolved with git in the current directory. The files of the working tree are analyzed, so without --inventory it must be the commit checked out, and the analyzed packages must have no uncommitted changes.").Default("HEAD").String()
+	c.recordInventory = c.record.Flag("inventory", "Record this inventory written by the list command instead of analyzing files, e.g. to backfill the history from CI artifacts.").ExistingFile()
+	c.recordPaths = c.record.Arg("files", "Files, directories or package patterns, e.g. ./... or std, to parse metrics.").Strings()
+	c.recordFilter = registerFileFilter(c.record)
+	c.recordPackages = c.record.Flag("prometheus-package", prometheusPackageHelp).Strings()
+
+	c.log = cmd.Command("log", "Print the changes of the metrics made by each recorded commit, oldest first.")
+	c.logDB = c.log.Flag("db", "Path of the history database.").Required().ExistingFile()
+	c.logMetric = c.log.Flag("metric", "Only print the changes of this metric, including those made under its previous names.").String()
+	c.logLabel = c.log.Flag("label", "Only print the changes adding or removing this label, or adding a metric with it.").String()
+
+	c.diff = cmd.Command("diff", "Print the changelog between the inventories of two recorded commits.")
+	c.diffDB = c.diff.Flag("db", "Path of the history database.").Required().ExistingFile()
+	c.diffFrom = c.diff.Arg("from", "Recorded commit of the previous version, possibly abbreviated, or a revision resolved with git, e.g. HEAD~1.").Required().String()
+	c.diffTo = c.diff.Arg("to", "Recorded commit of the current version, like from.").Required().String()
+	return c
+}
+
+func (c *historyCommand) run(command string, logger *slog.Logger) {
+	switch command {
+	case c.record.FullCommand():
+		h := openHistory(*c.recordDB)
+		commit, when, err := promlinter.ResolveCommit(".", *c.recordCommit)
+		if err != nil {
+			fatalf("resolving commit: %v", err)
+		}
+		var inv *promlinter.Inventory
+		if *c.recordInventory != "" {
+			inv = readInventory(*c.recordInventory)
+		} else {
+			// The files of the working tree are analyzed, so they must be
+			// the ones of the commit.
+			head, _, err := promlinter.ResolveCommit(".", "HEAD")
+			if err != nil {
+				fatalf("resolving HEAD: %v", err)
+			}
+			if commit != head {
+				fatalf("--commit %s is not the commit checked out: check it out, or record its inventory with --inventory", *c.recordCommit)
+			}
+			files := collectFiles(*c.recordPaths, c.recordFilter)
+			if changed := uncommittedPackageFiles(files); len(changed) > 0 {
+				fatalf("the packages have uncommitted changes, so their inventory is not the one of %s: commit or stash the changes of %s", *c.recordCommit, strings.Join(changed, ", "))
+			}
+			setting := promlinter.Setting{PrometheusPackages: *c.recordPackages, Logger: logger}
+			res, err := promlinter.AnalyzeFiles(token.NewFileSet(), files, setting)
+			if err != nil {
+				fatalf("%v", err)
+			}
+			warnSyntaxErrors(logger, res.SyntaxErrors)
+			inv = promlinter.NewInventory(res.Metrics)
+		}
+		if err := h.Record(promlinter.HistoryEntry{Commit: commit, Time: when, Inventory: inv}); err != nil {
+			fatalf("recording history: %v", err)
+		}
+
+	case c.log.FullCommand():
+		for _, change := range openHistory(*c.logDB).Log(*c.logMetric, *c.logLabel) {
+			commit := change.Commit
+			if len(commit) > 12 {
+				commit = commit[:12]
+			}
+			fmt.Printf("%s %s %s\n", commit, change.Time.Format("2006-01-02"), change)
+		}
+
+	case c.diff.FullCommand():
+		h := openHistory(*c.diffDB)
+		changes, err := h.Diff(historyCommit(h, *c.diffFrom), historyCommit(h, *c.diffTo))
+		if err != nil {
+			fatalf("%v", err)
+		}
+		fmt.Print(promlinter.FormatChangelog(changes))
+	}
+}
+
+// uncommittedPackageFiles returns the uncommitted Go files of the packages of
+// files, see promlinter.UncommittedFiles, relative to the working directory
+// if possible.
+func uncommittedPackageFiles(files []string) []string {
+	dirs := make(map[string]bool)
+	for _, f := range files {
+		dirs[realDir(f)] = true
+	}
+	uncommitted, err := promlinter.UncommittedFiles(".")
+	if err != nil {
+		fatalf("listing uncommitted files: %v", err)
+	}
+	var res []string
+	for _, f := range uncommitted {
+		if dirs[realDir(f)] {
+			res = append(res, f)
+		}
+	}
+	return relativePaths(res)
+}
+
+// realDir returns the absolute path of the directory of file, with the
+// symbolic links resolved as git does.
+func realDir(file string) string {
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		return real
+	}
+	return dir
+}
+
+// historyCommit returns the recorded commit rev, or the hash of the
+// revision rev, e.g. HEAD~1, if git resolves it in the current directory.
+func historyCommit(h *promlinter.History, rev string) string {
+	if _, err := h.Lookup(rev); err == nil {
+		return rev
+	}
+	if commit, _, err := promlinter.ResolveCommit(".", rev); err == nil {
+		return commit
+	}
+	return rev
+}
+
+func openHistory(path string) *promlinter.History {
+	h, err := promlinter.OpenHistory(path)
+	if err != nil {
+		fatalf("opening history: %v", err)
+	}
+	return h
+}
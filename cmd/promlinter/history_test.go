@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistoryRecordCommit(t *testing.T) {
+	repo, git := gitRepo(t)
+	writeFile(t, repo, "app/a.go", counterSource)
+	writeFile(t, repo, "other/b.go", strings.Replace(counterSource, "package app", "package other", 1))
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	git("commit", "-q", "--allow-empty", "-m", "second")
+
+	// The working tree is not the one of the previous commit.
+	if _, stderr, code := execPromlinter(t, repo, "history", "record", "--db=history.jsonl", "--commit=HEAD~1", "app"); code != exitFailure || !bytes.Contains(stderr, []byte("--inventory")) {
+		t.Fatalf("expected recording another commit to fail, got exit code %d: %s", code, stderr)
+	}
+
+	// Uncommitted changes of the analyzed packages are refused, including
+	// new files, those of other packages ignored.
+	writeFile(t, repo, "app/new.go", "package app\n")
+	writeFile(t, repo, "other/b.go", "package other\n")
+	if _, stderr, code := execPromlinter(t, repo, "history", "record", "--db=history.jsonl", "app"); code != exitFailure || !bytes.Contains(stderr, []byte(filepath.Join("app", "new.go"))) || bytes.Contains(stderr, []byte("b.go")) {
+		t.Fatalf("expected recording uncommitted changes to fail, got exit code %d: %s", code, stderr)
+	}
+	if err := os.Remove(filepath.Join(repo, "app", "new.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	runPromlinter(t, repo, "history", "record", "--db=history.jsonl", "app")
+	// Another commit is recorded from its inventory.
+	inv, _ := promlinterOutput(t, repo, "list", "app")
+	writeFile(t, repo, "inventory.json", string(inv))
+	runPromlinter(t, repo, "history", "record", "--db=history.jsonl", "--commit=HEAD~1", "--inventory=inventory.json")
+
+	out, _ := promlinterOutput(t, repo, "history", "log", "--db=history.jsonl")
+	if lines := strings.Split(strings.TrimSpace(string(out)), "\n"); len(lines) != 1 || !strings.Contains(lines[0], "requests") {
+		t.Fatalf("expected the metric to be added by the first commit, got %q", out)
+	}
+}
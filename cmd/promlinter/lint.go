@@ -0,0 +1,582 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/yeya24/promlinter"
+)
+
+// lintCommand holds the flags of the lint command.
+type lintCommand struct {
+	cmd *kingpin.CmdClause
+
+	paths             *[]string
+	strict            *bool
+	ratchet           *string
+	ratchetPerPackage *bool
+	ratchetPerRule    *bool
+	output            *string
+	blame             *bool
+	groupBy           *string
+	summary           *string
+	failOn            *string
+	failOnRules       *[]string
+	reportOnly        *bool
+	quiet             *bool
+	count             *bool
+	maxIssues         *int
+	failFast          *bool
+	metricsTextfile   *string
+	pushgateway       *string
+	pushgatewayJob    *string
+	pushgatewayGroup  *map[string]string
+	reports           *[]string
+	staged            *bool
+	archive           *string
+	module            *string
+	tree              sourceCopy
+	reportLabels      *map[string]string
+	concurrency       *int
+	cacheDir          *string
+	lowMemory         *bool
+	enable            *[]string
+	preset            *string
+	profiles          *[]string
+	dictionaries      *[]string
+	reservedLabels    *[]string
+	allowedLabels     *[]string
+	hierarchy         *string
+	overrides         *string
+	scriptRules       *string
+	messages          *string
+	nameValidation    *string
+	nameEscaping      *string
+	daemon            *string
+	workspace         *string
+	filter            *fileFilter
+	generated         *string
+	packages          *[]string
+	ssa               *bool
+	seriesBudget      *int
+	goVersion         *string
+	similarity        *string
+	deduplicate       *bool
+	shards            *shardFlags
+	unresolvedValues  *int
+	createdTimestamps *string
+}
+
+func registerLint(app *kingpin.Application) *lintCommand {
+	c := registerLintFlags(app.Command("lint", "Lint metrics via promlint.").Default())
+	c.paths = c.cmd.Arg("files", "Files, directories or package patterns, e.g. ./... or std, to parse metrics.").Strings()
+	return c
+}
+
+// registerMod registers the mod command, which lints a module downloaded to
+// the module cache with the flags of the lint command.
+func registerMod(app *kingpin.Application) *lintCommand {
+	c := registerLintFlags(app.Command("mod", "Download a module, e.g. example.com/some/lib@v1.4.0, to the module cache and lint it, to audit the instrumentation of a dependency before adopting it."))
+	c.module = c.cmd.Arg("module", "Module path, with the version to lint after @. Defaults to the latest version.").Required().String()
+	c.paths = new([]string)
+	return c
+}
+
+func registerLintFlags(cmd *kingpin.CmdClause) *lintCommand {
+	c := &lintCommand{cmd: cmd}
+	c.strict = c.cmd.Flag("strict", "Strict mode. If true, linter will output more issues including parsing failures.").Default("false").Bool()
+	c.ratchet = c.cmd.Flag("ratchet", "Ratchet file recording the issue count. The run fails only if the count increases; the file is created or tightened otherwise.").String()
+	c.ratchetPerPackage = c.cmd.Flag("ratchet-per-package", "Record and compare the ratchet issue count per package.").Default("false").Bool()
+	c.ratchetPerRule = c.cmd.Flag("ratchet-per-rule", "Record and compare the ratchet issue count per rule.").Default("false").Bool()
+	c.output = c.cmd.Flag("output", "Print the issues in a registered format: "+strings.Join(promlinter.FormatterNames(), ", ")+".").Short('o').Default("text").Enum(promlinter.FormatterNames()...)
+	c.blame = c.cmd.Flag("blame", "Attribute each issue to the last commit which changed its line, using git blame. Only shown in the JSON output.").Default("false").Bool()
+	c.groupBy = c.cmd.Flag("group-by", "Group the reported issues by metric or by rule.").Enum("metric", "rule")
+	c.summary = c.cmd.Flag("summary", "Print a summary of the run after the issues, as text or JSON.").Enum("text", "json")
+	c.failOn = c.cmd.Flag("fail-on", "Exit with code 1 if an issue of at least this severity is reported.").Default("none").Enum("error", "warning", "info", "none")
+	c.failOnRules = c.cmd.Flag("fail-on-rule", "Exit with code 1 if an issue of the rule with the given ID or name is reported, whatever its severity. Can be repeated.").Strings()
+	c.reportOnly = c.cmd.Flag("report-only", "Always exit with code 0 once the issues are reported, ignoring --fail-on, --fail-on-rule and --ratchet.").Default("false").Bool()
+	c.quiet = c.cmd.Flag("quiet", "Do not print the issues, only their total.").Short('q').Default("false").Bool()
+	c.count = c.cmd.Flag("count", "Only print the number of issues.").Default("false").Bool()
+	c.maxIssues = c.cmd.Flag("max-issues", "Stop the analysis and fail once this number of issues is found. Zero means no limit.").Default("0").Int()
+	c.failFast = c.cmd.Flag("fail-fast", "Stop the analysis and fail at the first issue. Same as --max-issues=1.").Default("false").Bool()
+	c.metricsTextfile = c.cmd.Flag("metrics-textfile", "Write metrics about the run to this file in the text exposition format, e.g. for the node exporter textfile collector.").String()
+	c.pushgateway = c.cmd.Flag("pushgateway", "Push metrics about the run to the Pushgateway at this URL, e.g. to trend the issues of a repository.").String()
+	c.pushgatewayJob = c.cmd.Flag("pushgateway-job", "Job of the metrics pushed with --pushgateway.").Default("promlinter").String()
+	c.pushgatewayGroup = c.cmd.Flag("pushgateway-grouping", "Grouping label of the metrics pushed with --pushgateway, e.g. repo=acme/api. Can be repeated.").PlaceHolder("NAME=VALUE").StringMap()
+	c.archive = c.cmd.Flag("archive", "Lint the Go files of a tar, gzipped tar or zip archive of a source tree, e.g. written by git archive, without a checkout. The issues have the paths of the files in the archive, and no files may be given.").PlaceHolder("FILE").ExistingFile()
+	c.staged = c.cmd.Flag("staged", "Lint the version of the files staged in the git index instead of the working tree, e.g. in a pre-commit hook. Only the issues of the staged files are reported, and no files may be given.").Default("false").Bool()
+	c.reports = c.cmd.Flag("report", "Send the issues and the metadata of the run to a sink at the end of the run: webhook=URL posts them as JSON, slack=URL posts a message to a Slack incoming webhook and file=PATH appends them to a file as a line of JSON. Can be repeated.").PlaceHolder("KIND=TARGET").Strings()
+	c.reportLabels = c.cmd.Flag("report-label", "Label of the run sent with --report, e.g. repo=acme/api. Can be repeated.").PlaceHolder("NAME=VALUE").StringMap()
+	c.concurrency = c.cmd.Flag("concurrency", "Number of packages analyzed in parallel. Zero uses the number of CPUs.").Default("0").Int()
+	c.cacheDir = c.cmd.Flag("cache-dir", "Cache the analysis of each package in this directory, so unchanged packages are not analyzed again.").String()
+	c.lowMemory = c.cmd.Flag("low-memory", "Analyze one package at a time and print its issues right away, keeping only the metrics and their uses in memory. Not compatible with --group-by, --metrics-textfile and --pushgateway.").Default("false").Bool()
+	c.preset = c.cmd.Flag("preset", "Preset of rules and severities: minimal only reports the errors, default the rules which are not opt-in, strict also raises the warnings to errors and all enables every rule. --enable and --rule-overrides take precedence.").Default("default").Enum(promlinter.PresetNames()...)
+	c.profiles = c.cmd.Flag("profile", "Ecosystem profile of the service: "+strings.Join(promlinter.ProfileNames(), ", ")+". Reports the metrics using the names of the metrics of its libraries and adjusts the severities of some rules. Can be repeated.").Enums(promlinter.ProfileNames()...)
+	c.enable = c.cmd.Flag("enable", "Enable the opt-in rule with the given ID or name. Can be repeated.").Strings()
+	c.reservedLabels = c.cmd.Flag("reserved-label", "Report the ConstLabels with this name, in addition to job and instance. Can be repeated.").Strings()
+	c.allowedLabels = c.cmd.Flag("allowed-const-label", "Only allow ConstLabels with this name, reporting the others. Can be repeated.").Strings()
+	c.similarity = c.cmd.Flag("similarity-rules", "YAML file of the rules telling which metric names are near-duplicates for the opt-in NearDuplicate rule: the number of leading components and the trivial suffixes by which they may differ.").ExistingFile()
+	c.hierarchy = c.cmd.Flag("hierarchy", "YAML policy mapping namespaces to their allowed subsystems. Metrics outside of the hierarchy are reported.").ExistingFile()
+	c.overrides = c.cmd.Flag("rule-overrides", "YAML file enabling, disabling or changing the severity of rules for the metrics of some name prefixes.").ExistingFile()
+	c.nameValidation = c.cmd.Flag("name-validation", "Validate metric and label names with the legacy character set, or allow UTF-8 names as Prometheus 3.x does.").Default("legacy").Enum("legacy", "utf8")
+	c.nameEscaping = c.cmd.Flag("name-escaping", "Escaping scheme of the UTF-8 names for the clients without UTF-8 support, used with --name-validation=utf8 to report the names colliding once escaped.").Default("underscores").Enum("underscores", "dots", "values")
+	c.scriptRules = c.cmd.Flag("script-rules", "YAML file of custom rules written as text/template expressions evaluated on each metric.").ExistingFile()
+	c.messages = c.cmd.Flag("message-templates", "YAML file of text/template messages replacing the text of the issues of some rules, e.g. to link to the naming guidelines of the organization.").ExistingFile()
+	c.dictionaries = c.cmd.Flag("spell-dictionary", "Report the words of help texts missing from this word list, with one word per line. Can be repeated, e.g. for a system dictionary and the custom words of the repository.").ExistingFiles()
+	c.generated = c.cmd.Flag("generated", `How to report the issues of generated files, i.e. files with a "Code generated ... DO NOT EDIT." header: skip them, downgrade them to the info severity or include them.`).Default("skip").Enum("skip", "downgrade", "include")
+	c.filter = registerFileFilter(c.cmd)
+	c.packages = c.cmd.Flag("prometheus-package", prometheusPackageHelp).Strings()
+	c.ssa = c.cmd.Flag("ssa", "Resolve metric names by constant propagation on the SSA form of the packages, following intermediate variables, reassignments and simple function calls, and falling back to the default resolution for the other values. Slower: packages are loaded with their dependencies, which must be available.").Default("false").Bool()
+	c.workspace = c.cmd.Flag("workspace", "go.work file of a multi-module workspace. Issues are attributed to their module and metrics defined in several modules are reported. Lints every module of the workspace if no files are given.").String()
+	c.deduplicate = c.cmd.Flag("deduplicate", "Collapse the issues of a metric defined identically, with the same name, type and help, in several places, e.g. in the main package of each binary, into one issue listing the other positions. Use --no-deduplicate to report each of them. Not supported by --low-memory.").Default("true").Bool()
+	c.goVersion = c.cmd.Flag("go", "Go language version of the analyzed files, e.g. 1.21. The uses of the features of later versions are logged as syntax errors, since the declarations using them may not be analyzed. Defaults to the version promlinter was built with.").PlaceHolder("VERSION").String()
+	c.seriesBudget = c.cmd.Flag("series-budget", "Report the metric families whose estimated number of series, from the label values enumerated at their call sites, exceeds this budget. Zero disables the check.").Default("0").Int()
+	c.unresolvedValues = c.cmd.Flag("unresolved-label-values", "Number of values assumed by --series-budget for the labels whose values cannot be enumerated. Zero skips the families with such labels.").Default("0").Int()
+	c.createdTimestamps = c.cmd.Flag("created-timestamps", "Policy of the opt-in CreatedTimestamp rule for the const counters: consistent reports the counters of a namespace without created timestamp when most of the others have one, and conversely; require and forbid report the counters without or with one.").Default("consistent").Enum("consistent", "require", "forbid")
+	c.shards = registerShardFlags(c.cmd)
+	c.daemon = c.cmd.Flag("daemon", "Send the files to the promlinter daemon listening on this socket instead of analyzing them in process.").String()
+	return c
+}
+
+func (c *lintCommand) run(logger *slog.Logger) {
+	if c.lint(logger) && !*c.reportOnly {
+		os.Exit(exitIssues)
+	}
+}
+
+// lint analyzes the files, prints, shards and reports the issues and tells
+// whether the run failed. The copy of the sources linted with --staged,
+// --archive or mod is removed once it returns.
+func (c *lintCommand) lint(logger *slog.Logger) bool {
+	start := time.Now()
+	setting := promlinter.Setting{
+		Strict:             *c.strict,
+		EnabledRules:       *c.enable,
+		ReservedLabels:     *c.reservedLabels,
+		AllowedConstLabels: *c.allowedLabels,
+		NameValidation:     promlinter.NameValidation(*c.nameValidation),
+		NameEscaping:       promlinter.NameEscaping(*c.nameEscaping),
+		MaxIssues:          *c.maxIssues,
+		Concurrency:        *c.concurrency,
+		Generated:          promlinter.GeneratedPolicy(*c.generated),
+		PrometheusPackages: *c.packages,
+		SSA:                *c.ssa,
+		Deduplicate:        *c.deduplicate,
+		Logger:             logger,
+
+		SeriesBudget:          *c.seriesBudget,
+		UnresolvedLabelValues: *c.unresolvedValues,
+		CreatedTimestamps:     promlinter.CreatedTimestampPolicy(*c.createdTimestamps),
+	}
+	for _, name := range *c.profiles {
+		if err := setting.ApplyProfile(name); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	if err := setting.ApplyPreset(*c.preset); err != nil {
+		fatalf("%v", err)
+	}
+	if *c.failFast {
+		setting.MaxIssues = 1
+	}
+	if *c.goVersion != "" {
+		v, err := promlinter.ParseGoVersion(*c.goVersion)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		setting.GoVersion = v
+	}
+	if *c.hierarchy != "" {
+		h, err := promlinter.LoadHierarchy(*c.hierarchy)
+		if err != nil {
+			fatalf("loading hierarchy: %v", err)
+		}
+		setting.Hierarchy = h
+	}
+	if *c.similarity != "" {
+		rules, err := promlinter.LoadSimilarityRules(*c.similarity)
+		if err != nil {
+			fatalf("loading similarity rules: %v", err)
+		}
+		setting.SimilarityRules = rules
+	}
+	if *c.overrides != "" {
+		overrides, err := promlinter.LoadRuleOverrides(*c.overrides)
+		if err != nil {
+			fatalf("loading rule overrides: %v", err)
+		}
+		setting.RuleOverrides = overrides
+	}
+	if *c.scriptRules != "" {
+		rules, err := promlinter.LoadScriptRules(*c.scriptRules)
+		if err != nil {
+			fatalf("loading script rules: %v", err)
+		}
+		setting.ScriptRules = rules
+	}
+	if *c.messages != "" {
+		templates, err := promlinter.LoadMessageTemplates(*c.messages)
+		if err != nil {
+			fatalf("loading message templates: %v", err)
+		}
+		setting.MessageTemplates = templates
+	}
+	if len(*c.dictionaries) > 0 {
+		d, err := promlinter.LoadDictionary(*c.dictionaries...)
+		if err != nil {
+			fatalf("loading dictionary: %v", err)
+		}
+		setting.Dictionary = d
+	}
+	failOnRules := make([]string, 0, len(*c.failOnRules))
+	for _, s := range *c.failOnRules {
+		r, ok := promlinter.ResolveRule(s)
+		if !ok {
+			fatalf("--fail-on-rule: unknown rule %s", s)
+		}
+		failOnRules = append(failOnRules, r.ID)
+	}
+	reporters := make([]promlinter.Reporter, 0, len(*c.reports))
+	for _, spec := range *c.reports {
+		kind, target, _ := strings.Cut(spec, "=")
+		r, err := promlinter.NewReporter(kind, target)
+		if err != nil {
+			fatalf("--report %s: %v", spec, err)
+		}
+		reporters = append(reporters, r)
+	}
+	if *c.cacheDir != "" {
+		cache, err := promlinter.NewCache(*c.cacheDir)
+		if err != nil {
+			fatalf("creating cache: %v", err)
+		}
+		setting.Cache = cache
+	}
+	if *c.workspace != "" {
+		ws, err := promlinter.LoadWorkspace(*c.workspace)
+		if err != nil {
+			fatalf("loading workspace: %v", err)
+		}
+		setting.Workspace = ws
+		if len(*c.paths) == 0 {
+			*c.paths = relativePaths(ws.Dirs())
+		}
+	}
+
+	if *c.staged {
+		if len(*c.paths) > 0 || *c.workspace != "" {
+			fatalf("--staged lints the staged files, no files nor --workspace may be given")
+		}
+		tree, err := promlinter.CheckoutStaged(".")
+		if err != nil {
+			fatalf("%v", err)
+		}
+		c.tree = tree
+		*c.paths = tree.Paths()
+	}
+	if c.module != nil {
+		if *c.staged || *c.archive != "" || *c.workspace != "" {
+			fatalf("mod lints the files of the module, --staged, --archive and --workspace may not be given")
+		}
+		tree, err := promlinter.DownloadModule(*c.module)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		c.tree = tree
+		*c.paths = tree.Paths()
+	}
+	if *c.archive != "" {
+		if len(*c.paths) > 0 || *c.workspace != "" || *c.staged {
+			fatalf("--archive lints the files of the archive, no files nor --workspace or --staged may be given")
+		}
+		tree, err := promlinter.ExtractArchive(*c.archive)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		c.tree = tree
+		*c.paths = tree.Paths()
+	}
+	if c.tree != nil {
+		defer c.tree.Close()
+	}
+
+	var (
+		issues    []promlinter.Issue
+		summary   *promlinter.Summary
+		truncated bool
+	)
+	switch {
+	case *c.daemon != "":
+		issues, summary = c.runDaemon(setting)
+	case *c.lowMemory:
+		issues, summary, truncated = c.runLowMemory(setting)
+	default:
+		issues, summary, truncated = c.runAll(setting)
+	}
+	if !*c.count {
+		printSummary(os.Stdout, *c.summary, summary)
+	}
+	if *c.shards.dir != "" {
+		sharder := promlinter.NewSharder(promlinter.ShardBy(*c.shards.by), setting.Workspace)
+		byShard := sharder.ShardIssues(issues)
+		shards := make(map[string]bool)
+		for key := range byShard {
+			shards[key] = true
+		}
+		c.shards.writeShards(sharder, shards, c.restorePaths(collectFiles(*c.paths, c.filter)), func(key string, w io.Writer) error {
+			printJSON(w, append([]promlinter.Issue{}, byShard[key]...))
+			return nil
+		})
+	}
+
+	if len(reporters) > 0 {
+		run := &promlinter.RunReport{
+			Start:    start,
+			Duration: time.Since(start).Seconds(),
+			Paths:    c.restorePaths(*c.paths),
+			Labels:   *c.reportLabels,
+			Summary:  summary,
+			Issues:   append([]promlinter.Issue{}, issues...),
+		}
+		for i, r := range reporters {
+			if err := r.Report(run); err != nil {
+				fatalf("reporting to %s: %v", (*c.reports)[i], err)
+			}
+		}
+	}
+
+	failed := truncated
+	if truncated {
+		fmt.Fprintf(os.Stderr, "stopped after %d issues\n", len(issues))
+	}
+	failed = failed || *c.ratchet != "" && !ratchet(*c.ratchet, promlinter.NewRatchet(issues, *c.ratchetPerPackage, *c.ratchetPerRule))
+	for _, iss := range issues {
+		if contains(failOnRules, iss.RuleID) || (*c.failOn != "none" && iss.Severity.AtLeast(promlinter.Severity(*c.failOn))) {
+			failed = true
+		}
+	}
+	return failed
+}
+
+// runAll analyzes all the files at once and prints the issues.
+func (c *lintCommand) runAll(setting promlinter.Setting) ([]promlinter.Issue, *promlinter.Summary, bool) {
+	start := time.Now()
+	res, err := promlinter.AnalyzeFiles(token.NewFileSet(), collectFiles(*c.paths, c.filter), setting)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	warnSyntaxErrors(setting.Logger, res.SyntaxErrors)
+	issues := c.restore(res.Issues)
+	c.print(issues)
+
+	if *c.metricsTextfile != "" || *c.pushgateway != "" {
+		reg := prometheus.NewRegistry()
+		promlinter.NewInstrumentation(reg).ObserveRun(res, issues, time.Since(start))
+		if *c.metricsTextfile != "" {
+			if err := prometheus.WriteToTextfile(*c.metricsTextfile, reg); err != nil {
+				fatalf("writing metrics: %v", err)
+			}
+		}
+		if *c.pushgateway != "" {
+			if err := promlinter.Push(*c.pushgateway, *c.pushgatewayJob, *c.pushgatewayGroup, reg); err != nil {
+				fatalf("pushing metrics: %v", err)
+			}
+		}
+	}
+
+	return issues, promlinter.Summarize(res, issues), res.Truncated
+}
+
+// runDaemon sends the files to the daemon and prints the issues it reports.
+// Only the flags which affect the analysis are sent; the daemon ignores its
+// own.
+func (c *lintCommand) runDaemon(setting promlinter.Setting) ([]promlinter.Issue, *promlinter.Summary) {
+	if *c.lowMemory || *c.metricsTextfile != "" || *c.pushgateway != "" || *c.cacheDir != "" || setting.MaxIssues != 0 || setting.Workspace != nil || setting.Dictionary != nil || setting.Hierarchy != nil || setting.RuleOverrides != nil || setting.ScriptRules != nil || setting.MessageTemplates != nil || setting.SimilarityRules != nil || setting.SSA || len(setting.PrometheusPackages) > 0 {
+		fatalf("--daemon is not compatible with --low-memory, --metrics-textfile, --pushgateway, --cache-dir, --max-issues, --fail-fast, --workspace, --spell-dictionary, --hierarchy, --rule-overrides, --script-rules, --message-templates, --similarity-rules, --ssa and --prometheus-package")
+	}
+
+	// The daemon may run in another directory, so send absolute paths and
+	// map the positions back to the paths given on the command line.
+	var (
+		paths     []string
+		relatives = make(map[string]string)
+	)
+	for _, path := range collectFiles(*c.paths, c.filter) {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		paths = append(paths, abs)
+		relatives[abs] = path
+		relatives[filepath.Dir(abs)] = filepath.Dir(path)
+	}
+
+	client, err := promlinter.Dial(*c.daemon)
+	if err != nil {
+		fatalf("connecting to daemon: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.Lint(promlinter.LintRequest{Paths: paths, Strict: setting.Strict, DisabledRules: setting.DisabledRules, EnabledRules: setting.EnabledRules, Severities: setting.Severities, Profiles: setting.Profiles, ReservedLabels: setting.ReservedLabels, AllowedConstLabels: setting.AllowedConstLabels, NameValidation: setting.NameValidation, NameEscaping: setting.NameEscaping, SeriesBudget: setting.SeriesBudget, UnresolvedLabelValues: setting.UnresolvedLabelValues, GoVersion: setting.GoVersion, Deduplicate: setting.Deduplicate, CreatedTimestamps: setting.CreatedTimestamps, Generated: setting.Generated})
+	if err != nil {
+		fatalf("daemon: %v", err)
+	}
+	if resp.Error != "" {
+		fatalf("daemon: %s", resp.Error)
+	}
+	warnSyntaxErrors(setting.Logger, resp.SyntaxErrors)
+	for i := range resp.Issues {
+		resp.Issues[i] = resp.Issues[i].MapFilenames(func(filename string) string { return relatives[filename] })
+	}
+	for i := range resp.Summary.WorstPackages {
+		resp.Summary.WorstPackages[i].Package = relatives[resp.Summary.WorstPackages[i].Package]
+	}
+	issues := c.restore(resp.Issues)
+	c.print(issues)
+
+	return issues, resp.Summary
+}
+
+// sourceCopy is a copy of the sources to lint, such as the staged files or
+// the files of an archive.
+type sourceCopy interface {
+	Paths() []string
+	Restore(issues []promlinter.Issue) []promlinter.Issue
+	RestorePath(filename string) (string, bool)
+	Close() error
+}
+
+// restore maps the issues of the copied files back to the original files, see
+// promlinter.StagedTree.Restore and promlinter.ArchiveTree.Restore.
+func (c *lintCommand) restore(issues []promlinter.Issue) []promlinter.Issue {
+	if c.tree == nil {
+		return issues
+	}
+	return c.tree.Restore(issues)
+}
+
+// restorePaths maps the paths of the copied files back to the original
+// files, as in the issues, dropping those whose issues are not reported, e.g.
+// the files which are not staged.
+func (c *lintCommand) restorePaths(paths []string) []string {
+	if c.tree == nil {
+		return paths
+	}
+	res := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if restored, ok := c.tree.RestorePath(path); ok {
+			res = append(res, restored)
+		}
+	}
+	return res
+}
+
+// print adds the blame information to issues if requested and prints them.
+func (c *lintCommand) print(issues []promlinter.Issue) {
+	if *c.blame {
+		if err := promlinter.AddBlame(issues); err != nil {
+			fatalf("%v", err)
+		}
+	}
+
+	switch {
+	case *c.count:
+		fmt.Println(len(issues))
+	case *c.quiet:
+		fmt.Printf("%d issues\n", len(issues))
+	case *c.output == "text" && *c.groupBy == "metric":
+		printByMetric(os.Stdout, issues)
+	case *c.output == "text" && *c.groupBy == "rule":
+		printByRule(os.Stdout, issues)
+	default:
+		c.format(issues)
+	}
+}
+
+// format prints issues with the formatter selected by --output.
+func (c *lintCommand) format(issues []promlinter.Issue) {
+	f, _ := promlinter.LookupFormatter(*c.output)
+	if err := f.Format(os.Stdout, issues); err != nil {
+		fatalf("writing %s output: %v", *c.output, err)
+	}
+}
+
+// runLowMemory analyzes one package at a time and prints its issues as soon
+// as it is analyzed, then those of the module checks. The JSON output is
+// printed as one array per package (JSON lines).
+func (c *lintCommand) runLowMemory(setting promlinter.Setting) ([]promlinter.Issue, *promlinter.Summary, bool) {
+	if *c.groupBy != "" || *c.metricsTextfile != "" || *c.pushgateway != "" {
+		fatalf("--low-memory is not compatible with --group-by, --metrics-textfile and --pushgateway")
+	}
+
+	var (
+		issues []promlinter.Issue
+		header = true
+	)
+	res, err := promlinter.AnalyzeStream(collectFiles(*c.paths, c.filter), setting, func(pkgIssues []promlinter.Issue) error {
+		pkgIssues = c.restore(pkgIssues)
+		if *c.blame {
+			if err := promlinter.AddBlame(pkgIssues); err != nil {
+				return err
+			}
+		}
+
+		if !*c.count && !*c.quiet {
+			switch *c.output {
+			case "json":
+				if len(pkgIssues) > 0 {
+					printJSONLine(os.Stdout, pkgIssues)
+				}
+			case "csv":
+				if err := promlinter.WriteIssuesCSV(os.Stdout, pkgIssues, header); err != nil {
+					return err
+				}
+				header = false
+			default:
+				c.format(pkgIssues)
+			}
+		}
+		issues = append(issues, pkgIssues...)
+		return nil
+	})
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	warnSyntaxErrors(setting.Logger, res.SyntaxErrors)
+
+	switch {
+	case *c.count:
+		fmt.Println(len(issues))
+	case *c.quiet:
+		fmt.Printf("%d issues\n", len(issues))
+	}
+
+	return issues, res.Summarize(issues), res.Truncated
+}
+
+// relativePaths makes paths relative to the working directory when possible,
+// to keep the reported positions short.
+func relativePaths(paths []string) []string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return paths
+	}
+	rel := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if r, err := filepath.Rel(wd, path); err == nil {
+			path = r
+		}
+		rel = append(rel, path)
+	}
+	return rel
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeya24/promlinter"
+)
+
+// binary is the path of the promlinter binary built by TestMain.
+var binary string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "promlinter-test-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	binary = filepath.Join(dir, "promlinter")
+	if out, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "building promlinter: %v: %s", err, out)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// runPromlinter runs the binary with args in dir and returns its exit code.
+func runPromlinter(t *testing.T, dir string, args ...string) int {
+	t.Helper()
+	_, code := promlinterOutput(t, dir, args...)
+	return code
+}
+
+// promlinterOutput runs the binary with args in dir and returns its standard
+// output and exit code. The test fails if the run fails.
+func promlinterOutput(t *testing.T, dir string, args ...string) ([]byte, int) {
+	t.Helper()
+	out, stderr, code := execPromlinter(t, dir, args...)
+	if code == exitFailure {
+		t.Fatalf("promlinter %v failed: %s%s", args, out, stderr)
+	}
+	return out, code
+}
+
+// execPromlinter runs the binary with args in dir and returns its standard
+// output and error and its exit code, whatever it is.
+func execPromlinter(t *testing.T, dir string, args ...string) ([]byte, []byte, int) {
+	t.Helper()
+	cmd := exec.Command(binary, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exit *exec.ExitError
+	switch {
+	case errors.As(err, &exit):
+		return out, stderr.Bytes(), exit.ExitCode()
+	case err != nil:
+		t.Fatal(err)
+	}
+	return out, stderr.Bytes(), 0
+}
+
+// writeFile writes src to the file name of dir, creating its directory.
+func writeFile(t *testing.T, dir, name, src string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readShards returns the files of the issues of each shard of dir.
+func readShards(t *testing.T, dir string) map[string][]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards := make(map[string][]string)
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var issues []promlinter.Issue
+		if err := json.Unmarshal(data, &issues); err != nil {
+			t.Fatalf("parsing shard %s: %v", e.Name(), err)
+		}
+		files := []string{}
+		for _, iss := range issues {
+			files = append(files, filepath.ToSlash(iss.Pos.Filename))
+		}
+		shards[e.Name()] = files
+	}
+	return shards
+}
+
+// gitRepo creates a git repository and returns its directory and a function
+// running git in it. The test is skipped if git is not installed.
+func gitRepo(t *testing.T) (string, func(args ...string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	return repo, git
+}
+
+const counterSource = "package app\n\nimport \"github.com/prometheus/client_golang/prometheus\"\n\nvar c = prometheus.NewCounter(prometheus.CounterOpts{Name: \"requests\", Help: \"Help.\"})\n"
+
+func TestLintStagedShards(t *testing.T) {
+	repo, git := gitRepo(t)
+	writeFile(t, repo, "app/a.go", counterSource)
+	git("add", ".")
+
+	runPromlinter(t, repo, "lint", "--staged", "--shard-by=dir", "--shard-dir=shards")
+
+	expected := map[string][]string{"app.json": {"app/a.go"}}
+	if got := readShards(t, filepath.Join(repo, "shards")); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected the shards %v, got %v", expected, got)
+	}
+}
+
+func TestLintArchiveShards(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "src.tar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := tar.NewWriter(f)
+	if err := w.WriteHeader(&tar.Header{Name: "app/a.go", Mode: 0o644, Size: int64(len(counterSource))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(counterSource)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	runPromlinter(t, dir, "lint", "--archive=src.tar", "--shard-by=dir", "--shard-dir=shards")
+
+	expected := map[string][]string{"app.json": {"app/a.go"}}
+	if got := readShards(t, filepath.Join(dir, "shards")); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected the shards %v, got %v", expected, got)
+	}
+}
+
+func TestLintDaemonParity(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "app/a.go", counterSource)
+	writeFile(t, dir, "gen/gen.go", "// Code generated by metricsgen. DO NOT EDIT.\n\n"+strings.Replace(counterSource, "package app", "package gen", 1))
+
+	socket := filepath.Join(dir, "promlinter.sock")
+	daemon := exec.Command(binary, "daemon", "--socket="+socket)
+	if err := daemon.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		daemon.Process.Kill()
+		daemon.Wait()
+	})
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(socket); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the daemon did not listen on its socket")
+		}
+	}
+
+	for _, policy := range []string{"skip", "downgrade", "include"} {
+		args := []string{"lint", "-o", "json", "--generated=" + policy, "app", "gen"}
+		expected, expectedCode := promlinterOutput(t, dir, args...)
+		got, code := promlinterOutput(t, dir, append(args, "--daemon="+socket)...)
+		if !bytes.Equal(got, expected) || code != expectedCode {
+			t.Fatalf("expected the daemon to report with --generated=%s\n%s(exit code %d), got\n%s(exit code %d)", policy, expected, expectedCode, got, code)
+		}
+	}
+}
+
+func TestLintExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	// The counter is reported by PL003 (Counter), an error.
+	writeFile(t, dir, "app/a.go", counterSource)
+
+	for _, tc := range []struct {
+		args []string
+		code int
+	}{
+		{args: nil, code: 0},
+		{args: []string{"--fail-on=error"}, code: exitIssues},
+		{args: []string{"--fail-on=warning"}, code: exitIssues},
+		{args: []string{"--fail-on=none"}, code: 0},
+		{args: []string{"--fail-on-rule=PL003"}, code: exitIssues},
+		{args: []string{"--fail-on-rule=counter"}, code: exitIssues},
+		{args: []string{"--fail-on-rule=PL001"}, code: 0},
+		{args: []string{"--fail-on=error", "--report-only"}, code: 0},
+		{args: []string{"--fail-on-rule=PL003", "--report-only"}, code: 0},
+		// Usage errors are failures, not failing issues.
+		{args: []string{"--bogus-flag"}, code: exitFailure},
+		{args: []string{"--fail-on=errr"}, code: exitFailure},
+		{args: []string{"--fail-on-rule=bogus"}, code: exitFailure},
+	} {
+		args := append(append([]string{"lint"}, tc.args...), "app")
+		if _, stderr, code := execPromlinter(t, dir, args...); code != tc.code {
+			t.Errorf("expected promlinter %v to exit with code %d, got %d: %s", args, tc.code, code, stderr)
+		}
+	}
+}
+
+func TestLintDaemonIncompatibleFlags(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "app/a.go", counterSource)
+	socket := filepath.Join(dir, "promlinter.sock")
+
+	for _, flag := range []string{"--ssa", "--prometheus-package=example.com/metrics"} {
+		if _, stderr, code := execPromlinter(t, dir, "lint", "--daemon="+socket, flag, "app"); code != exitFailure || !bytes.Contains(stderr, []byte("not compatible")) {
+			t.Errorf("expected %s to be rejected with --daemon, got exit code %d: %s", flag, code, stderr)
+		}
+	}
+}
@@ -1,70 +1,506 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"go/ast"
-	"go/parser"
 	"go/token"
+	"io"
+	"log/slog"
+	"net"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"gopkg.in/alecthomas/kingpin.v2"
 
 	"github.com/yeya24/promlinter"
 )
 
+const prometheusPackageHelp = "Import path of a fork or internal mirror of a client_golang package, to treat like the prometheus and promauto packages. Can be repeated."
+
+// shardFlags are the flags writing a file per shard of the output.
+type shardFlags struct {
+	by  *string
+	dir *string
+}
+
+func registerShardFlags(cmd *kingpin.CmdClause) *shardFlags {
+	return &shardFlags{
+		by:  cmd.Flag("shard-by", "Split the output written to --shard-dir by Go module or by top-level directory.").Default(string(promlinter.ShardByModule)).Enum(string(promlinter.ShardByModule), string(promlinter.ShardByDir)),
+		dir: cmd.Flag("shard-dir", "Also write the output of each shard to a JSON file in this directory, so that each team can pick up its own.").String(),
+	}
+}
+
+// writeShards writes a file per shard to the shard directory, including the
+// shards of files without output. write writes the output of a shard.
+func (f *shardFlags) writeShards(sharder *promlinter.Sharder, shards map[string]bool, files []string, write func(key string, w io.Writer) error) {
+	for _, file := range files {
+		shards[sharder.Shard(file)] = true
+	}
+	if err := os.MkdirAll(*f.dir, 0o755); err != nil {
+		fatalf("creating shard directory: %v", err)
+	}
+	for key := range shards {
+		path := filepath.Join(*f.dir, promlinter.ShardFilename(key)+".json")
+		out, err := os.Create(path)
+		if err != nil {
+			fatalf("creating shard: %v", err)
+		}
+		err = write(key, out)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fatalf("writing shard %s: %v", path, err)
+		}
+	}
+}
+
+// Exit codes of promlinter.
+const (
+	exitIssues  = 1
+	exitFailure = 2
+)
+
 func main() {
 	app := kingpin.New(filepath.Base(os.Args[0]), "Prometheus metrics linter tool for golang.")
 	app.Version("v0.0.1")
 	app.HelpFlag.Short('h')
 
-	paths := app.Arg("files", "").Strings()
-	strict := app.Flag("strict", "Strict mode. If true, linter will output more issues including parsing failures.").Default("false").Bool()
+	logLevel := app.Flag("log.level", "Only log messages with the given severity or above.").Default("info").Enum("debug", "info", "warn", "error")
+	logFormat := app.Flag("log.format", "Output format of log messages.").Default("logfmt").Enum("logfmt", "json")
 
-	kingpin.MustParse(app.Parse(os.Args[1:]))
+	lint := registerLint(app)
+	mod := registerMod(app)
+	history := registerHistory(app)
+	drift := registerDrift(app)
+	metadata := registerMetadata(app)
+	aggregate := registerAggregate(app)
+	relabel := registerRelabel(app)
 
-	var files []*ast.File
-	fileSet := token.NewFileSet()
+	listCmd := app.Command("list", "List metrics as a JSON inventory.")
+	listPaths := listCmd.Arg("files", "Files, directories or package patterns, e.g. ./... or std, to parse metrics.").Strings()
+	listStrict := listCmd.Flag("strict", "Strict mode. If true, linter will output more issues including parsing failures.").Default("false").Bool()
+	listFilter := registerFileFilter(listCmd)
+	listPackages := listCmd.Flag("prometheus-package", prometheusPackageHelp).Strings()
+	listShards := registerShardFlags(listCmd)
+	listFormat := listCmd.Flag("format", "Format of the inventory printed.").Default("json").Enum("json", "csv")
 
-	for _, path := range *paths {
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			os.Exit(1)
+	usageCmd := app.Command("usage", "Report the call sites updating each metric (Inc, Add, Set, Observe, ...) as JSON.")
+	usagePaths := usageCmd.Arg("files", "Files, directories or package patterns, e.g. ./... or std, to parse metrics.").Strings()
+	usageFilter := registerFileFilter(usageCmd)
+	usagePackages := usageCmd.Flag("prometheus-package", prometheusPackageHelp).Strings()
+
+	exportCmd := app.Command("export", "Export the metrics and the issues as a SQL script, e.g. to import in SQLite with the sqlite3 shell.")
+	exportPaths := exportCmd.Arg("files", "Files, directories or package patterns, e.g. ./... or std, to parse metrics.").Strings()
+	exportRepo := exportCmd.Flag("repo", "Name of the repository, stored with each row so that the exports of several repositories can share a database.").Required().String()
+	exportFormat := exportCmd.Flag("format", "Format of the export: sql-script writes the SQL statements creating and filling the tables.").Default("sql-script").Enum("sql-script")
+	exportStrict := exportCmd.Flag("strict", "Strict mode. If true, linter will output more issues including parsing failures.").Default("false").Bool()
+	exportEnable := exportCmd.Flag("enable", "Enable the opt-in rule with the given ID or name. Can be repeated.").Strings()
+	exportFilter := registerFileFilter(exportCmd)
+	exportPackages := exportCmd.Flag("prometheus-package", prometheusPackageHelp).Strings()
+
+	manifestCmd := app.Command("manifest", "Write the manifest of the metrics as JSON: their module, package, position, constructor and registration sites.")
+	manifestPaths := manifestCmd.Arg("files", "Files, directories or package patterns, e.g. ./... or std, to parse metrics.").Strings()
+	manifestFilter := registerFileFilter(manifestCmd)
+	manifestPackages := manifestCmd.Flag("prometheus-package", prometheusPackageHelp).Strings()
+
+	explainCmd := app.Command("explain", "Explain a rule: its rationale, examples and how to fix or suppress it.")
+	explainRule := explainCmd.Arg("rule", "Rule ID or name, e.g. PL001 or Help. Lists all rules if omitted.").String()
+
+	changelogCmd := app.Command("changelog", "Generate a metrics changelog from two inventories written by the list command.")
+	changelogOld := changelogCmd.Arg("old", "Inventory of the previous version.").Required().ExistingFile()
+	changelogNew := changelogCmd.Arg("new", "Inventory of the current version.").Required().ExistingFile()
+
+	dashboardCmd := app.Command("dashboard", "Generate a starter Grafana dashboard from an inventory written by the list command.")
+	dashboardInventory := dashboardCmd.Arg("inventory", "Inventory of the metrics.").Required().ExistingFile()
+	dashboardTitle := dashboardCmd.Flag("title", "Title of the dashboard.").Default("Metrics").String()
+
+	alertsCmd := app.Command("alerts", "Generate Prometheus alerting rule stubs from an inventory written by the list command.")
+	alertsInventory := alertsCmd.Arg("inventory", "Inventory of the metrics.").Required().ExistingFile()
+	alertsGroup := alertsCmd.Flag("group", "Name of the rule group.").Default(promlinter.DefaultAlertConfig.Group).String()
+	alertsCritical := alertsCmd.Flag("critical", "Pattern of the metrics to alert on when they are absent, e.g. 'up_*'. Can be repeated.").Strings()
+	alertsErrors := alertsCmd.Flag("errors", "Pattern of the error counters to alert on when their ratio to the total counter is too high. Can be repeated.").Default(promlinter.DefaultAlertConfig.Errors...).Strings()
+	alertsRatio := alertsCmd.Flag("error-ratio", "Ratio of errors above which the error alerts fire.").Default(fmt.Sprint(promlinter.DefaultAlertConfig.ErrorRatio)).Float64()
+	alertsFor := alertsCmd.Flag("for", "How long the conditions must hold before the alerts fire.").Default(promlinter.DefaultAlertConfig.For).String()
+
+	catalogCmd := app.Command("catalog", "Export an inventory written by the list command as a service catalog fragment.")
+	catalogInventory := catalogCmd.Arg("inventory", "Inventory of the metrics.").Required().ExistingFile()
+	catalogFormat := catalogCmd.Flag("format", "Format of the fragment: a Backstage Component entity or a generic YAML document.").Default(string(promlinter.CatalogBackstage)).Enum(string(promlinter.CatalogBackstage), string(promlinter.CatalogGeneric))
+	catalogComponent := catalogCmd.Flag("component", "Name of the component exposing the metrics.").Required().String()
+	catalogOwner := catalogCmd.Flag("owner", "Team owning the component.").String()
+	catalogSystem := catalogCmd.Flag("system", "System the component belongs to.").String()
+
+	scaffoldCmd := app.Command("scaffold", "Generate the Go declarations of the metrics of an inventory, used as a spec.")
+	scaffoldInventory := scaffoldCmd.Arg("inventory", "Inventory of the metrics, e.g. written by the list command.").Required().ExistingFile()
+	scaffoldPackage := scaffoldCmd.Flag("package", "Name of the package of the generated file.").Default("metrics").String()
+
+	jsonnetCmd := app.Command("jsonnet", "Write the metrics of an inventory as a jsonnet library for monitoring mixins.")
+	jsonnetInventory := jsonnetCmd.Arg("inventory", "Inventory of the metrics, e.g. written by the list command.").Required().ExistingFile()
+
+	daemonCmd := app.Command("daemon", "Serve lint requests on a unix socket, keeping the analysis of unchanged packages in memory. Use lint --daemon to send requests.")
+	daemonSocket := daemonCmd.Flag("socket", "Path of the unix socket to listen on.").Required().String()
+	daemonConcurrency := daemonCmd.Flag("concurrency", "Number of packages analyzed in parallel per request. Zero uses the number of CPUs.").Default("0").Int()
+	daemonPackages := daemonCmd.Flag("prometheus-package", prometheusPackageHelp).Strings()
+
+	lspCmd := app.Command("lsp", "Run a Language Server Protocol server on the standard input and output, publishing diagnostics when Go files are opened or saved.")
+	lspStrict := lspCmd.Flag("strict", "Strict mode. If true, linter will output more issues including parsing failures.").Default("false").Bool()
+	lspEnable := lspCmd.Flag("enable", "Enable the opt-in rule with the given ID or name. Can be repeated.").Strings()
+	lspPackages := lspCmd.Flag("prometheus-package", prometheusPackageHelp).Strings()
+
+	// Usage errors are internal failures too: exit code 1 is kept for the
+	// failing issues.
+	parsedCmd, err := app.Parse(os.Args[1:])
+	if err != nil {
+		fatalf("%s, try --help", err)
+	}
+	logger := newLogger(*logLevel, *logFormat)
+
+	switch parsedCmd {
+	case lint.cmd.FullCommand():
+		lint.run(logger)
+	case mod.cmd.FullCommand():
+		mod.run(logger)
+	case history.record.FullCommand(), history.log.FullCommand(), history.diff.FullCommand():
+		history.run(parsedCmd, logger)
+	case drift.cmd.FullCommand():
+		drift.run(logger)
+	case metadata.cmd.FullCommand():
+		metadata.run(logger)
+	case aggregate.cmd.FullCommand():
+		aggregate.run()
+	case relabel.cmd.FullCommand():
+		relabel.run(logger)
+
+	case listCmd.FullCommand():
+		setting := promlinter.Setting{Strict: *listStrict, PrometheusPackages: *listPackages, Logger: logger}
+		files := collectFiles(*listPaths, listFilter)
+		res, err := promlinter.AnalyzeFiles(token.NewFileSet(), files, setting)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		warnSyntaxErrors(logger, res.SyntaxErrors)
+		inv := promlinter.NewInventory(res.Metrics)
+		if *listFormat == "csv" {
+			err = inv.WriteCSV(os.Stdout)
+		} else {
+			err = inv.Write(os.Stdout)
 		}
-		for f := range findFiles(path) {
-			file, err := parser.ParseFile(fileSet, f, nil, parser.AllErrors)
-			if err != nil {
-				os.Exit(1)
+		if err != nil {
+			fatalf("writing inventory: %v", err)
+		}
+		if *listShards.dir != "" {
+			sharder := promlinter.NewSharder(promlinter.ShardBy(*listShards.by), nil)
+			metrics := sharder.ShardMetrics(res.Metrics)
+			shards := make(map[string]bool)
+			for key := range metrics {
+				shards[key] = true
+			}
+			listShards.writeShards(sharder, shards, files, func(key string, w io.Writer) error {
+				return promlinter.NewInventory(metrics[key]).Write(w)
+			})
+		}
+
+	case usageCmd.FullCommand():
+		setting := promlinter.Setting{PrometheusPackages: *usagePackages, Logger: logger}
+		res, err := promlinter.AnalyzeFiles(token.NewFileSet(), collectFiles(*usagePaths, usageFilter), setting)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		warnSyntaxErrors(logger, res.SyntaxErrors)
+		if err := promlinter.NewUsageReport(res).Write(os.Stdout); err != nil {
+			fatalf("writing usage report: %v", err)
+		}
+
+	case exportCmd.FullCommand():
+		setting := promlinter.Setting{Strict: *exportStrict, EnabledRules: *exportEnable, PrometheusPackages: *exportPackages, Logger: logger}
+		res, err := promlinter.AnalyzeFiles(token.NewFileSet(), collectFiles(*exportPaths, exportFilter), setting)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		warnSyntaxErrors(logger, res.SyntaxErrors)
+		switch *exportFormat {
+		case "sql-script":
+			err = promlinter.WriteSQLScript(os.Stdout, *exportRepo, promlinter.NewInventory(res.Metrics), res.Issues)
+		}
+		if err != nil {
+			fatalf("writing export: %v", err)
+		}
+
+	case manifestCmd.FullCommand():
+		setting := promlinter.Setting{PrometheusPackages: *manifestPackages, Logger: logger}
+		res, err := promlinter.AnalyzeFiles(token.NewFileSet(), collectFiles(*manifestPaths, manifestFilter), setting)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		warnSyntaxErrors(logger, res.SyntaxErrors)
+		if err := promlinter.NewManifest(res).Write(os.Stdout); err != nil {
+			fatalf("writing manifest: %v", err)
+		}
+
+	case explainCmd.FullCommand():
+		if *explainRule == "" {
+			for _, r := range promlinter.Rules {
+				fmt.Printf("%s %s: %s\n", r.ID, r.Name, r.Summary)
 			}
-			files = append(files, file)
+			return
+		}
+
+		r, ok := promlinter.ResolveRule(*explainRule)
+		if !ok {
+			fatalf("unknown rule %s", *explainRule)
+		}
+		fmt.Print(r.Explain())
+
+	case daemonCmd.FullCommand():
+		serveDaemon(*daemonSocket, promlinter.Setting{Concurrency: *daemonConcurrency, PrometheusPackages: *daemonPackages, Logger: logger})
+
+	case lspCmd.FullCommand():
+		setting := promlinter.Setting{Strict: *lspStrict, EnabledRules: *lspEnable, PrometheusPackages: *lspPackages, Logger: logger}
+		if err := promlinter.ServeLSP(os.Stdin, os.Stdout, setting); err != nil {
+			fatalf("%v", err)
+		}
+
+	case changelogCmd.FullCommand():
+		from, to := readInventory(*changelogOld), readInventory(*changelogNew)
+		fmt.Print(promlinter.FormatChangelog(promlinter.Changelog(from, to)))
+
+	case alertsCmd.FullCommand():
+		config := promlinter.AlertConfig{Group: *alertsGroup, Critical: *alertsCritical, Errors: *alertsErrors, ErrorRatio: *alertsRatio, For: *alertsFor}
+		rules, err := promlinter.NewAlertRules(readInventory(*alertsInventory), config)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		if err := rules.Write(os.Stdout); err != nil {
+			fatalf("writing alerting rules: %v", err)
+		}
+
+	case catalogCmd.FullCommand():
+		config := promlinter.CatalogConfig{Format: promlinter.CatalogFormat(*catalogFormat), Component: *catalogComponent, Owner: *catalogOwner, System: *catalogSystem}
+		if err := promlinter.WriteCatalog(os.Stdout, readInventory(*catalogInventory), config); err != nil {
+			fatalf("writing catalog: %v", err)
+		}
+
+	case scaffoldCmd.FullCommand():
+		if err := promlinter.WriteScaffold(os.Stdout, readInventory(*scaffoldInventory), *scaffoldPackage); err != nil {
+			fatalf("writing declarations: %v", err)
+		}
+
+	case jsonnetCmd.FullCommand():
+		if err := promlinter.WriteJsonnet(os.Stdout, readInventory(*jsonnetInventory)); err != nil {
+			fatalf("writing jsonnet: %v", err)
+		}
+
+	case dashboardCmd.FullCommand():
+		if err := promlinter.NewDashboard(readInventory(*dashboardInventory), *dashboardTitle).Write(os.Stdout); err != nil {
+			fatalf("writing dashboard: %v", err)
 		}
 	}
+}
 
-	for _, iss := range promlinter.Run(fileSet, files, *strict) {
-		fmt.Printf("%s %s %s\n", iss.Pos, iss.Metric, iss.Text)
+func newLogger(level, format string) *slog.Logger {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		fatalf("invalid log level %q: %v", level, err)
 	}
+
+	opts := &slog.HandlerOptions{Level: l}
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
 }
 
-func findFiles(root string) chan string {
-	out := make(chan string)
+// fileFilter holds the flags selecting the files to analyze.
+type fileFilter struct {
+	builds        *[]string
+	tests         *bool
+	externalTests *bool
 
-	go func() {
-		defer close(out)
-		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-			sep := string(filepath.Separator)
-			if strings.HasPrefix(path, "vendor"+sep) || strings.Contains(path, sep+"vendor"+sep) {
-				return nil
-			}
-			if !info.IsDir() && !strings.HasSuffix(info.Name(), "_test.go") &&
-				strings.HasSuffix(info.Name(), ".go") {
-				out <- path
-			}
-			return nil
-		})
+	followSymlinks *bool
+	nestedModules  *bool
+	testdata       *bool
+	hidden         *bool
+}
+
+func registerFileFilter(cmd *kingpin.CmdClause) *fileFilter {
+	return &fileFilter{
+		builds:        cmd.Flag("build", "Only analyze the files built with this configuration, e.g. linux/arm64, :integration or windows/amd64:e2e,race. Can be repeated to analyze the files of every configuration. By default, build constraints are ignored.").PlaceHolder("GOOS/GOARCH:TAGS").Strings(),
+		tests:         cmd.Flag("tests", "Also analyze the _test.go files of the packages under test.").Default("false").Bool(),
+		externalTests: cmd.Flag("external-tests", "Also analyze the _test.go files of external test packages, i.e. packages with the _test suffix.").Default("false").Bool(),
+
+		followSymlinks: cmd.Flag("follow-symlinks", "Follow the symbolic links when walking directories. By default, they are skipped.").Default("false").Bool(),
+		nestedModules:  cmd.Flag("nested-modules", "Walk the subdirectories with their own go.mod file. By default, they are skipped like with ./....").Default("false").Bool(),
+		testdata:       cmd.Flag("include-testdata", "Walk the testdata directories. By default, they are skipped.").Default("false").Bool(),
+		hidden:         cmd.Flag("include-hidden", "Walk the directories whose name starts with . or _. By default, they are skipped.").Default("false").Bool(),
+	}
+}
+
+// walkOptions returns the rules of the walk of the directories.
+func (f *fileFilter) walkOptions() promlinter.WalkOptions {
+	return promlinter.WalkOptions{FollowSymlinks: *f.followSymlinks, NestedModules: *f.nestedModules, Testdata: *f.testdata, Hidden: *f.hidden}
+}
+
+// collectFiles returns the Go files found in paths, which are files,
+// directories or package patterns, and selected by filter, without
+// duplicates.
+func collectFiles(paths []string, filter *fileFilter) []string {
+	var (
+		files []string
+		seen  = make(map[string]bool)
+	)
+	add := func(f string) {
+		if clean := filepath.Clean(f); !seen[clean] {
+			seen[clean] = true
+			files = append(files, f)
+		}
+	}
+	var patterns []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil || strings.Contains(path, "...") {
+			patterns = append(patterns, path)
+			continue
+		}
+		found, err := promlinter.WalkGoFiles(path, filter.walkOptions())
 		if err != nil {
-			os.Exit(1)
+			fatalf("walking %s: %v", path, err)
+		}
+		for _, f := range found {
+			add(f)
 		}
+	}
+	// The other arguments are package patterns of the go command, e.g. ./...
+	// or example.com/mod/....
+	if len(patterns) > 0 {
+		matched, err := promlinter.LoadPatterns("", patterns, *filter.tests || *filter.externalTests)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		for _, f := range relativePaths(matched) {
+			add(f)
+		}
+	}
+
+	files, err := promlinter.FilterTestFiles(files, promlinter.TestFilePolicy{Internal: *filter.tests, External: *filter.externalTests})
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	configs := make([]promlinter.BuildConfig, 0, len(*filter.builds))
+	for _, b := range *filter.builds {
+		c, err := promlinter.ParseBuildConfig(b)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		configs = append(configs, c)
+	}
+	files, err = promlinter.FilterFiles(files, configs)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	return files
+}
+
+// warnSyntaxErrors logs the syntax errors of the files which were analyzed
+// partially.
+func warnSyntaxErrors(logger *slog.Logger, errs []promlinter.SyntaxError) {
+	for _, e := range errs {
+		logger.Warn("syntax error, file analyzed partially", "pos", e.Pos.String(), "err", e.Msg)
+	}
+}
+
+// serveDaemon serves lint requests on the unix socket at path until the
+// process is interrupted.
+func serveDaemon(path string, setting promlinter.Setting) {
+	// Remove a socket left over by a previous daemon.
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		fatalf("removing stale socket: %v", err)
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		fatalf("listening: %v", err)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		l.Close()
 	}()
 
-	return out
+	setting.Logger.Info("serving lint requests", "socket", path)
+	server := promlinter.NewServer(setting)
+	if err := server.Serve(l); err != nil && !errors.Is(err, net.ErrClosed) {
+		fatalf("serving: %v", err)
+	}
+}
+
+func readInventory(path string) *promlinter.Inventory {
+	f, err := os.Open(path)
+	if err != nil {
+		fatalf("opening inventory: %v", err)
+	}
+	defer f.Close()
+
+	inv, err := promlinter.ReadInventory(f)
+	if err != nil {
+		fatalf("reading inventory %s: %v", path, err)
+	}
+	return inv
+}
+
+// ratchet compares current with the ratchet recorded at path, and records
+// current if there is no ratchet yet or if it improves on the recorded one.
+// It returns false if the issue count increased.
+func ratchet(path string, current *promlinter.Ratchet) bool {
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		fatalf("opening ratchet: %v", err)
+	}
+
+	if err == nil {
+		recorded, err := promlinter.ReadRatchet(f)
+		f.Close()
+		if err != nil {
+			fatalf("reading ratchet %s: %v", path, err)
+		}
+
+		if regressions := recorded.Regressions(current); len(regressions) > 0 {
+			for _, r := range regressions {
+				fmt.Fprintf(os.Stderr, "ratchet: %s\n", r)
+			}
+			return false
+		}
+		if !recorded.Improved(current) {
+			return true
+		}
+	}
+
+	f, err = os.Create(path)
+	if err != nil {
+		fatalf("creating ratchet: %v", err)
+	}
+	defer f.Close()
+
+	if err := current.Write(f); err != nil {
+		fatalf("writing ratchet %s: %v", path, err)
+	}
+	return true
+}
+
+// fatalf reports an internal failure and exits with exitFailure.
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "%s: error: %s\n", filepath.Base(os.Args[0]), fmt.Sprintf(format, args...))
+	os.Exit(exitFailure)
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
 }
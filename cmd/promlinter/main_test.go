@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestReadmeUsage checks that the usage block of the README is the output of
+// promlinter -h.
+func TestReadmeUsage(t *testing.T) {
+	out, err := exec.Command(binary, "-h").CombinedOutput()
+	if err != nil {
+		t.Fatalf("promlinter -h: %v: %s", err, out)
+	}
+	readme, err := os.ReadFile("../../README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, block, ok := strings.Cut(string(readme), "``` bash\npromlinter -h\n")
+	if !ok {
+		t.Fatal("no usage block in the README")
+	}
+	block, _, _ = strings.Cut(block, "```")
+	if got, expected := strings.TrimSpace(block), strings.TrimSpace(string(out)); got != expected {
+		t.Errorf("the usage block of the README is not the output of promlinter -h, regenerate it:\n%s", expected)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+
+	"github.com/yeya24/promlinter"
+)
+
+func printJSON(w io.Writer, v interface{}) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fatalf("writing JSON: %v", err)
+	}
+}
+
+// printJSONLine prints v as JSON on a single line.
+func printJSONLine(w io.Writer, v interface{}) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fatalf("writing JSON: %v", err)
+	}
+}
+
+// printByMetric prints one entry per metric listing its definition sites and
+// its problems.
+func printByMetric(w io.Writer, issues []promlinter.Issue) {
+	for _, g := range promlinter.GroupByMetric(issues) {
+		name := g.Key
+		if name == "" {
+			name = "(no metric)"
+		}
+		fmt.Fprintf(w, "%s\n", name)
+
+		var sites, problems []string
+		for _, iss := range g.Issues {
+			for _, p := range append([]token.Position{iss.Pos}, iss.Duplicates...) {
+				if pos := p.String(); !contains(sites, pos) {
+					sites = append(sites, pos)
+				}
+			}
+			if problem := iss.RuleID + " " + iss.Text; !contains(problems, problem) {
+				problems = append(problems, problem)
+			}
+		}
+
+		fmt.Fprintf(w, "  defined at:\n")
+		for _, s := range sites {
+			fmt.Fprintf(w, "    %s\n", s)
+		}
+		fmt.Fprintf(w, "  problems:\n")
+		for _, p := range problems {
+			fmt.Fprintf(w, "    %s\n", p)
+		}
+	}
+}
+
+// printByRule prints all the issues of each rule together.
+func printByRule(w io.Writer, issues []promlinter.Issue) {
+	for _, g := range promlinter.GroupByRule(issues) {
+		name := ""
+		if r, ok := promlinter.LookupRule(g.Key); ok {
+			name = r.Name
+		}
+		fmt.Fprintf(w, "%s %s (%d issues)\n", g.Key, name, len(g.Issues))
+		for _, iss := range g.Issues {
+			fmt.Fprintf(w, "  %s %s %s\n", iss.Pos, iss.Metric, iss.Text)
+			for _, pos := range iss.Duplicates {
+				fmt.Fprintf(w, "    also at %s\n", pos)
+			}
+		}
+	}
+}
+
+// printSummary prints the summary in the given format, if any.
+func printSummary(w io.Writer, format string, summary *promlinter.Summary) {
+	switch format {
+	case "text":
+		summary.Print(w)
+	case "json":
+		printJSON(w, summary)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/yeya24/promlinter"
+)
+
+// inventoryFlags are the flags of the commands checking the inventory of the
+// code: an inventory written by the list command, or the analyzed files.
+type inventoryFlags struct {
+	inventory *string
+	paths     *[]string
+	filter    *fileFilter
+	packages  *[]string
+}
+
+func registerInventoryFlags(cmd *kingpin.CmdClause) *inventoryFlags {
+	f := &inventoryFlags{}
+	f.inventory = cmd.Flag("inventory", "Check this inventory written by the list command instead of analyzing files.").ExistingFile()
+	f.paths = cmd.Arg("files", "Files, directories or package patterns, e.g. ./... or std, to parse metrics.").Strings()
+	f.filter = registerFileFilter(cmd)
+	f.packages = cmd.Flag("prometheus-package", prometheusPackageHelp).Strings()
+	return f
+}
+
+// codeInventory returns the inventory given with --inventory, or the one of
+// the analyzed files.
+func (f *inventoryFlags) codeInventory(logger *slog.Logger) *promlinter.Inventory {
+	if *f.inventory != "" {
+		return readInventory(*f.inventory)
+	}
+	setting := promlinter.Setting{PrometheusPackages: *f.packages, Logger: logger}
+	res, err := promlinter.AnalyzeFiles(token.NewFileSet(), collectFiles(*f.paths, f.filter), setting)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	warnSyntaxErrors(logger, res.SyntaxErrors)
+	return promlinter.NewInventory(res.Metrics)
+}
+
+// prometheusFlags are the flags of the commands comparing the metrics of the
+// code with a Prometheus server.
+type prometheusFlags struct {
+	*inventoryFlags
+
+	url     *string
+	headers *map[string]string
+	jobs    *[]string
+}
+
+// registerPrometheusFlags registers the flags of cmd, whose --job flag is
+// registered by the caller.
+func registerPrometheusFlags(cmd *kingpin.CmdClause) *prometheusFlags {
+	f := &prometheusFlags{}
+	f.url = cmd.Flag("prometheus", "URL of the Prometheus server, e.g. http://prometheus:9090.").Required().String()
+	f.headers = cmd.Flag("header", "Header sent to the server, e.g. Authorization='Bearer token'. Can be repeated.").StringMap()
+	f.inventoryFlags = registerInventoryFlags(cmd)
+	return f
+}
+
+func (f *prometheusFlags) client() *promlinter.PrometheusClient {
+	return &promlinter.PrometheusClient{URL: *f.url, Headers: *f.headers}
+}
+
+// driftCommand holds the flags of the drift command.
+type driftCommand struct {
+	cmd *kingpin.CmdClause
+	*prometheusFlags
+
+	lookback  *time.Duration
+	absentFor *time.Duration
+	ignore    *[]string
+	format    *string
+}
+
+func registerDrift(app *kingpin.Application) *driftCommand {
+	c := &driftCommand{cmd: app.Command("drift", "Compare the metrics of the code with the series stored by a Prometheus server for the jobs built from it.")}
+	c.prometheusFlags = registerPrometheusFlags(c.cmd)
+	c.jobs = c.cmd.Flag("job", "Value of the job label of the targets built from the code. Can be repeated.").Required().Strings()
+	c.lookback = c.cmd.Flag("lookback", "Report the series stored during this period which the code no longer defines.").Default("1h").Duration()
+	c.absentFor = c.cmd.Flag("absent-for", "Report the metrics of the code without series during this period.").Default("168h").Duration()
+	c.ignore = c.cmd.Flag("ignore", "Prefix of the series not compared, e.g. those of another exporter of the job. Can be repeated.").Strings()
+	c.format = c.cmd.Flag("format", "Format of the drift printed.").Default("text").Enum("text", "json")
+	return c
+}
+
+func (c *driftCommand) run(logger *slog.Logger) {
+	drift, err := c.client().Drift(c.codeInventory(logger), promlinter.DriftOptions{
+		Jobs:      *c.jobs,
+		Lookback:  *c.lookback,
+		AbsentFor: *c.absentFor,
+		Ignore:    *c.ignore,
+	})
+	if err != nil {
+		fatalf("querying Prometheus: %v", err)
+	}
+
+	if *c.format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(drift); err != nil {
+			fatalf("%v", err)
+		}
+	} else {
+		for _, name := range drift.Stale {
+			fmt.Printf("%s: stored during the last %s but no longer defined in the code\n", name, *c.lookback)
+		}
+		for _, m := range drift.Absent {
+			fmt.Printf("%s: %s defined in the code but without series during the last %s\n", m.Position, m.Name, *c.absentFor)
+		}
+	}
+	if !drift.Empty() {
+		os.Exit(exitIssues)
+	}
+}
+
+// metadataCommand holds the flags of the metadata command.
+type metadataCommand struct {
+	cmd *kingpin.CmdClause
+	*prometheusFlags
+
+	output *string
+	failOn *string
+}
+
+func registerMetadata(app *kingpin.Application) *metadataCommand {
+	c := &metadataCommand{cmd: app.Command("metadata", "Report the metrics of the code whose name is exposed by other jobs of a Prometheus server with another type or help text.")}
+	c.prometheusFlags = registerPrometheusFlags(c.cmd)
+	c.jobs = c.cmd.Flag("job", "Value of the job label of the targets built from the code, whose metadata is not compared. Can be repeated.").Strings()
+	c.output = c.cmd.Flag("output", "Print the issues in a registered format: "+strings.Join(promlinter.FormatterNames(), ", ")+".").Short('o').Default("text").Enum(promlinter.FormatterNames()...)
+	c.failOn = c.cmd.Flag("fail-on", "Exit with code 1 if an issue of at least this severity is reported.").Default("none").Enum("error", "warning", "info", "none")
+	return c
+}
+
+func (c *metadataCommand) run(logger *slog.Logger) {
+	issues, err := c.client().MetadataConflicts(c.codeInventory(logger), *c.jobs)
+	if err != nil {
+		fatalf("querying Prometheus: %v", err)
+	}
+	f, _ := promlinter.LookupFormatter(*c.output)
+	if err := f.Format(os.Stdout, issues); err != nil {
+		fatalf("writing %s output: %v", *c.output, err)
+	}
+	for _, iss := range issues {
+		if *c.failOn != "none" && iss.Severity.AtLeast(promlinter.Severity(*c.failOn)) {
+			os.Exit(exitIssues)
+		}
+	}
+}
+
+// relabelCommand holds the flags of the relabel command.
+type relabelCommand struct {
+	cmd *kingpin.CmdClause
+	*inventoryFlags
+
+	config *string
+	jobs   *[]string
+	output *string
+	failOn *string
+}
+
+func registerRelabel(app *kingpin.Application) *relabelCommand {
+	c := &relabelCommand{cmd: app.Command("relabel", "Simulate the metric_relabel_configs of Prometheus scrape configs on the metrics of the code, reporting the series and labels dropped or changed at scrape time.")}
+	c.config = c.cmd.Flag("config", "Prometheus configuration file holding the scrape configs, e.g. prometheus.yml.").Required().ExistingFile()
+	c.jobs = c.cmd.Flag("job", "Job name of the scrape config scraping the code. Can be repeated. Defaults to every scrape config with metric_relabel_configs.").Strings()
+	c.inventoryFlags = registerInventoryFlags(c.cmd)
+	c.output = c.cmd.Flag("output", "Print the issues in a registered format: "+strings.Join(promlinter.FormatterNames(), ", ")+".").Short('o').Default("text").Enum(promlinter.FormatterNames()...)
+	c.failOn = c.cmd.Flag("fail-on", "Exit with code 1 if an issue of at least this severity is reported.").Default("none").Enum("error", "warning", "info", "none")
+	return c
+}
+
+func (c *relabelCommand) run(logger *slog.Logger) {
+	configs, err := promlinter.LoadScrapeConfigs(*c.config)
+	if err != nil {
+		fatalf("loading scrape configs: %v", err)
+	}
+	if len(*c.jobs) > 0 {
+		byJob := make(map[string]promlinter.ScrapeConfig, len(configs))
+		for _, sc := range configs {
+			byJob[sc.JobName] = sc
+		}
+		configs = configs[:0]
+		for _, job := range *c.jobs {
+			sc, ok := byJob[job]
+			if !ok {
+				fatalf("no scrape config with the job name %s in %s", job, *c.config)
+			}
+			configs = append(configs, sc)
+		}
+	}
+
+	issues := promlinter.SimulateRelabeling(c.codeInventory(logger), configs)
+	f, _ := promlinter.LookupFormatter(*c.output)
+	if err := f.Format(os.Stdout, issues); err != nil {
+		fatalf("writing %s output: %v", *c.output, err)
+	}
+	for _, iss := range issues {
+		if *c.failOn != "none" && iss.Severity.AtLeast(promlinter.Severity(*c.failOn)) {
+			os.Exit(exitIssues)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// implicitSuffixes are the suffixes of the series exposed by each histogram
+// and summary in addition to their quantiles, e.g. latency_seconds_count.
+var implicitSuffixes = map[dto.MetricType][]string{
+	dto.MetricType_HISTOGRAM: {"_bucket", "_sum", "_count"},
+	dto.MetricType_SUMMARY:   {"_sum", "_count"},
+}
+
+// seriesCollisions reports the metrics whose name is the name of one of the
+// implicit series of a histogram or summary, e.g. a counter named
+// latency_seconds_count next to a latency_seconds histogram: both expose the
+// same series, which the registry rejects or the scrape duplicates.
+func seriesCollisions(res *partialResult) []Issue {
+	type implicit struct {
+		metric MetricFamilyWithPos
+		suffix string
+	}
+	series := make(map[string]implicit)
+	for _, m := range res.metrics {
+		for _, suffix := range implicitSuffixes[m.MetricFamily.GetType()] {
+			name := m.MetricFamily.GetName() + suffix
+			if prev, ok := series[name]; !ok || positionLess(m.Pos, prev.metric.Pos) {
+				series[name] = implicit{m, suffix}
+			}
+		}
+	}
+
+	var issues []Issue
+	for _, m := range res.metrics {
+		name := m.MetricFamily.GetName()
+		s, ok := series[name]
+		if !ok {
+			continue
+		}
+		issues = append(issues, Issue{
+			Pos:        m.Pos,
+			Metric:     name,
+			Text:       fmt.Sprintf("metric collides with the %s series of the %s %s defined at %s", s.suffix, metricTypeName(s.metric.MetricFamily.GetType()), s.metric.MetricFamily.GetName(), s.metric.Pos),
+			RuleID:     RuleSeriesCollision,
+			Severity:   ruleSeverity(RuleSeriesCollision),
+			End:        m.End,
+			MetricType: metricTypeName(m.MetricFamily.GetType()),
+			Labels:     m.Labels(),
+		})
+	}
+	return issues
+}
+
+// reservedSuffixes are the suffixes of the series of histograms and
+// summaries.
+var reservedSuffixes = []string{"_bucket", "_sum", "_count"}
+
+// implicitSuffixNames reports the metrics named with one of the suffixes of
+// the series of histograms and summaries which the HistogramSummaryReserved
+// validation of promlint does not report: histograms and summaries, whose
+// series are then named e.g. latency_count_count, and untyped metrics, which
+// it skips. Queries such as sum(rate(latency_count[5m])) silently aggregate
+// the wrong series.
+func implicitSuffixNames(res *partialResult) []Issue {
+	var issues []Issue
+	for _, m := range res.metrics {
+		name, t := m.MetricFamily.GetName(), m.MetricFamily.GetType()
+		for _, suffix := range reservedSuffixes {
+			if !strings.HasSuffix(name, suffix) {
+				continue
+			}
+			var text string
+			switch t {
+			case dto.MetricType_HISTOGRAM, dto.MetricType_SUMMARY:
+				implicit := make([]string, 0, len(implicitSuffixes[t]))
+				for _, s := range implicitSuffixes[t] {
+					implicit = append(implicit, name+s)
+				}
+				text = fmt.Sprintf("%s name ends with %s, its series are named %s", metricTypeName(t), suffix, strings.Join(implicit, ", "))
+			case dto.MetricType_UNTYPED:
+				text = fmt.Sprintf("untyped metric name ends with %s, like the series of histograms and summaries", suffix)
+			default:
+				// Reported by promlint.
+				continue
+			}
+			issues = append(issues, Issue{
+				Pos:        m.Pos,
+				Metric:     name,
+				Text:       text,
+				RuleID:     RuleImplicitSuffix,
+				Severity:   ruleSeverity(RuleImplicitSuffix),
+				End:        m.End,
+				MetricType: metricTypeName(t),
+				Labels:     m.Labels(),
+			})
+			break
+		}
+	}
+	return issues
+}
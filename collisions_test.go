@@ -0,0 +1,54 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSeriesCollisions(t *testing.T) {
+	path := filepath.Join("testdata", "collisions", "collisions.go")
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		path + ":10:39: latency_seconds_count metric collides with the _count series of the histogram latency_seconds defined at " + path + ":6:36",
+		path + ":14:39: latency_seconds_bucket metric collides with the _bucket series of the histogram latency_seconds defined at " + path + ":6:36",
+		path + ":22:31: rpc_duration_seconds_sum metric collides with the _sum series of the summary rpc_duration_seconds defined at " + path + ":18:30",
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleSeriesCollision {
+			got = append(got, iss.Pos.String()+": "+iss.Metric+" "+iss.Text)
+		}
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestImplicitSuffixNames(t *testing.T) {
+	path := filepath.Join("testdata", "collisions", "suffixes.go")
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		path + ":6:35: jobs_queued_count histogram name ends with _count, its series are named jobs_queued_count_bucket, jobs_queued_count_sum, jobs_queued_count_count",
+		path + ":10:34: rpc_size_sum summary name ends with _sum, its series are named rpc_size_sum_sum, rpc_size_sum_count",
+		path + ":23:8: legacy_latency_bucket untyped metric name ends with _bucket, like the series of histograms and summaries",
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleImplicitSuffix {
+			got = append(got, iss.Pos.String()+": "+iss.Metric+" "+iss.Text)
+		}
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
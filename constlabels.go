@@ -0,0 +1,88 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// DefaultReservedLabels are the labels attached by Prometheus to every
+// scraped series, which ConstLabels should not use.
+var DefaultReservedLabels = []string{"job", "instance"}
+
+// constLabelKey is a key of the ConstLabels of a metric.
+type constLabelKey struct {
+	name string
+	node ast.Node
+}
+
+// constLabelKeys returns the keys of a ConstLabels expression, a
+// prometheus.Labels or map[string]string literal or a variable assigned one.
+// The keys which cannot be resolved are skipped.
+func (v *visitor) constLabelKeys(expr ast.Expr, depth int) []constLabelKey {
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if value := v.reachingValue(expr); value != nil && depth < maxCallDepth {
+			return v.constLabelKeys(value, depth+1)
+		}
+
+	case *ast.CompositeLit:
+		var keys []constLabelKey
+		for _, elt := range expr.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if name := v.labelValue(kv.Key); name != nil {
+				keys = append(keys, constLabelKey{*name, kv.Key})
+			}
+		}
+		return keys
+	}
+	return nil
+}
+
+// hasConstLabels reports whether the ConstLabels expression with the
+// resolved keys sets labels: it is assumed to if its keys could not be
+// resolved, unless it is nil or an empty literal.
+func hasConstLabels(expr ast.Expr, keys []constLabelKey) bool {
+	if len(keys) > 0 {
+		return true
+	}
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return expr.Name != "nil"
+	case *ast.CompositeLit:
+		return len(expr.Elts) > 0
+	}
+	return true
+}
+
+// reportConstLabels reports the ConstLabels keys of metric which are
+// reserved target labels, such as job and instance: Prometheus overwrites
+// them at scrape time, or renames them to exported_job unless honor_labels
+// is set, in which case they override the identity of the target. If the
+// setting lists the allowed keys, the other keys are reported too.
+func (v *visitor) reportConstLabels(metric MetricFamilyWithPos, keys []constLabelKey) {
+	for _, key := range keys {
+		var ruleID, text string
+		switch {
+		case contains(DefaultReservedLabels, key.name) || contains(v.setting.ReservedLabels, key.name):
+			ruleID, text = RuleReservedConstLabel, fmt.Sprintf("const label %q collides with the target label set at scrape time", key.name)
+		case len(v.setting.AllowedConstLabels) > 0 && !contains(v.setting.AllowedConstLabels, key.name):
+			ruleID, text = RuleConstLabelNotAllowed, fmt.Sprintf("const label %q is not allowed, use one of %s", key.name, strings.Join(v.setting.AllowedConstLabels, ", "))
+		default:
+			continue
+		}
+		v.addIssue(Issue{
+			Pos:        v.fs.Position(key.node.Pos()),
+			End:        v.fs.Position(key.node.End()),
+			Metric:     metric.MetricFamily.GetName(),
+			Text:       text,
+			RuleID:     ruleID,
+			Severity:   ruleSeverity(ruleID),
+			MetricType: metricTypeName(metric.MetricFamily.GetType()),
+			Labels:     metric.Labels(),
+		})
+	}
+}
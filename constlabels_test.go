@@ -0,0 +1,66 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReservedConstLabels(t *testing.T) {
+	paths := []string{filepath.Join("testdata", "constlabels", "constlabels.go")}
+	for _, tc := range []struct {
+		reserved []string
+		expected []string
+	}{
+		{
+			expected: []string{
+				// The keys are reported where they are written.
+				`queue_length: const label "instance" collides with the target label set at scrape time`,
+				`requests_total: const label "job" collides with the target label set at scrape time`,
+				`build_info: const label "instance" collides with the target label set at scrape time`,
+			},
+		},
+		{
+			reserved: []string{"region"},
+			expected: []string{
+				`queue_length: const label "instance" collides with the target label set at scrape time`,
+				`requests_total: const label "job" collides with the target label set at scrape time`,
+				`up: const label "region" collides with the target label set at scrape time`,
+				`build_info: const label "instance" collides with the target label set at scrape time`,
+			},
+		},
+	} {
+		res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{ReservedLabels: tc.reserved})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, iss := range res.Issues {
+			if iss.RuleID == RuleReservedConstLabel {
+				got = append(got, iss.Metric+": "+iss.Text)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("expected %q with reserved labels %q, got %q", tc.expected, tc.reserved, got)
+		}
+	}
+}
+
+func TestAllowedConstLabels(t *testing.T) {
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "constlabels", "constlabels.go")}, Setting{AllowedConstLabels: []string{"component", "version"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleConstLabelNotAllowed {
+			got = append(got, iss.Metric+": "+iss.Text)
+		}
+	}
+	// Reserved labels are only reported once, as reserved.
+	expected := []string{`up: const label "region" is not allowed, use one of component, version`}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
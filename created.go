@@ -0,0 +1,143 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// CreatedTimestampPolicy tells which counters the opt-in CreatedTimestamp
+// rule reports.
+type CreatedTimestampPolicy string
+
+const (
+	// CreatedTimestampsConsistent reports the const counters of a namespace
+	// which do not follow most of the others, with or without created
+	// timestamp. It is the default.
+	CreatedTimestampsConsistent CreatedTimestampPolicy = "consistent"
+	// CreatedTimestampsRequired reports the const counters without created
+	// timestamp.
+	CreatedTimestampsRequired CreatedTimestampPolicy = "require"
+	// CreatedTimestampsForbidden reports the const counters with a created
+	// timestamp.
+	CreatedTimestampsForbidden CreatedTimestampPolicy = "forbid"
+)
+
+// createdTimestampConstructors map the constructors of const metrics setting
+// a created timestamp to the ones which do not.
+var createdTimestampConstructors = map[string]string{
+	"MustNewConstMetricWithCreatedTimestamp": "MustNewConstMetric",
+}
+
+// hasCreatedTimestamp reports whether m is a const metric, whose created
+// timestamp is set by the code, and whether it sets one. The counters of the
+// other constructors track their creation time themselves, since
+// client_golang v1.17.
+func hasCreatedTimestamp(m MetricFamilyWithPos) (created, isConst bool) {
+	if _, ok := createdTimestampConstructors[m.Constructor]; ok {
+		return true, true
+	}
+	_, ok := constMetricArgs[m.Constructor]
+	return false, ok
+}
+
+// createdTimestampFix tells which constructor to use instead of that of m.
+func createdTimestampFix(m MetricFamilyWithPos, created bool) string {
+	if created {
+		return "use " + createdTimestampConstructors[m.Constructor]
+	}
+	for ct, plain := range createdTimestampConstructors {
+		if plain == m.Constructor {
+			return "use " + ct
+		}
+	}
+	return "set a created timestamp"
+}
+
+// metricNamespace returns the namespace of a metric name, its first component
+// with the underscore, e.g. acme_.
+func metricNamespace(name string) string {
+	if i := strings.Index(name, "_"); i > 0 {
+		return name[:i+1]
+	}
+	return ""
+}
+
+// createdTimestamps returns the check of the const counters with or without
+// created timestamps following policy. The created timestamps of the other
+// types are reported too, which make MustNewConstMetricWithCreatedTimestamp
+// panic.
+func createdTimestamps(policy CreatedTimestampPolicy) func(*partialResult) []Issue {
+	return func(res *partialResult) []Issue {
+		var issues []Issue
+		report := func(m MetricFamilyWithPos, severity Severity, text string) {
+			issues = append(issues, Issue{
+				Pos:        m.Pos,
+				Metric:     m.MetricFamily.GetName(),
+				Text:       text,
+				RuleID:     RuleCreatedTimestamp,
+				Severity:   severity,
+				End:        m.End,
+				MetricType: metricTypeName(m.MetricFamily.GetType()),
+				Labels:     m.Labels(),
+			})
+		}
+
+		type count struct{ created, total int }
+		var (
+			counters    []MetricFamilyWithPos
+			byNamespace = make(map[string]*count)
+		)
+		for _, m := range res.metrics {
+			created, isConst := hasCreatedTimestamp(m)
+			if !isConst {
+				continue
+			}
+			if t := m.MetricFamily.GetType(); t != dto.MetricType_COUNTER {
+				if created {
+					report(m, SeverityError, fmt.Sprintf("created timestamps are only supported for counters, %s panics on a %s", m.Constructor, metricTypeName(t)))
+				}
+				continue
+			}
+			counters = append(counters, m)
+			ns := metricNamespace(m.MetricFamily.GetName())
+			c := byNamespace[ns]
+			if c == nil {
+				c = &count{}
+				byNamespace[ns] = c
+			}
+			c.total++
+			if created {
+				c.created++
+			}
+		}
+
+		for _, m := range counters {
+			created, _ := hasCreatedTimestamp(m)
+			ns := metricNamespace(m.MetricFamily.GetName())
+			c := byNamespace[ns]
+			switch policy {
+			case CreatedTimestampsRequired:
+				if !created {
+					report(m, ruleSeverity(RuleCreatedTimestamp), "counter has no created timestamp, which the policy requires; "+createdTimestampFix(m, created))
+				}
+			case CreatedTimestampsForbidden:
+				if created {
+					report(m, ruleSeverity(RuleCreatedTimestamp), "counter has a created timestamp, which the policy forbids; "+createdTimestampFix(m, created))
+				}
+			default:
+				if ns == "" {
+					continue
+				}
+				switch with, without := c.created, c.total-c.created; {
+				case !created && with > without:
+					report(m, ruleSeverity(RuleCreatedTimestamp), fmt.Sprintf("counter has no created timestamp while %d of the %d const counters of the namespace %s have one; %s", with, c.total, ns, createdTimestampFix(m, created)))
+				case created && without > with:
+					report(m, ruleSeverity(RuleCreatedTimestamp), fmt.Sprintf("counter has a created timestamp while %d of the %d const counters of the namespace %s have none; %s", without, c.total, ns, createdTimestampFix(m, created)))
+				}
+			}
+		}
+		return issues
+	}
+}
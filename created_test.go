@@ -0,0 +1,60 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCreatedTimestamps(t *testing.T) {
+	path := filepath.Join("testdata", "created", "created.go")
+	enabled := []string{"CreatedTimestamp"}
+
+	for _, tc := range []struct {
+		setting  Setting
+		expected []string
+	}{
+		// The rule is opt-in.
+		{setting: Setting{}},
+		{
+			// The namespace exporter_ is split evenly, and not reported.
+			setting: Setting{EnabledRules: enabled},
+			expected: []string{
+				"acme_retries_total counter has no created timestamp while 2 of the 3 const counters of the namespace acme_ have one; use MustNewConstMetricWithCreatedTimestamp",
+				"acme_queue_length created timestamps are only supported for counters, MustNewConstMetricWithCreatedTimestamp panics on a gauge",
+			},
+		},
+		{
+			setting: Setting{EnabledRules: enabled, CreatedTimestamps: CreatedTimestampsRequired},
+			expected: []string{
+				"acme_retries_total counter has no created timestamp, which the policy requires; use MustNewConstMetricWithCreatedTimestamp",
+				"acme_queue_length created timestamps are only supported for counters, MustNewConstMetricWithCreatedTimestamp panics on a gauge",
+				"exporter_scrapes_total counter has no created timestamp, which the policy requires; use MustNewConstMetricWithCreatedTimestamp",
+			},
+		},
+		{
+			setting: Setting{EnabledRules: enabled, CreatedTimestamps: CreatedTimestampsForbidden},
+			expected: []string{
+				"acme_jobs_total counter has a created timestamp, which the policy forbids; use MustNewConstMetric",
+				"acme_errors_total counter has a created timestamp, which the policy forbids; use MustNewConstMetric",
+				"acme_queue_length created timestamps are only supported for counters, MustNewConstMetricWithCreatedTimestamp panics on a gauge",
+				"exporter_failures_total counter has a created timestamp, which the policy forbids; use MustNewConstMetric",
+			},
+		},
+	} {
+		res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, tc.setting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, iss := range res.Issues {
+			if iss.RuleID == RuleCreatedTimestamp {
+				got = append(got, iss.Metric+" "+iss.Text)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("expected %v, got %v", tc.expected, got)
+		}
+	}
+}
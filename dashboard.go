@@ -0,0 +1,158 @@
+package promlinter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Dashboard is a Grafana dashboard, in the JSON model of Grafana, with a
+// panel per metric family. It reads the metrics from a Prometheus data
+// source chosen with the datasource variable.
+type Dashboard struct {
+	Title         string            `json:"title"`
+	Tags          []string          `json:"tags,omitempty"`
+	SchemaVersion int               `json:"schemaVersion"`
+	Time          DashboardTime     `json:"time"`
+	Templating    DashboardTemplate `json:"templating"`
+	Panels        []Panel           `json:"panels"`
+}
+
+// DashboardTime is the default time range of a Dashboard.
+type DashboardTime struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DashboardTemplate holds the variables of a Dashboard.
+type DashboardTemplate struct {
+	List []DashboardVariable `json:"list"`
+}
+
+// DashboardVariable is a variable of a Dashboard.
+type DashboardVariable struct {
+	Name  string `json:"name"`
+	Label string `json:"label,omitempty"`
+	Type  string `json:"type"`
+	Query string `json:"query"`
+}
+
+// Panel is a panel of a Dashboard.
+type Panel struct {
+	ID          int             `json:"id"`
+	Type        string          `json:"type"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	GridPos     PanelGridPos    `json:"gridPos"`
+	Datasource  PanelDatasource `json:"datasource"`
+	Targets     []PanelTarget   `json:"targets"`
+}
+
+// PanelGridPos is the position and size of a Panel.
+type PanelGridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// PanelDatasource is the data source of a Panel.
+type PanelDatasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+// PanelTarget is a query of a Panel.
+type PanelTarget struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+	Format       string `json:"format,omitempty"`
+}
+
+// The panels are laid out two per row.
+const (
+	panelWidth  = 12
+	panelHeight = 8
+)
+
+// NewDashboard builds a starter dashboard titled title for the metrics of
+// inv: the per-second rate of counters, a heatmap of the buckets of
+// histograms, the average observed by summaries and the current value of
+// gauges. Counters, histograms and summaries are summed by their labels.
+func NewDashboard(inv *Inventory, title string) *Dashboard {
+	d := &Dashboard{
+		Title:         title,
+		Tags:          []string{"promlinter"},
+		SchemaVersion: 39,
+		Time:          DashboardTime{From: "now-6h", To: "now"},
+		Templating: DashboardTemplate{List: []DashboardVariable{
+			{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"},
+		}},
+		Panels: make([]Panel, 0, len(inv.Metrics)),
+	}
+
+	seen := make(map[string]bool)
+	for _, m := range inv.Metrics {
+		if seen[m.Name] {
+			continue
+		}
+		seen[m.Name] = true
+
+		i := len(d.Panels)
+		p := Panel{
+			ID:          i + 1,
+			Title:       m.Name,
+			Description: m.Help,
+			GridPos:     PanelGridPos{H: panelHeight, W: panelWidth, X: i % 2 * panelWidth, Y: i / 2 * panelHeight},
+			Datasource:  PanelDatasource{Type: "prometheus", UID: "${datasource}"},
+		}
+		by, legend := sumBy(m.Labels), legendFormat(m.Labels)
+		switch m.Type {
+		case "counter":
+			p.Type = "timeseries"
+			p.Targets = []PanelTarget{{Expr: fmt.Sprintf("sum%s(rate(%s[$__rate_interval]))", by, m.Name), LegendFormat: legend}}
+		case "histogram":
+			p.Type = "heatmap"
+			p.Targets = []PanelTarget{{Expr: fmt.Sprintf("sum by (le) (rate(%s_bucket[$__rate_interval]))", m.Name), LegendFormat: "{{le}}", Format: "heatmap"}}
+		case "summary":
+			p.Type = "timeseries"
+			p.Targets = []PanelTarget{{Expr: fmt.Sprintf("sum%[1]s(rate(%[2]s_sum[$__rate_interval])) / sum%[1]s(rate(%[2]s_count[$__rate_interval]))", by, m.Name), LegendFormat: legend}}
+		case "gauge":
+			p.Type = "stat"
+			p.Targets = []PanelTarget{{Expr: fmt.Sprintf("sum%s(%s)", by, m.Name), LegendFormat: legend}}
+		default:
+			p.Type = "timeseries"
+			p.Targets = []PanelTarget{{Expr: m.Name}}
+		}
+		p.Targets[0].RefID = "A"
+		d.Panels = append(d.Panels, p)
+	}
+	return d
+}
+
+// sumBy returns the by clause of a sum keeping labels, surrounded by spaces,
+// or an empty string if there are no labels.
+func sumBy(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return " by (" + strings.Join(labels, ", ") + ") "
+}
+
+// legendFormat returns the legend showing the values of labels.
+func legendFormat(labels []string) string {
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, l+"={{"+l+"}}")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Write encodes the dashboard as indented JSON, to import in Grafana.
+func (d *Dashboard) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
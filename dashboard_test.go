@@ -0,0 +1,48 @@
+package promlinter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestDashboard(t *testing.T) {
+	inv := &Inventory{Metrics: []InventoryMetric{
+		{Name: "requests_total", Type: "counter", Help: "Requests.", Labels: []string{"code", "method"}},
+		{Name: "request_duration_seconds", Type: "histogram", Help: "Duration of requests."},
+		{Name: "queue_length", Type: "gauge", Help: "Length of the queue."},
+		{Name: "rpc_latency_seconds", Type: "summary", Help: "RPC latency.", Labels: []string{"service"}},
+		{Name: "queue_length", Type: "gauge", Help: "Length of the queue."},
+	}}
+	d := NewDashboard(inv, "API")
+
+	expected := []struct {
+		typ, expr string
+		gridPos   PanelGridPos
+	}{
+		{"timeseries", "sum by (code, method) (rate(requests_total[$__rate_interval]))", PanelGridPos{H: 8, W: 12, X: 0, Y: 0}},
+		{"heatmap", "sum by (le) (rate(request_duration_seconds_bucket[$__rate_interval]))", PanelGridPos{H: 8, W: 12, X: 12, Y: 0}},
+		{"stat", "sum(queue_length)", PanelGridPos{H: 8, W: 12, X: 0, Y: 8}},
+		{"timeseries", "sum by (service) (rate(rpc_latency_seconds_sum[$__rate_interval])) / sum by (service) (rate(rpc_latency_seconds_count[$__rate_interval]))", PanelGridPos{H: 8, W: 12, X: 12, Y: 8}},
+	}
+	if len(d.Panels) != len(expected) {
+		t.Fatalf("expected %d panels, got %d: %+v", len(expected), len(d.Panels), d.Panels)
+	}
+	for i, p := range d.Panels {
+		if p.Type != expected[i].typ || p.Targets[0].Expr != expected[i].expr || p.GridPos != expected[i].gridPos {
+			t.Errorf("panel %d: expected %s %q at %+v, got %s %q at %+v", i, expected[i].typ, expected[i].expr, expected[i].gridPos, p.Type, p.Targets[0].Expr, p.GridPos)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Dashboard
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Title != "API" || len(decoded.Panels) != len(expected) {
+		t.Fatalf("dashboard did not round-trip: %+v", decoded)
+	}
+}
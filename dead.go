@@ -0,0 +1,193 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// registrationFuncs are the methods of prometheus.Registerer, and the
+// functions of the prometheus package, taking collectors to register.
+var registrationFuncs = map[string]bool{
+	"Register":     true,
+	"MustRegister": true,
+	"Unregister":   true,
+}
+
+// holder returns the key of the variable or struct field to which the result
+// of the constructor call is assigned, or an empty string if it is not
+// assigned to one, e.g. when the metric is returned or passed to a function.
+func (v *visitor) holder(call *ast.CallExpr) string {
+	path := v.types.enclosing(call.Pos())
+	for i, n := range path {
+		if n != call || i+1 == len(path) {
+			continue
+		}
+
+		switch parent := path[i+1].(type) {
+		case *ast.AssignStmt:
+			for j, rhs := range parent.Rhs {
+				if rhs == call && j < len(parent.Lhs) {
+					return v.referenceKey(parent.Lhs[j], true)
+				}
+			}
+		case *ast.ValueSpec:
+			for j, value := range parent.Values {
+				if value == call && j < len(parent.Names) {
+					return v.referenceKey(parent.Names[j], true)
+				}
+			}
+		case *ast.KeyValueExpr:
+			// &metrics{requests: prometheus.NewCounter(...)}
+			if key, ok := parent.Key.(*ast.Ident); ok && parent.Value == call {
+				v.defined[key] = true
+				return "field:" + key.Name
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// referenceKey returns the key of the variable or field referred to by expr,
+// or an empty string if it is not a variable or a field. Package variables
+// are identified by their package name, fields by their name only, since the
+// types of other packages are unknown. define marks expr as the definition of
+// a holder, which is not a reference.
+func (v *visitor) referenceKey(expr ast.Expr, define bool) string {
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if define {
+			v.defined[expr] = true
+		}
+		obj := v.types.info.Defs[expr]
+		if obj == nil {
+			obj = v.types.info.Uses[expr]
+		}
+		return v.objectKey(obj)
+
+	case *ast.SelectorExpr:
+		if define {
+			v.defined[expr.Sel] = true
+		}
+		if x, ok := expr.X.(*ast.Ident); ok {
+			if pkg, ok := v.types.info.Uses[x].(*types.PkgName); ok {
+				return pkg.Imported().Name() + "." + expr.Sel.Name
+			}
+		}
+		return "field:" + expr.Sel.Name
+	}
+	return ""
+}
+
+func (v *visitor) objectKey(obj types.Object) string {
+	variable, ok := obj.(*types.Var)
+	switch {
+	case !ok:
+		return ""
+	case variable.IsField():
+		return "field:" + variable.Name()
+	case variable.Pkg() != nil && variable.Parent() == variable.Pkg().Scope():
+		return variable.Pkg().Name() + "." + variable.Name()
+	}
+	return "local:" + v.fs.Position(variable.Pos()).String()
+}
+
+// collectReferences records the variables and fields referenced in file,
+// apart from the definitions of holders, and those registered.
+func (v *visitor) collectReferences(file *ast.File) {
+	var visit func(ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			v.parseWrite(n)
+			v.parseTimer(n)
+			v.parseLabelSite(n)
+			v.parseOTelInstrument(n)
+			if !registrationFuncs[funcName(n.Fun)] {
+				return true
+			}
+			ast.Inspect(n.Fun, visit)
+			for _, arg := range n.Args {
+				if key := v.referenceKey(arg, false); key != "" {
+					v.registrations[key] = append(v.registrations[key], v.fs.Position(n.Pos()))
+				} else {
+					ast.Inspect(arg, visit)
+				}
+			}
+			return false
+
+		case *ast.SelectorExpr:
+			if v.defined[n.Sel] {
+				ast.Inspect(n.X, visit)
+				return false
+			}
+			if key := v.referenceKey(n, false); key != "" {
+				v.references[key] = true
+			}
+
+		case *ast.Ident:
+			if !v.defined[n] {
+				if key := v.objectKey(v.types.info.Uses[n]); key != "" {
+					v.references[key] = true
+				}
+			}
+		}
+		return true
+	}
+	ast.Inspect(file, visit)
+}
+
+func funcName(fun ast.Expr) string {
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+// deadMetrics reports the registered metrics whose holder is never
+// referenced, so that their value is never updated. Exported holders are not
+// reported, since they may be used by packages which were not analyzed.
+func deadMetrics(res *partialResult) []Issue {
+	var issues []Issue
+	for _, m := range res.metrics {
+		if m.holder == "" || isExportedKey(m.holder) || res.references[m.holder] || !(m.AutoRegistered || len(res.registrations[m.holder]) > 0) {
+			continue
+		}
+		issues = append(issues, Issue{
+			Pos:        m.Pos,
+			Metric:     m.MetricFamily.GetName(),
+			Text:       "metric is registered but its value is never updated",
+			RuleID:     RuleDeadMetric,
+			Severity:   ruleSeverity(RuleDeadMetric),
+			End:        m.End,
+			MetricType: metricTypeName(m.MetricFamily.GetType()),
+			Labels:     m.Labels(),
+		})
+	}
+	return issues
+}
+
+// isExportedKey reports whether the holder key names an exported package
+// variable or field.
+func isExportedKey(key string) bool {
+	if strings.HasPrefix(key, "local:") {
+		return false
+	}
+	return token.IsExported(key[strings.LastIndexAny(key, ".:")+1:])
+}
+
+// sortedSet returns the keys of a set in order.
+func sortedSet(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
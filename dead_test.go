@@ -0,0 +1,61 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDeadMetrics(t *testing.T) {
+	paths := []string{
+		filepath.Join("testdata", "dead", "dead.go"),
+		filepath.Join("testdata", "dead", "use.go"),
+	}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"retries_total", "queued", "local_total"}; !reflect.DeepEqual(deadMetricNames(res), expected) {
+		t.Fatalf("expected dead metrics %v, got %v", expected, deadMetricNames(res))
+	}
+
+	var streamed []string
+	if _, err := AnalyzeStream(paths, Setting{}, func(issues []Issue) error {
+		streamed = append(streamed, deadMetricNames(&Result{Issues: issues})...)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"retries_total", "queued", "local_total"}; !reflect.DeepEqual(streamed, expected) {
+		t.Fatalf("expected the streamed analysis to report the dead metrics %v, got %v", expected, streamed)
+	}
+
+	// Without the file using them, the other metrics are dead too.
+	res, err = AnalyzeFiles(token.NewFileSet(), paths[:1], Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"requests_total", "retries_total", "errors_total", "latency_seconds", "inflight", "queued", "local_total"}; !reflect.DeepEqual(deadMetricNames(res), expected) {
+		t.Fatalf("expected dead metrics %v, got %v", expected, deadMetricNames(res))
+	}
+
+	res, err = AnalyzeFiles(token.NewFileSet(), paths[:1], Setting{DisabledRules: []string{RuleDeadMetric}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := deadMetricNames(res); len(names) != 0 {
+		t.Fatalf("unexpected dead metrics with the rule disabled: %v", names)
+	}
+}
+
+func deadMetricNames(res *Result) []string {
+	var names []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleDeadMetric {
+			names = append(names, iss.Metric)
+		}
+	}
+	return names
+}
@@ -0,0 +1,97 @@
+package promlinter
+
+import (
+	"go/token"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// deduplicate collapses the issues of a metric defined identically, with the
+// same name, type and help text, in several places, e.g. in the main package
+// of each binary of a repository: the issues with the same rule and text are
+// reported once, at the first position, with the other positions in
+// Issue.Duplicates, each listed once. issues must be sorted.
+func deduplicate(issues []Issue, metrics []MetricFamilyWithPos) []Issue {
+	identical := identicalMetrics(metrics)
+
+	type key struct{ metric, rule, text string }
+	var (
+		res   = issues[:0:0]
+		first = make(map[key]int)
+	)
+	for _, iss := range issues {
+		if !identical[iss.Metric] {
+			res = append(res, iss)
+			continue
+		}
+		k := key{iss.Metric, iss.RuleID, iss.Text}
+		if i, ok := first[k]; ok {
+			// The constructors sharing an opts value report the same
+			// definition site.
+			if iss.Pos != res[i].Pos && !containsPosition(res[i].Duplicates, iss.Pos) {
+				res[i].Duplicates = append(res[i].Duplicates, iss.Pos)
+			}
+			continue
+		}
+		first[k] = len(res)
+		res = append(res, iss)
+	}
+	return res
+}
+
+// containsPosition reports whether positions holds pos.
+func containsPosition(positions []token.Position, pos token.Position) bool {
+	for _, p := range positions {
+		if p == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// identicalMetrics returns the names of the metrics defined several times,
+// always with the same type and help text.
+func identicalMetrics(metrics []MetricFamilyWithPos) map[string]bool {
+	type definition struct {
+		typ  dto.MetricType
+		help string
+	}
+	var (
+		defs        = make(map[string]definition)
+		count       = make(map[string]int)
+		conflicting = make(map[string]bool)
+	)
+	for _, m := range metrics {
+		name := m.MetricFamily.GetName()
+		d := definition{m.MetricFamily.GetType(), m.MetricFamily.GetHelp()}
+		if prev, ok := defs[name]; !ok {
+			defs[name] = d
+		} else if prev != d {
+			conflicting[name] = true
+		}
+		count[name]++
+	}
+
+	names := make(map[string]bool)
+	for name, n := range count {
+		if n > 1 && !conflicting[name] {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// duplicatesText formats the positions of the duplicates of an issue, e.g.
+// " (also at cmd/b/main.go:10:2, cmd/c/main.go:10:2)", or an empty string if
+// it has none.
+func duplicatesText(duplicates []token.Position) string {
+	if len(duplicates) == 0 {
+		return ""
+	}
+	positions := make([]string, 0, len(duplicates))
+	for _, pos := range duplicates {
+		positions = append(positions, pos.String())
+	}
+	return " (also at " + strings.Join(positions, ", ") + ")"
+}
@@ -0,0 +1,73 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDeduplicate(t *testing.T) {
+	var paths []string
+	for _, b := range []string{"a", "b", "c"} {
+		paths = append(paths, filepath.Join("testdata", "dedup", "cmd", b, "main.go"))
+	}
+
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Issues) != 6 {
+		t.Fatalf("expected an issue per definition, got %v", res.Issues)
+	}
+
+	res, err = AnalyzeFiles(token.NewFileSet(), paths, Setting{Deduplicate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string][]string)
+	for _, iss := range res.Issues {
+		positions := []string{iss.Pos.String()}
+		for _, pos := range iss.Duplicates {
+			positions = append(positions, pos.String())
+		}
+		got[iss.Metric] = append(got[iss.Metric], positions...)
+	}
+	expected := map[string][]string{
+		// The identical definitions are collapsed.
+		"dedup_jobs": {paths[0] + ":6:8", paths[1] + ":6:8", paths[2] + ":6:8"},
+		// The help texts differ: the issues are reported separately.
+		"dedup_tasks": {paths[0] + ":11:8", paths[1] + ":11:8", paths[2] + ":11:8"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if len(res.Issues) != 4 {
+		t.Errorf("expected one issue for dedup_jobs and three for dedup_tasks, got %v", res.Issues)
+	}
+}
+
+func TestDeduplicateSharedOpts(t *testing.T) {
+	// Both constructors report the issue at the name of the shared opts.
+	paths := []string{filepath.Join("testdata", "dedup", "shared", "shared.go")}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{Deduplicate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Issues) != 1 {
+		t.Fatalf("expected one issue for the shared opts, got %v", res.Issues)
+	}
+	if iss := res.Issues[0]; len(iss.Duplicates) != 0 {
+		t.Errorf("expected no duplicate of the position %s, got %v", iss.Pos, iss.Duplicates)
+	}
+}
+
+func TestDuplicatesText(t *testing.T) {
+	if got := duplicatesText(nil); got != "" {
+		t.Errorf("expected no text, got %q", got)
+	}
+	got := duplicatesText([]token.Position{{Filename: "b.go", Line: 1, Column: 2}, {Filename: "c.go", Line: 3, Column: 4}})
+	if expected := " (also at b.go:1:2, c.go:3:4)"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,233 @@
+package promlinter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusClient queries the HTTP API of a Prometheus server.
+type PrometheusClient struct {
+	// URL is the address of the server, e.g. http://prometheus:9090.
+	URL     string
+	Headers map[string]string
+	// Client sends the requests; it defaults to a client with a timeout of
+	// 30 seconds.
+	Client *http.Client
+}
+
+// apiResponse is the envelope of the responses of the API.
+type apiResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+}
+
+// get queries the API endpoint path, e.g. /api/v1/metadata, and decodes the
+// data of the response into data.
+func (c *PrometheusClient) get(path string, params url.Values, data interface{}) error {
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	u := strings.TrimSuffix(c.URL, "/") + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	for name, value := range c.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var r apiResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("%s: %s: %s", u, resp.Status, strings.TrimSpace(string(body[:min(len(body), 512)])))
+		}
+		return fmt.Errorf("%s: %v", u, err)
+	}
+	if r.Status != "success" {
+		return fmt.Errorf("%s: %s: %s", u, r.ErrorType, r.Error)
+	}
+	return json.Unmarshal(r.Data, data)
+}
+
+// MetricNames returns the names of the series matching one of the selectors
+// between start and end.
+func (c *PrometheusClient) MetricNames(selectors []string, start, end time.Time) ([]string, error) {
+	params := url.Values{
+		"match[]": selectors,
+		"start":   {formatAPITime(start)},
+		"end":     {formatAPITime(end)},
+	}
+	var names []string
+	if err := c.get("/api/v1/label/__name__/values", params, &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func formatAPITime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', 3, 64)
+}
+
+// DriftOptions configures the comparison of the metrics of the code with the
+// series of a Prometheus server.
+type DriftOptions struct {
+	// Jobs are the values of the job label of the targets built from the
+	// analyzed code.
+	Jobs []string
+	// Lookback is how far back the series are looked up to find the ones no
+	// longer defined in the code; it defaults to an hour, so that series
+	// removed by an earlier deploy are not reported.
+	Lookback time.Duration
+	// AbsentFor is how long a metric of the code may have no series before it
+	// is reported as absent; it defaults to a week.
+	AbsentFor time.Duration
+	// Ignore are the prefixes of the series not compared, e.g. those of
+	// exporters running in the same job; the series of the standard
+	// collectors of client_golang and those added by the scrape are always
+	// ignored.
+	Ignore []string
+	// Now is the end of the compared period; it defaults to the current time.
+	Now time.Time
+}
+
+// Drift is the difference between the metrics defined in the code and the
+// series stored by a Prometheus server.
+type Drift struct {
+	// Stale are the names of the series stored during the lookback period
+	// which no metric of the code exposes, e.g. after a rename.
+	Stale []string `json:"stale"`
+	// Absent are the metrics of the code which exposed no series during the
+	// absent period, e.g. because they are never updated or registered.
+	Absent []InventoryMetric `json:"absent"`
+}
+
+// Empty reports whether the code and the server agree.
+func (d *Drift) Empty() bool {
+	return len(d.Stale) == 0 && len(d.Absent) == 0
+}
+
+// scrapeSeries are the series added by Prometheus to each scraped target.
+var scrapeSeries = regexp.MustCompile(`^(up|scrape_[a-z_]+)$`)
+
+// exposedNames returns the names of the series exposed by a metric of the
+// inventory: those of its samples, the implicit series of histograms and
+// summaries, and the _created series of the exposition formats with created
+// timestamps.
+func exposedNames(m InventoryMetric) []string {
+	names := []string{m.Name}
+	for t, suffixes := range implicitSuffixes {
+		if metricTypeName(t) != m.Type {
+			continue
+		}
+		for _, suffix := range suffixes {
+			names = append(names, m.Name+suffix)
+		}
+	}
+	switch m.Type {
+	case "counter", "histogram", "summary":
+		names = append(names, strings.TrimSuffix(m.Name, "_total")+"_created")
+	}
+	return names
+}
+
+// Drift compares the metrics of inv with the series stored by the server for
+// the jobs of opts: the series of the jobs stored during the lookback period
+// but exposed by no metric of inv, and the metrics of inv without series
+// during the absent period.
+func (c *PrometheusClient) Drift(inv *Inventory, opts DriftOptions) (*Drift, error) {
+	if len(opts.Jobs) == 0 {
+		return nil, fmt.Errorf("comparing with Prometheus without job")
+	}
+	if opts.Lookback == 0 {
+		opts.Lookback = time.Hour
+	}
+	if opts.AbsentFor == 0 {
+		opts.AbsentFor = 7 * 24 * time.Hour
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	jobs := make([]string, len(opts.Jobs))
+	for i, job := range opts.Jobs {
+		jobs[i] = regexp.QuoteMeta(job)
+	}
+	selector := fmt.Sprintf("{job=~%q}", strings.Join(jobs, "|"))
+
+	recent, err := c.MetricNames([]string{selector}, opts.Now.Add(-opts.Lookback), opts.Now)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := c.MetricNames([]string{selector}, opts.Now.Add(-opts.AbsentFor), opts.Now)
+	if err != nil {
+		return nil, err
+	}
+	storedSet := make(map[string]bool, len(stored))
+	for _, name := range stored {
+		storedSet[name] = true
+	}
+
+	ignored := func(name string) bool {
+		if scrapeSeries.MatchString(name) {
+			return true
+		}
+		for _, rc := range runtimeCollectors {
+			if strings.HasPrefix(name, rc.prefix) {
+				return true
+			}
+		}
+		for _, prefix := range opts.Ignore {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	d := &Drift{}
+	exposed := make(map[string]bool)
+	for _, m := range inv.Metrics {
+		if exposed[m.Name] {
+			continue
+		}
+		names := exposedNames(m)
+		found := false
+		for _, name := range names {
+			exposed[name] = true
+			found = found || storedSet[name]
+		}
+		if !found && !ignored(m.Name) {
+			d.Absent = append(d.Absent, m)
+		}
+	}
+	for _, name := range recent {
+		if !exposed[name] && !ignored(name) {
+			d.Stale = append(d.Stale, name)
+		}
+	}
+	sort.Strings(d.Stale)
+	sort.Slice(d.Absent, func(i, j int) bool { return d.Absent[i].Name < d.Absent[j].Name })
+	return d, nil
+}
@@ -0,0 +1,74 @@
+package promlinter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDrift(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var selectors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/label/__name__/values" {
+			http.NotFound(w, r)
+			return
+		}
+		selectors = append(selectors, r.URL.Query()["match[]"]...)
+		start, _ := strconv.ParseFloat(r.URL.Query().Get("start"), 64)
+		names := []string{"up", "scrape_duration_seconds", "go_goroutines", "http_requests_total", "request_duration_seconds_bucket", "request_duration_seconds_count", "legacy_requests_total"}
+		if now.Sub(time.Unix(int64(start), 0)) > time.Hour {
+			names = append(names, "queue_length", "removed_last_month_total")
+		}
+		fmt.Fprintf(w, `{"status":"success","data":["%s"]}`, strings.Join(names, `","`))
+	}))
+	defer server.Close()
+
+	inv := &Inventory{Metrics: []InventoryMetric{
+		{Name: "http_requests_total", Type: "counter"},
+		{Name: "request_duration_seconds", Type: "histogram"},
+		{Name: "queue_length", Type: "gauge"},
+		{Name: "jobs_failed_total", Type: "counter"},
+		{Name: "go_custom", Type: "gauge"},
+	}}
+	c := &PrometheusClient{URL: server.URL}
+	d, err := c.Drift(inv, DriftOptions{Jobs: []string{"api", "api.canary"}, Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"legacy_requests_total"}; !reflect.DeepEqual(d.Stale, want) {
+		t.Errorf("expected stale series %v, got %v", want, d.Stale)
+	}
+	if len(d.Absent) != 1 || d.Absent[0].Name != "jobs_failed_total" {
+		t.Errorf("expected jobs_failed_total to be absent, got %v", d.Absent)
+	}
+	if want := `{job=~"api|api\\.canary"}`; len(selectors) != 2 || selectors[0] != want {
+		t.Errorf("expected the selector %s, got %v", want, selectors)
+	}
+
+	d, err = c.Drift(inv, DriftOptions{Jobs: []string{"api"}, Ignore: []string{"legacy_", "jobs_"}, Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Empty() {
+		t.Errorf("expected the ignored prefixes to be skipped, got %+v", d)
+	}
+}
+
+func TestDriftError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"invalid parameter \"match[]\""}`)
+	}))
+	defer server.Close()
+
+	_, err := (&PrometheusClient{URL: server.URL}).Drift(&Inventory{}, DriftOptions{Jobs: []string{"api"}})
+	if err == nil || !strings.Contains(err.Error(), "bad_data: invalid parameter") {
+		t.Fatalf("expected the error of the API, got %v", err)
+	}
+}
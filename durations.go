@@ -0,0 +1,99 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// durationMethods map the methods of time.Duration converting it to a number
+// to the unit of the result.
+var durationMethods = map[string]string{
+	"Hours":        "hours",
+	"Minutes":      "minutes",
+	"Seconds":      "seconds",
+	"Milliseconds": "milliseconds",
+	"Microseconds": "microseconds",
+	"Nanoseconds":  "nanoseconds",
+}
+
+// nameUnits map the suffixes of metric names to the time unit they declare.
+var nameUnits = []struct {
+	suffix, unit string
+}{
+	{"_hours", "hours"},
+	{"_minutes", "minutes"},
+	{"_seconds", "seconds"},
+	{"_milliseconds", "milliseconds"},
+	{"_ms", "milliseconds"},
+	{"_microseconds", "microseconds"},
+	{"_nanoseconds", "nanoseconds"},
+}
+
+// durationUnit returns the time unit of the value of expr if it converts a
+// duration to a number, e.g. "milliseconds" for time.Since(start).Milliseconds()
+// or float64(d.Milliseconds()). The dependencies are not loaded, so any call
+// of a method named like those of time.Duration without arguments is taken
+// as one.
+func (v *visitor) durationUnit(expr ast.Expr) string {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		if ident, ok := ast.Unparen(expr).(*ast.Ident); ok {
+			if value := v.reachingValue(ident); value != nil {
+				return v.durationUnit(value)
+			}
+		}
+		return ""
+	}
+	// Conversions, e.g. float64(d.Milliseconds()).
+	if len(call.Args) == 1 && v.types.info.Types[call.Fun].IsType() {
+		return v.durationUnit(call.Args[0])
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && len(call.Args) == 0 {
+		return durationMethods[sel.Sel.Name]
+	}
+	return ""
+}
+
+// nameUnit returns the time unit declared by the suffix of a metric name, if
+// any, ignoring the _total suffix of counters.
+func nameUnit(name string) string {
+	name = strings.TrimSuffix(name, "_total")
+	for _, u := range nameUnits {
+		if strings.HasSuffix(name, u.suffix) {
+			return u.unit
+		}
+	}
+	return ""
+}
+
+// unitMismatches reports the calls updating a metric with a duration in a
+// different unit than the one of its name, e.g. a _seconds histogram
+// observing time.Since(start).Milliseconds().
+func unitMismatches(res *partialResult) []Issue {
+	byHolder := metricsByHolder(res)
+
+	var issues []Issue
+	for _, w := range res.writes {
+		if w.Unit == "" {
+			continue
+		}
+		for _, m := range byHolder[w.Holder] {
+			unit := nameUnit(m.MetricFamily.GetName())
+			if unit == "" || unit == w.Unit {
+				continue
+			}
+			issues = append(issues, Issue{
+				Pos:        w.Pos,
+				Metric:     m.MetricFamily.GetName(),
+				Text:       fmt.Sprintf("%s called with a duration in %s, but the metric name declares %s", w.Method, w.Unit, unit),
+				RuleID:     RuleUnitMismatch,
+				Severity:   ruleSeverity(RuleUnitMismatch),
+				End:        w.Pos,
+				MetricType: metricTypeName(m.MetricFamily.GetType()),
+				Labels:     m.Labels(),
+			})
+		}
+	}
+	return issues
+}
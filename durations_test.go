@@ -0,0 +1,30 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUnitMismatch(t *testing.T) {
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "durations", "durations.go")}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]int)
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleUnitMismatch {
+			got[iss.Metric] = iss.Pos.Line
+		}
+	}
+	expected := map[string]int{
+		"request_duration_seconds":    30,
+		"query_duration_milliseconds": 31,
+		"wait_seconds_total":          34,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected unit mismatches %v, got %v: %v", expected, got, res.Issues)
+	}
+}
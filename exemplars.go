@@ -0,0 +1,183 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+	"unicode/utf8"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// exemplarMethod is a method updating a metric with an exemplar.
+type exemplarMethod struct {
+	// iface is the interface of client_golang declaring the method, plain
+	// the method updating the metric without exemplar, and metricType the
+	// only type implementing the interface.
+	iface, plain string
+	metricType   dto.MetricType
+}
+
+// exemplarMethods are the methods updating a metric with an exemplar.
+var exemplarMethods = map[string]exemplarMethod{
+	"AddWithExemplar":     {"ExemplarAdder", "Add", dto.MetricType_COUNTER},
+	"ObserveWithExemplar": {"ExemplarObserver", "Observe", dto.MetricType_HISTOGRAM},
+}
+
+// isExemplarInterface reports whether name is one of the interfaces of
+// client_golang declaring the exemplar methods.
+func isExemplarInterface(name string) bool {
+	for _, m := range exemplarMethods {
+		if m.iface == name {
+			return true
+		}
+	}
+	return false
+}
+
+// plainMethod returns the method updating a metric like method, without its
+// exemplar, e.g. Observe for ObserveWithExemplar.
+func plainMethod(method string) string {
+	if m, ok := exemplarMethods[method]; ok {
+		return m.plain
+	}
+	return method
+}
+
+const (
+	// exemplarMaxRunes is prometheus.ExemplarMaxRunes, the maximum length of
+	// the names and values of the labels of an exemplar.
+	exemplarMaxRunes = 128
+	// exemplarValueRunes is the length assumed for the values of the labels
+	// of an exemplar which are not constant, the length of a trace ID.
+	exemplarValueRunes = 32
+)
+
+// exemplarLabels is the label set of an exemplar.
+type exemplarLabels struct {
+	Pos token.Position `json:"pos"`
+	// Names are the resolved label names, and Runes the length of the names
+	// and of the constant values. Dynamic is the number of values which are
+	// not constant, and Unresolved is true if some names could not be
+	// resolved.
+	Names      []string `json:"names,omitempty"`
+	Runes      int      `json:"runes"`
+	Dynamic    int      `json:"dynamic,omitempty"`
+	Unresolved bool     `json:"unresolved,omitempty"`
+}
+
+// exemplarLabels resolves the prometheus.Labels of an exemplar: a literal, a
+// variable assigned one, or nil.
+func (v *visitor) exemplarLabels(expr ast.Expr, depth int) *exemplarLabels {
+	l := &exemplarLabels{Pos: v.fs.Position(expr.Pos())}
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if e.Name == "nil" {
+			return l
+		}
+		if value := v.reachingValue(e); value != nil && depth < maxCallDepth {
+			resolved := v.exemplarLabels(value, depth+1)
+			resolved.Pos = l.Pos
+			return resolved
+		}
+
+	case *ast.CompositeLit:
+		for _, elt := range e.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				l.Unresolved = true
+				continue
+			}
+			name := v.labelValue(kv.Key)
+			if name == nil {
+				l.Unresolved = true
+				continue
+			}
+			l.Names = append(l.Names, *name)
+			l.Runes += utf8.RuneCountInString(*name)
+			if value := v.labelValue(kv.Value); value != nil {
+				l.Runes += utf8.RuneCountInString(*value)
+			} else {
+				l.Dynamic++
+			}
+		}
+		return l
+	}
+	l.Unresolved = true
+	return l
+}
+
+// pluralType returns the plural of the name of the metric type t, e.g.
+// summaries.
+func pluralType(t dto.MetricType) string {
+	if t == dto.MetricType_SUMMARY {
+		return "summaries"
+	}
+	return metricTypeName(t) + "s"
+}
+
+// exemplarIssues reports the exemplar methods called on the metrics whose
+// type does not implement them, whose type assertion panics, and the label
+// sets of the exemplars which are invalid or longer than prometheus.ExemplarMaxRunes,
+// which make the call panic, or which leave little room for the values which
+// are not constant.
+func exemplarIssues(res *partialResult) []Issue {
+	byHolder := metricsByHolder(res)
+
+	var issues []Issue
+	for _, w := range res.writes {
+		method, ok := exemplarMethods[w.Method]
+		metrics := byHolder[w.Holder]
+		if !ok || len(metrics) == 0 {
+			continue
+		}
+		for _, m := range metrics {
+			if t := m.MetricFamily.GetType(); t != method.metricType {
+				issues = append(issues, Issue{
+					Pos:        w.Pos,
+					Metric:     m.MetricFamily.GetName(),
+					Text:       fmt.Sprintf("%s do not implement prometheus.%s, only %s do: the type assertion to call %s panics", pluralType(t), method.iface, pluralType(method.metricType), w.Method),
+					RuleID:     RuleExemplarType,
+					Severity:   ruleSeverity(RuleExemplarType),
+					End:        w.Pos,
+					MetricType: metricTypeName(t),
+					Labels:     m.Labels(),
+				})
+			}
+		}
+
+		l := w.Exemplar
+		if l == nil {
+			continue
+		}
+		m := metrics[0]
+		report := func(severity Severity, text string) {
+			issues = append(issues, Issue{
+				Pos:        l.Pos,
+				Metric:     m.MetricFamily.GetName(),
+				Text:       text,
+				RuleID:     RuleExemplarLabels,
+				Severity:   severity,
+				End:        l.Pos,
+				MetricType: metricTypeName(m.MetricFamily.GetType()),
+				Labels:     m.Labels(),
+			})
+		}
+		invalid := false
+		for _, name := range l.Names {
+			if !isLegacyName(name, true) || strings.HasPrefix(name, "__") {
+				report(SeverityError, fmt.Sprintf("exemplar label name %q is invalid, %s panics", name, w.Method))
+				invalid = true
+			}
+		}
+		switch {
+		case invalid || l.Unresolved:
+		case l.Runes > exemplarMaxRunes:
+			report(SeverityError, fmt.Sprintf("exemplar labels are %d runes long, more than the %d allowed: %s panics", l.Runes, exemplarMaxRunes, w.Method))
+		case l.Runes+l.Dynamic*exemplarValueRunes > exemplarMaxRunes:
+			report(ruleSeverity(RuleExemplarLabels), fmt.Sprintf("exemplar labels leave %d of the %d runes allowed to %d values which are not constant, and %s panics if they are longer; keep the exemplar to a trace ID", exemplarMaxRunes-l.Runes, exemplarMaxRunes, l.Dynamic, w.Method))
+		}
+	}
+	return issues
+}
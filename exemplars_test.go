@@ -0,0 +1,42 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExemplarIssues(t *testing.T) {
+	path := filepath.Join("testdata", "exemplars", "exemplars.go")
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		path + ":31:2: PL046 acme_queue_duration_seconds summaries do not implement prometheus.ExemplarObserver, only histograms do: the type assertion to call ObserveWithExemplar panics (error)",
+		path + ":32:2: PL046 acme_requests_in_flight gauges do not implement prometheus.ExemplarAdder, only counters do: the type assertion to call AddWithExemplar panics (error)",
+		path + ":35:2: PL018 acme_request_duration_seconds observing the negative value -1 (warning)",
+		path + ":35:64: PL047 acme_request_duration_seconds exemplar labels leave 91 of the 128 runes allowed to 4 values which are not constant, and ObserveWithExemplar panics if they are longer; keep the exemplar to a trace ID (warning)",
+		path + ":36:79: PL047 acme_requests_total exemplar label name \"trace-id\" is invalid, AddWithExemplar panics (error)",
+		path + ":37:79: PL047 acme_requests_total exemplar labels are 168 runes long, more than the 128 allowed: AddWithExemplar panics (error)",
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		switch iss.RuleID {
+		case RuleExemplarType, RuleExemplarLabels, RuleSuspiciousObservation:
+			got = append(got, iss.Pos.String()+": "+iss.RuleID+" "+iss.Metric+" "+iss.Text+" ("+string(iss.Severity)+")")
+		}
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, got)
+	}
+
+	usage := NewUsageReport(res)
+	for _, m := range usage.Metrics {
+		if m.Name == "acme_requests_total" && m.Writes != 3 {
+			t.Errorf("expected the 3 calls with exemplars to be reported as writes of %s, got %v", m.Name, m.Sites)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package promlinter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// sqlSchema creates the tables of WriteSQLScript. The rows of each repository are
+// replaced when its dump is imported again.
+const sqlSchema = `CREATE TABLE IF NOT EXISTS metrics (
+  repo TEXT NOT NULL,
+  name TEXT NOT NULL,
+  type TEXT NOT NULL,
+  help TEXT,
+  position TEXT,
+  series INTEGER
+);
+CREATE TABLE IF NOT EXISTS metric_labels (
+  repo TEXT NOT NULL,
+  metric TEXT NOT NULL,
+  label TEXT NOT NULL,
+  position INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS issues (
+  repo TEXT NOT NULL,
+  file TEXT,
+  line INTEGER,
+  col INTEGER,
+  metric TEXT,
+  rule_id TEXT NOT NULL,
+  severity TEXT NOT NULL,
+  text TEXT NOT NULL
+);
+`
+
+// WriteSQLScript writes the metrics of inv and the issues as a SQL script
+// which creates and fills the tables metrics, metric_labels and issues, e.g.
+// to import in SQLite with sqlite3 promlinter.db < dump.sql. It does not
+// write the database itself. The rows are
+// tagged with repo, so that the dumps of many repositories can be imported
+// in the same database; importing a dump again replaces the rows of its
+// repository.
+func WriteSQLScript(w io.Writer, repo string, inv *Inventory, issues []Issue) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "BEGIN TRANSACTION;")
+	fmt.Fprint(bw, sqlSchema)
+	for _, table := range []string{"metrics", "metric_labels", "issues"} {
+		fmt.Fprintf(bw, "DELETE FROM %s WHERE repo = %s;\n", table, sqlString(repo))
+	}
+
+	for _, m := range inv.Metrics {
+		series := "NULL"
+		if m.Series > 0 {
+			series = fmt.Sprint(m.Series)
+		}
+		fmt.Fprintf(bw, "INSERT INTO metrics VALUES (%s, %s, %s, %s, %s, %s);\n",
+			sqlString(repo), sqlString(m.Name), sqlString(m.Type), sqlString(m.Help), sqlString(m.Position), series)
+		for i, label := range m.Labels {
+			fmt.Fprintf(bw, "INSERT INTO metric_labels VALUES (%s, %s, %s, %d);\n", sqlString(repo), sqlString(m.Name), sqlString(label), i)
+		}
+	}
+	for _, iss := range issues {
+		fmt.Fprintf(bw, "INSERT INTO issues VALUES (%s, %s, %d, %d, %s, %s, %s, %s);\n",
+			sqlString(repo), sqlString(iss.Pos.Filename), iss.Pos.Line, iss.Pos.Column, sqlString(iss.Metric),
+			sqlString(iss.RuleID), sqlString(string(iss.Severity)), sqlString(iss.Text))
+	}
+	fmt.Fprintln(bw, "COMMIT;")
+	return bw.Flush()
+}
+
+// sqlString quotes s as a SQL string literal.
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// WriteIssuesCSV writes issues as CSV rows, with a header row first if
+// header is true, e.g. to triage them in a spreadsheet. The labels of the
+// metric are separated by spaces.
+func WriteIssuesCSV(w io.Writer, issues []Issue, header bool) error {
+	cw := csv.NewWriter(w)
+	if header {
+		if err := cw.Write([]string{"file", "line", "column", "rule_id", "severity", "metric", "metric_type", "labels", "module", "text"}); err != nil {
+			return err
+		}
+	}
+	for _, iss := range issues {
+		record := []string{
+			iss.Pos.Filename, strconv.Itoa(iss.Pos.Line), strconv.Itoa(iss.Pos.Column),
+			iss.RuleID, string(iss.Severity), iss.Metric, iss.MetricType, strings.Join(iss.Labels, " "), iss.Module, iss.Text,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCSV writes the metrics of the inventory as CSV rows, after a header
+// row. The labels are separated by spaces, and the series column is empty if
+// the number of series could not be estimated.
+func (inv *Inventory) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "type", "help", "labels", "position", "series"}); err != nil {
+		return err
+	}
+	for _, m := range inv.Metrics {
+		series := ""
+		if m.Series > 0 {
+			series = strconv.Itoa(m.Series)
+		}
+		if err := cw.Write([]string{m.Name, m.Type, m.Help, strings.Join(m.Labels, " "), m.Position, series}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,78 @@
+package promlinter
+
+import (
+	"bytes"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestWriteSQLScript(t *testing.T) {
+	inv := &Inventory{Metrics: []InventoryMetric{
+		{Name: "requests_total", Type: "counter", Help: "Requests of the user's session.", Labels: []string{"code", "method"}, Position: "main.go:10:2", Series: 4},
+	}}
+	issues := []Issue{
+		{Pos: token.Position{Filename: "main.go", Line: 10, Column: 2}, Metric: "requests_total", RuleID: RuleHelp, Severity: SeverityWarning, Text: "no help text"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSQLScript(&buf, "acme/api", inv, issues); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, expected := range []string{
+		"DELETE FROM metrics WHERE repo = 'acme/api';\n",
+		"INSERT INTO metrics VALUES ('acme/api', 'requests_total', 'counter', 'Requests of the user''s session.', 'main.go:10:2', 4);\n",
+		"INSERT INTO metric_labels VALUES ('acme/api', 'requests_total', 'method', 1);\n",
+		"INSERT INTO issues VALUES ('acme/api', 'main.go', 10, 2, 'requests_total', 'PL001', 'warning', 'no help text');\n",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("expected the script to contain %q, got:\n%s", expected, got)
+		}
+	}
+	if !strings.HasPrefix(got, "BEGIN TRANSACTION;\n") || !strings.HasSuffix(got, "COMMIT;\n") {
+		t.Errorf("expected the script to run in a transaction, got:\n%s", got)
+	}
+}
+
+func TestWriteIssuesCSV(t *testing.T) {
+	issues := []Issue{
+		{Pos: token.Position{Filename: "main.go", Line: 10, Column: 2}, Metric: "requests_total", MetricType: "counter", Labels: []string{"code", "method"}, RuleID: RuleHelp, Severity: SeverityWarning, Text: `help "text", missing`},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteIssuesCSV(&buf, issues, true); err != nil {
+		t.Fatal(err)
+	}
+	expected := "file,line,column,rule_id,severity,metric,metric_type,labels,module,text\n" +
+		`main.go,10,2,PL001,warning,requests_total,counter,code method,,"help ""text"", missing"` + "\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	buf.Reset()
+	if err := WriteIssuesCSV(&buf, issues, false); err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasPrefix(buf.String(), "file,") {
+		t.Errorf("expected no header, got:\n%s", buf.String())
+	}
+}
+
+func TestInventoryWriteCSV(t *testing.T) {
+	inv := &Inventory{Metrics: []InventoryMetric{
+		{Name: "requests_total", Type: "counter", Help: "Requests.", Labels: []string{"code", "method"}, Position: "main.go:10:2", Series: 4},
+		{Name: "up", Type: "gauge", Help: "Up.", Position: "main.go:12:2"},
+	}}
+
+	var buf bytes.Buffer
+	if err := inv.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := "name,type,help,labels,position,series\n" +
+		"requests_total,counter,Requests.,code method,main.go:10:2,4\n" +
+		"up,gauge,Up.,,main.go:12:2,\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
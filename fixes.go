@@ -0,0 +1,227 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SuggestedFix is a change of the source fixing an issue, e.g. the rename of
+// a metric to its base unit. The language server offers them as code
+// actions.
+type SuggestedFix struct {
+	Message string     `json:"message"`
+	Edits   []TextEdit `json:"edits"`
+}
+
+// TextEdit replaces the source between Pos and End with NewText.
+type TextEdit struct {
+	Pos     token.Position `json:"pos"`
+	End     token.Position `json:"end"`
+	NewText string         `json:"new_text"`
+}
+
+// baseUnitText matches the text of the problems of the MetricUnits validation
+// of promlint.
+var baseUnitText = regexp.MustCompile(`^use base unit "([a-z]+)" instead of "([a-z]+)"$`)
+
+// nonBaseUnit returns the unit and the base unit of a problem of the
+// MetricUnits validation.
+func nonBaseUnit(text string) (unit, base string, ok bool) {
+	match := baseUnitText.FindStringSubmatch(text)
+	if match == nil {
+		return "", "", false
+	}
+	return match[2], match[1], true
+}
+
+// renameUnit replaces the component unit of name by base, e.g.
+// request_latency_seconds for request_latency_milliseconds.
+func renameUnit(name, unit, base string) string {
+	components := strings.Split(name, "_")
+	for i, c := range components {
+		if c == unit {
+			components[i] = base
+			break
+		}
+	}
+	return strings.Join(components, "_")
+}
+
+// unitFactors are the sizes of the units of promlint in their base unit. The
+// units whose conversion is not a factor, such as fahrenheit, are missing.
+var unitFactors = map[string]float64{
+	"amperes": 1,
+	"bytes":   1,
+	"celsius": 1,
+	"grams":   1,
+	"joules":  1,
+	"kelvin":  1,
+	"meters":  1,
+	"metres":  1,
+	"seconds": 1,
+	"volts":   1,
+
+	"minutes":  60,
+	"hours":    3600,
+	"days":     86400,
+	"weeks":    604800,
+	"kelvins":  1,
+	"inches":   0.0254,
+	"yards":    0.9144,
+	"miles":    1609.344,
+	"bits":     0.125,
+	"calories": 4.184,
+	"pounds":   453.59237,
+	"ounces":   28.349523125,
+}
+
+// unitPrefixFactors are the factors of the unit prefixes of promlint,
+// including its spelling mibi.
+var unitPrefixFactors = map[string]float64{
+	"pico": 1e-12, "nano": 1e-9, "micro": 1e-6, "milli": 1e-3, "centi": 1e-2, "deci": 1e-1,
+	"deca": 1e1, "hecto": 1e2, "kilo": 1e3, "mega": 1e6, "giga": 1e9, "tera": 1e12, "peta": 1e15,
+	"kibi": 1 << 10, "mebi": 1 << 20, "mibi": 1 << 20, "gibi": 1 << 30, "tebi": 1 << 40, "pebi": 1 << 50,
+}
+
+// unitFactor returns the size of unit in its base unit, e.g. 0.001 for
+// milliseconds.
+func unitFactor(unit string) (float64, bool) {
+	if f, ok := unitFactors[unit]; ok {
+		return f, true
+	}
+	for prefix, pf := range unitPrefixFactors {
+		if f, ok := unitFactors[strings.TrimPrefix(unit, prefix)]; ok && strings.HasPrefix(unit, prefix) {
+			return pf * f, true
+		}
+	}
+	return 0, false
+}
+
+// conversionText tells how to convert a value in a unit of the given size to
+// the base unit, e.g. "divide it by 1000" for milliseconds.
+func conversionText(factor float64) string {
+	if factor < 1 {
+		if inv := 1 / factor; math.Abs(inv-math.Round(inv)) < 1e-9*inv {
+			return "divide it by " + strconv.FormatFloat(math.Round(inv), 'f', -1, 64)
+		}
+	}
+	return "multiply it by " + strconv.FormatFloat(factor, 'f', -1, 64)
+}
+
+// baseUnitFixes returns the fix of an issue of the MetricUnits validation
+// renaming the metric to its base unit, if its name is set by a string
+// literal holding the unit.
+func (v *visitor) baseUnitFixes(m MetricFamilyWithPos, unit, base string) []SuggestedFix {
+	lit := v.nameLiteral(m)
+	if lit == nil {
+		return nil
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return nil
+	}
+	renamed := renameUnit(value, unit, base)
+	if renamed == value {
+		return nil
+	}
+	return []SuggestedFix{{
+		Message: fmt.Sprintf("Rename to %s", renameUnit(m.MetricFamily.GetName(), unit, base)),
+		Edits: []TextEdit{{
+			Pos:     v.fs.Position(lit.Pos()),
+			End:     v.fs.Position(lit.End()),
+			NewText: strconv.Quote(renamed),
+		}},
+	}}
+}
+
+// nameLiteral returns the string literal setting the name of m, in its Opts
+// or in its Desc, or nil if the name is set otherwise.
+func (v *visitor) nameLiteral(m MetricFamilyWithPos) *ast.BasicLit {
+	if m.call == nil || len(m.call.Args) == 0 || !m.nameSpan.pos.IsValid() {
+		return nil
+	}
+	exprs := v.fieldExprs(m.call.Args[0], "Name")
+	if desc := v.descCall(m.call.Args[0]); desc != nil && len(desc.Args) == 4 {
+		exprs = desc.Args[:1]
+	}
+	if len(exprs) != 1 {
+		return nil
+	}
+	lit, ok := ast.Unparen(exprs[0]).(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING || v.fs.Position(lit.Pos()) != m.nameSpan.pos {
+		return nil
+	}
+	return lit
+}
+
+// unitConversions reports the calls updating the metrics which are not in
+// their base unit, whose values need a conversion once the metric is renamed
+// as suggested by the MetricUnits validation, e.g. Observe(ms) on
+// request_latency_milliseconds.
+func unitConversions(res *partialResult) []Issue {
+	byHolder := metricsByHolder(res)
+	type units struct {
+		unit, base string
+		ok         bool
+	}
+	byName := make(map[string]units)
+	nonBase := func(m MetricFamilyWithPos) units {
+		name := m.MetricFamily.GetName()
+		if u, ok := byName[name]; ok {
+			return u
+		}
+		var u units
+		for _, p := range promlintProblems(m.MetricFamily) {
+			if p.ruleID == RuleMetricUnits {
+				if u.unit, u.base, u.ok = nonBaseUnit(p.Text); u.ok {
+					break
+				}
+			}
+		}
+		byName[name] = u
+		return u
+	}
+
+	var issues []Issue
+	for _, w := range res.writes {
+		if w.Method == "SetToCurrentTime" {
+			continue
+		}
+		for _, m := range byHolder[w.Holder] {
+			u := nonBase(m)
+			if !u.ok {
+				continue
+			}
+			renamed := renameUnit(m.MetricFamily.GetName(), u.unit, u.base)
+			var text string
+			factor, known := unitFactor(u.unit)
+			switch {
+			case w.Unit == u.unit && u.base == "seconds":
+				text = fmt.Sprintf("the value is a duration in %s, use the Seconds method of the duration once the metric is renamed to %s", u.unit, renamed)
+			case !known:
+				text = fmt.Sprintf("the value is in %s, convert it to %s once the metric is renamed to %s", u.unit, u.base, renamed)
+			case w.Method == "Inc" || w.Method == "Dec":
+				method := map[string]string{"Inc": "Add", "Dec": "Sub"}[w.Method]
+				text = fmt.Sprintf("%s counts in %s, call %s(%s) once the metric is renamed to %s", w.Method, u.unit, method, strconv.FormatFloat(factor, 'f', -1, 64), renamed)
+			default:
+				text = fmt.Sprintf("the value is in %s, %s once the metric is renamed to %s", u.unit, conversionText(factor), renamed)
+			}
+			issues = append(issues, Issue{
+				Pos:        w.Pos,
+				Metric:     m.MetricFamily.GetName(),
+				Text:       text,
+				RuleID:     RuleMetricUnits,
+				Severity:   SeverityInfo,
+				End:        w.Pos,
+				MetricType: metricTypeName(m.MetricFamily.GetType()),
+				Labels:     m.Labels(),
+			})
+		}
+	}
+	return issues
+}
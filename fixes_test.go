@@ -0,0 +1,64 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBaseUnitFixes(t *testing.T) {
+	path := filepath.Join("testdata", "fixes", "fixes.go")
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type fix struct {
+		pos, message string
+		edits        []string
+	}
+	expected := []fix{
+		{path + ":11:9", "Rename to request_latency_seconds", []string{path + ":11:9-11:39 \"request_latency_seconds\""}},
+		{path + ":16:14", "Rename to acme_cache_size_bytes", []string{path + ":16:14-16:36 \"cache_size_bytes\""}},
+		{path + ":20:9", "Rename to sent_bytes_total", []string{path + ":20:9-20:31 \"sent_bytes_total\""}},
+		{path + ":23:30", "Rename to uptime_seconds", []string{path + ":23:30-23:44 \"uptime_seconds\""}},
+	}
+	var got []fix
+	for _, iss := range res.Issues {
+		for _, f := range iss.Fixes {
+			var edits []string
+			for _, e := range f.Edits {
+				edits = append(edits, e.Pos.String()+"-"+e.End.String()[len(path)+1:]+" "+e.NewText)
+			}
+			got = append(got, fix{iss.Pos.String(), f.Message, edits})
+		}
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestUnitConversions(t *testing.T) {
+	path := filepath.Join("testdata", "fixes", "fixes.go")
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		path + ":27:2: request_latency_milliseconds the value is a duration in milliseconds, use the Seconds method of the duration once the metric is renamed to request_latency_seconds",
+		path + ":28:2: request_latency_milliseconds the value is in milliseconds, divide it by 1000 once the metric is renamed to request_latency_seconds",
+		path + ":29:2: acme_cache_size_kilobytes the value is in kilobytes, multiply it by 1000 once the metric is renamed to acme_cache_size_bytes",
+		path + ":30:2: sent_kilobytes_total Inc counts in kilobytes, call Add(1000) once the metric is renamed to sent_bytes_total",
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleMetricUnits && iss.Severity == SeverityInfo {
+			got = append(got, iss.Pos.String()+": "+iss.Metric+" "+iss.Text)
+		}
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,323 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// definition is a declaration or an assignment of a variable or constant, or
+// an assignment to one field of a struct variable, e.g. opts.Name = "foo".
+type definition struct {
+	// field is the assigned field, or empty if the whole variable is defined.
+	field string
+	// value is the assigned expression, or nil if it is unknown, e.g. for
+	// the results of a function call or the variables of a range loop.
+	value ast.Expr
+	// stmt is the statement of the definition, parent the node holding it
+	// and fn the innermost enclosing function, nil at package level.
+	stmt   ast.Node
+	parent ast.Node
+	fn     ast.Node
+}
+
+// collectDefinitions records the declarations and assignments of the
+// variables and constants of file.
+func (t *typesInfo) collectDefinitions(file *ast.File) {
+	var stack []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if obj := t.info.Defs[n.Name]; obj != nil {
+				t.funcs[obj] = n
+			}
+
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				var value ast.Expr
+				if len(n.Names) == len(n.Values) {
+					value = n.Values[i]
+				}
+				t.define(t.info.Defs[name], "", value, stack)
+			}
+
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				var value ast.Expr
+				if len(n.Lhs) == len(n.Rhs) {
+					value = n.Rhs[i]
+				}
+				switch n.Tok {
+				case token.DEFINE, token.ASSIGN:
+				case token.ADD_ASSIGN:
+					// name += "_total" concatenates to the value reaching lhs.
+					if value != nil {
+						value = &ast.BinaryExpr{X: lhs, OpPos: n.TokPos, Op: token.ADD, Y: value}
+					}
+				default:
+					value = nil
+				}
+
+				switch lhs := ast.Unparen(lhs).(type) {
+				case *ast.Ident:
+					obj := t.info.Defs[lhs]
+					if obj == nil {
+						obj = t.info.Uses[lhs]
+					}
+					t.define(obj, "", value, stack)
+				case *ast.SelectorExpr:
+					if x, ok := lhs.X.(*ast.Ident); ok {
+						t.define(t.info.Uses[x], lhs.Sel.Name, value, stack)
+					}
+				}
+			}
+
+		case *ast.RangeStmt:
+			for _, e := range []ast.Expr{n.Key, n.Value} {
+				if ident, ok := e.(*ast.Ident); ok {
+					obj := t.info.Defs[ident]
+					if obj == nil {
+						obj = t.info.Uses[ident]
+					}
+					t.define(obj, "", nil, stack)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// define records a definition of obj made by the innermost node of stack.
+func (t *typesInfo) define(obj types.Object, field string, value ast.Expr, stack []ast.Node) {
+	if obj == nil {
+		return
+	}
+
+	// The statement is the innermost statement holding the definition, or
+	// the declaration itself at package level.
+	i := len(stack) - 1
+	for ; i > 0; i-- {
+		if _, ok := stack[i].(ast.Stmt); ok {
+			break
+		}
+		if _, ok := stack[i].(*ast.GenDecl); ok {
+			if _, ok := stack[i-1].(*ast.DeclStmt); !ok {
+				break
+			}
+		}
+	}
+
+	d := &definition{field: field, value: value, stmt: stack[i], parent: stack[i-1]}
+	for j := i - 1; j >= 0 && d.fn == nil; j-- {
+		switch stack[j].(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			d.fn = stack[j]
+		}
+	}
+	t.defs[obj] = append(t.defs[obj], d)
+}
+
+// reaching returns the definitions of the variable or constant referred to
+// by use, or of its given field, which may reach use. A definition of the
+// whole variable also defines all its fields. ok is false if the identifier
+// is not declared in the checked files.
+//
+// The analysis follows the nesting of blocks rather than the control flow
+// graph: a definition made earlier in a block enclosing use hides the ones
+// before it, while the definitions made in other blocks, in other functions
+// or later in an enclosing loop may all reach use.
+func (t *typesInfo) reaching(use *ast.Ident, field string) (defs []*definition, ok bool) {
+	obj := t.info.Uses[use]
+	if obj == nil || len(t.defs[obj]) == 0 {
+		return nil, false
+	}
+
+	path := t.enclosing(use.Pos())
+	onPath := make(map[ast.Node]bool, len(path))
+	var fn ast.Node
+	for _, n := range path {
+		onPath[n] = true
+		switch n.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			if fn == nil {
+				fn = n
+			}
+		}
+	}
+
+	var (
+		before    []*definition
+		loops     = make(map[*definition]ast.Node)
+		dominator *definition
+	)
+	for _, d := range t.defs[obj] {
+		switch {
+		case d.field != "" && d.field != field:
+		case fn == nil:
+			// Package variables are initialized before any function assigns
+			// them, in dependency rather than source order.
+			if d.fn == nil {
+				defs = append(defs, d)
+			}
+		case d.fn == nil || (d.fn != fn && onPath[d.fn]):
+			// Package initializers and definitions in an enclosing function
+			// run before the function of use, unless they are made later
+			// in the enclosing function.
+			before = append(before, d)
+		case d.fn != fn:
+			defs = append(defs, d)
+		case isRange(d.stmt) && onPath[d.stmt]:
+			// The variables of a range loop are defined in each iteration.
+			before = append(before, d)
+			if dominator == nil || d.stmt.Pos() > dominator.stmt.Pos() {
+				dominator = d
+			}
+		case onPath[d.stmt]:
+			// use is part of the definition, e.g. name = name + "_total".
+		case d.stmt.End() <= use.Pos():
+			before = append(before, d)
+			if onPath[d.parent] && (dominator == nil || d.stmt.Pos() > dominator.stmt.Pos()) {
+				dominator = d
+			}
+		default:
+			if loop := carryingLoop(path, fn, obj, d); loop != nil {
+				loops[d] = loop
+			}
+		}
+	}
+
+	for _, d := range before {
+		if dominator == nil || (d.fn == fn && d.stmt.Pos() >= dominator.stmt.Pos()) {
+			defs = append(defs, d)
+		}
+	}
+	for _, d := range t.defs[obj] {
+		// A definition made earlier in the iteration hides the one carried
+		// from the previous iteration.
+		if loop, ok := loops[d]; ok && (dominator == nil || dominator.stmt.Pos() < loop.Pos()) {
+			defs = append(defs, d)
+		}
+	}
+	return defs, true
+}
+
+func isRange(n ast.Node) bool {
+	_, ok := n.(*ast.RangeStmt)
+	return ok
+}
+
+// enclosing returns the nodes enclosing pos, innermost first.
+func (t *typesInfo) enclosing(pos token.Pos) []ast.Node {
+	for _, f := range t.files {
+		if f.FileStart <= pos && pos <= f.FileEnd {
+			path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+			return path
+		}
+	}
+	return nil
+}
+
+// carryingLoop returns the innermost loop of fn enclosing both the use
+// enclosed by path and d, made after it, but not the declaration of obj, which
+// is otherwise a new variable in each iteration. It returns nil if d cannot
+// reach the use.
+func carryingLoop(path []ast.Node, fn ast.Node, obj types.Object, d *definition) ast.Node {
+	for _, n := range path {
+		if n == fn {
+			return nil
+		}
+		switch n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			if n.Pos() <= d.stmt.Pos() && d.stmt.End() <= n.End() && (obj.Pos() < n.Pos() || obj.Pos() >= n.End()) {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+// resolveDefinitions resolves the values of the definitions of object
+// reaching use, where nil values are unknown. It reports an issue if the
+// values differ, since the metric depends on the code path taken.
+func (v *visitor) resolveDefinitions(use *ast.Ident, object, field string, values []ast.Expr) (string, bool) {
+	var (
+		resolved []string
+		ok       = true
+	)
+	for _, value := range values {
+		if value == nil {
+			v.debug(use, "identifier is not defined by a value", "field", field, "identifier", object)
+			ok = false
+			continue
+		}
+		s, parsed := v.parseValue(field, value)
+		if !parsed {
+			ok = false
+			continue
+		}
+		if !contains(resolved, s) {
+			resolved = append(resolved, s)
+		}
+	}
+
+	if len(resolved) > 1 {
+		v.report(use, RuleConflictingDefinitions, fmt.Sprintf("conflicting definitions of %s may reach this use: %q and %q", object, resolved[0], resolved[1]))
+		return "", false
+	}
+	if !ok {
+		return "", false
+	}
+	return resolved[0], true
+}
+
+// reachingValue returns the value of the single definition reaching ident, or
+// nil if there is none or several.
+func (v *visitor) reachingValue(ident *ast.Ident) ast.Expr {
+	defs, _ := v.types.reaching(ident, "")
+	if len(defs) != 1 {
+		if len(defs) > 1 {
+			v.debug(ident, "several definitions reach the identifier", "identifier", ident.Name)
+		}
+		return nil
+	}
+	return defs[0].value
+}
+
+// fieldExprs returns the expressions which may define field of the Opts arg,
+// a composite literal or a variable. The fields missing from the composite
+// literals reaching a variable are empty strings.
+func (v *visitor) fieldExprs(arg ast.Expr, field string) []ast.Expr {
+	switch arg := arg.(type) {
+	case *ast.CompositeLit:
+		if value, ok := compositeField(arg, field); ok {
+			return []ast.Expr{value}
+		}
+
+	case *ast.Ident:
+		defs, _ := v.types.reaching(arg, field)
+		var values []ast.Expr
+		for _, d := range defs {
+			value := d.value
+			if lit, ok := value.(*ast.CompositeLit); ok && d.field == "" {
+				var found bool
+				if value, found = compositeField(lit, field); !found {
+					value = &ast.BasicLit{ValuePos: lit.Pos(), Kind: token.STRING, Value: `""`}
+				}
+			}
+			if value != nil {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReachingDefinitions(t *testing.T) {
+	bothResolvers(t, func(t *testing.T, ssa bool) {
+		res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "flow", "flow.go")}, Setting{SSA: ssa})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, m := range res.Metrics {
+			names = append(names, m.MetricFamily.GetName())
+		}
+		expected := []string{"requests_total", "retries_total", "jobs_total", "temperature_celsius"}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("expected metrics %v, got %v", expected, names)
+		}
+
+		var conflicts []int
+		for _, iss := range res.Issues {
+			if iss.RuleID == RuleConflictingDefinitions {
+				conflicts = append(conflicts, iss.Pos.Line)
+			}
+		}
+		if expected := []int{40, 48, 54}; !reflect.DeepEqual(conflicts, expected) {
+			t.Fatalf("expected conflicting definitions on lines %v, got %v: %v", expected, conflicts, res.Issues)
+		}
+	})
+}
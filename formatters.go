@@ -0,0 +1,111 @@
+package promlinter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Formatter writes the issues of an analysis in an output format. Formatters
+// are registered by name with RegisterFormatter, so that other formats, such
+// as the schema of an internal ticketing system, can be added without
+// changing promlinter.
+type Formatter interface {
+	Format(w io.Writer, issues []Issue) error
+}
+
+// FormatterFunc adapts a function to the Formatter interface.
+type FormatterFunc func(w io.Writer, issues []Issue) error
+
+// Format calls f(w, issues).
+func (f FormatterFunc) Format(w io.Writer, issues []Issue) error {
+	return f(w, issues)
+}
+
+// formatters are the registered formatters by name.
+var formatters = map[string]Formatter{
+	"text": FormatterFunc(formatText),
+	"json": FormatterFunc(formatJSON),
+	"csv": FormatterFunc(func(w io.Writer, issues []Issue) error {
+		return WriteIssuesCSV(w, issues, true)
+	}),
+	"errorformat": FormatterFunc(formatErrorformat),
+}
+
+// RegisterFormatter registers the formatter f under name, e.g. to select it
+// with the --output flag of the command. It should be called from an init
+// function. It panics if name is empty or already registered.
+func RegisterFormatter(name string, f Formatter) {
+	if name == "" {
+		panic("promlinter: formatter without a name")
+	}
+	if _, ok := formatters[name]; ok {
+		panic(fmt.Sprintf("promlinter: formatter %s is already registered", name))
+	}
+	formatters[name] = f
+}
+
+// LookupFormatter returns the formatter registered under name.
+func LookupFormatter(name string) (Formatter, bool) {
+	f, ok := formatters[name]
+	return f, ok
+}
+
+// FormatterNames returns the sorted names of the registered formatters,
+// including the built-in text, json, csv and errorformat formats.
+func FormatterNames() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatText writes one issue per line: its position, rule, metric and text,
+// followed by the positions of its duplicates.
+func formatText(w io.Writer, issues []Issue) error {
+	for _, iss := range issues {
+		if _, err := fmt.Fprintf(w, "%s %s %s %s%s\n", iss.Pos, iss.RuleID, iss.Metric, iss.Text, duplicatesText(iss.Duplicates)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatErrorformat writes one issue per line in the format understood by the
+// quickfix list of vim (errorformat %f:%l:%c:\ %m) and by the compilation
+// mode of Emacs, with the rule ID first in the message:
+//
+//	main.go:10:2: PL001 warning: requests_total: no help text
+//
+// The format is stable. Newlines in the text are replaced by spaces, and the
+// metric is omitted if the issue has none.
+func formatErrorformat(w io.Writer, issues []Issue) error {
+	for _, iss := range issues {
+		line, col := iss.Pos.Line, iss.Pos.Column
+		if line < 1 {
+			line = 1
+		}
+		if col < 1 {
+			col = 1
+		}
+		msg := strings.Join(strings.Fields(iss.Text), " ") + duplicatesText(iss.Duplicates)
+		if iss.Metric != "" {
+			msg = iss.Metric + ": " + msg
+		}
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s %s: %s\n", iss.Pos.Filename, line, col, iss.RuleID, iss.Severity, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatJSON writes the issues as an indented JSON array.
+func formatJSON(w io.Writer, issues []Issue) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
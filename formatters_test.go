@@ -0,0 +1,66 @@
+package promlinter
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestFormatters(t *testing.T) {
+	RegisterFormatter("test-tickets", FormatterFunc(func(w io.Writer, issues []Issue) error {
+		for _, iss := range issues {
+			fmt.Fprintf(w, "TICKET %s: %s\n", iss.RuleID, iss.Metric)
+		}
+		return nil
+	}))
+	defer delete(formatters, "test-tickets")
+
+	expected := []string{"csv", "errorformat", "json", "test-tickets", "text"}
+	if got := FormatterNames(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the formatters %v, got %v", expected, got)
+	}
+
+	issues := []Issue{
+		{Pos: token.Position{Filename: "main.go", Line: 10, Column: 2}, Metric: "requests_total", RuleID: RuleHelp, Severity: SeverityWarning, Text: "no help text"},
+	}
+	for name, expected := range map[string]string{
+		"text":         "main.go:10:2 PL001 requests_total no help text\n",
+		"errorformat":  "main.go:10:2: PL001 warning: requests_total: no help text\n",
+		"test-tickets": "TICKET PL001: requests_total\n",
+	} {
+		f, ok := LookupFormatter(name)
+		if !ok {
+			t.Fatalf("expected the formatter %s to be registered", name)
+		}
+		var buf bytes.Buffer
+		if err := f.Format(&buf, issues); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, got)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a duplicate formatter to panic")
+		}
+	}()
+	RegisterFormatter("json", FormatterFunc(formatJSON))
+}
+
+func TestFormatErrorformat(t *testing.T) {
+	issues := []Issue{
+		{Pos: token.Position{Filename: "main.go"}, RuleID: RuleUnsupportedExpr, Severity: SeverityInfo, Text: "unsupported\nexpression"},
+	}
+	var buf bytes.Buffer
+	if err := formatErrorformat(&buf, issues); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "main.go:1:1: PL010 info: unsupported expression\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
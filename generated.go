@@ -0,0 +1,40 @@
+package promlinter
+
+// GeneratedPolicy tells how to report the issues found in generated files,
+// i.e. files with a "// Code generated ... DO NOT EDIT." header. Their
+// metrics are discovered whatever the policy.
+type GeneratedPolicy string
+
+const (
+	// GeneratedSkip drops the issues of generated files, which users cannot
+	// fix by hand. It is the default.
+	GeneratedSkip GeneratedPolicy = "skip"
+	// GeneratedDowngrade reports the issues of generated files with the info
+	// severity.
+	GeneratedDowngrade GeneratedPolicy = "downgrade"
+	// GeneratedInclude reports the issues of generated files like any other.
+	GeneratedInclude GeneratedPolicy = "include"
+)
+
+func (s Setting) generatedPolicy() GeneratedPolicy {
+	if s.Generated == "" {
+		return GeneratedSkip
+	}
+	return s.Generated
+}
+
+// applyGenerated reports whether iss is reported under the policy of
+// generated files, downgrading it if needed. generated holds the names of
+// the generated files.
+func (s Setting) applyGenerated(iss *Issue, generated map[string]bool) bool {
+	if !generated[iss.Pos.Filename] {
+		return true
+	}
+	switch s.generatedPolicy() {
+	case GeneratedSkip:
+		return false
+	case GeneratedDowngrade:
+		iss.Severity = SeverityInfo
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+)
+
+func TestGeneratedPolicy(t *testing.T) {
+	paths := []string{filepath.Join("testdata", "generated", "generated.go")}
+
+	for _, tc := range []struct {
+		policy   GeneratedPolicy
+		issues   int
+		severity Severity
+	}{
+		{policy: "", issues: 0},
+		{policy: GeneratedSkip, issues: 0},
+		{policy: GeneratedDowngrade, issues: 1, severity: SeverityInfo},
+		{policy: GeneratedInclude, issues: 1, severity: SeverityError},
+	} {
+		res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{Generated: tc.policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Metrics) != 1 {
+			t.Fatalf("expected the metric to be discovered with policy %q, got %d metrics", tc.policy, len(res.Metrics))
+		}
+		if len(res.Issues) != tc.issues || tc.issues > 0 && res.Issues[0].Severity != tc.severity {
+			t.Fatalf("unexpected issues with policy %q: %+v", tc.policy, res.Issues)
+		}
+	}
+}
+
+func TestGeneratedPolicyModuleChecks(t *testing.T) {
+	// The negative value is reported by a check of the whole module.
+	paths := []string{filepath.Join("testdata", "generated", "negative", "negative.go")}
+
+	for _, tc := range []struct {
+		policy   GeneratedPolicy
+		issues   int
+		severity Severity
+	}{
+		{policy: "", issues: 0},
+		{policy: GeneratedDowngrade, issues: 1, severity: SeverityInfo},
+		{policy: GeneratedInclude, issues: 1, severity: ruleSeverity(RuleNegativeCounterAdd)},
+	} {
+		cache, err := NewCache(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		setting := Setting{Generated: tc.policy, Cache: cache}
+		res, err := AnalyzeFiles(token.NewFileSet(), paths, setting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The second analysis uses the cached package.
+		cached, err := AnalyzeFiles(token.NewFileSet(), paths, setting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var streamed []Issue
+		if _, err := AnalyzeStream(paths, Setting{Generated: tc.policy}, func(issues []Issue) error {
+			streamed = append(streamed, issues...)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, issues := range [][]Issue{res.Issues, cached.Issues, streamed} {
+			if len(issues) != tc.issues || tc.issues > 0 && (issues[0].RuleID != RuleNegativeCounterAdd || issues[0].Severity != tc.severity) {
+				t.Fatalf("unexpected issues with policy %q: %+v", tc.policy, issues)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/types"
+	"go/version"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// versionError matches the messages of the type checker about the uses of
+// language features of a later Go version than the one of the files.
+var versionError = regexp.MustCompile(`requires go1\.\d+ or later|requires newer Go version`)
+
+// ParseGoVersion returns the Go language version of s in the format of the
+// go command, e.g. go1.21 for 1.21 or go1.21.3.
+func ParseGoVersion(s string) (string, error) {
+	v := s
+	if !strings.HasPrefix(v, "go") {
+		v = "go" + v
+	}
+	if !version.IsValid(v) {
+		return "", fmt.Errorf("invalid Go version %q, expected e.g. 1.21 or go1.21", s)
+	}
+	return version.Lang(v), nil
+}
+
+// toolchainVersion returns the language version of the toolchain promlinter
+// was built with, or an empty string for development toolchains.
+func toolchainVersion() string {
+	return version.Lang(runtime.Version())
+}
+
+// checkVersion returns the version to type-check the files with: goVersion,
+// unless it is newer than the toolchain, whose type checker does not know
+// its features.
+func checkVersion(goVersion string) string {
+	if goVersion == "" || newerThanToolchain(goVersion) {
+		return ""
+	}
+	return goVersion
+}
+
+func newerThanToolchain(goVersion string) bool {
+	tc := toolchainVersion()
+	return goVersion != "" && tc != "" && version.Compare(goVersion, tc) > 0
+}
+
+// versionErrors returns the type errors of errs reporting the features of a
+// later Go version, as syntax errors: the declarations using them may not be
+// analyzed.
+func versionErrors(errs []error, goVersion string) []SyntaxError {
+	var res []SyntaxError
+	for _, err := range errs {
+		terr, ok := err.(types.Error)
+		if !ok || !versionError.MatchString(terr.Msg) {
+			continue
+		}
+		msg := terr.Msg
+		if goVersion != "" {
+			msg = fmt.Sprintf("%s (analyzed as %s)", msg, goVersion)
+		}
+		res = append(res, SyntaxError{Pos: terr.Fset.Position(terr.Pos), Msg: msg})
+	}
+	return res
+}
+
+// newerSyntaxHint adds a hint to the syntax errors of files written for a
+// Go version newer than the toolchain promlinter was built with, whose parser
+// may not know their syntax.
+func newerSyntaxHint(errs []SyntaxError, goVersion string) []SyntaxError {
+	if !newerThanToolchain(goVersion) {
+		return errs
+	}
+	for i := range errs {
+		errs[i].Msg = fmt.Sprintf("%s (the files are written for %s, newer than %s which promlinter was built with: rebuild it with a newer toolchain)", errs[i].Msg, goVersion, toolchainVersion())
+	}
+	return errs
+}
@@ -0,0 +1,67 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGoVersion(t *testing.T) {
+	for input, expected := range map[string]string{
+		"1.21":     "go1.21",
+		"go1.18":   "go1.18",
+		"1.22.3":   "go1.22",
+		"go1.23.0": "go1.23",
+	} {
+		v, err := ParseGoVersion(input)
+		if err != nil || v != expected {
+			t.Errorf("%s: expected %s, got %s, %v", input, expected, v, err)
+		}
+	}
+	for _, input := range []string{"", "latest", "go2.x", "1.x"} {
+		if _, err := ParseGoVersion(input); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
+
+func TestGoVersion(t *testing.T) {
+	paths := []string{filepath.Join("testdata", "goversion", "goversion.go")}
+
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.SyntaxErrors) != 0 {
+		t.Errorf("expected no errors with the version of the toolchain, got %v", res.SyntaxErrors)
+	}
+
+	res, err = AnalyzeFiles(token.NewFileSet(), paths, Setting{GoVersion: "go1.17"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for _, e := range res.SyntaxErrors {
+		lines = append(lines, e.Pos.Line)
+	}
+	// The type parameter and the range over a function.
+	if expected := []int{5, 19}; !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expected errors at lines %v, got %v", expected, res.SyntaxErrors)
+	}
+	// The metric is still discovered.
+	if len(res.Metrics) != 1 {
+		t.Errorf("expected the metric, got %v", res.Metrics)
+	}
+}
+
+func TestNewerSyntaxHint(t *testing.T) {
+	errs := []SyntaxError{{Msg: "expected ';', found 'IDENT'"}}
+	if got := newerSyntaxHint(errs, ""); got[0].Msg != "expected ';', found 'IDENT'" {
+		t.Errorf("expected no hint, got %s", got[0].Msg)
+	}
+	got := newerSyntaxHint(errs, "go1.999")
+	if expected := "expected ';', found 'IDENT' (the files are written for go1.999, newer than " + toolchainVersion() + " which promlinter was built with: rebuild it with a newer toolchain)"; got[0].Msg != expected {
+		t.Errorf("expected %s, got %s", expected, got[0].Msg)
+	}
+}
@@ -0,0 +1,44 @@
+package promlinter
+
+import (
+	"sort"
+)
+
+// IssueGroup is a set of issues sharing the same metric name or rule ID.
+type IssueGroup struct {
+	Key    string
+	Issues []Issue
+}
+
+// GroupByMetric groups issues by metric name. Issues which are not related to
+// a metric are grouped under the empty key.
+func GroupByMetric(issues []Issue) []IssueGroup {
+	return groupBy(issues, func(iss Issue) string { return iss.Metric })
+}
+
+// GroupByRule groups issues by rule ID.
+func GroupByRule(issues []Issue) []IssueGroup {
+	return groupBy(issues, func(iss Issue) string { return iss.RuleID })
+}
+
+// groupBy groups issues by key, keeping the order of the issues within each
+// group. Groups are sorted by key.
+func groupBy(issues []Issue, key func(Issue) string) []IssueGroup {
+	index := make(map[string]int)
+	var groups []IssueGroup
+	for _, iss := range issues {
+		k := key(iss)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, IssueGroup{Key: k})
+		}
+		groups[i].Issues = append(groups[i].Issues, iss)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
@@ -0,0 +1,23 @@
+package promlinter
+
+import (
+	"testing"
+)
+
+func TestGroupBy(t *testing.T) {
+	issues := []Issue{
+		{Metric: "foo", RuleID: RuleHelp},
+		{Metric: "bar", RuleID: RuleHelp},
+		{Metric: "foo", RuleID: RuleCounter},
+	}
+
+	groups := GroupByMetric(issues)
+	if len(groups) != 2 || groups[0].Key != "bar" || groups[1].Key != "foo" || len(groups[1].Issues) != 2 {
+		t.Fatalf("unexpected groups %+v", groups)
+	}
+
+	groups = GroupByRule(issues)
+	if len(groups) != 2 || groups[0].Key != RuleHelp || len(groups[0].Issues) != 2 || groups[1].Key != RuleCounter {
+		t.Fatalf("unexpected groups %+v", groups)
+	}
+}
@@ -0,0 +1,123 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// helpFillers are the words of help texts which do not describe a metric,
+// such as articles and the words used for any counter or gauge.
+var helpFillers = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "for": true, "in": true, "on": true, "by": true, "to": true,
+	"and": true, "or": true, "is": true, "are": true, "this": true, "that": true, "how": true, "many": true,
+	"number": true, "total": true, "count": true, "counts": true, "counter": true, "counted": true, "amount": true,
+	"current": true, "value": true, "metric": true, "gauge": true, "histogram": true, "summary": true, "so": true, "far": true,
+}
+
+// helpUnits map the unit suffixes of metric names to the words of a help
+// text mentioning the unit, without their plural s.
+var helpUnits = []struct {
+	suffix string
+	words  []string
+}{
+	{"_seconds", []string{"second", "sec"}},
+	{"_bytes", []string{"byte"}},
+	{"_meters", []string{"meter", "metre"}},
+	{"_volts", []string{"volt"}},
+	{"_amperes", []string{"ampere", "amp"}},
+	{"_joules", []string{"joule"}},
+	{"_grams", []string{"gram"}},
+	{"_celsius", []string{"celsius"}},
+	{"_ratio", []string{"ratio", "fraction", "proportion"}},
+	{"_percent", []string{"percent", "percentage"}},
+}
+
+// lintHelp reports the help text of metric which only restates its name,
+// which does not mention the unit of its name, or with words missing from
+// the dictionary of the setting.
+func (v *visitor) lintHelp(metric MetricFamilyWithPos) {
+	name, help := metric.MetricFamily.GetName(), metric.MetricFamily.GetHelp()
+	if help == "" {
+		return
+	}
+	pos, end := metric.helpRange()
+	report := func(ruleID, text string) {
+		v.addIssue(Issue{
+			Pos:        pos,
+			Metric:     name,
+			Text:       text,
+			RuleID:     ruleID,
+			Severity:   ruleSeverity(ruleID),
+			End:        end,
+			MetricType: metricTypeName(metric.MetricFamily.GetType()),
+			Labels:     metric.Labels(),
+		})
+	}
+	if restatesName(name, help) {
+		report(RuleHelpRestatesName, "help text only restates the metric name")
+	}
+	if unit, ok := missingUnit(name, help); ok {
+		report(RuleHelpUnit, fmt.Sprintf("help text should mention the unit %q", unit))
+	}
+	if d := v.setting.Dictionary; d != nil {
+		for _, word := range d.misspelled(metric, help) {
+			text := fmt.Sprintf("possible typo %q in help text", word)
+			if s := d.suggest(word); s != "" {
+				text += fmt.Sprintf(", did you mean %q?", s)
+			}
+			report(RuleHelpSpelling, text)
+		}
+	}
+}
+
+// missingUnit returns the unit suffix of the metric name, e.g. "seconds" for
+// request_duration_seconds_total, if the help text does not mention it.
+func missingUnit(name, help string) (string, bool) {
+	name = strings.TrimSuffix(name, "_total")
+	for _, u := range helpUnits {
+		if !strings.HasSuffix(name, u.suffix) {
+			continue
+		}
+		if u.suffix == "_percent" && strings.Contains(help, "%") {
+			return "", false
+		}
+		for _, w := range helpWords(help) {
+			if contains(u.words, w) {
+				return "", false
+			}
+		}
+		return u.suffix[1:], true
+	}
+	return "", false
+}
+
+// restatesName reports whether every word of help is a word of the metric
+// name or a filler, e.g. "Total number of HTTP requests." for
+// http_requests_total. Plurals are ignored.
+func restatesName(name, help string) bool {
+	nameWords := make(map[string]bool)
+	for _, w := range helpWords(name) {
+		nameWords[w] = true
+	}
+	for _, w := range helpWords(help) {
+		if !nameWords[w] && !helpFillers[w] {
+			return false
+		}
+	}
+	return true
+}
+
+// helpWords splits s into lower case words, without their plural s, e.g.
+// "seconds" into "second" but not "celsius" into "celsiu".
+func helpWords(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && !helpFillers[w] {
+			words[i] = strings.TrimSuffix(w, "s")
+		}
+	}
+	return words
+}
@@ -0,0 +1,77 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHelpRestatesName(t *testing.T) {
+	files := []string{filepath.Join("testdata", "help", "help.go")}
+	helpIssues := func(setting Setting) []string {
+		res, err := AnalyzeFiles(token.NewFileSet(), files, setting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var metrics []string
+		for _, iss := range res.Issues {
+			if iss.RuleID == RuleHelpRestatesName {
+				metrics = append(metrics, iss.Metric)
+			}
+		}
+		return metrics
+	}
+
+	if got := helpIssues(Setting{}); len(got) > 0 {
+		t.Fatalf("expected the opt-in rule to be disabled by default, got %q", got)
+	}
+	expected := []string{"foo_total", "http_requests_total", "request_duration_seconds"}
+	if got := helpIssues(Setting{EnabledRules: []string{RuleHelpRestatesName}}); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestHelpUnit(t *testing.T) {
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "help", "help.go")}, Setting{EnabledRules: []string{RuleHelpUnit}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleHelpUnit {
+			got = append(got, iss.Metric+": "+iss.Text)
+		}
+	}
+	expected := []string{`request_duration_seconds: help text should mention the unit "seconds"`}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestHelpSpelling(t *testing.T) {
+	d, err := LoadDictionary(filepath.Join("testdata", "help", "words.txt"), filepath.Join("testdata", "help", "words.dic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "help", "spelling.go")}, Setting{Dictionary: d})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleHelpSpelling {
+			got = append(got, iss.Metric+": "+iss.Text)
+		}
+	}
+	expected := []string{
+		`messages_received_total: possible typo "recieved" in help text, did you mean "received"?`,
+		`requests_handled_total: possible typo "kubelet" in help text`,
+		`requests_handled_total: possible typo "per" in help text`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,173 @@
+package promlinter
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Hierarchy is a policy placing metrics in namespaces and subsystems, e.g.
+//
+//	namespaces:
+//	  - name: acme
+//	    team: platform
+//	    subsystems:
+//	      - name: http
+//	        team: web
+//	        repos: [acme/web-*]
+//	      - name: db
+//
+// Every metric name must start with a namespace of the hierarchy, followed by
+// one of its subsystems if it lists any, e.g. acme_http_requests_total. The
+// repos of a namespace or subsystem are checked on aggregated inventories, see
+// CrossRepoIssues.
+type Hierarchy struct {
+	Namespaces []HierarchyNamespace `yaml:"namespaces" json:"namespaces"`
+}
+
+// HierarchyNamespace is a namespace of a Hierarchy, with its allowed
+// subsystems and optionally the team owning it and the repositories allowed
+// to define its metrics, as path.Match patterns.
+type HierarchyNamespace struct {
+	Name       string               `yaml:"name" json:"name"`
+	Team       string               `yaml:"team,omitempty" json:"team,omitempty"`
+	Repos      []string             `yaml:"repos,omitempty" json:"repos,omitempty"`
+	Subsystems []HierarchySubsystem `yaml:"subsystems,omitempty" json:"subsystems,omitempty"`
+}
+
+// HierarchySubsystem is a subsystem of a namespace. Its team and repos
+// default to those of the namespace.
+type HierarchySubsystem struct {
+	Name  string   `yaml:"name" json:"name"`
+	Team  string   `yaml:"team,omitempty" json:"team,omitempty"`
+	Repos []string `yaml:"repos,omitempty" json:"repos,omitempty"`
+}
+
+// LoadHierarchy reads the YAML hierarchy policy at path.
+func LoadHierarchy(path string) (*Hierarchy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	h := &Hierarchy{}
+	if err := yaml.UnmarshalStrict(data, h); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for _, ns := range h.Namespaces {
+		if ns.Name == "" {
+			return nil, fmt.Errorf("%s: namespace without a name", path)
+		}
+		for _, sub := range ns.Subsystems {
+			if sub.Name == "" {
+				return nil, fmt.Errorf("%s: subsystem without a name in namespace %s", path, ns.Name)
+			}
+			if err := checkRepoPatterns(sub.Repos); err != nil {
+				return nil, fmt.Errorf("%s: subsystem %s of namespace %s: %w", path, sub.Name, ns.Name, err)
+			}
+		}
+		if err := checkRepoPatterns(ns.Repos); err != nil {
+			return nil, fmt.Errorf("%s: namespace %s: %w", path, ns.Name, err)
+		}
+	}
+	return h, nil
+}
+
+func checkRepoPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid repos pattern %q", p)
+		}
+	}
+	return nil
+}
+
+// namespaceOf returns the namespace of the hierarchy holding the metric name,
+// the longest one if several match, or nil.
+func (h *Hierarchy) namespaceOf(name string) *HierarchyNamespace {
+	var ns *HierarchyNamespace
+	for i, n := range h.Namespaces {
+		if strings.HasPrefix(name, n.Name+"_") && (ns == nil || len(n.Name) > len(ns.Name)) {
+			ns = &h.Namespaces[i]
+		}
+	}
+	return ns
+}
+
+// owner returns the prefix of the namespace or subsystem holding the metric
+// name, with its team and the repositories allowed to define it. The repos
+// are empty if any repository may.
+func (h *Hierarchy) owner(name string) (prefix, team string, repos []string) {
+	ns := h.namespaceOf(name)
+	if ns == nil {
+		return "", "", nil
+	}
+	prefix, team, repos = ns.Name+"_", ns.Team, ns.Repos
+	for _, sub := range ns.Subsystems {
+		if p := ns.Name + "_" + sub.Name + "_"; strings.HasPrefix(name, p) {
+			prefix = p
+			if sub.Team != "" {
+				team = sub.Team
+			}
+			if len(sub.Repos) > 0 {
+				repos = sub.Repos
+			}
+			break
+		}
+	}
+	return prefix, team, repos
+}
+
+// misplaced describes why the metric name is not placed in the hierarchy,
+// with the expected prefixes, or returns an empty string.
+func (h *Hierarchy) misplaced(name string) string {
+	ns := h.namespaceOf(name)
+	if ns == nil {
+		prefixes := make([]string, 0, len(h.Namespaces))
+		for _, n := range h.Namespaces {
+			prefixes = append(prefixes, n.Name+"_")
+		}
+		return fmt.Sprintf("metric is not in a namespace of the hierarchy, expected a prefix among %s", strings.Join(prefixes, ", "))
+	}
+	if len(ns.Subsystems) == 0 {
+		return ""
+	}
+
+	prefixes := make([]string, 0, len(ns.Subsystems))
+	for _, sub := range ns.Subsystems {
+		prefix := ns.Name + "_" + sub.Name + "_"
+		if strings.HasPrefix(name, prefix) {
+			return ""
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	owner := ""
+	if ns.Team != "" {
+		owner = fmt.Sprintf(" (owned by %s)", ns.Team)
+	}
+	return fmt.Sprintf("metric is not in a subsystem of the namespace %s%s, expected a prefix among %s", ns.Name, owner, strings.Join(prefixes, ", "))
+}
+
+// lintHierarchy reports metric if it is misplaced in the hierarchy of the
+// setting.
+func (v *visitor) lintHierarchy(metric MetricFamilyWithPos) {
+	h := v.setting.Hierarchy
+	if h == nil {
+		return
+	}
+	name := metric.MetricFamily.GetName()
+	if text := h.misplaced(name); text != "" {
+		v.addIssue(Issue{
+			Pos:        metric.Pos,
+			End:        metric.End,
+			Metric:     name,
+			Text:       text,
+			RuleID:     RuleMisplacedMetric,
+			Severity:   ruleSeverity(RuleMisplacedMetric),
+			MetricType: metricTypeName(metric.MetricFamily.GetType()),
+			Labels:     metric.Labels(),
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHierarchy(t *testing.T) {
+	h, err := LoadHierarchy(filepath.Join("testdata", "hierarchy", "policy.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "hierarchy", "hierarchy.go")}, Setting{Hierarchy: h})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleMisplacedMetric {
+			got = append(got, iss.Metric+": "+iss.Text)
+		}
+	}
+	expected := []string{
+		"acme_cache_hits_total: metric is not in a subsystem of the namespace acme (owned by platform), expected a prefix among acme_http_, acme_db_",
+		"queue_length: metric is not in a namespace of the hierarchy, expected a prefix among acme_, legacy_",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,284 @@
+package promlinter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistoryEntry is the inventory of the metrics at a commit.
+type HistoryEntry struct {
+	Commit string `json:"commit"`
+	// Time is the time of the commit, which orders the entries.
+	Time      time.Time  `json:"time"`
+	Inventory *Inventory `json:"inventory"`
+}
+
+// HistoryChange is a change of the inventory made by a commit.
+type HistoryChange struct {
+	Commit string    `json:"commit"`
+	Time   time.Time `json:"time"`
+	Change
+}
+
+// History is a local database of the inventories of the commits of a
+// repository, to query the changes of the metrics, e.g. when a label was
+// added to a metric, without analyzing the old revisions again. It is stored
+// as a file holding an entry per line as JSON, to which each recorded commit
+// is appended.
+type History struct {
+	path string
+	// Entries are the recorded entries, ordered by commit time.
+	Entries []HistoryEntry
+}
+
+// OpenHistory reads the history stored at path. The history is empty if the
+// file does not exist; it is created by the first Record.
+func OpenHistory(path string) (*History, error) {
+	h := &History{path: path}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<30)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var e HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		h.add(e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// add adds e to the entries, replacing the entry of the same commit, and
+// reports whether it replaced one.
+func (h *History) add(e HistoryEntry) bool {
+	if e.Inventory == nil {
+		e.Inventory = &Inventory{}
+	}
+	replaced := false
+	for i := range h.Entries {
+		if h.Entries[i].Commit == e.Commit {
+			h.Entries = append(h.Entries[:i], h.Entries[i+1:]...)
+			replaced = true
+			break
+		}
+	}
+	i := sort.Search(len(h.Entries), func(i int) bool { return h.Entries[i].Time.After(e.Time) })
+	h.Entries = append(h.Entries, HistoryEntry{})
+	copy(h.Entries[i+1:], h.Entries[i:])
+	h.Entries[i] = e
+	return replaced
+}
+
+// Record adds the inventory of a commit to the history and stores it. The
+// entry is appended to the file, unless the commit was already recorded, in
+// which case its entry is replaced and the file rewritten.
+func (h *History) Record(e HistoryEntry) error {
+	if e.Commit == "" {
+		return fmt.Errorf("recording an inventory without commit")
+	}
+	if h.add(e) {
+		return h.rewrite()
+	}
+
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := writeHistoryEntry(f, e); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rewrite replaces the file of the history with its entries.
+func (h *History) rewrite() error {
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), ".promlinter-history-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	for _, e := range h.Entries {
+		if err := writeHistoryEntry(tmp, e); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), h.path)
+}
+
+func writeHistoryEntry(f *os.File, e HistoryEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Lookup returns the entry of commit, which may be abbreviated like with
+// git.
+func (h *History) Lookup(commit string) (*HistoryEntry, error) {
+	var found *HistoryEntry
+	for i, e := range h.Entries {
+		if e.Commit == commit {
+			return &h.Entries[i], nil
+		}
+		if commit != "" && strings.HasPrefix(e.Commit, commit) {
+			if found != nil {
+				return nil, fmt.Errorf("commit %s is ambiguous in the history", commit)
+			}
+			found = &h.Entries[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("commit %s is not in the history", commit)
+	}
+	return found, nil
+}
+
+// Diff returns the changelog between the inventories of two recorded
+// commits.
+func (h *History) Diff(from, to string) ([]Change, error) {
+	a, err := h.Lookup(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := h.Lookup(to)
+	if err != nil {
+		return nil, err
+	}
+	return Changelog(a.Inventory, b.Inventory), nil
+}
+
+// Log returns the changes made by each recorded commit to the inventory of
+// the previous one, oldest first; the metrics of the first entry are
+// reported as added by it. If metric is set, only the changes of the metric
+// are returned, including those made under its previous names; if label is
+// set, only the changes adding the label, removing it, or adding a metric
+// with it are returned.
+func (h *History) Log(metric, label string) []HistoryChange {
+	var (
+		changes []HistoryChange
+		// labels holds the labels of the metrics added by each change.
+		labels [][]string
+		prev   = &Inventory{}
+	)
+	for _, e := range h.Entries {
+		metrics := e.Inventory.byName()
+		for _, c := range Changelog(prev, e.Inventory) {
+			changes = append(changes, HistoryChange{Commit: e.Commit, Time: e.Time, Change: c})
+			labels = append(labels, metrics[c.Metric].Labels)
+		}
+		prev = e.Inventory
+	}
+
+	names := map[string]bool{metric: true}
+	matches := make([]bool, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		if metric != "" {
+			if !names[c.Metric] {
+				continue
+			}
+			if c.Kind == MetricRenamed {
+				names[c.OldMetric] = true
+			}
+		}
+		switch {
+		case label == "":
+		case c.Kind == LabelAdded || c.Kind == LabelRemoved:
+			if c.Label != label {
+				continue
+			}
+		case c.Kind == MetricAdded:
+			if !contains(labels[i], label) {
+				continue
+			}
+		default:
+			continue
+		}
+		matches[i] = true
+	}
+
+	var res []HistoryChange
+	for i, c := range changes {
+		if matches[i] {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// ResolveCommit returns the hash and the time of the commit rev of the git
+// repository holding dir, e.g. HEAD.
+func ResolveCommit(dir, rev string) (string, time.Time, error) {
+	out, err := git(dir, "log", "-1", "--format=%H %cI", rev, "--")
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return "", time.Time{}, fmt.Errorf("unexpected output of git log %q", out)
+	}
+	t, err := time.Parse(time.RFC3339, fields[1])
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return fields[0], t, nil
+}
+
+// UncommittedFiles returns the absolute paths of the Go files of the git
+// repository holding dir whose content differs from the one of HEAD: the
+// modified, added, deleted and untracked files, staged or not.
+func UncommittedFiles(dir string) ([]string, error) {
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	top := strings.TrimSpace(string(root))
+	out, err := git(top, "status", "--porcelain", "-z", "--untracked-files=all", "--", "*.go")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	entries := splitNull(out)
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if len(e) < 4 {
+			return nil, fmt.Errorf("unexpected output of git status %q", e)
+		}
+		files = append(files, filepath.Join(top, filepath.FromSlash(e[3:])))
+		// Renames and copies are followed by the path of their source.
+		if e[0] == 'R' || e[0] == 'C' {
+			i++
+			if i < len(entries) {
+				files = append(files, filepath.Join(top, filepath.FromSlash(entries[i])))
+			}
+		}
+	}
+	return files, nil
+}
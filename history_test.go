@@ -0,0 +1,170 @@
+package promlinter
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	h, err := OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	requests := func(labels ...string) InventoryMetric {
+		return InventoryMetric{Name: "http_requests_total", Type: "counter", Help: "Requests.", Labels: labels}
+	}
+	// Recorded out of order, e.g. when backfilling older commits.
+	for _, e := range []HistoryEntry{
+		{Commit: "c3", Time: day(3), Inventory: &Inventory{Metrics: []InventoryMetric{requests("code", "method")}}},
+		{Commit: "a1", Time: day(1), Inventory: &Inventory{Metrics: []InventoryMetric{{Name: "requests_total", Type: "counter", Help: "Requests.", Labels: []string{"code"}}}}},
+		{Commit: "b2", Time: day(2), Inventory: &Inventory{Metrics: []InventoryMetric{requests("code")}}},
+		{Commit: "d4", Time: day(4), Inventory: &Inventory{Metrics: []InventoryMetric{requests("code")}}},
+	} {
+		if err := h.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Recording a commit again replaces its entry.
+	if err := h.Record(HistoryEntry{Commit: "d4", Time: day(4), Inventory: &Inventory{Metrics: []InventoryMetric{requests("code", "method", "path")}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err = OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var commits []string
+	for _, e := range h.Entries {
+		commits = append(commits, e.Commit)
+	}
+	if expected := []string{"a1", "b2", "c3", "d4"}; !reflect.DeepEqual(commits, expected) {
+		t.Fatalf("expected the commits %v, got %v", expected, commits)
+	}
+
+	log := func(metric, label string) []string {
+		var res []string
+		for _, c := range h.Log(metric, label) {
+			res = append(res, c.Commit+" "+c.String())
+		}
+		return res
+	}
+	if got, expected := log("http_requests_total", ""), []string{
+		"a1 Added requests_total counter",
+		"b2 Renamed requests_total → http_requests_total",
+		"c3 Added label method to http_requests_total",
+		"d4 Added label path to http_requests_total",
+	}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got, expected := log("http_requests_total", "method"), []string{"c3 Added label method to http_requests_total"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got, expected := log("", "code"), []string{"a1 Added requests_total counter"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	changes, err := h.Diff("a", "b2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := FormatChangelog(changes), "- Renamed requests_total → http_requests_total\n"; got != expected {
+		t.Errorf("expected the changelog %q, got %q", expected, got)
+	}
+	if _, err := h.Diff("e5", "c3"); err == nil {
+		t.Error("expected an error for an unknown commit")
+	}
+}
+
+func TestResolveCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	cmd := exec.Command("git", "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE=2024-01-02T03:04:05Z")
+	if out, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v: %s", err, out)
+	}
+
+	commit, when, err := ResolveCommit(repo, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commit) < 40 || strings.Trim(commit, "0123456789abcdef") != "" {
+		t.Errorf("unexpected commit %q", commit)
+	}
+	if expected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !when.Equal(expected) {
+		t.Errorf("expected the time %v, got %v", expected, when)
+	}
+}
+
+func TestUncommittedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(name, src string) {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	for _, name := range []string{"a.go", "b.go", "c.go", "pkg/d.go"} {
+		write(name, "package app\n")
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	write("a.go", "package app\n\nvar x int\n")
+	write("pkg/new.go", "package app\n")
+	write("README", "Not Go.\n")
+	if err := os.Remove(filepath.Join(repo, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+	git("mv", "c.go", "renamed.go")
+
+	files, err := UncommittedFiles(filepath.Join(repo, "pkg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := filepath.EvalSymlinks(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rel []string
+	for _, f := range files {
+		r, err := filepath.Rel(root, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	sort.Strings(rel)
+	if expected := []string{"a.go", "b.go", "c.go", "pkg/new.go", "renamed.go"}; !reflect.DeepEqual(rel, expected) {
+		t.Fatalf("expected the uncommitted files %v, got %v", expected, rel)
+	}
+}
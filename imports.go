@@ -0,0 +1,125 @@
+package promlinter
+
+import (
+	"go/ast"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// DefaultPrometheusPackages are the import paths of the client_golang
+// packages defining metric constructors.
+var DefaultPrometheusPackages = []string{
+	"github.com/prometheus/client_golang/prometheus",
+	"github.com/prometheus/client_golang/prometheus/promauto",
+}
+
+// fileImports holds the imports of the file being walked.
+type fileImports struct {
+	// dot is true if a prometheus package is dot-imported, and dotPromauto
+	// if that package is promauto.
+	dot         bool
+	dotPromauto bool
+	// otel is true if an OpenTelemetry package is imported.
+	otel bool
+	// prometheus and other hold the names of the imported prometheus
+	// packages and of the other packages, and promauto the names of the
+	// prometheus packages registering metrics on construction.
+	prometheus map[string]bool
+	promauto   map[string]bool
+	other      map[string]bool
+}
+
+// isPrometheusPackage reports whether importPath is one of the default
+// prometheus packages or one of the configured ones, possibly vendored.
+func (s Setting) isPrometheusPackage(importPath string) bool {
+	if i := strings.LastIndex(importPath, "/vendor/"); i >= 0 {
+		importPath = importPath[i+len("/vendor/"):]
+	}
+	importPath = strings.TrimPrefix(importPath, "vendor/")
+	return contains(DefaultPrometheusPackages, importPath) || contains(s.PrometheusPackages, importPath)
+}
+
+func (v *visitor) parseImports(file *ast.File) {
+	v.imports = fileImports{prometheus: make(map[string]bool), promauto: make(map[string]bool), other: make(map[string]bool)}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		v.imports.otel = v.imports.otel || strings.HasPrefix(importPath, otelPackagePrefix)
+		prom := v.setting.isPrometheusPackage(importPath)
+		auto := prom && path.Base(importPath) == "promauto"
+		switch {
+		case name == ".":
+			v.imports.dot = v.imports.dot || prom
+			v.imports.dotPromauto = v.imports.dotPromauto || auto
+		case prom:
+			v.imports.prometheus[name] = true
+			v.imports.promauto[name] = auto
+		default:
+			v.imports.other[name] = true
+		}
+	}
+}
+
+// isDotImported reports whether ident refers to a declaration of a
+// dot-imported prometheus package rather than of the checked package.
+func (v *visitor) isDotImported(ident *ast.Ident) bool {
+	return v.imports.dot && v.types.info.Uses[ident] == nil
+}
+
+// isPromauto reports whether x, the receiver of a metric constructor, is a
+// promauto package or a factory returned by promauto.With, whose
+// constructors register the metrics they create.
+func (v *visitor) isPromauto(x ast.Expr) bool {
+	switch x := ast.Unparen(x).(type) {
+	case *ast.Ident:
+		if v.types.isPackage(x) {
+			return v.imports.promauto[x.Name]
+		}
+		if value := v.reachingValue(x); value != nil {
+			return v.isPromauto(value)
+		}
+
+	case *ast.CallExpr:
+		switch fun := x.Fun.(type) {
+		case *ast.Ident:
+			return fun.Name == "With" && v.imports.dotPromauto && v.isDotImported(fun)
+		case *ast.SelectorExpr:
+			return fun.Sel.Name == "With" && v.isPromauto(fun.X)
+		}
+	}
+	return false
+}
+
+// isOtherPackage reports whether x refers to an imported package which is not
+// a prometheus package.
+func (v *visitor) isOtherPackage(x ast.Expr) bool {
+	ident, ok := x.(*ast.Ident)
+	return ok && v.types.isPackage(ident) && v.imports.other[ident.Name] && !v.imports.prometheus[ident.Name]
+}
+
+// hasPrometheusOpts reports whether the arguments of a call start with an
+// Opts literal of a prometheus package, as when a wrapper of another package
+// takes prometheus.CounterOpts.
+func (v *visitor) hasPrometheusOpts(call *ast.CallExpr) bool {
+	if len(call.Args) == 0 {
+		return false
+	}
+	lit, ok := call.Args[0].(*ast.CompositeLit)
+	if !ok {
+		return false
+	}
+	sel, ok := lit.Type.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && v.imports.prometheus[pkg.Name]
+}
@@ -0,0 +1,75 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPrometheusPackages(t *testing.T) {
+	paths := []string{filepath.Join("testdata", "imports", "imports.go")}
+
+	for _, tc := range []struct {
+		packages []string
+		expected []string
+	}{
+		{expected: []string{"wrapped_requests"}},
+		{packages: []string{"example.com/platform/prometheus"}, expected: []string{"forked_requests", "wrapped_requests"}},
+	} {
+		res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{PrometheusPackages: tc.packages})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, m := range res.Metrics {
+			names = append(names, m.MetricFamily.GetName())
+		}
+		if !reflect.DeepEqual(names, tc.expected) {
+			t.Fatalf("expected metrics %v with packages %v, got %v", tc.expected, tc.packages, names)
+		}
+	}
+}
+
+func TestIsPrometheusPackage(t *testing.T) {
+	s := Setting{PrometheusPackages: []string{"example.com/platform/prometheus"}}
+	for importPath, expected := range map[string]bool{
+		"github.com/prometheus/client_golang/prometheus":                        true,
+		"github.com/prometheus/client_golang/prometheus/promauto":               true,
+		"example.com/app/vendor/github.com/prometheus/client_golang/prometheus": true,
+		"example.com/platform/prometheus":                                       true,
+		"example.com/other/metrics":                                             false,
+	} {
+		if got := s.isPrometheusPackage(importPath); got != expected {
+			t.Fatalf("expected %v for %s, got %v", expected, importPath, got)
+		}
+	}
+}
+
+func TestDotImports(t *testing.T) {
+	paths := []string{
+		filepath.Join("testdata", "dotimport", "prom", "prom.go"),
+		filepath.Join("testdata", "dotimport", "auto", "auto.go"),
+	}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registered := make(map[string]bool)
+	for _, m := range res.Metrics {
+		registered[m.MetricFamily.GetName()] = m.AutoRegistered
+	}
+	for name, expected := range map[string]bool{
+		"prom_requests_total": false,
+		"auto_requests_total": true,
+		"auto_jobs_total":     true,
+	} {
+		if got, ok := registered[name]; !ok || got != expected {
+			t.Errorf("expected %s to be found with auto-registration %v, got %v (found: %v)", name, expected, got, ok)
+		}
+	}
+	if _, ok := registered["queue_length"]; ok {
+		t.Errorf("unexpected metric of the local NewGauge function")
+	}
+}
@@ -0,0 +1,80 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// isInfoMetric reports whether name follows the info-metric pattern, e.g.
+// build_info, whose labels describe the target and whose value is 1.
+func isInfoMetric(name string) bool {
+	return strings.HasSuffix(name, "_info") && name != "_info"
+}
+
+// infoMetrics reports the metrics named like info metrics which do not follow
+// the convention: they must be gauges, have labels, and only be set to 1, so
+// that they can be joined to other series by multiplication.
+func infoMetrics(res *partialResult) []Issue {
+	var issues []Issue
+	byHolder := make(map[string][]MetricFamilyWithPos)
+	for _, m := range res.metrics {
+		name := m.MetricFamily.GetName()
+		if !isInfoMetric(name) {
+			continue
+		}
+		if m.holder != "" {
+			byHolder[m.holder] = append(byHolder[m.holder], m)
+		}
+
+		issue := Issue{
+			Pos:        m.Pos,
+			Metric:     name,
+			RuleID:     RuleInfoMetric,
+			Severity:   ruleSeverity(RuleInfoMetric),
+			End:        m.End,
+			MetricType: metricTypeName(m.MetricFamily.GetType()),
+			Labels:     m.Labels(),
+		}
+		if t := m.MetricFamily.GetType(); t != dto.MetricType_GAUGE {
+			issue.Text = fmt.Sprintf("info metric is a %s, use a gauge set to 1", metricTypeName(t))
+			issues = append(issues, issue)
+		}
+		if len(m.Labels()) == 0 && !m.constLabels {
+			issue.Text = "info metric has no labels, add the labels identifying what it describes, e.g. version"
+			issues = append(issues, issue)
+		}
+	}
+
+	for _, w := range res.writes {
+		for _, m := range byHolder[w.Holder] {
+			var (
+				text     string
+				severity = ruleSeverity(RuleInfoMetric)
+			)
+			switch {
+			case w.Method != "Set":
+				text = fmt.Sprintf("info metric is updated with %s, only set it to 1", w.Method)
+			case w.Value == nil:
+				text = "info metric is set to a value which may not be 1, only set it to 1"
+				severity = SeverityInfo
+			case *w.Value != 1:
+				text = fmt.Sprintf("info metric is set to %v, only set it to 1", *w.Value)
+			default:
+				continue
+			}
+			issues = append(issues, Issue{
+				Pos:        w.Pos,
+				Metric:     m.MetricFamily.GetName(),
+				Text:       text,
+				RuleID:     RuleInfoMetric,
+				Severity:   severity,
+				End:        w.Pos,
+				MetricType: metricTypeName(m.MetricFamily.GetType()),
+				Labels:     m.Labels(),
+			})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,34 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInfoMetrics(t *testing.T) {
+	path := filepath.Join("testdata", "info", "info.go")
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		path + ":15:37: acme_config_info info metric has no labels, add the labels identifying what it describes, e.g. version (warning)",
+		path + ":15:37: acme_config_info info metric is a counter, use a gauge set to 1 (warning)",
+		path + ":19:36: acme_feature_info info metric has no labels, add the labels identifying what it describes, e.g. version (warning)",
+		path + ":28:2: acme_config_info info metric is updated with Inc, only set it to 1 (warning)",
+		path + ":29:2: acme_feature_info info metric is set to 2, only set it to 1 (warning)",
+		path + ":30:2: acme_feature_info info metric is set to a value which may not be 1, only set it to 1 (info)",
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleInfoMetric {
+			got = append(got, iss.Pos.String()+": "+iss.Metric+" "+iss.Text+" ("+string(iss.Severity)+")")
+		}
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
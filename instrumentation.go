@@ -0,0 +1,77 @@
+package promlinter
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// Instrumentation holds the metrics promlinter exposes about its own runs.
+type Instrumentation struct {
+	filesParsed       prometheus.Counter
+	metricsDiscovered *prometheus.CounterVec
+	metricsSkipped    prometheus.Counter
+	issues            *prometheus.CounterVec
+	runDuration       prometheus.Gauge
+}
+
+// NewInstrumentation creates the metrics about promlinter runs and registers
+// them with reg.
+func NewInstrumentation(reg prometheus.Registerer) *Instrumentation {
+	i := &Instrumentation{
+		filesParsed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "promlinter",
+			Name:      "files_parsed_total",
+			Help:      "Total number of Go files analyzed.",
+		}),
+		metricsDiscovered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "promlinter",
+			Name:      "metrics_discovered_total",
+			Help:      "Total number of metric definitions discovered, by metric type.",
+		}, []string{"type"}),
+		metricsSkipped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "promlinter",
+			Name:      "metrics_skipped_total",
+			Help:      "Total number of metric definitions which could not be resolved.",
+		}),
+		issues: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "promlinter",
+			Name:      "issues_total",
+			Help:      "Total number of issues reported, by rule and severity.",
+		}, []string{"rule_id", "severity"}),
+		runDuration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "promlinter",
+			Name:      "last_run_duration_seconds",
+			Help:      "Duration of the last run in seconds.",
+		}),
+	}
+
+	reg.MustRegister(i.filesParsed, i.metricsDiscovered, i.metricsSkipped, i.issues, i.runDuration)
+	return i
+}
+
+// ObserveRun records the outcome of a run. Issues can be a filtered subset of the
+// result issues.
+func (i *Instrumentation) ObserveRun(res *Result, issues []Issue, duration time.Duration) {
+	i.filesParsed.Add(float64(res.Files))
+	for _, m := range res.Metrics {
+		i.metricsDiscovered.WithLabelValues(metricTypeName(m.MetricFamily.GetType())).Inc()
+	}
+	i.metricsSkipped.Add(float64(res.Skipped))
+	for _, iss := range issues {
+		i.issues.WithLabelValues(iss.RuleID, string(iss.Severity)).Inc()
+	}
+	i.runDuration.Set(duration.Seconds())
+}
+
+// Push replaces the metrics of the group of job and the grouping labels on
+// the Pushgateway at url with those gathered by g, e.g. to trend the issues
+// of a repository across CI runs.
+func Push(url, job string, grouping map[string]string, g prometheus.Gatherer) error {
+	pusher := push.New(url, job).Gatherer(g)
+	for name, value := range grouping {
+		pusher = pusher.Grouping(name, value)
+	}
+	return pusher.Push()
+}
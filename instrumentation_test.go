@@ -0,0 +1,59 @@
+package promlinter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentation(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	i := NewInstrumentation(reg)
+
+	res := &Result{
+		Files:   2,
+		Issues:  []Issue{{RuleID: RuleHelp, Severity: SeverityWarning}},
+		Skipped: 1,
+	}
+	i.ObserveRun(res, res.Issues, 2*time.Second)
+
+	if v := testutil.ToFloat64(i.filesParsed); v != 2 {
+		t.Fatalf("expected 2 files parsed, got %v", v)
+	}
+	if v := testutil.ToFloat64(i.issues.WithLabelValues(RuleHelp, "warning")); v != 1 {
+		t.Fatalf("expected 1 issue, got %v", v)
+	}
+	if v := testutil.ToFloat64(i.runDuration); v != 2 {
+		t.Fatalf("expected a duration of 2s, got %v", v)
+	}
+}
+
+func TestPush(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	reg := prometheus.NewRegistry()
+	NewInstrumentation(reg).ObserveRun(&Result{Files: 3}, nil, time.Second)
+	if err := Push(server.URL, "promlinter", map[string]string{"repo": "api", "branch": "main"}, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	// The push package orders the grouping labels randomly.
+	if method != http.MethodPut || !strings.HasPrefix(path, "/metrics/job/promlinter/") || !strings.Contains(path, "/branch/main") || !strings.Contains(path, "/repo/api") {
+		t.Fatalf("expected a PUT to the group of the repository, got %s %s", method, path)
+	}
+	if !strings.Contains(body, "promlinter_files_parsed_total") {
+		t.Fatalf("expected the metrics of the run to be pushed, got %q", body)
+	}
+}
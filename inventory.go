@@ -0,0 +1,80 @@
+package promlinter
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// Inventory is a serializable snapshot of the metrics defined in a code base.
+type Inventory struct {
+	Metrics []InventoryMetric `json:"metrics"`
+}
+
+// InventoryMetric describes a single metric family of an Inventory.
+type InventoryMetric struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Help     string   `json:"help,omitempty"`
+	Labels   []string `json:"labels,omitempty"`
+	Position string   `json:"position,omitempty"`
+	// LabelValues and Series are the enumerated label values and the
+	// estimated number of series, see MetricFamilyWithPos.EstimatedSeries.
+	// Series is 0 if a label could not be enumerated.
+	LabelValues map[string][]string `json:"label_values,omitempty"`
+	Series      int                 `json:"series,omitempty"`
+	// Repo is the repository defining the metric in an inventory merged by
+	// MergeInventories.
+	Repo string `json:"repo,omitempty"`
+}
+
+// NewInventory builds an inventory from the discovered metric families.
+func NewInventory(metrics []MetricFamilyWithPos) *Inventory {
+	inv := &Inventory{Metrics: make([]InventoryMetric, 0, len(metrics))}
+	for _, m := range metrics {
+		mf := m.MetricFamily
+		inv.Metrics = append(inv.Metrics, InventoryMetric{
+			Name:     mf.GetName(),
+			Type:     metricTypeName(mf.GetType()),
+			Help:     mf.GetHelp(),
+			Labels:   m.Labels(),
+			Position: m.Pos.String(),
+
+			LabelValues: m.LabelValues,
+			Series:      m.EstimatedSeries(0),
+		})
+	}
+
+	sort.SliceStable(inv.Metrics, func(i, j int) bool {
+		return inv.Metrics[i].Name < inv.Metrics[j].Name
+	})
+	return inv
+}
+
+// ReadInventory decodes an inventory previously written by Write.
+func ReadInventory(r io.Reader) (*Inventory, error) {
+	inv := &Inventory{}
+	if err := json.NewDecoder(r).Decode(inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// Write encodes the inventory as indented JSON.
+func (inv *Inventory) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(inv)
+}
+
+// byName indexes the inventory by metric name. If a metric is defined more than
+// once, the first definition wins.
+func (inv *Inventory) byName() map[string]InventoryMetric {
+	metrics := make(map[string]InventoryMetric, len(inv.Metrics))
+	for _, m := range inv.Metrics {
+		if _, ok := metrics[m.Name]; !ok {
+			metrics[m.Name] = m
+		}
+	}
+	return metrics
+}
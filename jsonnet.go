@@ -0,0 +1,87 @@
+package promlinter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// jsonnetIdentifier matches the field names which do not need to be quoted.
+var jsonnetIdentifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// jsonnetKeywords may not be used as unquoted field names.
+var jsonnetKeywords = map[string]bool{
+	"assert": true, "else": true, "error": true, "false": true, "for": true, "function": true, "if": true,
+	"import": true, "importstr": true, "importbin": true, "in": true, "local": true, "null": true,
+	"self": true, "super": true, "tailstrict": true, "then": true, "true": true,
+}
+
+// WriteJsonnet writes the metrics of inv as a jsonnet library, e.g.
+// metrics.libsonnet, for monitoring mixins to build their dashboards and
+// alerts from the metrics of the code, or to check that they only query
+// metrics which exist:
+//
+//	local metrics = import 'metrics.libsonnet';
+//	assert std.objectHas(metrics.metrics, 'http_requests_total');
+//
+// The metrics field maps each name to its type, help and labels, and the
+// hidden names field lists the names in order. A metric defined at several
+// positions is written once, with its first definition.
+func WriteJsonnet(w io.Writer, inv *Inventory) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "// Code generated by promlinter jsonnet. DO NOT EDIT.")
+	fmt.Fprintln(bw, "{")
+	fmt.Fprintln(bw, "  metrics: {")
+	seen := make(map[string]bool)
+	for _, m := range inv.Metrics {
+		if seen[m.Name] {
+			continue
+		}
+		seen[m.Name] = true
+
+		fmt.Fprintf(bw, "    %s: {\n", jsonnetField(m.Name))
+		fmt.Fprintf(bw, "      type: %s,\n", jsonnetString(m.Type))
+		fmt.Fprintf(bw, "      help: %s,\n", jsonnetString(m.Help))
+		fmt.Fprintf(bw, "      labels: %s,\n", jsonnetStrings(m.Labels))
+		if len(m.LabelValues) > 0 {
+			fmt.Fprintln(bw, "      label_values: {")
+			for _, l := range m.Labels {
+				if values, ok := m.LabelValues[l]; ok {
+					fmt.Fprintf(bw, "        %s: %s,\n", jsonnetField(l), jsonnetStrings(values))
+				}
+			}
+			fmt.Fprintln(bw, "      },")
+		}
+		fmt.Fprintln(bw, "    },")
+	}
+	fmt.Fprintln(bw, "  },")
+	fmt.Fprintln(bw, "  names:: std.objectFields(self.metrics),")
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// jsonnetString quotes s. JSON strings are valid jsonnet strings.
+func jsonnetString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+func jsonnetStrings(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, jsonnetString(v))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// jsonnetField returns name as a field name, quoted if it is not an
+// identifier, e.g. for recording rules such as job:requests:rate5m.
+func jsonnetField(name string) string {
+	if jsonnetIdentifier.MatchString(name) && !jsonnetKeywords[name] {
+		return name
+	}
+	return jsonnetString(name)
+}
@@ -0,0 +1,42 @@
+package promlinter
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteJsonnet(t *testing.T) {
+	inv := &Inventory{Metrics: []InventoryMetric{
+		{Name: "http_requests_total", Type: "counter", Help: `Requests of the "api".`, Labels: []string{"code", "method"}, LabelValues: map[string][]string{"method": {"GET", "POST"}}},
+		{Name: "http_requests_total", Type: "counter", Help: "Duplicate."},
+		{Name: "job:up:sum", Type: "gauge", Help: "Up."},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteJsonnet(&buf, inv); err != nil {
+		t.Fatal(err)
+	}
+	expected := `// Code generated by promlinter jsonnet. DO NOT EDIT.
+{
+  metrics: {
+    http_requests_total: {
+      type: "counter",
+      help: "Requests of the \"api\".",
+      labels: ["code", "method"],
+      label_values: {
+        method: ["GET", "POST"],
+      },
+    },
+    "job:up:sum": {
+      type: "gauge",
+      help: "Up.",
+      labels: [],
+    },
+  },
+  names:: std.objectFields(self.metrics),
+}
+`
+	if got := buf.String(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,433 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// labelSite is a call selecting the child metric of a Vec by its label
+// values, e.g. errors.WithLabelValues("500").
+type labelSite struct {
+	Holder string         `json:"holder"`
+	Pos    token.Position `json:"pos"`
+	// Args holds the possible values passed to WithLabelValues by position,
+	// and Labels those of a Labels map by label name, see labelValues. The
+	// values are nil if they could not be resolved. Unknown is true if the
+	// labels selected by the call could not be resolved at all, e.g. for a
+	// Labels variable or a curried Vec.
+	Args    [][]string          `json:"args,omitempty"`
+	Labels  map[string][]string `json:"labels,omitempty"`
+	Unknown bool                `json:"unknown,omitempty"`
+}
+
+// parseLabelSite records call if it selects a child metric of a Vec.
+func (v *visitor) parseLabelSite(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !labelMethods[sel.Sel.Name] {
+		return
+	}
+	key := v.metricKey(sel.X)
+	if key == "" {
+		return
+	}
+
+	site := labelSite{Holder: key, Pos: v.fs.Position(call.Pos())}
+	_, chained := ast.Unparen(sel.X).(*ast.CallExpr)
+	switch {
+	case chained || strings.Contains(sel.Sel.Name, "Curry"):
+		site.Unknown = true
+	case strings.HasSuffix(sel.Sel.Name, "LabelValues"):
+		if call.Ellipsis.IsValid() {
+			site.Unknown = true
+			break
+		}
+		for _, arg := range call.Args {
+			site.Args = append(site.Args, v.labelValues(arg, 0))
+		}
+	default:
+		lit, ok := ast.Unparen(firstArg(call)).(*ast.CompositeLit)
+		if !ok {
+			site.Unknown = true
+			break
+		}
+		site.Labels = make(map[string][]string)
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				site.Unknown = true
+				break
+			}
+			name := v.labelValue(kv.Key)
+			if name == nil {
+				site.Unknown = true
+				break
+			}
+			site.Labels[*name] = v.labelValues(kv.Value, 0)
+		}
+	}
+	v.labels = append(v.labels, site)
+}
+
+func firstArg(call *ast.CallExpr) ast.Expr {
+	if len(call.Args) == 0 {
+		return nil
+	}
+	return call.Args[0]
+}
+
+// labelValue returns the constant string value of expr, or nil.
+func (v *visitor) labelValue(expr ast.Expr) *string {
+	v.quiet = true
+	defer func() { v.quiet = false }()
+	if s, ok := v.parseValue("label", expr); ok {
+		return &s
+	}
+	return nil
+}
+
+// labelValues enumerates the values which expr may have, if they are drawn
+// from a constant set, or returns nil. The set covers:
+//
+//   - constants, and the variables whose reaching definitions are constants;
+//   - the tag of a switch statement in one of its cases: switch code { case "200", "404": ... };
+//   - the variables of a range loop over a literal slice, array or map: for _, queue := range []string{"default", "batch"};
+//   - the parameters and variables of a string type declared in the package, e.g. type Status string,
+//     which may have the values of the constants of this type declared in the package.
+//
+// depth bounds the definitions followed, which may depend on each other in
+// loops.
+func (v *visitor) labelValues(expr ast.Expr, depth int) []string {
+	if s := v.labelValue(expr); s != nil {
+		return []string{*s}
+	}
+	if depth >= maxCallDepth {
+		return nil
+	}
+
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		// Conversions, e.g. string(status).
+		if len(expr.Args) == 1 && v.types.info.Types[expr.Fun].IsType() {
+			return v.labelValues(expr.Args[0], depth+1)
+		}
+
+	case *ast.Ident:
+		if values := v.caseValues(expr); values != nil {
+			return values
+		}
+		defs, ok := v.types.reaching(expr, "")
+		if ok && len(defs) > 0 {
+			set := make(map[string]bool)
+			for _, d := range defs {
+				var values []string
+				switch {
+				case d.value != nil:
+					values = v.labelValues(d.value, depth+1)
+				default:
+					if loop, ok := d.stmt.(*ast.RangeStmt); ok {
+						values = v.rangeValues(loop, expr, depth)
+					}
+				}
+				if values == nil {
+					set = nil
+					break
+				}
+				for _, value := range values {
+					set[value] = true
+				}
+			}
+			if set != nil {
+				return sortedSet(set)
+			}
+		}
+		if obj := v.types.info.Uses[expr]; obj != nil {
+			return enumConstants(obj.Type())
+		}
+	}
+	return nil
+}
+
+// caseValues returns the values of the case clause enclosing ident, if ident
+// is the tag of its switch statement.
+func (v *visitor) caseValues(ident *ast.Ident) []string {
+	obj := v.types.info.Uses[ident]
+	if obj == nil {
+		return nil
+	}
+	path := v.types.enclosing(ident.Pos())
+	for i, n := range path {
+		switch n.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			return nil
+		}
+		clause, ok := n.(*ast.CaseClause)
+		if !ok || i+2 >= len(path) || clause.List == nil {
+			continue
+		}
+		sw, ok := path[i+2].(*ast.SwitchStmt)
+		if !ok {
+			continue
+		}
+		if tag, ok := sw.Tag.(*ast.Ident); !ok || v.types.info.Uses[tag] != obj {
+			continue
+		}
+		values := make([]string, 0, len(clause.List))
+		for _, e := range clause.List {
+			value := v.labelValue(e)
+			if value == nil {
+				return nil
+			}
+			values = append(values, *value)
+		}
+		return values
+	}
+	return nil
+}
+
+// rangeValues returns the values of the key or value variable ident of loop,
+// if it loops over a literal of constants.
+func (v *visitor) rangeValues(loop *ast.RangeStmt, ident *ast.Ident, depth int) []string {
+	x := ast.Unparen(loop.X)
+	if x, ok := x.(*ast.Ident); ok {
+		if value := v.reachingValue(x); value != nil {
+			return v.rangeValues(&ast.RangeStmt{Key: loop.Key, Value: loop.Value, X: value}, ident, depth)
+		}
+	}
+	lit, ok := x.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+
+	obj := v.types.info.Uses[ident]
+	isVar := func(e ast.Expr) bool {
+		id, ok := e.(*ast.Ident)
+		return ok && (v.types.info.Defs[id] == obj || v.types.info.Uses[id] == obj)
+	}
+	var isMap bool
+	if typ := v.types.info.Types[lit].Type; typ != nil {
+		_, isMap = typ.Underlying().(*types.Map)
+	}
+	if !isVar(loop.Value) && !(isMap && isVar(loop.Key)) {
+		return nil
+	}
+
+	set := make(map[string]bool)
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = kv.Value
+			if isVar(loop.Key) {
+				elt = kv.Key
+			}
+		}
+		values := v.labelValues(elt, depth+1)
+		if values == nil {
+			return nil
+		}
+		for _, value := range values {
+			set[value] = true
+		}
+	}
+	return sortedSet(set)
+}
+
+// enumConstants returns the values of the string constants of the named type
+// typ declared in its package, if typ is a string type of the checked
+// package with such constants.
+func enumConstants(typ types.Type) []string {
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return nil
+	}
+	if basic, ok := named.Underlying().(*types.Basic); !ok || basic.Info()&types.IsString == 0 {
+		return nil
+	}
+
+	scope := named.Obj().Pkg().Scope()
+	set := make(map[string]bool)
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if ok && types.Identical(c.Type(), named) && c.Val().Kind() == constant.String {
+			set[constant.StringVal(c.Val())] = true
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return sortedSet(set)
+}
+
+// value returns the possible values of the label at index i of the metric,
+// or nil if they are unresolved or not passed.
+func (s labelSite) value(name string, i int) []string {
+	if s.Labels != nil {
+		return s.Labels[name]
+	}
+	if i < len(s.Args) {
+		return s.Args[i]
+	}
+	return nil
+}
+
+// constLabelCandidates reports the labels of Vecs which have the same
+// constant value at every call site, since they only add a label to every
+// series: they should be ConstLabels, or be dropped. Exported holders are not
+// reported, since packages outside the analyzed ones may use other values.
+func constLabelCandidates(res *partialResult) []Issue {
+	byHolder := make(map[string][]labelSite)
+	for _, s := range res.labels {
+		byHolder[s.Holder] = append(byHolder[s.Holder], s)
+	}
+
+	var issues []Issue
+	for _, m := range res.metrics {
+		sites := byHolder[m.holder]
+		if m.holder == "" || isExportedKey(m.holder) || len(sites) == 0 {
+			continue
+		}
+		unknown := false
+		for _, s := range sites {
+			unknown = unknown || s.Unknown
+		}
+		if unknown {
+			continue
+		}
+
+		for i, name := range m.Labels() {
+			values := make(map[string]bool)
+			for _, s := range sites {
+				value := s.value(name, i)
+				if len(value) != 1 {
+					values = nil
+					break
+				}
+				values[value[0]] = true
+			}
+			if len(values) != 1 {
+				continue
+			}
+			value := sortedSet(values)[0]
+			issues = append(issues, Issue{
+				Pos:        m.Pos,
+				Metric:     m.MetricFamily.GetName(),
+				Text:       fmt.Sprintf("label %q has the value %q at every call site (%d); use a ConstLabel or drop it", name, value, len(sites)),
+				RuleID:     RuleConstLabelCandidate,
+				Severity:   ruleSeverity(RuleConstLabelCandidate),
+				End:        m.End,
+				MetricType: metricTypeName(m.MetricFamily.GetType()),
+				Labels:     m.Labels(),
+			})
+		}
+	}
+	return issues
+}
+
+// enumerateLabels sets the LabelValues of the metrics of res from their call
+// sites. A label is enumerated if its values are resolved at every call site.
+func enumerateLabels(res *partialResult) {
+	byHolder := make(map[string][]labelSite)
+	for _, s := range res.labels {
+		byHolder[s.Holder] = append(byHolder[s.Holder], s)
+	}
+
+	for i := range res.metrics {
+		m := &res.metrics[i]
+		sites := byHolder[m.holder]
+		if m.holder == "" || len(sites) == 0 {
+			continue
+		}
+		for j, name := range m.Labels() {
+			set := make(map[string]bool)
+			for _, s := range sites {
+				values := s.value(name, j)
+				if s.Unknown || values == nil {
+					set = nil
+					break
+				}
+				for _, value := range values {
+					set[value] = true
+				}
+			}
+			if set == nil {
+				continue
+			}
+			if m.LabelValues == nil {
+				m.LabelValues = make(map[string][]string)
+			}
+			m.LabelValues[name] = sortedSet(set)
+		}
+	}
+}
+
+// seriesBudget reports the metric families of res whose estimated number of
+// series exceeds Setting.SeriesBudget.
+func seriesBudget(res *partialResult, setting Setting) []Issue {
+	var issues []Issue
+	for _, m := range res.metrics {
+		series := m.EstimatedSeries(setting.UnresolvedLabelValues)
+		if series <= setting.SeriesBudget {
+			continue
+		}
+		text := fmt.Sprintf("metric family has an estimated %d series, above the budget of %d", series, setting.SeriesBudget)
+		var unresolved []string
+		for _, name := range m.Labels() {
+			if len(m.LabelValues[name]) == 0 {
+				unresolved = append(unresolved, name)
+			}
+		}
+		if len(unresolved) > 0 {
+			text += fmt.Sprintf(", assuming %d values for the labels %s", setting.UnresolvedLabelValues, strings.Join(unresolved, ", "))
+		}
+		issues = append(issues, Issue{
+			Pos:        m.Pos,
+			Metric:     m.MetricFamily.GetName(),
+			Text:       text,
+			RuleID:     RuleSeriesBudget,
+			Severity:   ruleSeverity(RuleSeriesBudget),
+			End:        m.End,
+			MetricType: metricTypeName(m.MetricFamily.GetType()),
+			Labels:     m.Labels(),
+		})
+	}
+	return issues
+}
+
+// EstimatedSeries estimates the number of series of the metric family: the
+// product of the number of values of its labels, times the series of each
+// child, e.g. the buckets, sum and count of a histogram. unresolved is the
+// number of values assumed for the labels which are not enumerated in
+// LabelValues; if it is 0, EstimatedSeries returns 0 when a label is not
+// enumerated. The objectives of summaries, which add a series each, are not
+// counted, and histograms whose buckets could not be resolved are assumed to
+// use the default buckets.
+func (m *MetricFamilyWithPos) EstimatedSeries(unresolved int) int {
+	children := 1
+	for _, name := range m.Labels() {
+		n := len(m.LabelValues[name])
+		if n == 0 {
+			if unresolved <= 0 {
+				return 0
+			}
+			n = unresolved
+		}
+		children *= n
+	}
+
+	switch m.MetricFamily.GetType() {
+	case dto.MetricType_HISTOGRAM:
+		buckets := m.buckets
+		if buckets == nil {
+			buckets = defBuckets
+		}
+		// The +Inf bucket, the sum and the count.
+		return children * (len(buckets) + 3)
+	case dto.MetricType_SUMMARY:
+		return children * 2
+	}
+	return children
+}
@@ -0,0 +1,112 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConstLabelCandidates(t *testing.T) {
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "labels", "labels.go")}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleConstLabelCandidate {
+			got = append(got, iss.Text)
+		}
+	}
+	expected := []string{
+		`label "service" has the value "api" at every call site (2); use a ConstLabel or drop it`,
+		`label "db" has the value "main" at every call site (2); use a ConstLabel or drop it`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestLabelValues(t *testing.T) {
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "labels", "enums.go")}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		values     map[string][]string
+		series     int
+		unresolved int
+	}{
+		{
+			name:   "jobs_processed_total",
+			values: map[string][]string{"queue": {"batch", "default"}, "status": {"failed", "ok"}},
+			series: 4,
+		},
+		{
+			name:   "job_latency_seconds",
+			values: map[string][]string{"queue": {"batch", "default"}},
+			series: 2 * 6,
+		},
+		{
+			name:   "responses_total",
+			values: map[string][]string{"code": {"not_found", "ok", "other"}},
+			series: 0,
+		},
+		{
+			name:   "cache_hits_total",
+			values: map[string][]string{"cache": {"groups", "users"}},
+			series: 2,
+		},
+		{
+			name:       "responses_total",
+			values:     map[string][]string{"code": {"not_found", "ok", "other"}},
+			series:     30,
+			unresolved: 10,
+		},
+	} {
+		var m *MetricFamilyWithPos
+		for i := range res.Metrics {
+			if res.Metrics[i].MetricFamily.GetName() == tc.name {
+				m = &res.Metrics[i]
+			}
+		}
+		if m == nil {
+			t.Fatalf("metric %s not found", tc.name)
+		}
+		if !reflect.DeepEqual(m.LabelValues, tc.values) {
+			t.Errorf("%s: expected label values %v, got %v", tc.name, tc.values, m.LabelValues)
+		}
+		if series := m.EstimatedSeries(tc.unresolved); series != tc.series {
+			t.Errorf("%s: expected %d series assuming %d values per unresolved label, got %d", tc.name, tc.series, tc.unresolved, series)
+		}
+	}
+}
+
+func TestSeriesBudget(t *testing.T) {
+	for _, tc := range []struct {
+		unresolved int
+		expected   []string
+	}{
+		{expected: []string{"jobs_processed_total", "job_latency_seconds"}},
+		{unresolved: 10, expected: []string{"jobs_processed_total", "responses_total", "job_latency_seconds"}},
+	} {
+		setting := Setting{SeriesBudget: 3, UnresolvedLabelValues: tc.unresolved}
+		res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "labels", "enums.go")}, setting)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, iss := range res.Issues {
+			if iss.RuleID == RuleSeriesBudget {
+				got = append(got, iss.Metric)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("assuming %d values per unresolved label, expected %v over budget, got %v", tc.unresolved, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// reportLoopName reports the constructor call whose name, given by the first
+// argument arg, could not be resolved because it depends on the variable of
+// an enclosing loop, e.g.
+//
+//	for _, queue := range queues {
+//		prometheus.NewGauge(prometheus.GaugeOpts{Name: queue + "_length"})
+//	}
+//
+// Each iteration creates a metric family, so their number is unbounded. It
+// returns whether an issue was reported.
+func (v *visitor) reportLoopName(call *ast.CallExpr, arg ast.Expr, desc bool) bool {
+	loopVars := v.loopVariables(call.Pos())
+	if len(loopVars) == 0 {
+		return false
+	}
+
+	seen := make(map[types.Object]bool)
+	for _, name := range v.nameExprs(arg, desc) {
+		if ident := v.loopDependency(name, loopVars, seen); ident != nil {
+			v.report(name, RuleLoopDynamicName, fmt.Sprintf("metric name depends on the loop variable %s, creating a metric family per iteration", ident.Name))
+			return true
+		}
+	}
+	return false
+}
+
+// loopVariables returns the variables declared by the loops enclosing pos in
+// its function.
+func (v *visitor) loopVariables(pos token.Pos) map[types.Object]bool {
+	vars := make(map[types.Object]bool)
+	add := func(e ast.Expr) {
+		if ident, ok := e.(*ast.Ident); ok {
+			if obj := v.types.info.Defs[ident]; obj != nil {
+				vars[obj] = true
+			} else if obj := v.types.info.Uses[ident]; obj != nil {
+				vars[obj] = true
+			}
+		}
+	}
+
+	for _, n := range v.types.enclosing(pos) {
+		switch n := n.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			return vars
+		case *ast.RangeStmt:
+			add(n.Key)
+			add(n.Value)
+		case *ast.ForStmt:
+			if init, ok := n.Init.(*ast.AssignStmt); ok {
+				for _, lhs := range init.Lhs {
+					add(lhs)
+				}
+			}
+		}
+	}
+	return vars
+}
+
+// nameExprs returns the expressions which may define the metric name of a
+// constructor whose first argument is arg: the Name field of the Opts, or the
+// name argument of NewDesc if desc is true.
+func (v *visitor) nameExprs(arg ast.Expr, desc bool) []ast.Expr {
+	if !desc {
+		return v.fieldExprs(arg, "Name")
+	}
+	if ident, ok := arg.(*ast.Ident); ok {
+		arg = v.reachingValue(ident)
+	}
+	if call, ok := arg.(*ast.CallExpr); ok && len(call.Args) > 0 {
+		return []ast.Expr{call.Args[0]}
+	}
+	return nil
+}
+
+// loopDependency returns the identifier of a loop variable which expr
+// depends on, directly or through the definitions of other variables.
+func (v *visitor) loopDependency(expr ast.Expr, loopVars, seen map[types.Object]bool) *ast.Ident {
+	var dep *ast.Ident
+	ast.Inspect(expr, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok || dep != nil {
+			return dep == nil
+		}
+		obj := v.types.info.Uses[ident]
+		if obj == nil || seen[obj] {
+			return false
+		}
+		seen[obj] = true
+		if loopVars[obj] {
+			dep = ident
+			return false
+		}
+
+		defs, _ := v.types.reaching(ident, "")
+		for _, d := range defs {
+			if d.value != nil && dep == nil {
+				dep = v.loopDependency(d.value, loopVars, seen)
+			}
+		}
+		return false
+	})
+	return dep
+}
@@ -0,0 +1,28 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoopDynamicName(t *testing.T) {
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "loops", "loops.go")}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []int
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleLoopDynamicName {
+			lines = append(lines, iss.Pos.Line)
+		}
+	}
+	if expected := []int{12, 22, 29}; !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("expected dynamic names on lines %v, got %v: %v", expected, lines, res.Issues)
+	}
+	if len(res.Metrics) != 1 || res.Metrics[0].MetricFamily.GetName() != "loops_total" {
+		t.Fatalf("expected only the static metric, got %v", res.Metrics)
+	}
+}
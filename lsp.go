@@ -0,0 +1,363 @@
+package promlinter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The LSP diagnostic severities.
+const (
+	lspSeverityError       = 1
+	lspSeverityWarning     = 2
+	lspSeverityInformation = 3
+)
+
+// The JSON-RPC error codes of messages which are not valid JSON, of unknown
+// methods and of invalid parameters.
+const (
+	lspParseError     = -32700
+	lspMethodNotFound = -32601
+	lspInvalidParams  = -32602
+)
+
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *lspError        `json:"error,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspTextDocumentParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code,omitempty"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspPublishDiagnosticsParams struct {
+	URI         string          `json:"uri"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+type lspCodeActionParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Range lspRange `json:"range"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspWorkspaceEdit struct {
+	Changes map[string][]lspTextEdit `json:"changes"`
+}
+
+type lspCodeAction struct {
+	Title       string           `json:"title"`
+	Kind        string           `json:"kind"`
+	Diagnostics []lspDiagnostic  `json:"diagnostics"`
+	Edit        lspWorkspaceEdit `json:"edit"`
+}
+
+// ServeLSP runs a minimal Language Server Protocol server reading requests
+// from r and writing responses to w, usually the standard input and output
+// of the process. Diagnostics are published when a Go file is opened or
+// saved, and the suggested fixes of their issues are offered as quick fixes.
+// It returns when the client sends the exit notification or closes r.
+//
+// Positions are reported in bytes rather than in UTF-16 code units, which
+// only differs on lines with non-ASCII characters before the issue.
+func ServeLSP(r io.Reader, w io.Writer, setting Setting) error {
+	if setting.Cache == nil {
+		setting.Cache = NewMemoryCache()
+	}
+
+	// issues holds the issues of the last diagnostics of each file, for the
+	// code actions.
+	issues := make(map[string][]Issue)
+	in := bufio.NewReader(r)
+	for {
+		msg, err := readLSPMessage(in)
+		if err == io.EOF {
+			return nil
+		}
+		var bodyErr *lspBodyError
+		if errors.As(err, &bodyErr) {
+			// The id of a message which could not be parsed is unknown.
+			null := json.RawMessage("null")
+			if err := writeLSPMessage(w, &lspMessage{ID: &null, Error: &lspError{Code: lspParseError, Message: bodyErr.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		switch msg.Method {
+		case "initialize":
+			err = writeLSPMessage(w, &lspMessage{ID: msg.ID, Result: map[string]interface{}{
+				"capabilities": map[string]interface{}{
+					"textDocumentSync":   map[string]interface{}{"openClose": true, "save": true},
+					"codeActionProvider": true,
+				},
+				"serverInfo": map[string]string{"name": "promlinter"},
+			}})
+		case "shutdown":
+			err = writeLSPMessage(w, &lspMessage{ID: msg.ID, Result: json.RawMessage("null")})
+		case "exit":
+			return nil
+		case "textDocument/didOpen", "textDocument/didSave":
+			var params lspTextDocumentParams
+			if perr := json.Unmarshal(msg.Params, &params); perr != nil {
+				err = invalidLSPParams(w, msg, perr, setting)
+				break
+			}
+			issues[params.TextDocument.URI], err = publishDiagnostics(w, params.TextDocument.URI, setting)
+		case "textDocument/codeAction":
+			var params lspCodeActionParams
+			if perr := json.Unmarshal(msg.Params, &params); perr != nil {
+				err = invalidLSPParams(w, msg, perr, setting)
+				break
+			}
+			err = writeLSPMessage(w, &lspMessage{ID: msg.ID, Result: codeActions(issues[params.TextDocument.URI], params.Range)})
+		default:
+			// Notifications can be ignored, but requests need an answer.
+			if msg.ID != nil {
+				err = writeLSPMessage(w, &lspMessage{ID: msg.ID, Error: &lspError{Code: lspMethodNotFound, Message: "method not found: " + msg.Method}})
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// invalidLSPParams answers a request whose parameters could not be decoded
+// with an error. A notification has no answer: it is logged and ignored, so
+// that a bad message of the client does not stop the server.
+func invalidLSPParams(w io.Writer, msg *lspMessage, err error, setting Setting) error {
+	if msg.ID != nil {
+		return writeLSPMessage(w, &lspMessage{ID: msg.ID, Error: &lspError{Code: lspInvalidParams, Message: fmt.Sprintf("invalid params of %s: %v", msg.Method, err)}})
+	}
+	if setting.Logger != nil {
+		setting.Logger.Warn("ignoring notification with invalid params", "method", msg.Method, "err", err)
+	}
+	return nil
+}
+
+// publishDiagnostics publishes the diagnostics of the file at uri and returns
+// its issues.
+func publishDiagnostics(w io.Writer, uri string, setting Setting) ([]Issue, error) {
+	path, ok := uriToPath(uri)
+	if !ok || filepath.Ext(path) != ".go" {
+		return nil, nil
+	}
+
+	diagnostics := make([]lspDiagnostic, 0)
+	res, err := AnalyzeFiles(token.NewFileSet(), packageFiles(path), setting)
+	if err != nil {
+		if setting.Logger != nil {
+			setting.Logger.Debug("analyzing file failed", "file", path, "err", err)
+		}
+		// Unparsable files are the business of the compiler; clear the
+		// previous diagnostics rather than reporting stale ones.
+		res = &Result{}
+	}
+	var issues []Issue
+	for _, iss := range res.Issues {
+		if iss.Pos.Filename == path {
+			diagnostics = append(diagnostics, newLSPDiagnostic(iss))
+			issues = append(issues, iss)
+		}
+	}
+
+	params, err := json.Marshal(lspPublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+	if err != nil {
+		return nil, err
+	}
+	return issues, writeLSPMessage(w, &lspMessage{Method: "textDocument/publishDiagnostics", Params: params})
+}
+
+// codeActions returns the quick fixes of the issues within r.
+func codeActions(issues []Issue, r lspRange) []lspCodeAction {
+	actions := make([]lspCodeAction, 0)
+	for _, iss := range issues {
+		d := newLSPDiagnostic(iss)
+		if lspPositionLess(d.Range.End, r.Start) || lspPositionLess(r.End, d.Range.Start) {
+			continue
+		}
+		for _, fix := range iss.Fixes {
+			changes := make(map[string][]lspTextEdit)
+			for _, edit := range fix.Edits {
+				uri := pathToURI(edit.Pos.Filename)
+				changes[uri] = append(changes[uri], lspTextEdit{
+					Range:   lspRange{Start: newLSPPosition(edit.Pos), End: newLSPPosition(edit.End)},
+					NewText: edit.NewText,
+				})
+			}
+			actions = append(actions, lspCodeAction{Title: fix.Message, Kind: "quickfix", Diagnostics: []lspDiagnostic{d}, Edit: lspWorkspaceEdit{Changes: changes}})
+		}
+	}
+	return actions
+}
+
+func lspPositionLess(a, b lspPosition) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Character < b.Character
+}
+
+// packageFiles returns the file at path and the other files of its package, so
+// that identifiers declared in other files are resolved. Test files are only
+// included when linting a test file.
+func packageFiles(path string) []string {
+	files := []string{path}
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.go"))
+	if err != nil {
+		return files
+	}
+	for _, m := range matches {
+		if m != path && (strings.HasSuffix(path, "_test.go") || !strings.HasSuffix(m, "_test.go")) {
+			files = append(files, m)
+		}
+	}
+	return files
+}
+
+func newLSPDiagnostic(iss Issue) lspDiagnostic {
+	d := lspDiagnostic{
+		Range:    lspRange{Start: newLSPPosition(iss.Pos), End: newLSPPosition(iss.End)},
+		Severity: lspSeverityWarning,
+		Code:     iss.RuleID,
+		Source:   "promlinter",
+		Message:  iss.Text,
+	}
+	if !iss.End.IsValid() {
+		d.Range.End = d.Range.Start
+	}
+	if iss.Metric != "" {
+		d.Message = fmt.Sprintf("%s: %s", iss.Metric, iss.Text)
+	}
+	switch iss.Severity {
+	case SeverityError:
+		d.Severity = lspSeverityError
+	case SeverityInfo:
+		d.Severity = lspSeverityInformation
+	}
+	return d
+}
+
+// newLSPPosition converts a 1-based position to a 0-based LSP position.
+func newLSPPosition(pos token.Position) lspPosition {
+	p := lspPosition{Line: pos.Line - 1, Character: pos.Column - 1}
+	if p.Line < 0 {
+		p.Line = 0
+	}
+	if p.Character < 0 {
+		p.Character = 0
+	}
+	return p
+}
+
+func uriToPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
+
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// readLSPMessage reads a message framed by a Content-Length header. A body
+// which is not valid JSON is reported with an *lspBodyError.
+func readLSPMessage(r *bufio.Reader) (*lspMessage, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if v := strings.TrimPrefix(line, "Content-Length:"); v != line {
+			if length, err = strconv.Atoi(strings.TrimSpace(v)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q", v)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	msg := &lspMessage{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, &lspBodyError{err: err}
+	}
+	return msg, nil
+}
+
+// lspBodyError is returned by readLSPMessage for a message whose body is not
+// valid JSON. The message was read whole, so the next one can still be read.
+type lspBodyError struct {
+	err error
+}
+
+func (e *lspBodyError) Error() string {
+	return "parse error: " + e.err.Error()
+}
+
+func writeLSPMessage(w io.Writer, msg *lspMessage) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
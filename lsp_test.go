@@ -0,0 +1,232 @@
+package promlinter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServeLSP(t *testing.T) {
+	path, err := filepath.Abs("testdata/testdata.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + filepath.ToSlash(path)
+
+	var in bytes.Buffer
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didSave","params":{"textDocument":{"uri":%q}}}`, uri),
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+
+	var out bytes.Buffer
+	if err := ServeLSP(&in, &out, Setting{}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(&out)
+	var (
+		methods     []string
+		failures    int
+		diagnostics lspPublishDiagnosticsParams
+	)
+	for {
+		msg, err := readLSPMessage(r)
+		if err != nil {
+			break
+		}
+		methods = append(methods, msg.Method)
+		if msg.Error != nil {
+			failures++
+		}
+		if msg.Method == "textDocument/publishDiagnostics" {
+			if err := json.Unmarshal(msg.Params, &diagnostics); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if len(methods) != 4 || methods[1] != "textDocument/publishDiagnostics" || failures != 1 {
+		t.Fatalf("unexpected messages %v", methods)
+	}
+	if diagnostics.URI != uri || len(diagnostics.Diagnostics) != 2 {
+		t.Fatalf("unexpected diagnostics %+v", diagnostics)
+	}
+	d := diagnostics.Diagnostics[0]
+	if d.Code != RuleCounter || d.Severity != lspSeverityError || d.Range.Start != (lspPosition{Line: 13, Character: 9}) || d.Range.End != (lspPosition{Line: 13, Character: 27}) {
+		t.Fatalf("unexpected diagnostic %+v", d)
+	}
+}
+
+func TestServeLSPCodeActions(t *testing.T) {
+	path, err := filepath.Abs("testdata/fixes/fixes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + filepath.ToSlash(path)
+
+	var in bytes.Buffer
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":%q}}}`, uri),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"textDocument/codeAction","params":{"textDocument":{"uri":%q},"range":{"start":{"line":10,"character":10},"end":{"line":10,"character":10}}}}`, uri),
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+
+	var out bytes.Buffer
+	if err := ServeLSP(&in, &out, Setting{}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(&out)
+	var actions []lspCodeAction
+	for {
+		msg, err := readLSPMessage(r)
+		if err != nil {
+			break
+		}
+		if msg.ID != nil && string(*msg.ID) == "2" {
+			data, err := json.Marshal(msg.Result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(data, &actions); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if len(actions) != 1 || actions[0].Title != "Rename to request_latency_seconds" || actions[0].Kind != "quickfix" {
+		t.Fatalf("unexpected code actions %+v", actions)
+	}
+	expected := []lspTextEdit{{Range: lspRange{Start: lspPosition{Line: 10, Character: 8}, End: lspPosition{Line: 10, Character: 38}}, NewText: `"request_latency_seconds"`}}
+	if edits := actions[0].Edit.Changes[uri]; !reflect.DeepEqual(edits, expected) {
+		t.Fatalf("expected edits %+v, got %+v", expected, edits)
+	}
+}
+
+func TestServeLSPInvalidParams(t *testing.T) {
+	path, err := filepath.Abs("testdata/fixes/fixes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + filepath.ToSlash(path)
+
+	var in bytes.Buffer
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":"fixes.go"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/codeAction","params":{"range":5}}`,
+		fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":%q}}}`, uri),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":3,"method":"textDocument/codeAction","params":{"textDocument":{"uri":%q},"range":{"start":{"line":10,"character":10},"end":{"line":10,"character":10}}}}`, uri),
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+
+	var out bytes.Buffer
+	if err := ServeLSP(&in, &out, Setting{}); err != nil {
+		t.Fatalf("expected the server to survive invalid params, got %v", err)
+	}
+
+	r := bufio.NewReader(&out)
+	var (
+		published int
+		invalid   *lspError
+		actions   []lspCodeAction
+	)
+	for {
+		msg, err := readLSPMessage(r)
+		if err != nil {
+			break
+		}
+		switch {
+		case msg.Method == "textDocument/publishDiagnostics":
+			published++
+		case msg.ID != nil && string(*msg.ID) == "2":
+			invalid = msg.Error
+		case msg.ID != nil && string(*msg.ID) == "3":
+			data, err := json.Marshal(msg.Result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(data, &actions); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if invalid == nil || invalid.Code != lspInvalidParams {
+		t.Fatalf("expected an invalid params error for the code action, got %+v", invalid)
+	}
+	if published != 1 {
+		t.Fatalf("expected the diagnostics of the valid notification only, got %d", published)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("expected the code action of the valid request, got %+v", actions)
+	}
+}
+
+func TestServeLSPParseError(t *testing.T) {
+	var in bytes.Buffer
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":`,
+		`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+
+	var out bytes.Buffer
+	if err := ServeLSP(&in, &out, Setting{}); err != nil {
+		t.Fatalf("expected the server to survive a message which is not valid JSON, got %v", err)
+	}
+
+	// The null id of the parse error is decoded as a nil one.
+	if !bytes.Contains(out.Bytes(), []byte(`"id":null`)) {
+		t.Fatalf("expected an answer with a null id, got %s", out.Bytes())
+	}
+	r := bufio.NewReader(&out)
+	var (
+		ids      []string
+		parseErr *lspError
+	)
+	for {
+		msg, err := readLSPMessage(r)
+		if err != nil {
+			break
+		}
+		if msg.ID == nil {
+			ids = append(ids, "null")
+			parseErr = msg.Error
+			continue
+		}
+		ids = append(ids, string(*msg.ID))
+	}
+	if expected := []string{"1", "null", "3"}; !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected the answers %v, got %v", expected, ids)
+	}
+	if parseErr == nil || parseErr.Code != lspParseError {
+		t.Fatalf("expected a parse error, got %+v", parseErr)
+	}
+
+	// A broken framing stops the server.
+	if err := ServeLSP(strings.NewReader("Content-Length: many\r\n\r\n{}"), io.Discard, Setting{}); err == nil {
+		t.Fatal("expected an error for an invalid Content-Length")
+	}
+}
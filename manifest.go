@@ -0,0 +1,129 @@
+package promlinter
+
+import (
+	"encoding/json"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Manifest maps every discovered metric to where and how it is defined and
+// registered. It is the machine-readable artifact for other tools to build
+// on; its fields are only ever added to.
+type Manifest struct {
+	Metrics []ManifestMetric `json:"metrics"`
+}
+
+// ManifestMetric is the provenance of a metric family.
+type ManifestMetric struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Help   string   `json:"help,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+	// Module is the path of the module defining the metric and Package the
+	// import path of its package, if the file is part of a module.
+	Module  string `json:"module,omitempty"`
+	Package string `json:"package,omitempty"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	// Constructor is the function creating the metric, e.g. NewCounterVec,
+	// and AutoRegistered is true if it registers it, like promauto.
+	Constructor    string `json:"constructor"`
+	AutoRegistered bool   `json:"auto_registered,omitempty"`
+	// Registrations are the calls registering the variable or field holding
+	// the metric, e.g. prometheus.MustRegister(requests).
+	Registrations []ManifestSite `json:"registrations,omitempty"`
+}
+
+// ManifestSite is a position in the source code.
+type ManifestSite struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+func manifestSite(pos token.Position) ManifestSite {
+	return ManifestSite{File: pos.Filename, Line: pos.Line, Column: pos.Column}
+}
+
+// NewManifest builds the manifest of the metrics of res, in the order of
+// res.Metrics. The modules are found from the go.mod files enclosing the
+// files of the metrics.
+func NewManifest(res *Result) *Manifest {
+	modules := moduleFinder{dirs: make(map[string]moduleDir)}
+	manifest := &Manifest{Metrics: make([]ManifestMetric, 0, len(res.Metrics))}
+	for _, m := range res.Metrics {
+		mf := m.MetricFamily
+		entry := ManifestMetric{
+			Name:           mf.GetName(),
+			Type:           metricTypeName(mf.GetType()),
+			Help:           mf.GetHelp(),
+			Labels:         m.Labels(),
+			File:           m.Pos.Filename,
+			Line:           m.Pos.Line,
+			Column:         m.Pos.Column,
+			Constructor:    m.Constructor,
+			AutoRegistered: m.AutoRegistered,
+		}
+		if mod := modules.find(filepath.Dir(m.Pos.Filename)); mod.path != "" {
+			entry.Module = mod.path
+			entry.Package = path.Join(mod.path, filepath.ToSlash(mod.rel))
+		}
+		if m.holder != "" {
+			sites := append([]token.Position(nil), res.registrations[m.holder]...)
+			sort.Slice(sites, func(i, j int) bool { return positionLess(sites[i], sites[j]) })
+			for _, site := range sites {
+				entry.Registrations = append(entry.Registrations, manifestSite(site))
+			}
+		}
+		manifest.Metrics = append(manifest.Metrics, entry)
+	}
+	return manifest
+}
+
+// moduleDir is the module of a directory: its path, and the directory
+// relative to the root of the module.
+type moduleDir struct {
+	path, rel string
+}
+
+// moduleFinder finds the module of directories from their go.mod files,
+// caching the results.
+type moduleFinder struct {
+	dirs map[string]moduleDir
+}
+
+func (f *moduleFinder) find(dir string) moduleDir {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return moduleDir{}
+	}
+	if mod, ok := f.dirs[abs]; ok {
+		return mod
+	}
+
+	var mod moduleDir
+	if data, err := os.ReadFile(filepath.Join(abs, "go.mod")); err == nil {
+		mod = moduleDir{path: modfile.ModulePath(data), rel: "."}
+	} else if parent := filepath.Dir(abs); parent != abs {
+		mod = f.find(parent)
+		if mod.path != "" {
+			mod.rel = filepath.Join(mod.rel, filepath.Base(abs))
+		}
+	}
+	f.dirs[abs] = mod
+	return mod
+}
+
+// Write encodes the manifest as indented JSON.
+func (m *Manifest) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
@@ -0,0 +1,44 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir := filepath.Join("testdata", "dead")
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join(dir, "dead.go"), filepath.Join(dir, "use.go")}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := NewManifest(res)
+
+	byName := make(map[string]ManifestMetric)
+	for _, m := range manifest.Metrics {
+		byName[m.Name] = m
+	}
+
+	errors := byName["errors_total"]
+	expected := ManifestMetric{
+		Name:          "errors_total",
+		Type:          "counter",
+		Help:          "Number of errors.",
+		Labels:        []string{"code"},
+		Module:        "github.com/yeya24/promlinter",
+		Package:       "github.com/yeya24/promlinter/testdata/dead",
+		File:          filepath.Join(dir, "dead.go"),
+		Line:          11,
+		Column:        38,
+		Constructor:   "NewCounterVec",
+		Registrations: []ManifestSite{{File: filepath.Join(dir, "dead.go"), Line: 25, Column: 2}},
+	}
+	if !reflect.DeepEqual(errors, expected) {
+		t.Fatalf("expected\n%+v\ngot\n%+v", expected, errors)
+	}
+
+	if requests := byName["requests_total"]; !requests.AutoRegistered || requests.Constructor != "NewCounter" || len(requests.Registrations) != 0 {
+		t.Fatalf("expected requests_total to be registered by promauto, got %+v", requests)
+	}
+}
@@ -0,0 +1,80 @@
+package promlinter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// MessageData is given to the message templates: the issue, whose Text is
+// the default message, and its rule. Rule is empty for the script rules.
+type MessageData struct {
+	Issue
+	Rule Rule
+}
+
+// LoadMessageTemplates reads the message templates of the YAML file at path,
+// by rule ID or name, e.g. to link the issues to the naming guidelines of an
+// organization:
+//
+//	messages:
+//	  PL003: '{{.Text}}, see https://wiki.example.com/metrics#counters'
+//	  CamelCase: 'rename {{.Metric}} to snake_case'
+//
+// The templates receive a MessageData and may call the functions of the
+// script rules, see ScriptRule.
+func LoadMessageTemplates(path string) (map[string]*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Messages map[string]string `yaml:"messages"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	templates := make(map[string]*template.Template, len(file.Messages))
+	for rule, text := range file.Messages {
+		// The IDs of script rules are not known yet.
+		id := rule
+		if r, ok := ResolveRule(rule); ok {
+			id = r.ID
+		}
+		if _, ok := templates[id]; ok {
+			return nil, fmt.Errorf("%s: several messages for rule %s", path, id)
+		}
+		t, err := template.New(id).Funcs(scriptFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		templates[id] = t
+	}
+	return templates, nil
+}
+
+// formatMessages renders the text of the issues whose rule has a message
+// template. A template failing at runtime keeps the default text, followed
+// by the error.
+func (s Setting) formatMessages(issues []Issue) {
+	if len(s.MessageTemplates) == 0 {
+		return
+	}
+	var buf bytes.Buffer
+	for i, iss := range issues {
+		t, ok := s.MessageTemplates[iss.RuleID]
+		if !ok {
+			continue
+		}
+		r, _ := LookupRule(iss.RuleID)
+		buf.Reset()
+		if err := t.Execute(&buf, MessageData{iss, r}); err != nil {
+			issues[i].Text = fmt.Sprintf("%s (message template failed: %v)", iss.Text, err)
+			continue
+		}
+		issues[i].Text = buf.String()
+	}
+}
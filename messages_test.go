@@ -0,0 +1,35 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMessageTemplates(t *testing.T) {
+	templates, err := LoadMessageTemplates(filepath.Join("testdata", "messages", "messages.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{filepath.Join("testdata", "messages", "messages.go")}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{MessageTemplates: templates})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		got = append(got, iss.RuleID+" "+iss.Text)
+	}
+	sort.Strings(got)
+	expected := []string{
+		`PL001 no help text (message template failed: template: PL001:1:10: executing "PL001" at <.Missing.Field>: can't evaluate field Missing in type promlinter.MessageData)`,
+		`PL003 counter metrics should have "_total" suffix, see https://wiki.example.com/metrics#counters`,
+		"PL007 rename messageQueueLength (gauge) to snake_case, see CamelCase in the guidelines",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
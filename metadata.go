@@ -0,0 +1,145 @@
+package promlinter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MetricMetadata is the metadata of a metric name scraped by Prometheus.
+type MetricMetadata struct {
+	Type string `json:"type"`
+	Help string `json:"help"`
+	Unit string `json:"unit"`
+}
+
+// TargetMetadata is the metadata of a metric name exposed by a target.
+type TargetMetadata struct {
+	Target map[string]string `json:"target"`
+	MetricMetadata
+}
+
+// Metadata returns the distinct metadata of every metric name scraped by the
+// server, from /api/v1/metadata.
+func (c *PrometheusClient) Metadata() (map[string][]MetricMetadata, error) {
+	metadata := make(map[string][]MetricMetadata)
+	if err := c.get("/api/v1/metadata", nil, &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// TargetMetadata returns the metadata of the metric name exposed by each
+// target matching the selector, from /api/v1/targets/metadata.
+func (c *PrometheusClient) TargetMetadata(selector, metric string) ([]TargetMetadata, error) {
+	var metadata []TargetMetadata
+	params := url.Values{"match_target": {selector}, "metric": {metric}}
+	if err := c.get("/api/v1/targets/metadata", params, &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// metadataType returns the type of the metadata API for a type of the
+// inventory.
+func metadataType(t string) string {
+	if t == "untyped" {
+		return "unknown"
+	}
+	return t
+}
+
+// MetadataConflicts reports the metrics of inv whose name is exposed by other
+// jobs of the server with another type or help text. The jobs built from the
+// code of inv are excluded, so that the changes not yet deployed are not
+// reported.
+func (c *PrometheusClient) MetadataConflicts(inv *Inventory, jobs []string) ([]Issue, error) {
+	metadata, err := c.Metadata()
+	if err != nil {
+		return nil, err
+	}
+	selector := `{job=~".+"}`
+	if len(jobs) > 0 {
+		quoted := make([]string, len(jobs))
+		for i, job := range jobs {
+			quoted[i] = regexp.QuoteMeta(job)
+		}
+		selector = fmt.Sprintf("{job!~%q}", strings.Join(quoted, "|"))
+	}
+
+	// The help texts are not compared when the code has none, which the
+	// Help validation reports.
+	differs := func(m InventoryMetric, md MetricMetadata) bool {
+		return md.Type != metadataType(m.Type) || m.Help != "" && strings.TrimSpace(md.Help) != strings.TrimSpace(m.Help)
+	}
+
+	var issues []Issue
+	seen := make(map[string]bool)
+	for _, m := range inv.Metrics {
+		if seen[m.Name+"\x00"+m.Position] {
+			continue
+		}
+		seen[m.Name+"\x00"+m.Position] = true
+		conflict := false
+		for _, md := range metadata[m.Name] {
+			conflict = conflict || differs(m, md)
+		}
+		if !conflict {
+			continue
+		}
+
+		targets, err := c.TargetMetadata(selector, m.Name)
+		if err != nil {
+			return nil, err
+		}
+		// The jobs of the other targets are grouped by conflicting type,
+		// or by help text when the type agrees.
+		byType, byHelp := make(map[string][]string), make(map[string][]string)
+		for _, t := range targets {
+			if !differs(m, t.MetricMetadata) {
+				continue
+			}
+			if t.Type != metadataType(m.Type) {
+				byType[t.Type] = appendUnique(byType[t.Type], t.Target["job"])
+			} else {
+				byHelp[t.Help] = appendUnique(byHelp[t.Help], t.Target["job"])
+			}
+		}
+		for _, typ := range jobKeys(byType) {
+			issue := inventoryIssue(m, RuleMetadataConflict)
+			issue.Text = fmt.Sprintf("metric is exposed as a %s by the jobs %s", typ, strings.Join(byType[typ], ", "))
+			issue.Severity = SeverityError
+			issues = append(issues, issue)
+		}
+		for _, help := range jobKeys(byHelp) {
+			issue := inventoryIssue(m, RuleMetadataConflict)
+			issue.Text = fmt.Sprintf("metric is exposed with the help %q by the jobs %s", help, strings.Join(byHelp[help], ", "))
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// appendUnique appends s to the sorted slice list unless it holds it.
+func appendUnique(list []string, s string) []string {
+	i := sort.SearchStrings(list, s)
+	if i < len(list) && list[i] == s {
+		return list
+	}
+	list = append(list, "")
+	copy(list[i+1:], list[i:])
+	list[i] = s
+	return list
+}
+
+// jobKeys returns the sorted keys of jobs.
+func jobKeys(jobs map[string][]string) []string {
+	keys := make([]string, 0, len(jobs))
+	for k := range jobs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,76 @@
+package promlinter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetadataConflicts(t *testing.T) {
+	var selector string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data interface{}
+		switch r.URL.Path {
+		case "/api/v1/metadata":
+			data = map[string][]MetricMetadata{
+				"jobs_processed_total":   {{Type: "counter", Help: "Jobs processed."}, {Type: "gauge", Help: "Jobs processed."}},
+				"queue_length":           {{Type: "gauge", Help: "Jobs waiting."}, {Type: "gauge", Help: "Length of the queue."}},
+				"http_requests_total":    {{Type: "counter", Help: "Requests served."}},
+				"cache_hits":             {{Type: "unknown", Help: "Cache hits."}},
+				"build_duration_seconds": {{Type: "histogram", Help: "Older help."}},
+			}
+		case "/api/v1/targets/metadata":
+			selector = r.URL.Query().Get("match_target")
+			data = map[string][]TargetMetadata{
+				"jobs_processed_total": {
+					{Target: map[string]string{"job": "billing"}, MetricMetadata: MetricMetadata{Type: "gauge", Help: "Jobs processed."}},
+					{Target: map[string]string{"job": "batch"}, MetricMetadata: MetricMetadata{Type: "gauge", Help: "Jobs processed."}},
+					{Target: map[string]string{"job": "batch"}, MetricMetadata: MetricMetadata{Type: "gauge", Help: "Jobs processed."}},
+				},
+				"queue_length": {
+					{Target: map[string]string{"job": "worker"}, MetricMetadata: MetricMetadata{Type: "gauge", Help: "Length of the queue."}},
+				},
+				// Only the excluded jobs expose the older help.
+				"build_duration_seconds": {},
+			}[r.URL.Query().Get("metric")]
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "data": data})
+	}))
+	defer server.Close()
+
+	inv := &Inventory{Metrics: []InventoryMetric{
+		{Name: "jobs_processed_total", Type: "counter", Help: "Jobs processed.", Position: "jobs.go:10:2"},
+		{Name: "queue_length", Type: "gauge", Help: "Jobs waiting.", Position: "jobs.go:15:2"},
+		{Name: "http_requests_total", Type: "counter", Help: "Requests served.", Position: "http.go:8:2"},
+		{Name: "cache_hits", Type: "untyped", Help: "Cache hits.", Position: "cache.go:5:2"},
+		{Name: "build_duration_seconds", Type: "histogram", Help: "Duration of the builds.", Position: "build.go:5:2"},
+	}}
+	issues, err := (&PrometheusClient{URL: server.URL}).MetadataConflicts(inv, []string{"api", "api-canary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		pos      string
+		severity Severity
+		text     string
+	}{
+		{"jobs.go:10:2", SeverityError, "metric is exposed as a gauge by the jobs batch, billing"},
+		{"jobs.go:15:2", SeverityWarning, `metric is exposed with the help "Length of the queue." by the jobs worker`},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("expected %d issues, got %+v", len(expected), issues)
+	}
+	for i, e := range expected {
+		iss := issues[i]
+		if iss.Pos.String() != e.pos || iss.RuleID != RuleMetadataConflict || iss.Severity != e.severity || iss.Text != e.text {
+			t.Errorf("expected %s %s %q, got %s %s %s %q", e.pos, e.severity, e.text, iss.Pos, iss.RuleID, iss.Severity, iss.Text)
+		}
+	}
+	if want := `{job!~"api|api-canary"}`; selector != want {
+		t.Errorf("expected the jobs of the code to be excluded with %s, got %s", want, selector)
+	}
+}
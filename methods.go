@@ -0,0 +1,186 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// maxCallDepth bounds the nesting of the calls followed to resolve Opts.
+const maxCallDepth = 8
+
+// parseCallOpts parses the Opts returned by a call to a function or method
+// of the package, such as a method building them from the fields of a config
+// receiver:
+//
+//	func (c *Config) counterOpts() prometheus.CounterOpts {
+//		return prometheus.CounterOpts{Namespace: c.Namespace, Name: "requests_total"}
+//	}
+//
+//	cfg := &Config{Namespace: "acme"}
+//	prometheus.NewCounter(cfg.counterOpts())
+//
+// The receiver and the parameters are bound to the expressions of the call
+// while the returned Opts are parsed.
+func (v *visitor) parseCallOpts(call *ast.CallExpr) (*opt, *string) {
+	var (
+		fn   *ast.Ident
+		recv ast.Expr
+	)
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		fn = fun
+	case *ast.SelectorExpr:
+		fn, recv = fun.Sel, fun.X
+	default:
+		return nil, nil
+	}
+
+	decl := v.types.funcs[v.types.info.Uses[fn]]
+	if decl == nil || decl.Body == nil || len(v.bindings) >= maxCallDepth {
+		v.debug(call, "unresolved call", "function", fn.Name)
+		return nil, nil
+	}
+	result := singleResult(decl.Body)
+	if result == nil {
+		v.debug(call, "function does not have a single return statement", "function", fn.Name)
+		return nil, nil
+	}
+
+	bindings := make(map[types.Object]ast.Expr)
+	if decl.Recv != nil && recv != nil {
+		for _, field := range decl.Recv.List {
+			for _, name := range field.Names {
+				bindings[v.types.info.Defs[name]] = recv
+			}
+		}
+	}
+	i := 0
+	for _, field := range decl.Type.Params.List {
+		for _, name := range field.Names {
+			if _, variadic := field.Type.(*ast.Ellipsis); i < len(call.Args) && !variadic {
+				bindings[v.types.info.Defs[name]] = call.Args[i]
+			}
+			i++
+		}
+	}
+
+	v.bindings = append(v.bindings, bindings)
+	defer func() { v.bindings = v.bindings[:len(v.bindings)-1] }()
+	return v.parseOpts(result)
+}
+
+// singleResult returns the result of the only return statement of body, if
+// it returns a single value.
+func singleResult(body *ast.BlockStmt) ast.Expr {
+	var results []ast.Expr
+	returns := 0
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			returns++
+			results = n.Results
+		}
+		return true
+	})
+	if returns != 1 || len(results) != 1 {
+		return nil
+	}
+	return results[0]
+}
+
+// bound returns the expression bound to the receiver or parameter referred
+// to by ident, in the innermost call being parsed.
+func (v *visitor) bound(ident *ast.Ident) ast.Expr {
+	if len(v.bindings) == 0 {
+		return nil
+	}
+	obj := v.types.info.Uses[ident]
+	if obj == nil {
+		return nil
+	}
+	return v.bindings[len(v.bindings)-1][obj]
+}
+
+// caller parses the following expressions in the context of the caller of
+// the innermost call being parsed, to which the bound expressions belong. The
+// returned function restores the context.
+func (v *visitor) caller() func() {
+	saved := v.bindings
+	v.bindings = v.bindings[:len(v.bindings)-1]
+	return func() { v.bindings = saved }
+}
+
+// parseFieldValue parses the value of a field of a struct variable, or of a
+// receiver or parameter bound to a struct, e.g. c.Namespace.
+func (v *visitor) parseFieldValue(object string, sel *ast.SelectorExpr) (string, bool) {
+	x, ok := sel.X.(*ast.Ident)
+	if !ok || v.types.isPackage(x) {
+		return "", false
+	}
+
+	if bound := v.bound(x); bound != nil {
+		defer v.caller()()
+
+		if lit := structLiteral(bound); lit != nil {
+			if value := structField(lit, sel.Sel.Name); value != nil {
+				return v.parseValue(object, value)
+			}
+			return "", false
+		}
+		ident, ok := bound.(*ast.Ident)
+		if !ok {
+			return "", false
+		}
+		x = ident
+	}
+
+	defs, ok := v.types.reaching(x, sel.Sel.Name)
+	if !ok || len(defs) == 0 {
+		v.debug(sel, "unresolved field", "field", object, "identifier", x.Name)
+		return "", false
+	}
+	values := make([]ast.Expr, 0, len(defs))
+	for _, d := range defs {
+		value := d.value
+		if d.field == "" {
+			value = nil
+			if lit := structLiteral(d.value); lit != nil {
+				value = structField(lit, sel.Sel.Name)
+			}
+		}
+		values = append(values, value)
+	}
+	return v.resolveDefinitions(x, x.Name+"."+sel.Sel.Name, object, values)
+}
+
+// structLiteral returns the composite literal of a struct value or pointer,
+// e.g. Config{...} or &Config{...}.
+func structLiteral(expr ast.Expr) *ast.CompositeLit {
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		expr = u.X
+	}
+	lit, _ := expr.(*ast.CompositeLit)
+	return lit
+}
+
+// structField returns the value of a field in a keyed struct literal, or an
+// empty string literal if the field is not set. It returns nil for unkeyed
+// literals.
+func structField(lit *ast.CompositeLit, field string) ast.Expr {
+	if len(lit.Elts) > 0 {
+		if _, keyed := lit.Elts[0].(*ast.KeyValueExpr); !keyed {
+			return nil
+		}
+	}
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name == field {
+				return kv.Value
+			}
+		}
+	}
+	return &ast.BasicLit{ValuePos: lit.Pos(), Kind: token.STRING, Value: `""`}
+}
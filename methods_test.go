@@ -0,0 +1,29 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMethodOpts(t *testing.T) {
+	bothResolvers(t, func(t *testing.T, ssa bool) {
+		res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "methods", "methods.go")}, Setting{SSA: ssa})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names, helps []string
+		for _, m := range res.Metrics {
+			names = append(names, m.MetricFamily.GetName())
+			helps = append(helps, m.MetricFamily.GetHelp())
+		}
+		if expected := []string{"acme_api_requests_total", "worker_queue_jobs_total", "worker_pool_size", "acme_up"}; !reflect.DeepEqual(names, expected) {
+			t.Fatalf("expected metrics %v, got %v", expected, names)
+		}
+		if expected := "Number of requests."; helps[0] != expected {
+			t.Fatalf("expected help %q, got %q", expected, helps[0])
+		}
+	})
+}
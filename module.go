@@ -0,0 +1,109 @@
+package promlinter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ModuleTree is a module downloaded to the module cache, e.g. a dependency
+// whose instrumentation is audited before adopting it.
+type ModuleTree struct {
+	Path    string
+	Version string
+	// Dir is the directory of the module in the module cache.
+	Dir string
+	// Files holds the paths of the Go files of the packages of the module,
+	// relative to Dir.
+	Files []string
+}
+
+// DownloadModule downloads the module of query, e.g.
+// example.com/some/lib@v1.4.0 or example.com/some/lib for its latest version,
+// to the module cache with go mod download, which uses the GOPROXY, GOFLAGS
+// and GOPRIVATE settings of the environment.
+//
+// Like the ./... pattern, the files of the vendor and testdata directories and
+// of the directories starting with . or _ are left out.
+func DownloadModule(query string) (*ModuleTree, error) {
+	if !strings.Contains(query, "@") {
+		query += "@latest"
+	}
+	cmd := exec.Command("go", "mod", "download", "-json", query)
+	out, err := cmd.Output()
+	// The error of the download is part of the JSON output.
+	var mod struct {
+		Path, Version, Dir, Error string
+	}
+	jerr := json.Unmarshal(out, &mod)
+	if jerr == nil && mod.Error != "" {
+		return nil, fmt.Errorf("downloading %s: %s", query, mod.Error)
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return nil, fmt.Errorf("downloading %s: %v: %s", query, err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %v", query, err)
+	}
+	if jerr != nil {
+		return nil, fmt.Errorf("downloading %s: %v", query, jerr)
+	}
+
+	t := &ModuleTree{Path: mod.Path, Version: mod.Version, Dir: mod.Dir}
+	files, err := goFiles(t.Dir, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		rel, err := filepath.Rel(t.Dir, f)
+		if err != nil {
+			return nil, err
+		}
+		t.Files = append(t.Files, filepath.ToSlash(rel))
+	}
+	sort.Strings(t.Files)
+	return t, nil
+}
+
+// Paths returns the paths of the Go files in the module cache.
+func (t *ModuleTree) Paths() []string {
+	paths := make([]string, 0, len(t.Files))
+	for _, f := range t.Files {
+		paths = append(paths, filepath.Join(t.Dir, filepath.FromSlash(f)))
+	}
+	return paths
+}
+
+// Restore returns the issues with the paths of the files in the module cache
+// replaced by the module path and version followed by the path of the file in
+// the module, e.g. example.com/some/lib@v1.4.0/metrics.go, see RestorePath.
+func (t *ModuleTree) Restore(issues []Issue) []Issue {
+	restore := func(filename string) string {
+		path, _ := t.RestorePath(filename)
+		return path
+	}
+	res := make([]Issue, 0, len(issues))
+	for _, iss := range issues {
+		res = append(res, iss.MapFilenames(restore))
+	}
+	return res
+}
+
+// RestorePath returns the module path and version followed by the path in
+// the module of the file filename of the module cache, and whether it is a
+// file of the module. The other paths are returned unchanged.
+func (t *ModuleTree) RestorePath(filename string) (string, bool) {
+	rel, err := filepath.Rel(t.Dir, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filename, false
+	}
+	return t.Path + "@" + t.Version + "/" + filepath.ToSlash(rel), true
+}
+
+// Close does nothing: the module is kept in the module cache.
+func (t *ModuleTree) Close() error {
+	return nil
+}
@@ -0,0 +1,50 @@
+package promlinter
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestDownloadModule(t *testing.T) {
+	// client_golang is a dependency of promlinter, so it is in the module
+	// cache and is found without the network.
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+	tree, err := DownloadModule("github.com/prometheus/client_golang@v1.7.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+
+	if tree.Path != "github.com/prometheus/client_golang" || tree.Version != "v1.7.1" {
+		t.Errorf("expected client_golang v1.7.1, got %s %s", tree.Path, tree.Version)
+	}
+	var counter bool
+	for _, f := range tree.Files {
+		counter = counter || f == "prometheus/counter.go"
+		if strings.Contains(f, "testdata/") || strings.HasPrefix(f, ".") {
+			t.Errorf("expected %s to be left out", f)
+		}
+	}
+	if !counter {
+		t.Errorf("expected prometheus/counter.go in %v", tree.Files)
+	}
+
+	res, err := AnalyzeFiles(token.NewFileSet(), tree.Paths(), Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Metrics) == 0 {
+		t.Fatal("expected the metrics of client_golang")
+	}
+	for _, iss := range tree.Restore(res.Issues) {
+		if !strings.HasPrefix(iss.Pos.Filename, "github.com/prometheus/client_golang@v1.7.1/") {
+			t.Errorf("expected the path of the issue in the module, got %s", iss.Pos.Filename)
+		}
+	}
+
+	if _, err := DownloadModule("example.com/missing@v1.0.0"); err == nil {
+		t.Error("expected an error for a missing module")
+	}
+}
@@ -0,0 +1,174 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// NameValidation is the scheme validating metric and label names.
+type NameValidation string
+
+const (
+	// LegacyValidation only allows the names of the classic character set,
+	// [a-zA-Z_:][a-zA-Z0-9_:]* for metrics and [a-zA-Z_][a-zA-Z0-9_]* for
+	// labels. It is the default.
+	LegacyValidation NameValidation = "legacy"
+	// UTF8Validation allows any non-empty UTF-8 name, as Prometheus 3.x does.
+	UTF8Validation NameValidation = "utf8"
+)
+
+// NameEscaping is the scheme escaping UTF-8 names for the scrapers and
+// queriers which only support the legacy character set, like the escaping
+// schemes of Prometheus.
+type NameEscaping string
+
+const (
+	// UnderscoreEscaping replaces each invalid character with an
+	// underscore. It is the default.
+	UnderscoreEscaping NameEscaping = "underscores"
+	// DotsEscaping replaces dots with _dot_, underscores with __ and the
+	// other invalid characters with __.
+	DotsEscaping NameEscaping = "dots"
+	// ValueEscaping prefixes the name with U__ and replaces underscores with
+	// __ and the other invalid characters with their code point, e.g. _2e_.
+	ValueEscaping NameEscaping = "values"
+)
+
+// isLegacyRune reports whether r is valid at index i of a legacy metric name,
+// or of a label name if label is true.
+func isLegacyRune(r rune, i int, label bool) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_' || (r == ':' && !label) || (r >= '0' && r <= '9' && i > 0)
+}
+
+func isLegacyName(name string, label bool) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !isLegacyRune(r, i, label) {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeName escapes a metric name with the scheme.
+func escapeName(name string, scheme NameEscaping) string {
+	if isLegacyName(name, false) {
+		return name
+	}
+	var sb strings.Builder
+	switch scheme {
+	case DotsEscaping:
+		for i, r := range name {
+			switch {
+			case r == '_':
+				sb.WriteString("__")
+			case r == '.':
+				sb.WriteString("_dot_")
+			case isLegacyRune(r, i, false):
+				sb.WriteRune(r)
+			default:
+				sb.WriteString("__")
+			}
+		}
+	case ValueEscaping:
+		sb.WriteString("U__")
+		for i, r := range name {
+			switch {
+			case r == '_':
+				sb.WriteString("__")
+			case isLegacyRune(r, i, false):
+				sb.WriteRune(r)
+			default:
+				fmt.Fprintf(&sb, "_%x_", r)
+			}
+		}
+	default:
+		for i, r := range name {
+			if isLegacyRune(r, i, false) {
+				sb.WriteRune(r)
+			} else {
+				sb.WriteByte('_')
+			}
+		}
+	}
+	return sb.String()
+}
+
+// lintNames reports the metric and label names of metric which are invalid
+// in the validation scheme of the setting.
+func (v *visitor) lintNames(metric MetricFamilyWithPos) {
+	utf8Names := v.setting.NameValidation == UTF8Validation
+	valid := func(name string, label bool) bool {
+		if utf8Names {
+			return name != "" && utf8.ValidString(name)
+		}
+		return isLegacyName(name, label)
+	}
+	scheme := "legacy"
+	if utf8Names {
+		scheme = "UTF-8"
+	}
+
+	report := func(text string) {
+		v.addIssue(Issue{
+			Pos:        metric.Pos,
+			End:        metric.End,
+			Metric:     metric.MetricFamily.GetName(),
+			Text:       text,
+			RuleID:     RuleInvalidName,
+			Severity:   ruleSeverity(RuleInvalidName),
+			MetricType: metricTypeName(metric.MetricFamily.GetType()),
+			Labels:     metric.Labels(),
+		})
+	}
+	if name := metric.MetricFamily.GetName(); !valid(name, false) {
+		report(fmt.Sprintf("metric name %q is invalid in the %s validation scheme", name, scheme))
+	}
+	for _, l := range metric.Labels() {
+		if !valid(l, true) {
+			report(fmt.Sprintf("label name %q is invalid in the %s validation scheme", l, scheme))
+		}
+	}
+}
+
+// escapingCollisions returns a check reporting the UTF-8 metric names which
+// are escaped with scheme to the name of another metric, so that their
+// series collide for the clients which only support legacy names.
+func escapingCollisions(scheme NameEscaping) func(*partialResult) []Issue {
+	return func(res *partialResult) []Issue {
+		byEscaped := make(map[string][]MetricFamilyWithPos)
+		for _, m := range res.metrics {
+			escaped := escapeName(m.MetricFamily.GetName(), scheme)
+			byEscaped[escaped] = append(byEscaped[escaped], m)
+		}
+
+		var issues []Issue
+		for _, m := range res.metrics {
+			name := m.MetricFamily.GetName()
+			escaped := escapeName(name, scheme)
+			if escaped == name {
+				continue
+			}
+			for _, other := range byEscaped[escaped] {
+				if other.MetricFamily.GetName() == name {
+					continue
+				}
+				issues = append(issues, Issue{
+					Pos:        m.Pos,
+					End:        m.End,
+					Metric:     name,
+					Text:       fmt.Sprintf("name is escaped to %q like %s at %s, for the clients without UTF-8 support", escaped, other.MetricFamily.GetName(), other.Pos),
+					RuleID:     RuleEscapingCollision,
+					Severity:   ruleSeverity(RuleEscapingCollision),
+					MetricType: metricTypeName(m.MetricFamily.GetType()),
+					Labels:     m.Labels(),
+				})
+				break
+			}
+		}
+		return issues
+	}
+}
@@ -0,0 +1,66 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNameValidation(t *testing.T) {
+	paths := []string{filepath.Join("testdata", "names", "names.go")}
+	for _, tc := range []struct {
+		setting  Setting
+		expected []string
+	}{
+		{
+			setting: Setting{},
+			expected: []string{
+				`PL031: metric name "http.requests_total" is invalid in the legacy validation scheme`,
+				`PL031: label name "queue-name" is invalid in the legacy validation scheme`,
+			},
+		},
+		{
+			setting: Setting{NameValidation: UTF8Validation},
+			expected: []string{
+				`PL032: name is escaped to "http_requests_total" like http_requests_total at testdata/names/names.go:10:33, for the clients without UTF-8 support`,
+			},
+		},
+		{
+			setting:  Setting{NameValidation: UTF8Validation, NameEscaping: ValueEscaping},
+			expected: nil,
+		},
+	} {
+		res, err := AnalyzeFiles(token.NewFileSet(), paths, tc.setting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, iss := range res.Issues {
+			if iss.RuleID == RuleInvalidName || iss.RuleID == RuleEscapingCollision {
+				got = append(got, iss.RuleID+": "+iss.Text)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("expected %q with %s validation, got %q", tc.expected, tc.setting.NameValidation, got)
+		}
+	}
+}
+
+func TestEscapeName(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		scheme   NameEscaping
+		expected string
+	}{
+		{"http_requests_total", UnderscoreEscaping, "http_requests_total"},
+		{"http.requests_total", UnderscoreEscaping, "http_requests_total"},
+		{"http.requests_total", DotsEscaping, "http_dot_requests__total"},
+		{"http.requests_total", ValueEscaping, "U__http_2e_requests__total"},
+		{"1st", UnderscoreEscaping, "_st"},
+	} {
+		if got := escapeName(tc.name, tc.scheme); got != tc.expected {
+			t.Errorf("expected %s to be escaped to %s with %s, got %s", tc.name, tc.expected, tc.scheme, got)
+		}
+	}
+}
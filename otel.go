@@ -0,0 +1,292 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// otelPackagePrefix is the prefix of the import paths of the OpenTelemetry
+// packages, whose meters create instruments exported by the Prometheus
+// exporter next to the metrics of client_golang.
+const otelPackagePrefix = "go.opentelemetry.io/otel"
+
+// otelInstrumentTypes map the methods of metric.Meter creating an instrument
+// to the type of the Prometheus metric it is exported as: the counters are
+// monotonic sums, exported as counters, and the up-down counters and gauges
+// as gauges.
+var otelInstrumentTypes = map[string]dto.MetricType{
+	"Int64Counter":                   dto.MetricType_COUNTER,
+	"Int64UpDownCounter":             dto.MetricType_GAUGE,
+	"Int64Histogram":                 dto.MetricType_HISTOGRAM,
+	"Int64Gauge":                     dto.MetricType_GAUGE,
+	"Int64ObservableCounter":         dto.MetricType_COUNTER,
+	"Int64ObservableUpDownCounter":   dto.MetricType_GAUGE,
+	"Int64ObservableGauge":           dto.MetricType_GAUGE,
+	"Float64Counter":                 dto.MetricType_COUNTER,
+	"Float64UpDownCounter":           dto.MetricType_GAUGE,
+	"Float64Histogram":               dto.MetricType_HISTOGRAM,
+	"Float64Gauge":                   dto.MetricType_GAUGE,
+	"Float64ObservableCounter":       dto.MetricType_COUNTER,
+	"Float64ObservableUpDownCounter": dto.MetricType_GAUGE,
+	"Float64ObservableGauge":         dto.MetricType_GAUGE,
+}
+
+// otelInstrument is an OpenTelemetry instrument created by a call to a
+// method of a meter, e.g. meter.Float64Histogram("http.server.duration",
+// metric.WithUnit("ms")).
+type otelInstrument struct {
+	Pos    token.Position `json:"pos"`
+	End    token.Position `json:"end"`
+	Name   string         `json:"name"`
+	Unit   string         `json:"unit,omitempty"`
+	Method string         `json:"method"`
+}
+
+// parseOTelInstrument records call if it creates an OpenTelemetry instrument
+// with a constant name and unit. The meter is recognized by its type, or by
+// the method name in the files importing an OpenTelemetry package when the
+// type could not be resolved.
+func (v *visitor) parseOTelInstrument(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) == 0 {
+		return
+	}
+	if _, ok := otelInstrumentTypes[sel.Sel.Name]; !ok {
+		return
+	}
+	if t := v.types.info.TypeOf(sel.X); t != nil && t.String() != "invalid type" {
+		if !strings.HasPrefix(t.String(), otelPackagePrefix+"/") {
+			return
+		}
+	} else if !v.imports.otel {
+		return
+	}
+
+	name := v.labelValue(call.Args[0])
+	if name == nil {
+		v.debug(call, "skipped OpenTelemetry instrument: name could not be resolved", "method", sel.Sel.Name)
+		return
+	}
+	inst := otelInstrument{
+		Pos:    v.fs.Position(call.Args[0].Pos()),
+		End:    v.fs.Position(call.Args[0].End()),
+		Name:   *name,
+		Method: sel.Sel.Name,
+	}
+	for _, arg := range call.Args[1:] {
+		opt, ok := ast.Unparen(arg).(*ast.CallExpr)
+		if !ok || len(opt.Args) != 1 || funcName(opt.Fun) != "WithUnit" {
+			continue
+		}
+		unit := v.labelValue(opt.Args[0])
+		if unit == nil {
+			v.debug(call, "skipped OpenTelemetry instrument: unit could not be resolved", "method", sel.Sel.Name)
+			return
+		}
+		inst.Unit = *unit
+	}
+	v.instruments = append(v.instruments, inst)
+}
+
+// otelUnits are the Prometheus names of the UCUM units of OpenTelemetry, and
+// otelPerUnits those of the units following a slash, e.g. the s of By/s.
+var (
+	otelUnits = map[string]string{
+		"d":    "days",
+		"h":    "hours",
+		"min":  "minutes",
+		"s":    "seconds",
+		"ms":   "milliseconds",
+		"us":   "microseconds",
+		"ns":   "nanoseconds",
+		"By":   "bytes",
+		"KiBy": "kibibytes",
+		"MiBy": "mebibytes",
+		"GiBy": "gibibytes",
+		"TiBy": "tibibytes",
+		"KBy":  "kilobytes",
+		"MBy":  "megabytes",
+		"GBy":  "gigabytes",
+		"TBy":  "terabytes",
+		"m":    "meters",
+		"V":    "volts",
+		"A":    "amperes",
+		"J":    "joules",
+		"W":    "watts",
+		"g":    "grams",
+		"Cel":  "celsius",
+		"Hz":   "hertz",
+		"%":    "percent",
+	}
+	otelPerUnits = map[string]string{
+		"s":  "second",
+		"m":  "minute",
+		"h":  "hour",
+		"d":  "day",
+		"w":  "week",
+		"mo": "month",
+		"y":  "year",
+	}
+)
+
+// otelUnitSuffix returns the suffix added to the names of the instruments of
+// a unit by the Prometheus exporter, e.g. _milliseconds for ms and
+// _bytes_per_second for By/s. The annotations in braces, e.g. {request}, are
+// dropped, and the unit 1 is a ratio for gauges only.
+func otelUnitSuffix(unit string, t dto.MetricType) string {
+	for {
+		i := strings.Index(unit, "{")
+		j := strings.Index(unit, "}")
+		if i < 0 || j < i {
+			break
+		}
+		unit = unit[:i] + unit[j+1:]
+	}
+	unit = strings.TrimSpace(unit)
+	if unit == "1" {
+		if t == dto.MetricType_GAUGE {
+			return "_ratio"
+		}
+		return ""
+	}
+
+	main, per, _ := strings.Cut(unit, "/")
+	var parts []string
+	if main != "" {
+		if name, ok := otelUnits[main]; ok {
+			main = name
+		}
+		parts = append(parts, main)
+	}
+	if per != "" {
+		if name, ok := otelPerUnits[per]; ok {
+			per = name
+		}
+		parts = append(parts, "per", per)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "_" + strings.Trim(sanitizeOTelName(strings.Join(parts, "_")), "_")
+}
+
+// sanitizeOTelName replaces the characters of name invalid in Prometheus
+// names by underscores, collapsing them, and prefixes the names starting with
+// a digit with an underscore.
+func sanitizeOTelName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		valid := r == '_' || r == ':' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' && i > 0
+		switch {
+		case valid:
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			b.WriteString("_")
+			b.WriteRune(r)
+		default:
+			b.WriteString("_")
+		}
+	}
+	s := b.String()
+	for strings.Contains(s, "__") {
+		s = strings.ReplaceAll(s, "__", "_")
+	}
+	return s
+}
+
+// prometheusName returns the name of the Prometheus metric the instrument is
+// exported as, following the specification of the compatibility of
+// OpenTelemetry with Prometheus: the name is sanitized, suffixed with its
+// unit unless it already ends with it, and with _total for counters.
+func (inst otelInstrument) prometheusName() string {
+	t := otelInstrumentTypes[inst.Method]
+	name := sanitizeOTelName(inst.Name)
+	if t == dto.MetricType_COUNTER {
+		name = strings.TrimSuffix(name, "_total")
+	}
+	if suffix := otelUnitSuffix(inst.Unit, t); suffix != "" && !strings.HasSuffix(name, suffix) {
+		name += suffix
+	}
+	if t == dto.MetricType_COUNTER {
+		name += "_total"
+	}
+	return name
+}
+
+// otelCollisions reports the OpenTelemetry instruments whose Prometheus name,
+// or one of its implicit series, is also exposed by a metric of
+// client_golang or by an instrument of another name: both are exported by
+// the same binary, and the registry or the exporter rejects or merges them.
+func otelCollisions(res *partialResult) []Issue {
+	type exposer struct {
+		pos        token.Position
+		what, name string
+	}
+	series := make(map[string]exposer)
+	for _, m := range res.metrics {
+		name, t := m.MetricFamily.GetName(), m.MetricFamily.GetType()
+		e := exposer{m.Pos, metricTypeName(t), name}
+		for _, s := range append([]string{name}, seriesSuffixes(name, t)...) {
+			if prev, ok := series[s]; !ok || positionLess(m.Pos, prev.pos) {
+				series[s] = e
+			}
+		}
+	}
+
+	var (
+		issues      []Issue
+		instruments = make(map[string]otelInstrument)
+	)
+	for _, inst := range res.instruments {
+		name, t := inst.prometheusName(), otelInstrumentTypes[inst.Method]
+		report := func(text string) {
+			issues = append(issues, Issue{
+				Pos:        inst.Pos,
+				Metric:     name,
+				Text:       text,
+				RuleID:     RuleOTelCollision,
+				Severity:   ruleSeverity(RuleOTelCollision),
+				End:        inst.End,
+				MetricType: metricTypeName(t),
+			})
+		}
+		exported := fmt.Sprintf("OpenTelemetry instrument %q is exported as the %s %s", inst.Name, metricTypeName(t), name)
+
+		reported := false
+		for _, s := range append([]string{name}, seriesSuffixes(name, t)...) {
+			if e, ok := series[s]; ok && !reported {
+				reported = true
+				if s == name && s == e.name {
+					report(fmt.Sprintf("%s, also the name of the %s defined at %s", exported, e.what, e.pos))
+				} else {
+					report(fmt.Sprintf("%s, whose series %s is also exposed by the %s %s defined at %s", exported, s, e.what, e.name, e.pos))
+				}
+			}
+		}
+		if reported {
+			continue
+		}
+		prev, ok := instruments[name]
+		switch {
+		case !ok:
+			instruments[name] = inst
+		case prev.Name != inst.Name:
+			report(fmt.Sprintf("%s, also the name of the instrument %q created at %s", exported, prev.Name, prev.Pos))
+		}
+	}
+	return issues
+}
+
+// seriesSuffixes returns the implicit series of the histograms and summaries
+// named name, e.g. name_count.
+func seriesSuffixes(name string, t dto.MetricType) []string {
+	var series []string
+	for _, suffix := range implicitSuffixes[t] {
+		series = append(series, name+suffix)
+	}
+	return series
+}
@@ -0,0 +1,53 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOTelPrometheusName(t *testing.T) {
+	for _, tc := range []struct {
+		inst     otelInstrument
+		expected string
+	}{
+		{otelInstrument{Name: "http.server.duration", Unit: "ms", Method: "Float64Histogram"}, "http_server_duration_milliseconds"},
+		{otelInstrument{Name: "http.server.requests", Method: "Int64Counter"}, "http_server_requests_total"},
+		{otelInstrument{Name: "requests_total", Unit: "{request}", Method: "Int64ObservableCounter"}, "requests_total"},
+		{otelInstrument{Name: "network.io", Unit: "By/s", Method: "Int64Counter"}, "network_io_bytes_per_second_total"},
+		{otelInstrument{Name: "cpu.utilization", Unit: "1", Method: "Float64Gauge"}, "cpu_utilization_ratio"},
+		{otelInstrument{Name: "cpu.time", Unit: "1", Method: "Float64Counter"}, "cpu_time_total"},
+		{otelInstrument{Name: "jobs..queued", Unit: "s", Method: "Int64ObservableGauge"}, "jobs_queued_seconds"},
+		{otelInstrument{Name: "2xx.responses", Method: "Int64UpDownCounter"}, "_2xx_responses"},
+		{otelInstrument{Name: "process.duration_seconds", Unit: "s", Method: "Float64Histogram"}, "process_duration_seconds"},
+	} {
+		if got := tc.inst.prometheusName(); got != tc.expected {
+			t.Errorf("expected %s to be exported as %s, got %s", tc.inst.Name, tc.expected, got)
+		}
+	}
+}
+
+func TestOTelCollisions(t *testing.T) {
+	path := filepath.Join("testdata", "otel", "otel.go")
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleOTelCollision {
+			got = append(got, iss.Pos.String()+" "+iss.Metric+" "+iss.Text)
+		}
+	}
+	expected := []string{
+		path + `:29:21 http_server_requests_total OpenTelemetry instrument "http.server.requests" is exported as the counter http_server_requests_total, also the name of the counter defined at ` + path + `:10:38`,
+		path + `:30:25 rpc_duration_milliseconds OpenTelemetry instrument "rpc.duration" is exported as the histogram rpc_duration_milliseconds, also the name of the histogram defined at ` + path + `:14:37`,
+		path + `:31:23 jobs_queue_length OpenTelemetry instrument "jobs.queue.length" is exported as the histogram jobs_queue_length, whose series jobs_queue_length_count is also exposed by the gauge jobs_queue_length_count defined at ` + path + `:18:30`,
+		path + `:33:31 jobs_in_flight OpenTelemetry instrument "jobs-in-flight" is exported as the gauge jobs_in_flight, also the name of the instrument "jobs.in_flight" created at ` + path + `:32:27`,
+		path + `:36:21 network_io_bytes_total OpenTelemetry instrument "network.io_bytes_total" is exported as the counter network_io_bytes_total, also the name of the instrument "network.io" created at ` + path + `:35:21`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
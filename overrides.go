@@ -0,0 +1,103 @@
+package promlinter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// RuleOverride changes the rules applied to the metrics whose name starts
+// with Prefix, e.g. stricter help rules for the public_ metrics and relaxed
+// suffix rules for the legacy_ ones. The name is matched once resolved, with
+// its namespace and subsystem.
+type RuleOverride struct {
+	Prefix string `yaml:"prefix" json:"prefix"`
+	// Enable and Disable list IDs or names of rules enabled or disabled for
+	// the metrics of the prefix, whatever the global setting. Severity
+	// changes the severity of the issues of some rules.
+	Enable   []string            `yaml:"enable,omitempty" json:"enable,omitempty"`
+	Disable  []string            `yaml:"disable,omitempty" json:"disable,omitempty"`
+	Severity map[string]Severity `yaml:"severity,omitempty" json:"severity,omitempty"`
+}
+
+// LoadRuleOverrides reads the rule overrides of the YAML file at path, e.g.
+//
+//	overrides:
+//	  - prefix: public_
+//	    enable: [PL023, PL024]
+//	    severity:
+//	      PL001: error
+//	  - prefix: legacy_
+//	    disable: [PL003]
+func LoadRuleOverrides(path string) ([]RuleOverride, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Overrides []RuleOverride `yaml:"overrides"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for _, o := range file.Overrides {
+		if o.Prefix == "" {
+			return nil, fmt.Errorf("%s: override without a prefix", path)
+		}
+		ids := append(append([]string(nil), o.Enable...), o.Disable...)
+		for id, severity := range o.Severity {
+			if _, ok := severityRanks[severity]; !ok {
+				return nil, fmt.Errorf("%s: invalid severity %q for rule %s", path, severity, id)
+			}
+			ids = append(ids, id)
+		}
+		for _, id := range ids {
+			if _, ok := ResolveRule(id); !ok {
+				return nil, fmt.Errorf("%s: unknown rule %s in the override of %s", path, id, o.Prefix)
+			}
+		}
+	}
+	return file.Overrides, nil
+}
+
+// override returns the override with the longest prefix of the metric name,
+// or nil.
+func (s Setting) override(name string) *RuleOverride {
+	var match *RuleOverride
+	for i, o := range s.RuleOverrides {
+		if strings.HasPrefix(name, o.Prefix) && (match == nil || len(o.Prefix) > len(match.Prefix)) {
+			match = &s.RuleOverrides[i]
+		}
+	}
+	return match
+}
+
+// apply reports whether iss is reported, see reports, once Severities and
+// the override of its metric are applied, which may change its severity. The
+// issues of dynamic names are matched by their resolved prefix.
+func (s Setting) apply(iss *Issue) bool {
+	for rule, severity := range s.Severities {
+		if listsRule([]string{rule}, iss.RuleID) {
+			iss.Severity = severity
+		}
+	}
+	name := iss.Metric
+	if name == "" {
+		name = iss.NamePrefix
+	}
+	o := s.override(name)
+	if name == "" || o == nil {
+		return s.reports(iss.RuleID)
+	}
+	if listsRule(o.Disable, iss.RuleID) {
+		return false
+	}
+	for rule, severity := range o.Severity {
+		if listsRule([]string{rule}, iss.RuleID) {
+			iss.Severity = severity
+		}
+	}
+	return listsRule(o.Enable, iss.RuleID) || s.reports(iss.RuleID)
+}
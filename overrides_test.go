@@ -0,0 +1,49 @@
+package promlinter
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRuleOverrides(t *testing.T) {
+	overrides, err := LoadRuleOverrides(filepath.Join("testdata", "overrides", "overrides.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "overrides", "overrides.go")}, Setting{RuleOverrides: overrides})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		got = append(got, iss.Metric+" "+iss.RuleID+" "+string(iss.Severity))
+	}
+	expected := []string{
+		"public_requests_total PL023 warning",
+		"public_up PL001 error",
+		"other_requests PL003 error",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestLoadRuleOverridesErrors(t *testing.T) {
+	for _, content := range []string{
+		"overrides:\n  - enable: [PL001]\n",
+		"overrides:\n  - prefix: a_\n    disable: [PL999]\n",
+		"overrides:\n  - prefix: a_\n    severity:\n      PL001: fatal\n",
+	} {
+		path := filepath.Join(t.TempDir(), "overrides.yml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRuleOverrides(path); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// reportDynamicName reports a constructor whose metric name could only be
+// resolved partly, e.g. a constant namespace followed by a name computed at
+// runtime, with the resolved prefix. arg is the first argument of the
+// constructor: Opts, or a Desc if desc is true. The issue is a warning in
+// strict mode and informational otherwise.
+func (v *visitor) reportDynamicName(arg ast.Expr, desc bool, metricType string) {
+	prefix, dynamic := v.namePrefix(arg, desc)
+	if !dynamic || prefix == "" {
+		return
+	}
+
+	severity := SeverityInfo
+	if v.setting.Strict {
+		severity = SeverityWarning
+	}
+	v.addIssue(Issue{
+		Pos:        v.fs.Position(arg.Pos()),
+		End:        v.fs.Position(arg.End()),
+		Text:       fmt.Sprintf("dynamic metric name, only the prefix %q could be resolved", prefix),
+		RuleID:     RuleDynamicName,
+		Severity:   severity,
+		MetricType: metricType,
+		NamePrefix: prefix,
+	})
+}
+
+// namePrefix returns the resolved prefix of the metric name defined by arg,
+// and whether the rest of the name is dynamic.
+func (v *visitor) namePrefix(arg ast.Expr, desc bool) (string, bool) {
+	// The values were already parsed, and their issues reported.
+	v.quiet = true
+	defer func() { v.quiet = false }()
+
+	if desc {
+		names := v.nameExprs(arg, true)
+		if len(names) != 1 {
+			return "", false
+		}
+		prefix, complete := v.valuePrefix(names[0])
+		return prefix, !complete
+	}
+
+	// The parts are joined like prometheus.BuildFQName does.
+	var parts []string
+	for _, field := range []string{"Namespace", "Subsystem", "Name"} {
+		values := v.fieldExprs(arg, field)
+		if len(values) == 0 {
+			if _, lit := arg.(*ast.CompositeLit); lit {
+				continue
+			}
+			return strings.Join(parts, "_"), true
+		}
+
+		var (
+			prefix   string
+			complete bool
+		)
+		if len(values) == 1 {
+			prefix, complete = v.valuePrefix(values[0])
+		}
+		if !complete {
+			joined := strings.Join(parts, "_")
+			if len(parts) > 0 {
+				joined += "_"
+			}
+			return joined + prefix, true
+		}
+		if prefix != "" {
+			parts = append(parts, prefix)
+		}
+	}
+	return "", false
+}
+
+// valuePrefix resolves the longest prefix of the string value of expr, and
+// whether it is the whole value.
+func (v *visitor) valuePrefix(expr ast.Expr) (string, bool) {
+	if s, ok := v.parseValue("prefix", expr); ok {
+		return s, true
+	}
+
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		return v.valuePrefix(t.X)
+
+	case *ast.BinaryExpr:
+		if t.Op != token.ADD {
+			return "", false
+		}
+		x, ok := v.valuePrefix(t.X)
+		if !ok {
+			return x, false
+		}
+		y, ok := v.valuePrefix(t.Y)
+		return x + y, ok
+
+	case *ast.Ident:
+		defs, _ := v.types.reaching(t, "")
+		if len(defs) == 1 && defs[0].value != nil {
+			return v.valuePrefix(defs[0].value)
+		}
+
+	case *ast.CallExpr:
+		// fmt.Sprintf("shard_%d_requests_total", i) starts with the text
+		// before the first verb.
+		if sel, ok := t.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Sprintf" && len(t.Args) > 0 {
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "fmt" && v.types.isPackage(pkg) {
+				if format, ok := v.parseValue("format", t.Args[0]); ok {
+					prefix, _, found := strings.Cut(format, "%")
+					return prefix, !found && len(t.Args) == 1
+				}
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,37 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDynamicName(t *testing.T) {
+	paths := []string{filepath.Join("testdata", "partial", "partial.go")}
+
+	for _, strict := range []bool{false, true} {
+		res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{Strict: strict})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedSeverity := SeverityInfo
+		if strict {
+			expectedSeverity = SeverityWarning
+		}
+		var prefixes []string
+		for _, iss := range res.Issues {
+			if iss.RuleID != RuleDynamicName {
+				continue
+			}
+			if iss.Severity != expectedSeverity {
+				t.Errorf("expected severity %s with strict=%v, got %s", expectedSeverity, strict, iss.Severity)
+			}
+			prefixes = append(prefixes, iss.NamePrefix)
+		}
+		if expected := []string{"acme_", "acme_pool_size_", "worker_"}; !reflect.DeepEqual(prefixes, expected) {
+			t.Fatalf("expected prefixes %v with strict=%v, got %v: %v", expected, strict, prefixes, res.Issues)
+		}
+	}
+}
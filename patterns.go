@@ -0,0 +1,188 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// LoadPatterns returns the Go files of the packages matched by the patterns
+// of the go command, e.g. ./..., example.com/mod/... or std, resolved in
+// dir. Like for directories, the files excluded by build constraints are
+// included, so that FilterFiles selects them; tests adds the _test.go files
+// of the packages, for FilterTestFiles. Patterns matching no package are an
+// error.
+//
+// Outside of a module, e.g. for legacy GOPATH code or generated snippets, the
+// directory patterns, e.g. ./... or ./cmd, are resolved by walking the
+// directories, since the go command cannot list their packages.
+func LoadPatterns(dir string, patterns []string, tests bool) ([]string, error) {
+	if !inModule(dir) && localPatterns(patterns) {
+		return walkPatterns(dir, patterns, tests)
+	}
+	cfg := &packages.Config{Dir: dir, Mode: packages.NeedName | packages.NeedFiles, Tests: tests}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		files []string
+		seen  = make(map[string]bool)
+	)
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			if e.Kind == packages.ListError {
+				return nil, fmt.Errorf("%s: %s", pkg.PkgPath, e.Msg)
+			}
+		}
+		if isTestMain(pkg) {
+			continue
+		}
+		for _, f := range append(pkg.GoFiles, pkg.IgnoredFiles...) {
+			if strings.HasSuffix(f, ".go") && !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files matched by %s", strings.Join(patterns, " "))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// inModule reports whether dir is in a module or a workspace, according to
+// the go command.
+func inModule(dir string) bool {
+	cmd := exec.Command("go", "env", "GOMOD", "GOWORK")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		// Let go/packages report the problem.
+		return true
+	}
+	for _, f := range strings.Fields(string(out)) {
+		if f != os.DevNull && f != "off" {
+			return true
+		}
+	}
+	return false
+}
+
+// localPatterns reports whether the patterns are all directories, relative
+// or absolute.
+func localPatterns(patterns []string) bool {
+	for _, p := range patterns {
+		if !(p == "." || p == ".." || strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") || filepath.IsAbs(p)) {
+			return false
+		}
+	}
+	return true
+}
+
+// walkPatterns returns the Go files of the directory patterns in dir, without
+// the go command.
+func walkPatterns(dir string, patterns []string, tests bool) ([]string, error) {
+	var files []string
+	for _, p := range patterns {
+		root, recursive := strings.CutSuffix(p, "/...")
+		if root == "" {
+			root = "/"
+		}
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(dir, root)
+		}
+		found, err := goFiles(root, recursive)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range found {
+			if tests || !isTestFile(f) {
+				files = append(files, f)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files matched by %s", strings.Join(patterns, " "))
+	}
+	sort.Strings(files)
+	return dedupSorted(files), nil
+}
+
+func dedupSorted(list []string) []string {
+	res := list[:0]
+	for i, s := range list {
+		if i == 0 || s != list[i-1] {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// AnalyzePatterns analyzes the files of the packages matched by patterns in
+// dir, as returned by LoadPatterns, like AnalyzeFiles.
+func AnalyzePatterns(dir string, patterns []string, setting Setting) (*Result, error) {
+	files, err := LoadPatterns(dir, patterns, false)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeFiles(token.NewFileSet(), files, setting)
+}
+
+// AnalyzePackages analyzes packages loaded by the caller with go/packages,
+// e.g. by a driver already loading them for other analyzers. The positions
+// are those of the FileSet of each package, so packages loaded separately
+// can be analyzed together. The syntax of packages loaded without
+// packages.NeedSyntax is parsed again from their GoFiles.
+//
+// If the packages were loaded with tests, the test variant of a package is
+// analyzed instead of the package, and the generated test mains are skipped.
+func AnalyzePackages(pkgs []*packages.Package, setting Setting) (*Result, error) {
+	tested := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if pkg.ID != pkg.PkgPath && !strings.HasSuffix(pkg.PkgPath, "_test") {
+			tested[pkg.PkgPath] = true
+		}
+	}
+	var selected []*packages.Package
+	for _, pkg := range pkgs {
+		if isTestMain(pkg) || pkg.ID == pkg.PkgPath && tested[pkg.PkgPath] {
+			continue
+		}
+		selected = append(selected, pkg)
+	}
+
+	fs := token.NewFileSet()
+	return analyze(len(selected), setting, func(i int) (*partialResult, error) {
+		pkg := selected[i]
+		if len(pkg.Syntax) == 0 || pkg.Fset == nil {
+			if len(pkg.GoFiles) == 0 {
+				return &partialResult{}, nil
+			}
+			return analyzePackage(fs, pkg.GoFiles, setting, nil)
+		}
+
+		v := newVisitor(pkg.Fset, setting)
+		v.types = checkFiles(pkg.Fset, pkg.Syntax, setting.GoVersion)
+		for _, file := range pkg.Syntax {
+			v.walk(file)
+		}
+		v.lint(v.metrics)
+		res := v.result(len(pkg.Syntax))
+		res.syntaxErrors = v.types.versionErrors
+		return res, nil
+	})
+}
+
+// isTestMain reports whether pkg is the main package generated by go test.
+func isTestMain(pkg *packages.Package) bool {
+	return pkg.Name == "main" && strings.HasSuffix(pkg.PkgPath, ".test")
+}
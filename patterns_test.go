@@ -0,0 +1,162 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func relativeFiles(t *testing.T, files []string) []string {
+	t.Helper()
+	wd, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel := make([]string, 0, len(files))
+	for _, f := range files {
+		r, err := filepath.Rel(wd, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	return rel
+}
+
+func TestLoadPatterns(t *testing.T) {
+	files, err := LoadPatterns(".", []string{"./testdata/patterns/..."}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"testdata/patterns/a/a.go",
+		"testdata/patterns/a/b/b.go",
+		"testdata/patterns/a/windows.go",
+	}
+	if got := relativeFiles(t, files); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	files, err = LoadPatterns(".", []string{"./testdata/patterns/a"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{
+		"testdata/patterns/a/a.go",
+		"testdata/patterns/a/a_test.go",
+		"testdata/patterns/a/windows.go",
+	}
+	if got := relativeFiles(t, files); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	if _, err := LoadPatterns(".", []string{"./testdata/patterns/missing"}, false); err == nil {
+		t.Error("expected an error for a pattern matching no package")
+	}
+}
+
+func TestAnalyzePatterns(t *testing.T) {
+	res, err := AnalyzePatterns(".", []string{"./testdata/patterns/..."}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range res.Metrics {
+		names = append(names, m.MetricFamily.GetName())
+	}
+	expected := []string{"pattern_requests", "pattern_errors", "pattern_windows_services"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestAnalyzePackages(t *testing.T) {
+	// Two loads, so that the packages have different FileSets.
+	var pkgs []*packages.Package
+	for _, pattern := range []string{"./testdata/patterns/a", "./testdata/patterns/a/b"} {
+		loaded, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax, Tests: true}, pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkgs = append(pkgs, loaded...)
+	}
+
+	res, err := AnalyzePackages(pkgs, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, m := range res.Metrics {
+		rel := relativeFiles(t, []string{m.Pos.Filename})[0]
+		got[m.MetricFamily.GetName()] = fmt.Sprintf("%s:%d:%d", rel, m.Pos.Line, m.Pos.Column)
+	}
+	expected := map[string]string{
+		"pattern_requests": "testdata/patterns/a/a.go:5:38",
+		"pattern_errors":   "testdata/patterns/a/b/b.go:5:36",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if res.Files != 3 {
+		t.Errorf("expected the package with its test file and the other package, got %d files", res.Files)
+	}
+}
+
+func TestLoadPatternsWithoutModule(t *testing.T) {
+	dir := t.TempDir()
+	src := "package app\n\nimport \"github.com/prometheus/client_golang/prometheus\"\n\nvar c = prometheus.NewCounter(prometheus.CounterOpts{Name: \"legacy_requests\", Help: \"Help.\"})\n"
+	for _, name := range []string{"src/app/main.go", "src/app/main_test.go", "src/app/vendor/lib/lib.go", "src/app/testdata/data.go", "src/_old/old.go", "src/app/sub/sub.go"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := LoadPatterns(dir, []string{"./src/..."}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{filepath.Join(dir, "src", "app", "main.go"), filepath.Join(dir, "src", "app", "sub", "sub.go")}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+	files, err = LoadPatterns(dir, []string{"./src/app"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{filepath.Join(dir, "src", "app", "main.go"), filepath.Join(dir, "src", "app", "main_test.go")}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+
+	// The values are resolved syntactically.
+	res, err := AnalyzeFiles(token.NewFileSet(), files[:1], Setting{SSA: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Metrics) != 1 || len(res.Issues) != 1 || res.Issues[0].RuleID != RuleCounter {
+		t.Errorf("expected the counter issue of legacy_requests, got %v", res.Issues)
+	}
+}
+
+func TestLoadPatternsCgo(t *testing.T) {
+	// Without cgo, the files importing "C" are ignored by the go command,
+	// but their metrics are still analyzed.
+	t.Setenv("CGO_ENABLED", "0")
+	files, err := LoadPatterns(".", []string{"./testdata/cgo"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"testdata/cgo/cgo.go", "testdata/cgo/pure.go"}
+	if got := relativeFiles(t, files); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,156 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// Check is a custom check run on every discovered metric, so that
+// organizations can compile their own checks into promlinter without
+// changing the analysis. Checks are registered with RegisterCheck.
+type Check interface {
+	// Rule describes the check. Its ID must not be used by another rule,
+	// and is used to disable or enable the check like the built-in ones.
+	Rule() Rule
+	// Check returns the issues of the metric of ctx. The fields left empty
+	// in the issues, such as Pos, RuleID and Severity, are filled in from
+	// the metric and the rule.
+	Check(ctx *CheckContext) []Issue
+}
+
+// CheckContext is the metric given to a Check, along with the syntax and
+// types of its definition.
+type CheckContext struct {
+	Metric MetricFamilyWithPos
+	Fset   *token.FileSet
+	// Call is the constructor call, e.g. prometheus.NewCounterVec(...), or
+	// the MustNewConstMetric call of a collector.
+	Call *ast.CallExpr
+	// Info holds the types of the package of the metric. The dependencies
+	// are not loaded, so the types of other packages are incomplete.
+	Info *types.Info
+}
+
+// checks are the registered custom checks.
+var checks []Check
+
+// RegisterCheck registers a custom check run by every analysis, and adds its
+// rule to Rules. It should be called from an init function, before any
+// analysis. It panics if the ID of the rule is already used. The cache keys
+// include the IDs of the registered checks but not their code: change the ID
+// or clear the cache when a check changes.
+func RegisterCheck(c Check) {
+	r := c.Rule()
+	if r.ID == "" {
+		panic("promlinter: check without a rule ID")
+	}
+	if _, ok := LookupRule(r.ID); ok {
+		panic(fmt.Sprintf("promlinter: rule %s is already registered", r.ID))
+	}
+	Rules = append(Rules, r)
+	checks = append(checks, c)
+}
+
+// InventoryCheck is a custom check run once on all the discovered metrics,
+// for the rules on the whole inventory, e.g. every subsystem must define a
+// build_info metric. Inventory checks are registered with
+// RegisterInventoryCheck.
+type InventoryCheck interface {
+	// Rule describes the check, like Check.Rule.
+	Rule() Rule
+	// CheckInventory returns the issues of the metrics, sorted by position.
+	// The RuleID and Severity left empty in the issues are filled in from
+	// the rule.
+	CheckInventory(metrics []MetricFamilyWithPos) []Issue
+}
+
+// inventoryChecks are the registered inventory checks.
+var inventoryChecks []InventoryCheck
+
+// RegisterInventoryCheck registers an inventory check run at the end of every
+// analysis of all the packages, and adds its rule to Rules. It is not run when
+// the analysis stops early. Like RegisterCheck, it should be called from an
+// init function and panics if the ID is already used.
+func RegisterInventoryCheck(c InventoryCheck) {
+	r := c.Rule()
+	if r.ID == "" {
+		panic("promlinter: check without a rule ID")
+	}
+	if _, ok := LookupRule(r.ID); ok {
+		panic(fmt.Sprintf("promlinter: rule %s is already registered", r.ID))
+	}
+	Rules = append(Rules, r)
+	inventoryChecks = append(inventoryChecks, c)
+}
+
+// runInventoryChecks runs the registered inventory checks on the metrics of
+// res. They run on the merged results, so they are not part of the cache key
+// of the packages.
+func runInventoryChecks(res *partialResult) []Issue {
+	metrics := append([]MetricFamilyWithPos(nil), res.metrics...)
+	sortMetrics(metrics)
+
+	var issues []Issue
+	for _, c := range inventoryChecks {
+		r := c.Rule()
+		for _, iss := range c.CheckInventory(metrics) {
+			if iss.RuleID == "" {
+				iss.RuleID = r.ID
+			}
+			if iss.Severity == "" {
+				iss.Severity = r.Severity
+			}
+			issues = append(issues, iss)
+		}
+	}
+	return issues
+}
+
+// checkIDs returns the sorted rule IDs of the registered checks and
+// validations.
+func checkIDs() []string {
+	ids := make([]string, 0, len(checks)+len(validations))
+	for _, c := range checks {
+		ids = append(ids, c.Rule().ID)
+	}
+	for _, v := range validations {
+		ids = append(ids, v.rule.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// runChecks runs the registered checks on metric, defined by call.
+func (v *visitor) runChecks(metric MetricFamilyWithPos, call *ast.CallExpr) {
+	if len(checks) == 0 || call == nil {
+		return
+	}
+	ctx := &CheckContext{Metric: metric, Fset: v.fs, Call: call, Info: v.types.info}
+	for _, c := range checks {
+		r := c.Rule()
+		for _, iss := range c.Check(ctx) {
+			if iss.Pos == (token.Position{}) {
+				iss.Pos, iss.End = metric.Pos, metric.End
+			}
+			if iss.RuleID == "" {
+				iss.RuleID = r.ID
+			}
+			if iss.Severity == "" {
+				iss.Severity = r.Severity
+			}
+			if iss.Metric == "" {
+				iss.Metric = metric.MetricFamily.GetName()
+			}
+			if iss.MetricType == "" {
+				iss.MetricType = metricTypeName(metric.MetricFamily.GetType())
+			}
+			if iss.Labels == nil {
+				iss.Labels = metric.Labels()
+			}
+			v.addIssue(iss)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// teamCheck requires the metrics of the plugins fixture to have a team
+// ConstLabel, looking at the syntax of the constructor.
+type teamCheck struct{}
+
+func (teamCheck) Rule() Rule {
+	return Rule{ID: "TEST001", Name: "Team", Severity: SeverityError, Summary: "Metrics should have a team ConstLabel."}
+}
+
+func (teamCheck) Check(ctx *CheckContext) []Issue {
+	if !strings.HasPrefix(ctx.Metric.MetricFamily.GetName(), "plugin_") {
+		return nil
+	}
+	found := false
+	ast.Inspect(ctx.Call, func(n ast.Node) bool {
+		if kv, ok := n.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "ConstLabels" {
+				found = true
+			}
+		}
+		return !found
+	})
+	if found {
+		return nil
+	}
+	return []Issue{{Text: "metric has no team label"}}
+}
+
+// buildInfoCheck requires every namespace of the metrics of the inventory
+// fixture to define a build_info metric.
+type buildInfoCheck struct{}
+
+func (buildInfoCheck) Rule() Rule {
+	return Rule{ID: "TEST003", Name: "BuildInfo", Severity: SeverityWarning, Summary: "Every namespace should define a build_info metric."}
+}
+
+func (buildInfoCheck) CheckInventory(metrics []MetricFamilyWithPos) []Issue {
+	var (
+		first     = make(map[string]MetricFamilyWithPos)
+		buildInfo = make(map[string]bool)
+		order     []string
+	)
+	for _, m := range metrics {
+		if filepath.Base(filepath.Dir(m.Pos.Filename)) != "inventory" {
+			continue
+		}
+		namespace, rest, _ := strings.Cut(m.MetricFamily.GetName(), "_")
+		if _, ok := first[namespace]; !ok {
+			first[namespace] = m
+			order = append(order, namespace)
+		}
+		buildInfo[namespace] = buildInfo[namespace] || rest == "build_info"
+	}
+	var issues []Issue
+	for _, namespace := range order {
+		if !buildInfo[namespace] {
+			m := first[namespace]
+			issues = append(issues, Issue{Pos: m.Pos, Metric: m.MetricFamily.GetName(), Text: "namespace " + namespace + " has no build_info metric"})
+		}
+	}
+	return issues
+}
+
+func init() {
+	RegisterCheck(teamCheck{})
+	RegisterInventoryCheck(buildInfoCheck{})
+}
+
+func TestRegisterCheck(t *testing.T) {
+	if r, ok := LookupRule("TEST001"); !ok || r.Name != "Team" {
+		t.Fatalf("expected the rule of the check to be registered, got %v", r)
+	}
+
+	paths := []string{filepath.Join("testdata", "plugins", "plugins.go")}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == "TEST001" {
+			got = append(got, iss.Metric+" "+string(iss.Severity)+" "+iss.Pos.String()+": "+iss.Text)
+		}
+	}
+	expected := []string{"plugin_unowned_total error testdata/plugins/plugins.go:13:34: metric has no team label"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	res, err = AnalyzeFiles(token.NewFileSet(), paths, Setting{DisabledRules: []string{"TEST001"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iss := range res.Issues {
+		if iss.RuleID == "TEST001" {
+			t.Fatalf("expected the check to be disabled, got %v", iss)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected registering a rule ID twice to panic")
+		}
+	}()
+	RegisterCheck(teamCheck{})
+}
+
+func TestRegisterInventoryCheck(t *testing.T) {
+	path := filepath.Join("testdata", "plugins", "inventory", "inventory.go")
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == "TEST003" {
+			got = append(got, iss.Metric+" "+string(iss.Severity)+" "+iss.Pos.String()+": "+iss.Text)
+		}
+	}
+	expected := []string{"billing_requests_total warning testdata/plugins/inventory/inventory.go:14:42: namespace billing has no build_info metric"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,57 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// span is the range of an expression in the source.
+type span struct {
+	pos, end token.Position
+}
+
+// fieldSpan returns the range of the value of field in the Opts arg, e.g. the
+// string literal of the Name field, if it is set by a single expression.
+func (v *visitor) fieldSpan(arg ast.Expr, field string) span {
+	var value ast.Expr
+	switch arg := arg.(type) {
+	case *ast.CompositeLit:
+		value, _ = compositeField(arg, field)
+	case *ast.Ident:
+		defs, _ := v.types.reaching(arg, field)
+		if len(defs) != 1 {
+			return span{}
+		}
+		value = defs[0].value
+		if lit, ok := value.(*ast.CompositeLit); ok && defs[0].field == "" {
+			value, _ = compositeField(lit, field)
+		}
+	}
+	return v.exprSpan(value)
+}
+
+// exprSpan returns the range of expr, or an empty span if expr is nil.
+func (v *visitor) exprSpan(expr ast.Expr) span {
+	if expr == nil {
+		return span{}
+	}
+	return span{v.fs.Position(expr.Pos()), v.fs.Position(expr.End())}
+}
+
+// nameRange returns the range of the expression defining the name of the
+// metric, or that of the whole Opts or Desc if it is not known.
+func (m MetricFamilyWithPos) nameRange() (token.Position, token.Position) {
+	if m.nameSpan.pos.IsValid() {
+		return m.nameSpan.pos, m.nameSpan.end
+	}
+	return m.Pos, m.End
+}
+
+// helpRange returns the range of the expression defining the help text of
+// the metric, like nameRange.
+func (m MetricFamilyWithPos) helpRange() (token.Position, token.Position) {
+	if m.helpSpan.pos.IsValid() {
+		return m.helpSpan.pos, m.helpSpan.end
+	}
+	return m.Pos, m.End
+}
@@ -0,0 +1,36 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIssuePositions(t *testing.T) {
+	paths := []string{filepath.Join("testdata", "positions", "positions.go")}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, iss := range res.Issues {
+		got[iss.Metric+" "+iss.RuleID] = iss.Pos.String() + "-" + iss.End.String()
+	}
+	file := filepath.Join("testdata", "positions", "positions.go")
+	expected := map[string]string{
+		// The Name field of the literal.
+		"position_requests " + RuleCounter: file + ":7:9-" + file + ":7:28",
+		// No Help field: the whole Opts.
+		"position_queue_length " + RuleHelp: file + ":10:31-" + file + ":12:3",
+		// The label names are not part of the Opts.
+		"position_workers " + RuleCamelCase: file + ":13:34-" + file + ":16:3",
+		// The Name field of the Opts variable.
+		"position_errors " + RuleCounter: file + ":19:41-" + file + ":19:58",
+		// The name argument of NewDesc.
+		"position_jobs " + RuleCounter: file + ":25:31-" + file + ":25:46",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
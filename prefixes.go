@@ -0,0 +1,99 @@
+package promlinter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// minPrefixGroup is the smallest number of metrics of a group whose prefixes
+// are compared.
+const minPrefixGroup = 3
+
+// inconsistentPrefixes reports the metrics whose name does not start with the
+// first component shared by most of the other metrics of their group: the
+// metrics defined in the same file, and the Vecs of a package with the same
+// labels, e.g. foo_requests_in_flight next to http_requests_total and
+// http_request_duration_seconds.
+func inconsistentPrefixes(res *partialResult) []Issue {
+	var (
+		keys   []string
+		groups = make(map[string][]MetricFamilyWithPos)
+	)
+	add := func(key string, m MetricFamilyWithPos) {
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], m)
+	}
+	for _, m := range res.metrics {
+		add("file:"+m.Pos.Filename, m)
+		if labels := m.Labels(); len(labels) > 0 {
+			add("labels:"+filepath.Dir(m.Pos.Filename)+":"+strings.Join(labels, ","), m)
+		}
+	}
+
+	var (
+		issues   []Issue
+		reported = make(map[string]bool)
+	)
+	for _, key := range keys {
+		group, kind := groups[key], "file"
+		if strings.HasPrefix(key, "labels:") {
+			kind = "labels"
+		}
+		prefix, n, total := majorityPrefix(group)
+		if prefix == "" {
+			continue
+		}
+		for _, m := range group {
+			name := m.MetricFamily.GetName()
+			if strings.HasPrefix(name, prefix) || reported[m.Pos.String()] {
+				continue
+			}
+			reported[m.Pos.String()] = true
+			what := "metrics of the file"
+			if kind == "labels" {
+				what = "metrics of the package with the labels " + strings.Join(m.Labels(), ", ")
+			}
+			issues = append(issues, Issue{
+				Pos:        m.Pos,
+				Metric:     name,
+				Text:       fmt.Sprintf("metric name does not start with the prefix %s of %d of the %d %s", prefix, n, total, what),
+				RuleID:     RuleInconsistentPrefix,
+				Severity:   ruleSeverity(RuleInconsistentPrefix),
+				End:        m.End,
+				MetricType: metricTypeName(m.MetricFamily.GetType()),
+				Labels:     m.Labels(),
+			})
+		}
+	}
+	return issues
+}
+
+// majorityPrefix returns the first component shared by more than half of the
+// distinct names of a group but not by all of them, e.g. "http_", with the
+// number of names starting with it and the number of names, or an empty
+// string if there is none or if the group is too small.
+func majorityPrefix(group []MetricFamilyWithPos) (string, int, int) {
+	names := make(map[string]bool)
+	for _, m := range group {
+		names[m.MetricFamily.GetName()] = true
+	}
+	if len(names) < minPrefixGroup {
+		return "", 0, 0
+	}
+
+	counts := make(map[string]int)
+	for name := range names {
+		if i := strings.Index(name, "_"); i > 0 {
+			counts[name[:i+1]]++
+		}
+	}
+	for prefix, n := range counts {
+		if 2*n > len(names) && n < len(names) {
+			return prefix, n, len(names)
+		}
+	}
+	return "", 0, 0
+}
@@ -0,0 +1,42 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInconsistentPrefixes(t *testing.T) {
+	dir := filepath.Join("testdata", "prefixes")
+	paths := []string{filepath.Join(dir, "queue.go"), filepath.Join(dir, "server.go"), filepath.Join(dir, "workers.go")}
+
+	for _, tc := range []struct {
+		setting  Setting
+		expected []string
+	}{
+		// The rule is opt-in.
+		{setting: Setting{}},
+		{
+			setting: Setting{EnabledRules: []string{"InconsistentPrefix"}},
+			expected: []string{
+				filepath.Join(dir, "queue.go") + ":10:41 queue_errors_total metric name does not start with the prefix jobs_ of 2 of the 3 metrics of the package with the labels queue",
+				filepath.Join(dir, "server.go") + ":15:33 requests_in_flight metric name does not start with the prefix foo_ of 2 of the 3 metrics of the file",
+			},
+		},
+	} {
+		res, err := AnalyzeFiles(token.NewFileSet(), paths, tc.setting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, iss := range res.Issues {
+			if iss.RuleID == RuleInconsistentPrefix {
+				got = append(got, iss.Pos.String()+" "+iss.Metric+" "+iss.Text)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("expected %v, got %v", tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preset bundles the rules and severities of a level of strictness, so that
+// new adopters can start with one of them and tighten over time.
+type Preset struct {
+	Name        string
+	Description string
+
+	apply func(s *Setting)
+}
+
+// Presets are the presets of promlinter, from the loosest to the strictest.
+var Presets = []Preset{
+	{
+		Name:        "minimal",
+		Description: "Only the rules of the error severity, whose issues break queries or the exposition.",
+		apply: func(s *Setting) {
+			for _, r := range Rules {
+				if r.Severity != SeverityError {
+					s.DisabledRules = append(s.DisabledRules, r.ID)
+				}
+			}
+		},
+	},
+	{
+		Name:        "default",
+		Description: "The rules which are not opt-in, with their default severity.",
+		apply:       func(s *Setting) {},
+	},
+	{
+		Name:        "strict",
+		Description: "The rules which are not opt-in, in strict mode, with the warnings raised to errors.",
+		apply: func(s *Setting) {
+			s.Strict = true
+			for _, r := range Rules {
+				if r.Severity == SeverityWarning {
+					s.setSeverity(r.ID, SeverityError)
+				}
+			}
+		},
+	},
+	{
+		Name:        "all",
+		Description: "Every rule including the opt-in ones, in strict mode, with their default severity.",
+		apply: func(s *Setting) {
+			s.Strict = true
+			for _, r := range Rules {
+				if r.OptIn {
+					s.EnabledRules = append(s.EnabledRules, r.ID)
+				}
+			}
+		},
+	},
+}
+
+// LookupPreset returns the preset with the given name.
+func LookupPreset(name string) (Preset, bool) {
+	for _, p := range Presets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// PresetNames returns the names of the presets.
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for _, p := range Presets {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// ApplyPreset applies the preset with the given name to s. The rules already
+// listed in s.EnabledRules and s.DisabledRules and the severities already
+// set in s.Severities take precedence over those of the preset.
+func (s *Setting) ApplyPreset(name string) error {
+	p, ok := LookupPreset(name)
+	if !ok {
+		return fmt.Errorf("unknown preset %q, expected one of %s", name, strings.Join(PresetNames(), ", "))
+	}
+	preset := Setting{Severities: make(map[string]Severity)}
+	p.apply(&preset)
+
+	s.Strict = s.Strict || preset.Strict
+	for _, id := range preset.DisabledRules {
+		if !listsRule(s.EnabledRules, id) {
+			s.DisabledRules = append(s.DisabledRules, id)
+		}
+	}
+	for _, id := range preset.EnabledRules {
+		if !listsRule(s.DisabledRules, id) {
+			s.EnabledRules = append(s.EnabledRules, id)
+		}
+	}
+	for id, severity := range preset.Severities {
+		s.setSeverity(id, severity)
+	}
+	return nil
+}
+
+// setSeverity sets the severity of the rule id, unless it is already set.
+func (s *Setting) setSeverity(id string, severity Severity) {
+	for rule := range s.Severities {
+		if listsRule([]string{rule}, id) {
+			return
+		}
+	}
+	if s.Severities == nil {
+		s.Severities = make(map[string]Severity)
+	}
+	s.Severities[id] = severity
+}
@@ -0,0 +1,57 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	paths := []string{filepath.Join("testdata", "presets", "presets.go")}
+	severities := func(preset string, setting Setting) map[string]Severity {
+		if err := setting.ApplyPreset(preset); err != nil {
+			t.Fatal(err)
+		}
+		res, err := AnalyzeFiles(token.NewFileSet(), paths, setting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]Severity)
+		for _, iss := range res.Issues {
+			got[iss.RuleID] = iss.Severity
+		}
+		return got
+	}
+
+	got := severities("default", Setting{})
+	if got[RuleHelp] != SeverityWarning || got[RuleCounter] != SeverityError || got[RuleCamelCase] != SeverityError {
+		t.Errorf("expected the default severities, got %v", got)
+	}
+	got = severities("minimal", Setting{})
+	if _, ok := got[RuleHelp]; ok || got[RuleCounter] != SeverityError {
+		t.Errorf("expected only the errors with the minimal preset, got %v", got)
+	}
+	got = severities("minimal", Setting{EnabledRules: []string{"Help"}})
+	if got[RuleHelp] != SeverityWarning {
+		t.Errorf("expected the enabled rules to take precedence over the preset, got %v", got)
+	}
+	got = severities("strict", Setting{})
+	if got[RuleHelp] != SeverityError {
+		t.Errorf("expected the warnings to be raised to errors with the strict preset, got %v", got)
+	}
+	got = severities("strict", Setting{Severities: map[string]Severity{"Help": SeverityInfo}})
+	if got[RuleHelp] != SeverityInfo {
+		t.Errorf("expected the severities of the setting to take precedence over the preset, got %v", got)
+	}
+
+	var setting Setting
+	if err := setting.ApplyPreset("all"); err != nil {
+		t.Fatal(err)
+	}
+	if !setting.Strict || !setting.reports(RuleHelpRestatesName) || !setting.reports(RuleHelpUnit) {
+		t.Errorf("expected the opt-in rules to be enabled with the all preset, got %+v", setting)
+	}
+	if err := setting.ApplyPreset("paranoid"); err == nil {
+		t.Error("expected an unknown preset to fail")
+	}
+}
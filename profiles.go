@@ -0,0 +1,152 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Profile tunes the analysis for the services of an ecosystem, whose
+// libraries register metrics of their own.
+type Profile struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Metrics are the names of the metrics registered by the libraries of
+	// the ecosystem, with which the metrics of the service would collide.
+	Metrics []string `json:"metrics"`
+	// Prefixes are the name prefixes of the metrics of the libraries, which
+	// the metrics of the service should not use.
+	Prefixes []string `json:"prefixes"`
+	// Severities change the severity of the issues of some rules.
+	Severities map[string]Severity `json:"severities,omitempty"`
+}
+
+// Profiles are the ecosystem profiles of promlinter.
+var Profiles = []Profile{
+	{
+		Name:        "kubernetes-operator",
+		Description: "Operators built with controller-runtime, which registers the metrics of the controllers, work queues and REST clients.",
+		Metrics: []string{
+			"controller_runtime_active_workers",
+			"controller_runtime_max_concurrent_reconciles",
+			"controller_runtime_reconcile_errors_total",
+			"controller_runtime_reconcile_time_seconds",
+			"controller_runtime_reconcile_total",
+			"controller_runtime_webhook_requests_in_flight",
+			"controller_runtime_webhook_requests_total",
+			"leader_election_master_status",
+			"rest_client_request_latency_seconds",
+			"rest_client_requests_total",
+			"workqueue_adds_total",
+			"workqueue_depth",
+			"workqueue_longest_running_processor_seconds",
+			"workqueue_queue_duration_seconds",
+			"workqueue_retries_total",
+			"workqueue_unfinished_work_seconds",
+			"workqueue_work_duration_seconds",
+		},
+		Prefixes: []string{"controller_runtime_", "workqueue_", "rest_client_", "leader_election_", "certwatcher_"},
+		// Labels such as the name of the reconciled object grow with the
+		// cluster.
+		Severities: map[string]Severity{RuleSeriesBudget: SeverityError},
+	},
+	{
+		Name:        "grpc-service",
+		Description: "gRPC services instrumented with go-grpc-prometheus, which registers the metrics of the server and client RPCs.",
+		Metrics: []string{
+			"grpc_client_handled_total",
+			"grpc_client_handling_seconds",
+			"grpc_client_msg_received_total",
+			"grpc_client_msg_sent_total",
+			"grpc_client_started_total",
+			"grpc_server_handled_total",
+			"grpc_server_handling_seconds",
+			"grpc_server_msg_received_total",
+			"grpc_server_msg_sent_total",
+			"grpc_server_started_total",
+		},
+		Prefixes:   []string{"grpc_server_", "grpc_client_"},
+		Severities: map[string]Severity{RuleUnitMismatch: SeverityError, RuleTimerMisuse: SeverityError},
+	},
+	{
+		Name:        "http-service",
+		Description: "HTTP services exposing their metrics with promhttp, which registers the metrics of the metrics handler.",
+		Metrics: []string{
+			"promhttp_metric_handler_errors_total",
+			"promhttp_metric_handler_requests_in_flight",
+			"promhttp_metric_handler_requests_total",
+		},
+		Prefixes:   []string{"promhttp_"},
+		Severities: map[string]Severity{RuleUnitMismatch: SeverityError, RuleTimerMisuse: SeverityError},
+	},
+}
+
+// LookupProfile returns the ecosystem profile with the given name.
+func LookupProfile(name string) (Profile, bool) {
+	for _, p := range Profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// ProfileNames returns the names of the ecosystem profiles.
+func ProfileNames() []string {
+	names := make([]string, 0, len(Profiles))
+	for _, p := range Profiles {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// ApplyProfile adds the ecosystem profile with the given name to s.Profiles
+// and its severities to s.Severities, unless they are already set.
+func (s *Setting) ApplyProfile(name string) error {
+	p, ok := LookupProfile(name)
+	if !ok {
+		return fmt.Errorf("unknown profile %q, expected one of %s", name, strings.Join(ProfileNames(), ", "))
+	}
+	for id, severity := range p.Severities {
+		s.setSeverity(id, severity)
+	}
+	s.Profiles = append(s.Profiles, p)
+	return nil
+}
+
+// lintProfiles reports the metric if its name is used by the libraries of the
+// profiles of the setting: an error if it is the name of one of their
+// metrics, since the registration fails, a warning if it only uses one of
+// their prefixes.
+func (v *visitor) lintProfiles(metric MetricFamilyWithPos) {
+	name := metric.MetricFamily.GetName()
+	for _, p := range v.setting.Profiles {
+		var (
+			text     string
+			severity = ruleSeverity(RuleEcosystemName)
+		)
+		if contains(p.Metrics, name) {
+			text = fmt.Sprintf("metric %s is already registered by the libraries of the %s profile", name, p.Name)
+			severity = SeverityError
+		} else {
+			for _, prefix := range p.Prefixes {
+				if strings.HasPrefix(name, prefix) {
+					text = fmt.Sprintf("metric uses the prefix %s of the metrics of the libraries of the %s profile", prefix, p.Name)
+					break
+				}
+			}
+		}
+		if text == "" {
+			continue
+		}
+		v.addIssue(Issue{
+			Pos:        metric.Pos,
+			End:        metric.End,
+			Metric:     name,
+			Text:       text,
+			RuleID:     RuleEcosystemName,
+			Severity:   severity,
+			MetricType: metricTypeName(metric.MetricFamily.GetType()),
+			Labels:     metric.Labels(),
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	var setting Setting
+	if err := setting.ApplyProfile("kubernetes-operator"); err != nil {
+		t.Fatal(err)
+	}
+	if setting.Severities[RuleSeriesBudget] != SeverityError {
+		t.Errorf("expected the severities of the profile to be set, got %v", setting.Severities)
+	}
+
+	paths := []string{filepath.Join("testdata", "profiles", "profiles.go")}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, setting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleEcosystemName {
+			got = append(got, iss.Metric+" "+string(iss.Severity)+": "+iss.Text)
+		}
+	}
+	expected := []string{
+		"workqueue_adds_total error: metric workqueue_adds_total is already registered by the libraries of the kubernetes-operator profile",
+		"workqueue_backoffs_total warning: metric uses the prefix workqueue_ of the metrics of the libraries of the kubernetes-operator profile",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	res, err = AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleEcosystemName {
+			t.Errorf("expected no issue without a profile, got %v", iss)
+		}
+	}
+
+	if err := setting.ApplyProfile("cobol-batch"); err == nil {
+		t.Error("expected an unknown profile to fail")
+	}
+}
@@ -1,22 +1,32 @@
 package promlinter
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
+	"go/parser"
+	"go/scanner"
 	"go/token"
+	"go/types"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
 	dto "github.com/prometheus/client_model/go"
 )
 
 var (
-	metricsType     map[string]dto.MetricType
-	constMetricArgs map[string]int
-	validOptsFields map[string]bool
+	metricsType          map[string]dto.MetricType
+	constMetricArgs      map[string]int
+	validOptsFields      map[string]bool
+	positionalOptsFields []string
 )
 
 func init() {
@@ -32,11 +42,17 @@ func init() {
 	}
 
 	constMetricArgs = map[string]int{
-		"MustNewConstMetric": 3,
-		"MustNewHistogram":   4,
-		"MustNewSummary":     4,
+		"MustNewConstMetric":                     3,
+		"MustNewConstMetricWithCreatedTimestamp": 4,
+		"MustNewHistogram":                       4,
+		"MustNewSummary":                         4,
 	}
 
+	// The first fields of CounterOpts, GaugeOpts, HistogramOpts and
+	// SummaryOpts, in the order of their definition in client_golang, to
+	// resolve unkeyed literals.
+	positionalOptsFields = []string{"Namespace", "Subsystem", "Name", "Help"}
+
 	// Doesn't contain ConstLabels since we don't need this field here.
 	validOptsFields = map[string]bool{
 		"Name":      true,
@@ -46,18 +62,141 @@ func init() {
 	}
 }
 
-// Issue contains metric name, error text and metric position.
+// Issue contains metric name, error text, metric position and the ID of the
+// rule which reported it.
 type Issue struct {
-	Pos    token.Position
-	Metric string
-	Text   string
+	Pos    token.Position `json:"pos"`
+	Metric string         `json:"metric"`
+	Text   string         `json:"text"`
+	RuleID string         `json:"rule_id"`
+
+	Severity Severity `json:"severity"`
+	// End is the position of the end of the offending expression.
+	End token.Position `json:"end"`
+	// MetricType and Labels describe the offending metric. They are empty if
+	// the issue is not related to a discovered metric.
+	MetricType string   `json:"metric_type,omitempty"`
+	Labels     []string `json:"labels,omitempty"`
+	// Module is the path of the module defining the metric, if the analysis
+	// ran on a workspace.
+	Module string `json:"module,omitempty"`
+	// Repo is the repository defining the metric, for the checks of
+	// aggregated inventories.
+	Repo string `json:"repo,omitempty"`
+	// NamePrefix is the resolved prefix of a dynamic metric name.
+	NamePrefix string `json:"name_prefix,omitempty"`
+	// Duplicates are the positions of the identical definitions of the
+	// metric with the same issue, collapsed into this one, see
+	// Setting.Deduplicate.
+	Duplicates []token.Position `json:"duplicates,omitempty"`
+	// Fixes are the changes of the source fixing the issue, if they are
+	// known.
+	Fixes []SuggestedFix `json:"fixes,omitempty"`
+
+	// Blame is set by AddBlame.
+	Blame *Blame `json:"blame,omitempty"`
+}
+
+// MapFilenames returns a copy of the issue with the file names of its
+// positions, those of its duplicates and those of its fixes replaced by
+// f, e.g. to report the paths of a copy of the source in the original tree.
+func (iss Issue) MapFilenames(f func(string) string) Issue {
+	iss.Pos.Filename = f(iss.Pos.Filename)
+	if iss.End.Filename != "" {
+		iss.End.Filename = f(iss.End.Filename)
+	}
+	iss.Duplicates = append([]token.Position(nil), iss.Duplicates...)
+	for i := range iss.Duplicates {
+		iss.Duplicates[i].Filename = f(iss.Duplicates[i].Filename)
+	}
+	fixes := iss.Fixes
+	iss.Fixes = nil
+	for _, fix := range fixes {
+		fix.Edits = append([]TextEdit(nil), fix.Edits...)
+		for i := range fix.Edits {
+			fix.Edits[i].Pos.Filename = f(fix.Edits[i].Pos.Filename)
+			fix.Edits[i].End.Filename = f(fix.Edits[i].End.Filename)
+		}
+		iss.Fixes = append(iss.Fixes, fix)
+	}
+	return iss
+}
+
+// MetricFamilyWithPos is a metric family discovered in the source code together
+// with the position where it is defined.
+type MetricFamilyWithPos struct {
+	MetricFamily *dto.MetricFamily
+	Pos          token.Position
+	End          token.Position
+	// AutoRegistered is true if the metric is created by promauto, which
+	// registers it on construction.
+	AutoRegistered bool
+	// Constructor is the name of the function creating the metric, e.g.
+	// NewCounterVec or MustNewConstMetric.
+	Constructor string
+	// LabelValues holds the values each label may have, for the labels whose
+	// values at every call site of the Vec are drawn from constant sets, see
+	// EstimatedSeries. It is only set by the analysis of whole packages.
+	LabelValues map[string][]string
+
+	// holder is the key of the variable or field holding the metric, and
+	// buckets the upper bounds of a histogram, if they could be resolved.
+	// allowedValues holds the label values declared by a label-values
+	// directive. call is the constructor call, kept until the custom checks
+	// ran. nameSpan and helpSpan are the ranges of the expressions defining
+	// the name and the help text, if they are known. constLabels is true if
+	// the metric has const labels.
+	holder        string
+	buckets       []float64
+	allowedValues map[string][]string
+	call          *ast.CallExpr
+	nameSpan      span
+	helpSpan      span
+	constLabels   bool
+}
+
+// Labels returns the label names of the metric family.
+func (m *MetricFamilyWithPos) Labels() []string {
+	var labels []string
+	if len(m.MetricFamily.Metric) > 0 {
+		for _, label := range m.MetricFamily.Metric[0].Label {
+			labels = append(labels, label.GetName())
+		}
+	}
+	return labels
 }
 
 type visitor struct {
 	fs      *token.FileSet
-	metrics map[*dto.MetricFamily]token.Position
+	metrics []MetricFamilyWithPos
 	issues  []Issue
-	strict  bool
+	setting Setting
+	skipped int
+	// generated holds the names of the generated files walked.
+	generated map[string]bool
+	imports   fileImports
+	// types resolves identifiers. It is set when walking the files of a
+	// package.
+	types *typesInfo
+	// ssa resolves values by constant propagation if Setting.SSA is set.
+	ssa *ssaResolver
+	// quiet discards the issues and logs of values parsed a second time.
+	quiet bool
+	// bindings holds the expressions bound to the receivers and parameters
+	// of the calls followed to parse Opts, innermost last.
+	bindings []map[types.Object]ast.Expr
+	// defined holds the identifiers defining the holders of metrics,
+	// references the keys of the variables and fields referenced and
+	// registrations the positions where they are registered, by key.
+	defined       map[*ast.Ident]bool
+	references    map[string]bool
+	registrations map[string][]token.Position
+	writes        []writeSite
+	labels        []labelSite
+	instruments   []otelInstrument
+	// directives holds the label-values directives of the file walked, by
+	// line.
+	directives map[int]map[string][]string
 }
 
 type opt struct {
@@ -66,38 +205,636 @@ type opt struct {
 	name      string
 }
 
-func Run(fs *token.FileSet, files []*ast.File, strict bool) []Issue {
-	v := &visitor{
+// Setting configures the analysis.
+type Setting struct {
+	// Strict reports more issues, including parsing failures.
+	Strict bool
+	// DisabledRules holds the IDs of the rules whose issues are not
+	// reported. Rules can also be given by name, see ResolveRule.
+	DisabledRules []string
+	// EnabledRules holds the IDs or names of the opt-in rules to report, see
+	// Rule.OptIn.
+	EnabledRules []string
+	// Severities change the severity of the issues of some rules, by rule
+	// ID or name, e.g. as set by ApplyPreset.
+	Severities map[string]Severity
+	// RuleOverrides change the rules applied to the metrics of some name
+	// prefixes.
+	RuleOverrides []RuleOverride
+	// ScriptRules are custom rules evaluated on each metric, see
+	// LoadScriptRules.
+	ScriptRules []*ScriptRule
+	// MessageTemplates render the text of the issues of some rules, by
+	// rule ID, see LoadMessageTemplates.
+	MessageTemplates map[string]*template.Template
+	// MaxIssues stops the analysis once MaxIssues issues were found. Zero
+	// means no limit.
+	MaxIssues int
+	// Concurrency is the number of packages analyzed in parallel. Zero uses
+	// GOMAXPROCS.
+	Concurrency int
+	// PrometheusPackages holds the import paths of forks or mirrors of the
+	// client_golang packages which should be treated like them, in addition
+	// to DefaultPrometheusPackages.
+	PrometheusPackages []string
+	// SSA resolves metric names, help and labels by constant propagation on
+	// the SSA form of the packages instead of following the declarations of
+	// identifiers, which also resolves intermediate variables, reassignments
+	// and calls to functions returning a constant. Packages are loaded with
+	// their dependencies, which is slower and requires the files to be part
+	// of a module whose dependencies are available. Only AnalyzeFiles and
+	// AnalyzeStream support it, and the cache is not used. The values which
+	// constant propagation does not resolve, e.g. the elements of arrays or
+	// the fields read through pointers, are resolved syntactically. It is
+	// not the default since loading the packages is an order of magnitude
+	// slower than parsing them, and fails on the files outside of a module
+	// or without their dependencies.
+	SSA bool
+	// Generated tells how to report the issues of generated files. Empty
+	// means GeneratedSkip. Analyze only detects the generated files parsed
+	// with parser.ParseComments.
+	Generated GeneratedPolicy
+	// Cache stores the results of AnalyzeFiles per package. Nil disables
+	// caching.
+	Cache *Cache
+	// ReservedLabels are the ConstLabels keys reported in addition to
+	// DefaultReservedLabels, e.g. labels attached by relabeling rules.
+	ReservedLabels []string
+	// AllowedConstLabels are the only ConstLabels keys allowed, e.g.
+	// component and version, so that the static labels are consistent
+	// across services. Empty allows any key.
+	AllowedConstLabels []string
+	// NameValidation is the scheme validating the metric and label names.
+	// Empty means LegacyValidation. With UTF8Validation, NameEscaping is
+	// the scheme used to report the names which collide once escaped for
+	// the clients without UTF-8 support; empty means UnderscoreEscaping.
+	NameValidation NameValidation
+	NameEscaping   NameEscaping
+	// Profiles report the metrics using the names of the metrics of the
+	// libraries of their ecosystem, see ApplyProfile.
+	Profiles []Profile
+	// SimilarityRules tell which metric names the opt-in NearDuplicate rule
+	// reports. Nil means DefaultSimilarityRules.
+	SimilarityRules *SimilarityRules
+	// CreatedTimestamps tells which const counters the opt-in
+	// CreatedTimestamp rule reports. Empty means
+	// CreatedTimestampsConsistent.
+	CreatedTimestamps CreatedTimestampPolicy
+	// Hierarchy reports the metrics which are not placed in its namespaces
+	// and subsystems. Nil disables the check.
+	Hierarchy *Hierarchy
+	// Dictionary reports the words of help texts which are not in it, see
+	// RuleHelpSpelling. Nil disables the check.
+	Dictionary *Dictionary
+	// SeriesBudget reports the metric families whose estimated number of
+	// series exceeds it, see MetricFamilyWithPos.EstimatedSeries. Zero
+	// disables the check.
+	SeriesBudget int
+	// UnresolvedLabelValues is the number of values the series budget
+	// assumes for the labels whose values could not be enumerated. Zero
+	// skips the families with such labels.
+	UnresolvedLabelValues int
+	// GoVersion is the Go language version of the analyzed files, e.g.
+	// go1.21, see ParseGoVersion. The uses of the features of later
+	// versions, such as generics before go1.18 or range-over-func before
+	// go1.23, are listed in Result.SyntaxErrors. Empty means the version of
+	// the toolchain promlinter was built with.
+	GoVersion string
+	// Deduplicate collapses the issues of a metric defined identically, with
+	// the same name, type and help text, in several places, e.g. in the
+	// main package of each binary, into one issue per rule and text listing
+	// the other positions in Issue.Duplicates. AnalyzeStream does not
+	// support it.
+	Deduplicate bool
+	// Workspace attributes issues to the modules of a go.work workspace and
+	// reports the metrics defined in several of its modules. Nil disables
+	// workspace mode.
+	Workspace *Workspace
+	// Logger receives debug events about the analysis, such as unresolved
+	// identifiers and skipped constructors. Nil disables logging.
+	Logger *slog.Logger
+}
+
+// reports reports whether the issues of the rule with the given ID are
+// reported: it is not disabled, and enabled if it is opt-in.
+func (s Setting) reports(id string) bool {
+	if listsRule(s.DisabledRules, id) {
+		return false
+	}
+	r, ok := LookupRule(id)
+	return !ok || !r.OptIn || listsRule(s.EnabledRules, id)
+}
+
+func newVisitor(fs *token.FileSet, setting Setting) *visitor {
+	return &visitor{
 		fs:      fs,
-		metrics: make(map[*dto.MetricFamily]token.Position, 0),
+		metrics: make([]MetricFamilyWithPos, 0),
 		issues:  make([]Issue, 0),
-		strict:  strict,
+		setting: setting,
+
+		generated: make(map[string]bool),
+
+		defined:       make(map[*ast.Ident]bool),
+		references:    make(map[string]bool),
+		registrations: make(map[string][]token.Position),
 	}
+}
 
-	for _, file := range files {
-		ast.Walk(v, file)
+// Result is the outcome of the analysis of a set of files.
+type Result struct {
+	// Files is the number of files analyzed.
+	Files   int
+	Metrics []MetricFamilyWithPos
+	Issues  []Issue
+	// Skipped is the number of metric constructors whose metric could not be
+	// resolved, for instance because its name is computed at runtime.
+	Skipped int
+	// Truncated is true if the analysis stopped after Setting.MaxIssues issues.
+	Truncated bool
+	// SyntaxErrors holds the syntax errors of files which were analyzed
+	// partially, sorted by position.
+	SyntaxErrors []SyntaxError
+
+	// writes holds the calls updating metrics, see NewUsageReport, and
+	// registrations the positions where the holders of metrics are
+	// registered, see NewManifest.
+	writes        []writeSite
+	registrations map[string][]token.Position
+}
+
+// SyntaxError is a syntax error of an analyzed file. The declarations which
+// parsed are still analyzed, so that editors can show issues in files being
+// edited.
+type SyntaxError struct {
+	Pos token.Position `json:"pos"`
+	Msg string         `json:"msg"`
+}
+
+func (e SyntaxError) String() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Analyze discovers the metrics defined in files and lints them via promlint.
+//
+// Packages, i.e. the files of a same directory, are analyzed concurrently by
+// Setting.Concurrency workers, each with its own visitor.
+func Analyze(fs *token.FileSet, files []*ast.File, setting Setting) *Result {
+	pkgs := groupByPackage(len(files), func(i int) string {
+		return fs.Position(files[i].Pos()).Filename
+	})
+
+	res, _ := analyze(len(pkgs), setting, func(i int) (*partialResult, error) {
+		pkgFiles := make([]*ast.File, 0, len(pkgs[i]))
+		for _, f := range pkgs[i] {
+			pkgFiles = append(pkgFiles, files[f])
+		}
+
+		v := newVisitor(fs, setting)
+		v.types = checkFiles(fs, pkgFiles, setting.GoVersion)
+		for _, f := range pkgFiles {
+			v.walk(f)
+		}
+		v.lint(v.metrics)
+		res := v.result(len(pkgs[i]))
+		res.syntaxErrors = v.types.versionErrors
+		return res, nil
+	})
+	return res
+}
+
+// AnalyzeFiles parses the Go files at paths and analyzes them like Analyze. If
+// Setting.Cache is set, packages whose files did not change since a previous
+// run with the same setting are not parsed again. Files with syntax errors are
+// analyzed partially and their errors are listed in Result.SyntaxErrors.
+func AnalyzeFiles(fs *token.FileSet, paths []string, setting Setting) (*Result, error) {
+	pkgs := groupByPackage(len(paths), func(i int) string { return paths[i] })
+
+	// Packages outside of a module cannot be loaded with their dependencies:
+	// their values are resolved syntactically.
+	if setting.SSA && len(paths) > 0 && !inModule(filepath.Dir(paths[0])) {
+		if setting.Logger != nil {
+			setting.Logger.Warn("files outside of a module, resolving values syntactically", "dir", filepath.Dir(paths[0]))
+		}
+		setting.SSA = false
 	}
 
-	// lint metrics
-	for metric := range v.metrics {
-		problems, err := promlint.NewWithMetricFamilies([]*dto.MetricFamily{metric}).Lint()
+	var resolver *ssaResolver
+	if setting.SSA {
+		var err error
+		if resolver, err = loadSSA(paths, setting); err != nil {
+			return nil, err
+		}
+	}
+
+	return analyze(len(pkgs), setting, func(i int) (*partialResult, error) {
+		pkgPaths := make([]string, 0, len(pkgs[i]))
+		for _, f := range pkgs[i] {
+			pkgPaths = append(pkgPaths, paths[f])
+		}
+		return analyzePackage(fs, pkgPaths, setting, resolver)
+	})
+}
+
+// analyzePackage analyzes the files of a package, using the cache if any.
+// Values are resolved with resolver if it is not nil, in which case the cache
+// is not used: the result depends on other packages.
+func analyzePackage(fs *token.FileSet, paths []string, setting Setting, resolver *ssaResolver) (*partialResult, error) {
+	srcs := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
+		srcs = append(srcs, src)
+	}
+	if resolver != nil {
+		setting.Cache = nil
+	}
 
-		for _, p := range problems {
-			v.issues = append(v.issues, Issue{
-				Pos:    v.metrics[metric],
-				Metric: p.Metric,
-				Text:   p.Text,
-			})
+	var key, dir string
+	if setting.Cache != nil {
+		dir = filepath.Dir(paths[0])
+		key = setting.Cache.key(paths, srcs, setting)
+		if res, ok := setting.Cache.get(key); ok {
+			if setting.Logger != nil {
+				setting.Logger.Debug("using cached analysis", "package", dir)
+			}
+			return res, nil
 		}
 	}
 
-	sort.Slice(v.issues, func(i, j int) bool {
-		return v.issues[i].Pos.String() < v.issues[j].Pos.String()
+	var (
+		files        = make([]*ast.File, 0, len(paths))
+		syntaxErrors []SyntaxError
+	)
+	for i, path := range paths {
+		if setting.Logger != nil {
+			setting.Logger.Debug("parsing file", "file", path)
+		}
+		file, errs, err := parseFile(fs, path, srcs[i])
+		if err != nil {
+			return nil, err
+		}
+		if len(errs) > 0 && setting.Logger != nil {
+			setting.Logger.Debug("analyzing file with syntax errors", "file", path, "errors", len(errs))
+		}
+		files = append(files, file)
+		syntaxErrors = append(syntaxErrors, newerSyntaxHint(errs, setting.GoVersion)...)
+	}
+
+	v := newVisitor(fs, setting)
+	v.types = checkFiles(fs, files, setting.GoVersion)
+	syntaxErrors = append(syntaxErrors, v.types.versionErrors...)
+	v.ssa = resolver
+	for _, file := range files {
+		v.walk(file)
+	}
+	v.lint(v.metrics)
+	res := v.result(len(files))
+	res.syntaxErrors = syntaxErrors
+
+	if setting.Cache != nil {
+		if err := setting.Cache.put(dir, key, res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// parseFile parses a file, returning the syntax errors along with the partial
+// AST of the file if it has some.
+func parseFile(fs *token.FileSet, path string, src []byte) (*ast.File, []SyntaxError, error) {
+	file, err := parser.ParseFile(fs, path, src, parser.AllErrors|parser.ParseComments|parser.SkipObjectResolution)
+	if err == nil {
+		return file, nil, nil
+	}
+
+	var list scanner.ErrorList
+	if file == nil || !errors.As(err, &list) {
+		return nil, nil, err
+	}
+	// Only keep the first error of each line, the others usually follow
+	// from it.
+	list.RemoveMultiples()
+	errs := make([]SyntaxError, 0, len(list))
+	for _, e := range list {
+		errs = append(errs, SyntaxError{Pos: e.Pos, Msg: e.Msg})
+	}
+	return file, errs, nil
+}
+
+// partialResult is the result of the analysis of a package or a file.
+type partialResult struct {
+	files   int
+	metrics []MetricFamilyWithPos
+	issues  []Issue
+	skipped int
+
+	syntaxErrors []SyntaxError
+	// references and registrations hold the keys of the variables and
+	// fields referenced and the sites where they are registered, to find
+	// dead metrics.
+	references    map[string]bool
+	registrations map[string][]token.Position
+	writes        []writeSite
+	labels        []labelSite
+	instruments   []otelInstrument
+	// generated holds the names of the generated files, whose issues found
+	// by the module checks follow Setting.Generated too.
+	generated map[string]bool
+}
+
+func (v *visitor) result(files int) *partialResult {
+	return &partialResult{files: files, metrics: v.metrics, issues: v.issues, skipped: v.skipped, references: v.references, registrations: v.registrations, writes: v.writes, labels: v.labels, instruments: v.instruments, generated: v.generated}
+}
+
+func (p *partialResult) merge(other *partialResult) {
+	p.files += other.files
+	p.metrics = append(p.metrics, other.metrics...)
+	p.issues = append(p.issues, other.issues...)
+	p.skipped += other.skipped
+	p.syntaxErrors = append(p.syntaxErrors, other.syntaxErrors...)
+	for k := range other.references {
+		p.references[k] = true
+	}
+	for k, sites := range other.registrations {
+		p.registrations[k] = append(p.registrations[k], sites...)
+	}
+	p.writes = append(p.writes, other.writes...)
+	p.labels = append(p.labels, other.labels...)
+	p.instruments = append(p.instruments, other.instruments...)
+	for k := range other.generated {
+		p.generated[k] = true
+	}
+}
+
+// moduleChecks are the checks run on the merged results of all packages,
+// since metrics are often updated in packages other than the one defining
+// them. Every analysis runs them, AnalyzeStream included, see
+// runModuleChecks.
+var moduleChecks = []func(*partialResult) []Issue{
+	deadMetrics,
+	testOnlyMetrics,
+	testDefinitionDrift,
+	negativeCounterAdds,
+	suspiciousObservations,
+	unitMismatches,
+	timerTargets,
+	constLabelCandidates,
+	undeclaredLabelValues,
+	infoMetrics,
+	seriesCollisions,
+	implicitSuffixNames,
+	mixedUnits,
+	unitConversions,
+	exemplarIssues,
+	otelCollisions,
+}
+
+// analyze runs work for each of the n units of work with Setting.Concurrency
+// workers and merges the partial results.
+func analyze(n int, setting Setting, work func(i int) (*partialResult, error)) (*Result, error) {
+	workers := setting.Concurrency
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var (
+		merged = &partialResult{metrics: make([]MetricFamilyWithPos, 0), issues: make([]Issue, 0), references: make(map[string]bool), registrations: make(map[string][]token.Position), generated: make(map[string]bool)}
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		jobs   = make(chan int)
+		errs   []error
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				res, err := work(i)
+
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					merged.merge(res)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	dispatched := 0
+	for ; dispatched < n; dispatched++ {
+		mu.Lock()
+		done := len(errs) > 0 || setting.MaxIssues > 0 && len(merged.issues) >= setting.MaxIssues
+		mu.Unlock()
+		if done {
+			break
+		}
+		jobs <- dispatched
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	res := &Result{Files: merged.files, Metrics: merged.metrics, Issues: merged.issues, Skipped: merged.skipped, SyntaxErrors: merged.syntaxErrors, writes: merged.writes, registrations: merged.registrations}
+	sortSyntaxErrors(res.SyntaxErrors)
+	// These checks need the references and writes of every package.
+	if dispatched == n {
+		res.Issues = append(res.Issues, runModuleChecks(merged, setting)...)
+	}
+	if ws := setting.Workspace; ws != nil {
+		ws.attribute(res.Issues)
+		for _, iss := range ws.duplicates(res.Metrics) {
+			if setting.apply(&iss) && setting.applyGenerated(&iss, merged.generated) {
+				res.Issues = append(res.Issues, iss)
+			}
+		}
+	}
+	setting.formatMessages(res.Issues)
+	sortMetrics(res.Metrics)
+	sortIssues(res.Issues)
+	if setting.Deduplicate {
+		res.Issues = deduplicate(res.Issues, res.Metrics)
+	}
+	if limit := setting.MaxIssues; limit > 0 && len(res.Issues) >= limit {
+		res.Issues = res.Issues[:limit]
+		res.Truncated = true
+	}
+	return res, nil
+}
+
+// runModuleChecks runs the module checks, and those enabled by setting, on
+// the merged results of all packages and returns the issues setting reports,
+// following the policy of generated files.
+func runModuleChecks(merged *partialResult, setting Setting) []Issue {
+	enumerateLabels(merged)
+	checks := moduleChecks[:len(moduleChecks):len(moduleChecks)]
+	if setting.SeriesBudget > 0 {
+		checks = append(checks, func(res *partialResult) []Issue { return seriesBudget(res, setting) })
+	}
+	if setting.NameValidation == UTF8Validation {
+		checks = append(checks, escapingCollisions(setting.NameEscaping))
+	}
+	if setting.reports(RuleNearDuplicate) {
+		rules := DefaultSimilarityRules
+		if setting.SimilarityRules != nil {
+			rules = *setting.SimilarityRules
+		}
+		checks = append(checks, func(res *partialResult) []Issue { return nearDuplicates(res, rules) })
+	}
+	if setting.reports(RuleInconsistentPrefix) {
+		checks = append(checks, inconsistentPrefixes)
+	}
+	if setting.reports(RuleCreatedTimestamp) {
+		checks = append(checks, createdTimestamps(setting.CreatedTimestamps))
+	}
+	if len(inventoryChecks) > 0 {
+		checks = append(checks, runInventoryChecks)
+	}
+	var issues []Issue
+	for _, check := range checks {
+		for _, iss := range check(merged) {
+			if setting.apply(&iss) && setting.applyGenerated(&iss, merged.generated) {
+				issues = append(issues, iss)
+			}
+		}
+	}
+	return issues
+}
+
+// groupByPackage groups the indexes of n files by directory, keeping the order
+// of files.
+func groupByPackage(n int, filename func(i int) string) [][]int {
+	var (
+		pkgs  [][]int
+		index = make(map[string]int)
+	)
+	for i := 0; i < n; i++ {
+		dir := filepath.Dir(filename(i))
+		p, ok := index[dir]
+		if !ok {
+			p = len(pkgs)
+			index[dir] = p
+			pkgs = append(pkgs, nil)
+		}
+		pkgs[p] = append(pkgs[p], i)
+	}
+	return pkgs
+}
+
+// RunList returns all the metric families discovered in files, sorted by position.
+func RunList(fs *token.FileSet, files []*ast.File, strict bool) []MetricFamilyWithPos {
+	return Analyze(fs, files, Setting{Strict: strict}).Metrics
+}
+
+// Run lints the metrics discovered in files via promlint and returns the issues found.
+func Run(fs *token.FileSet, files []*ast.File, strict bool) []Issue {
+	return Analyze(fs, files, Setting{Strict: strict}).Issues
+}
+
+func sortMetrics(metrics []MetricFamilyWithPos) {
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return positionLess(metrics[i].Pos, metrics[j].Pos)
 	})
-	return v.issues
+}
+
+func sortSyntaxErrors(errs []SyntaxError) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		return positionLess(errs[i].Pos, errs[j].Pos)
+	})
+}
+
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Pos == issues[j].Pos {
+			if issues[i].RuleID == issues[j].RuleID {
+				return issues[i].Text < issues[j].Text
+			}
+			return issues[i].RuleID < issues[j].RuleID
+		}
+		return positionLess(issues[i].Pos, issues[j].Pos)
+	})
+}
+
+// positionLess orders positions by file name, line and column.
+func positionLess(a, b token.Position) bool {
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
+
+// lint lints metrics via promlint, the custom validations and the other
+// checks of single metrics. It drops the constructor calls of the metrics once
+// they are checked.
+func (v *visitor) lint(metrics []MetricFamilyWithPos) {
+	for i, metric := range metrics {
+		v.runChecks(metric, metric.call)
+		v.runScriptRules(metric)
+		metrics[i].call = nil
+
+		problems := promlintProblems(metric.MetricFamily)
+
+		v.lintHelp(metric)
+		v.lintHierarchy(metric)
+		v.lintProfiles(metric)
+		v.lintRuntimePrefix(metric)
+		v.lintNames(metric)
+		for _, p := range problems {
+			// The problems of the label names point at the whole Opts.
+			pos, end := metric.nameRange()
+			switch {
+			case p.ruleID == RuleHelp:
+				pos, end = metric.helpRange()
+			case strings.Contains(p.Text, "label"):
+				pos, end = metric.Pos, metric.End
+			}
+			iss := Issue{
+				Pos:        pos,
+				Metric:     p.Metric,
+				Text:       p.Text,
+				RuleID:     p.ruleID,
+				Severity:   ruleSeverity(p.ruleID),
+				End:        end,
+				MetricType: metricTypeName(metric.MetricFamily.GetType()),
+				Labels:     metric.Labels(),
+			}
+			if unit, base, ok := nonBaseUnit(p.Text); ok && p.ruleID == RuleMetricUnits {
+				iss.Fixes = v.baseUnitFixes(metric, unit, base)
+			}
+			v.addIssue(iss)
+		}
+	}
+}
+
+// addIssue records iss unless its rule is disabled, applying the rule
+// overrides and the policy of generated files.
+func (v *visitor) addIssue(iss Issue) {
+	if v.setting.apply(&iss) && v.setting.applyGenerated(&iss, v.generated) {
+		v.issues = append(v.issues, iss)
+	}
+}
+
+// walk walks file, recording its imports and whether it is generated. Files
+// parsed without parser.ParseComments are never considered generated.
+func (v *visitor) walk(file *ast.File) {
+	v.parseImports(file)
+	v.parseDirectives(file)
+	if ast.IsGenerated(file) {
+		filename := v.fs.Position(file.Pos()).Filename
+		v.debug(file, "file is generated", "policy", v.setting.generatedPolicy())
+		v.generated[filename] = true
+	}
+	ast.Walk(v, file)
+	v.collectReferences(file)
 }
 
 func (v *visitor) Visit(n ast.Node) ast.Visitor {
@@ -116,11 +853,34 @@ func (v *visitor) Visit(n ast.Node) ast.Visitor {
 	return v
 }
 
+// debug logs an event about the node n.
+func (v *visitor) debug(n ast.Node, msg string, args ...interface{}) {
+	if v.setting.Logger == nil || v.quiet {
+		return
+	}
+	v.setting.Logger.Debug(msg, append([]interface{}{"pos", v.fs.Position(n.Pos()).String()}, args...)...)
+}
+
+// report records an issue of the rule ruleID for the node n.
+func (v *visitor) report(n ast.Node, ruleID, text string) {
+	if v.quiet {
+		return
+	}
+	v.addIssue(Issue{
+		Pos:      v.fs.Position(n.Pos()),
+		End:      v.fs.Position(n.End()),
+		Text:     text,
+		RuleID:   ruleID,
+		Severity: ruleSeverity(ruleID),
+	})
+}
+
 func (v *visitor) parseCallerExpr(call *ast.CallExpr) ast.Visitor {
 	var (
-		metricType dto.MetricType
-		methodName string
-		ok         bool
+		metricType     dto.MetricType
+		methodName     string
+		ok             bool
+		autoRegistered bool
 	)
 	switch stmt := call.Fun.(type) {
 
@@ -131,10 +891,11 @@ func (v *visitor) parseCallerExpr(call *ast.CallExpr) ast.Visitor {
 			metric := NewCounter(CounterOpts{})
 	*/
 	case *ast.Ident:
-		if metricType, ok = metricsType[stmt.Name]; !ok {
+		if metricType, ok = metricsType[stmt.Name]; !ok || !v.isDotImported(stmt) {
 			return v
 		}
 		methodName = stmt.Name
+		autoRegistered = v.imports.dotPromauto
 
 	/*
 		This case covers the most of cases to initialize metrics.
@@ -150,7 +911,12 @@ func (v *visitor) parseCallerExpr(call *ast.CallExpr) ast.Visitor {
 		if metricType, ok = metricsType[stmt.Sel.Name]; !ok {
 			return v
 		}
+		if v.isOtherPackage(stmt.X) && !v.hasPrometheusOpts(call) {
+			v.debug(call, "skipped constructor of another package", "constructor", stmt.Sel.Name)
+			return v
+		}
 		methodName = stmt.Sel.Name
+		autoRegistered = v.isPromauto(stmt.X)
 
 	default:
 		return v
@@ -161,12 +927,8 @@ func (v *visitor) parseCallerExpr(call *ast.CallExpr) ast.Visitor {
 		argNum = 2
 	}
 	// The methods used to initialize metrics should have at least one arg.
-	if len(call.Args) < 1 && v.strict {
-		v.issues = append(v.issues, Issue{
-			Pos:    v.fs.Position(call.Pos()),
-			Metric: "",
-			Text:   fmt.Sprintf("%s should have at least %d arguments", methodName, argNum),
-		})
+	if len(call.Args) < 1 && v.setting.Strict {
+		v.report(call, RuleConstructorArgs, fmt.Sprintf("%s should have at least %d arguments", methodName, argNum))
 		return v
 	}
 
@@ -175,6 +937,11 @@ func (v *visitor) parseCallerExpr(call *ast.CallExpr) ast.Visitor {
 
 	opts, help := v.parseOpts(call.Args[0])
 	if opts == nil {
+		v.debug(call, "skipped metric constructor: opts could not be resolved", "constructor", methodName)
+		v.skipped++
+		if !v.reportLoopName(call, call.Args[0], false) {
+			v.reportDynamicName(call.Args[0], false, metricTypeName(metricType))
+		}
 		return v
 	}
 
@@ -186,7 +953,39 @@ func (v *visitor) parseCallerExpr(call *ast.CallExpr) ast.Visitor {
 	metricName := prometheus.BuildFQName(opts.namespace, opts.subsystem, opts.name)
 	currentMetric.Name = &metricName
 
-	v.metrics[&currentMetric] = optsPosition
+	// Vec constructors take the variable label names as the second arg.
+	var labels []string
+	if argNum == 2 && len(call.Args) > 1 {
+		if labels, ok = v.parseLabels(call.Args[1]); ok {
+			setLabels(&currentMetric, labels)
+		}
+	}
+
+	fields := []textField{{"Namespace", opts.namespace}, {"Subsystem", opts.subsystem}, {"Name", opts.name}}
+	if help != nil {
+		fields = append(fields, textField{"Help", *help})
+	}
+	v.reportWhitespace(optsPosition, v.fs.Position(call.Args[0].End()), metricName, metricTypeName(metricType), append(fields, labelFields(labels)...))
+
+	m := MetricFamilyWithPos{
+		MetricFamily:   &currentMetric,
+		Pos:            optsPosition,
+		End:            v.fs.Position(call.Args[0].End()),
+		AutoRegistered: autoRegistered,
+		Constructor:    methodName,
+		holder:         v.holder(call),
+		buckets:        v.histogramBuckets(metricType, call.Args[0]),
+		allowedValues:  v.annotatedValues(call),
+		call:           call,
+		nameSpan:       v.fieldSpan(call.Args[0], "Name"),
+		helpSpan:       v.fieldSpan(call.Args[0], "Help"),
+	}
+	for _, value := range v.fieldExprs(call.Args[0], "ConstLabels") {
+		keys := v.constLabelKeys(value, 0)
+		v.reportConstLabels(m, keys)
+		m.constLabels = m.constLabels || hasConstLabels(value, keys)
+	}
+	v.metrics = append(v.metrics, m)
 	return v
 }
 
@@ -203,29 +1002,33 @@ func (v *visitor) parseSendMetricChanExpr(chExpr *ast.SendStmt) ast.Visitor {
 	}
 	switch stmt := call.Fun.(type) {
 	case *ast.Ident:
-		if requiredArgNum, ok = constMetricArgs[stmt.Name]; !ok {
+		if requiredArgNum, ok = constMetricArgs[stmt.Name]; !ok || !v.isDotImported(stmt) {
 			return v
 		}
 		methodName = stmt.Name
 
 	case *ast.SelectorExpr:
-		if requiredArgNum, ok = constMetricArgs[stmt.Sel.Name]; !ok {
+		if requiredArgNum, ok = constMetricArgs[stmt.Sel.Name]; !ok || v.isOtherPackage(stmt.X) {
 			return v
 		}
 		methodName = stmt.Sel.Name
+
+	default:
+		return v
 	}
 
-	if len(call.Args) < requiredArgNum && v.strict {
-		v.issues = append(v.issues, Issue{
-			Pos:    v.fs.Position(call.Pos()),
-			Metric: "",
-			Text:   fmt.Sprintf("%s should have at least %d arguments", methodName, requiredArgNum),
-		})
+	if len(call.Args) < requiredArgNum && v.setting.Strict {
+		v.report(call, RuleConstructorArgs, fmt.Sprintf("%s should have at least %d arguments", methodName, requiredArgNum))
 		return v
 	}
 
-	name, help := v.parseConstMetricOpts(call.Args[0])
+	name, help, labels := v.parseConstMetricOpts(call.Args[0])
 	if name == nil {
+		v.debug(call, "skipped const metric: desc could not be resolved", "constructor", methodName)
+		v.skipped++
+		if !v.reportLoopName(call, call.Args[0], true) {
+			v.reportDynamicName(call.Args[0], true, "")
+		}
 		return v
 	}
 
@@ -233,8 +1036,9 @@ func (v *visitor) parseSendMetricChanExpr(chExpr *ast.SendStmt) ast.Visitor {
 		Name: name,
 		Help: help,
 	}
+	setLabels(metric, labels)
 	switch methodName {
-	case "MustNewConstMetric":
+	case "MustNewConstMetric", "MustNewConstMetricWithCreatedTimestamp":
 		switch t := call.Args[1].(type) {
 		case *ast.Ident:
 			metric.Type = getConstMetricType(t.Name)
@@ -249,8 +1053,22 @@ func (v *visitor) parseSendMetricChanExpr(chExpr *ast.SendStmt) ast.Visitor {
 		metricType = dto.MetricType_SUMMARY
 		metric.Type = &metricType
 	}
-
-	v.metrics[metric] = v.fs.Position(call.Pos())
+	v.reportWhitespace(v.fs.Position(call.Pos()), v.fs.Position(call.End()), *name, metricTypeName(metric.GetType()), append([]textField{{"fqName", *name}, {"help", *help}}, labelFields(labels)...))
+
+	m := MetricFamilyWithPos{
+		MetricFamily: metric,
+		Pos:          v.fs.Position(call.Pos()),
+		End:          v.fs.Position(call.End()),
+		Constructor:  methodName,
+		call:         call,
+	}
+	if desc := v.descCall(call.Args[0]); desc != nil && len(desc.Args) == 4 {
+		m.nameSpan, m.helpSpan = v.exprSpan(desc.Args[0]), v.exprSpan(desc.Args[1])
+		keys := v.constLabelKeys(desc.Args[3], 0)
+		v.reportConstLabels(m, keys)
+		m.constLabels = hasConstLabels(desc.Args[3], keys)
+	}
+	v.metrics = append(v.metrics, m)
 	return v
 }
 
@@ -260,42 +1078,120 @@ func (v *visitor) parseOpts(n ast.Node) (*opt, *string) {
 		return v.parseCompositeOpts(stmt)
 
 	case *ast.Ident:
-		if stmt.Obj != nil {
-			if decl, ok := stmt.Obj.Decl.(*ast.AssignStmt); ok && len(decl.Rhs) > 0 {
-				if t, ok := decl.Rhs[0].(*ast.CompositeLit); ok {
-					return v.parseCompositeOpts(t)
-				}
-			}
+		if bound := v.bound(stmt); bound != nil {
+			defer v.caller()()
+			return v.parseOpts(bound)
 		}
+		return v.parseIdentOpts(stmt)
+
+	case *ast.CallExpr:
+		return v.parseCallOpts(stmt)
 	}
 
 	return nil, nil
 }
 
-func (v *visitor) parseCompositeOpts(stmt *ast.CompositeLit) (*opt, *string) {
+// parseIdentOpts parses an Opts variable from the composite literals and field
+// assignments reaching ident, e.g.
+//
+//	opts := prometheus.CounterOpts{Name: "foo_total"}
+//	opts.Help = "Foo."
+//	prometheus.NewCounter(opts)
+func (v *visitor) parseIdentOpts(ident *ast.Ident) (*opt, *string) {
 	metricOption := &opt{}
 	var help *string
-	for _, elt := range stmt.Elts {
-		kvExpr, ok := elt.(*ast.KeyValueExpr)
-		if !ok {
-			continue
+	for _, field := range positionalOptsFields {
+		defs, ok := v.types.reaching(ident, field)
+		if !ok || len(defs) == 0 {
+			v.debug(ident, "unresolved identifier", "field", field, "identifier", ident.Name)
+			return nil, nil
+		}
+
+		values := make([]ast.Expr, 0, len(defs))
+		absent := true
+		for _, d := range defs {
+			value := d.value
+			if d.field == "" {
+				lit, ok := value.(*ast.CompositeLit)
+				if !ok {
+					v.debug(ident, "identifier is not defined by a composite literal", "identifier", ident.Name)
+					return nil, nil
+				}
+				var found bool
+				if value, found = compositeField(lit, field); !found {
+					value = &ast.BasicLit{ValuePos: lit.Pos(), Kind: token.STRING, Value: `""`}
+				}
+				absent = absent && !found
+			} else {
+				absent = false
+			}
+			values = append(values, value)
 		}
-		object, ok := kvExpr.Key.(*ast.Ident)
+
+		stringLiteral, ok := v.resolveDefinitions(ident, ident.Name+"."+field, field, values)
 		if !ok {
-			continue
+			return nil, nil
+		}
+
+		switch field {
+		case "Namespace":
+			metricOption.namespace = stringLiteral
+		case "Subsystem":
+			metricOption.subsystem = stringLiteral
+		case "Name":
+			metricOption.name = stringLiteral
+		case "Help":
+			if !absent {
+				help = &stringLiteral
+			}
 		}
+	}
+
+	return metricOption, help
+}
 
-		if _, ok := validOptsFields[object.Name]; !ok {
+// compositeField returns the value of field in an Opts composite literal.
+func compositeField(lit *ast.CompositeLit, field string) (ast.Expr, bool) {
+	for i, elt := range lit.Elts {
+		if kvExpr, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kvExpr.Key.(*ast.Ident); ok && key.Name == field {
+				return kvExpr.Value, true
+			}
+		} else if i < len(positionalOptsFields) && positionalOptsFields[i] == field {
+			return elt, true
+		}
+	}
+	return nil, false
+}
+
+func (v *visitor) parseCompositeOpts(stmt *ast.CompositeLit) (*opt, *string) {
+	metricOption := &opt{}
+	var help *string
+	for i, elt := range stmt.Elts {
+		var field string
+		value := elt
+		if kvExpr, ok := elt.(*ast.KeyValueExpr); ok {
+			object, ok := kvExpr.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			field, value = object.Name, kvExpr.Value
+		} else if i < len(positionalOptsFields) {
+			// Unkeyed literals, e.g. prometheus.CounterOpts{"ns", "sub", "name", "help"}.
+			field = positionalOptsFields[i]
+		}
+
+		if _, ok := validOptsFields[field]; !ok {
 			continue
 		}
 
 		// If failed to parse field value, stop parsing.
-		stringLiteral, ok := v.parseValue(object.Name, kvExpr.Value)
+		stringLiteral, ok := v.parseValue(field, value)
 		if !ok {
 			return nil, nil
 		}
 
-		switch object.Name {
+		switch field {
 		case "Namespace":
 			metricOption.namespace = stringLiteral
 		case "Subsystem":
@@ -311,6 +1207,19 @@ func (v *visitor) parseCompositeOpts(stmt *ast.CompositeLit) (*opt, *string) {
 }
 
 func (v *visitor) parseValue(object string, n ast.Node) (string, bool) {
+	// Constant propagation does not know the values bound to parameters.
+	if _, lit := n.(*ast.BasicLit); v.ssa != nil && !lit && len(v.bindings) == 0 {
+		if _, ok := n.(ast.Expr); ok {
+			// The values constant propagation cannot resolve, e.g. the
+			// elements of arrays, are resolved syntactically.
+			if s, ok, handled := v.ssa.resolve(v.fs.Position(n.Pos()), n); handled && ok {
+				return s, ok
+			} else if handled {
+				v.debug(n, "value could not be resolved by constant propagation", "field", object)
+			}
+		}
+	}
+
 	switch t := n.(type) {
 
 	// make sure it is string literal value
@@ -322,19 +1231,45 @@ func (v *visitor) parseValue(object string, n ast.Node) (string, bool) {
 		return "", false
 
 	case *ast.Ident:
-		if t.Obj == nil {
+		if s, ok := v.types.constant(t); ok {
+			return s, true
+		}
+		if bound := v.bound(t); bound != nil {
+			defer v.caller()()
+			return v.parseValue(object, bound)
+		}
+		defs, ok := v.types.reaching(t, "")
+		if !ok || len(defs) == 0 {
+			v.debug(t, "unresolved identifier", "field", object, "identifier", t.Name)
 			return "", false
 		}
+		values := make([]ast.Expr, 0, len(defs))
+		for _, d := range defs {
+			values = append(values, d.value)
+		}
+		return v.resolveDefinitions(t, t.Name, object, values)
 
-		if vs, ok := t.Obj.Decl.(*ast.ValueSpec); ok {
-			return v.parseValue(object, vs)
+	case *ast.SelectorExpr:
+		if s, ok := v.parseFieldValue(object, t); ok {
+			return s, true
+		}
+		if v.setting.Strict {
+			v.report(n, RuleUnsupportedExpr, fmt.Sprintf("parsing field %s with type %T is not supported", object, t))
 		}
 
-	case *ast.ValueSpec:
-		if len(t.Values) == 0 {
-			return "", false
+	// Names looked up in an array or map literal by a constant index.
+	case *ast.IndexExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			if lit, ok := v.reachingValue(x).(*ast.CompositeLit); ok {
+				if value := v.types.element(lit, t.Index); value != nil {
+					return v.parseValue(object, value)
+				}
+			}
+		}
+		v.debug(t, "unresolved index expression", "field", object)
+		if v.setting.Strict {
+			v.report(n, RuleUnsupportedExpr, fmt.Sprintf("parsing field %s with type %T is not supported", object, t))
 		}
-		return v.parseValue(object, t.Values[0])
 
 	// For binary expr, we only support adding two strings like `foo` + `bar`.
 	case *ast.BinaryExpr:
@@ -353,78 +1288,118 @@ func (v *visitor) parseValue(object string, n ast.Node) (string, bool) {
 		}
 
 	default:
-		if v.strict {
-			v.issues = append(v.issues, Issue{
-				Pos:    v.fs.Position(n.Pos()),
-				Metric: "",
-				Text:   fmt.Sprintf("parsing field %s with type %T is not supported", object, t),
-			})
+		v.debug(n, "unsupported expression", "field", object, "type", fmt.Sprintf("%T", t))
+		if v.setting.Strict {
+			v.report(n, RuleUnsupportedExpr, fmt.Sprintf("parsing field %s with type %T is not supported", object, t))
 		}
 	}
 
 	return "", false
 }
 
-func (v *visitor) parseConstMetricOpts(n ast.Node) (*string, *string) {
+// parseLabels parses a list of label names, such as the variable labels of a Vec
+// constructor or NewDesc.
+func (v *visitor) parseLabels(n ast.Node) ([]string, bool) {
+	switch t := n.(type) {
+	case *ast.CompositeLit:
+		labels := make([]string, 0, len(t.Elts))
+		for _, elt := range t.Elts {
+			label, ok := v.parseValue("labels", elt)
+			if !ok {
+				return nil, false
+			}
+			labels = append(labels, label)
+		}
+		return labels, true
+
+	case *ast.Ident:
+		if t.Name == "nil" {
+			return nil, true
+		}
+		value := v.reachingValue(t)
+		if value == nil {
+			return nil, false
+		}
+		return v.parseLabels(value)
+	}
+
+	return nil, false
+}
+
+// descCall returns the NewDesc call defining the desc n, a call or a
+// variable assigned one.
+func (v *visitor) descCall(n ast.Expr) *ast.CallExpr {
+	if ident, ok := n.(*ast.Ident); ok {
+		n = v.reachingValue(ident)
+	}
+	call, _ := n.(*ast.CallExpr)
+	return call
+}
+
+func (v *visitor) parseConstMetricOpts(n ast.Node) (*string, *string, []string) {
 	switch stmt := n.(type) {
 	case *ast.CallExpr:
 		return v.parseNewDescCallExpr(stmt)
 
 	case *ast.Ident:
-		if stmt.Obj != nil {
-			switch t := stmt.Obj.Decl.(type) {
-			case *ast.AssignStmt:
-				if len(t.Rhs) > 0 {
-					if call, ok := t.Rhs[0].(*ast.CallExpr); ok {
-						return v.parseNewDescCallExpr(call)
-					}
-				}
-			case *ast.ValueSpec:
-				if len(t.Values) > 0 {
-					if call, ok := t.Values[0].(*ast.CallExpr); ok {
-						return v.parseNewDescCallExpr(call)
-					}
-				}
+		if value := v.reachingValue(stmt); value != nil {
+			if call, ok := value.(*ast.CallExpr); ok {
+				return v.parseNewDescCallExpr(call)
 			}
 
-			if v.strict {
-				v.issues = append(v.issues, Issue{
-					Pos:    v.fs.Position(n.Pos()),
-					Metric: "",
-					Text:   fmt.Sprintf("parsing desc of type %T is not supported", stmt.Obj.Decl),
-				})
+			if v.setting.Strict {
+				v.report(n, RuleUnsupportedExpr, fmt.Sprintf("parsing desc of type %T is not supported", value))
 			}
 		}
 	}
 
-	return nil, nil
+	return nil, nil, nil
 }
 
-func (v *visitor) parseNewDescCallExpr(call *ast.CallExpr) (*string, *string) {
+func (v *visitor) parseNewDescCallExpr(call *ast.CallExpr) (*string, *string, []string) {
 	var (
 		help string
 		name string
 		ok   bool
 	)
-	if len(call.Args) != 4 && v.strict {
-		v.issues = append(v.issues, Issue{
-			Pos:    v.fs.Position(call.Pos()),
-			Metric: "",
-			Text:   "NewDesc should have 4 args",
-		})
-		return nil, nil
+	if len(call.Args) != 4 {
+		if v.setting.Strict {
+			v.report(call, RuleConstructorArgs, "NewDesc should have 4 args")
+		}
+		return nil, nil, nil
 	}
 
 	name, ok = v.parseValue("fqName", call.Args[0])
 	if !ok {
-		return nil, nil
+		return nil, nil, nil
 	}
 	help, ok = v.parseValue("help", call.Args[1])
 	if !ok {
-		return nil, nil
+		return nil, nil, nil
+	}
+
+	labels, _ := v.parseLabels(call.Args[2])
+	return &name, &help, labels
+}
+
+// setLabels records label names on the metric family so that promlint can
+// check them and the inventory can list them.
+func setLabels(mf *dto.MetricFamily, labels []string) {
+	if len(labels) == 0 {
+		return
+	}
+
+	metric := &dto.Metric{}
+	for i := range labels {
+		metric.Label = append(metric.Label, &dto.LabelPair{Name: &labels[i]})
 	}
+	mf.Metric = []*dto.Metric{metric}
+}
 
-	return &name, &help
+// metricTypeName returns the lower case name of a metric type, as used in the
+// exposition format.
+func metricTypeName(t dto.MetricType) string {
+	return strings.ToLower(t.String())
 }
 
 func mustUnquote(str string) string {
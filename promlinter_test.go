@@ -1,9 +1,16 @@
 package promlinter
 
 import (
+	"bytes"
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -26,4 +33,163 @@ func TestRun(t *testing.T) {
 	if issues[1].Metric != "test_metric_total" && issues[0].Text != `no help text` {
 		t.Fatal()
 	}
+
+	if issues[0].RuleID != RuleCounter || issues[1].RuleID != RuleHelp {
+		t.Fatalf("unexpected rule IDs %s, %s", issues[0].RuleID, issues[1].RuleID)
+	}
+
+	// The issues of the name point at the Name field.
+	if iss := issues[0]; iss.Severity != SeverityError || iss.MetricType != "counter" || iss.Pos.Line != 14 || iss.Pos.Column != 10 || iss.End.Column != 28 {
+		t.Fatalf("unexpected issue %+v", iss)
+	}
+}
+
+func TestRunList(t *testing.T) {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "./testdata/testdata.go", nil, parser.AllErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := RunList(fs, []*ast.File{file}, false)
+	if len(metrics) != 5 {
+		t.Fatalf("expected 5 metrics, got %d", len(metrics))
+	}
+
+	last := metrics[len(metrics)-1]
+	if last.MetricFamily.GetName() != "prometheus_operator_spec_replicas" {
+		t.Fatalf("unexpected metric %s", last.MetricFamily.GetName())
+	}
+	if labels := last.Labels(); len(labels) != 2 || labels[0] != "namespace" || labels[1] != "name" {
+		t.Fatalf("unexpected labels %v", labels)
+	}
+}
+
+func TestAnalyzeSetting(t *testing.T) {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "./testdata/testdata.go", nil, parser.AllErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := Analyze(fs, []*ast.File{file}, Setting{DisabledRules: []string{RuleCounter}})
+	if len(res.Issues) != 1 || res.Issues[0].RuleID != RuleHelp || res.Truncated {
+		t.Fatalf("unexpected issues %+v", res.Issues)
+	}
+
+	res = Analyze(fs, []*ast.File{file}, Setting{MaxIssues: 1})
+	if len(res.Issues) != 1 || !res.Truncated {
+		t.Fatalf("expected the analysis to stop after 1 issue, got %+v", res.Issues)
+	}
+}
+
+func TestAnalyzeLogger(t *testing.T) {
+	src := `package foo
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var c = prometheus.NewCounter(prometheus.CounterOpts{Name: name()})
+`
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "foo.go", src, parser.AllErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	res := Analyze(fs, []*ast.File{file}, Setting{Logger: logger})
+	if res.Skipped != 1 {
+		t.Fatalf("expected 1 skipped metric, got %d", res.Skipped)
+	}
+
+	for _, msg := range []string{`msg="unsupported expression" pos=foo.go:5:60`, `msg="skipped metric constructor: opts could not be resolved"`} {
+		if !strings.Contains(buf.String(), msg) {
+			t.Fatalf("expected log %q, got:\n%s", msg, buf.String())
+		}
+	}
+}
+
+func TestAnalyzeConcurrency(t *testing.T) {
+	fs := token.NewFileSet()
+	var files []*ast.File
+	for i := 0; i < 20; i++ {
+		src := fmt.Sprintf(`package foo
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var c = prometheus.NewCounter(prometheus.CounterOpts{Name: "foo_%d"})
+`, i)
+		file, err := parser.ParseFile(fs, fmt.Sprintf("pkg%02d/foo.go", i), src, parser.AllErrors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+
+	sequential := Analyze(fs, files, Setting{Concurrency: 1})
+	parallel := Analyze(fs, files, Setting{Concurrency: 8})
+	if len(sequential.Metrics) != 20 || len(parallel.Metrics) != 20 || parallel.Files != 20 {
+		t.Fatalf("expected 20 metrics, got %d and %d", len(sequential.Metrics), len(parallel.Metrics))
+	}
+	if !reflect.DeepEqual(sequential.Issues, parallel.Issues) {
+		t.Fatalf("expected the same issues, got %+v and %+v", sequential.Issues, parallel.Issues)
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.go")
+	src := `package broken
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var requests = prometheus.NewCounter(prometheus.CounterOpts{Name: "requests", Help: "Number of requests."})
+
+func handle() {
+	requests.Inc(
+}
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.SyntaxErrors) != 1 || res.SyntaxErrors[0].Pos.Line != 9 {
+		t.Fatalf("unexpected syntax errors %+v", res.SyntaxErrors)
+	}
+	if len(res.Issues) != 1 || res.Issues[0].Metric != "requests" || res.Issues[0].RuleID != RuleCounter {
+		t.Fatalf("expected the parsed declarations to be linted, got %+v", res.Issues)
+	}
+}
+
+func TestUnkeyedOpts(t *testing.T) {
+	src := `package unkeyed
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requests = prometheus.NewCounter(prometheus.CounterOpts{"acme", "http", "requests", "Number of requests.", nil})
+	latency  = prometheus.NewHistogram(prometheus.HistogramOpts{"acme", "http", "latency_seconds", "Latency of requests.", nil, nil})
+)
+`
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "unkeyed.go", src, parser.AllErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := Analyze(fs, []*ast.File{file}, Setting{})
+	var names []string
+	for _, m := range res.Metrics {
+		names = append(names, m.MetricFamily.GetName())
+	}
+	if !reflect.DeepEqual(names, []string{"acme_http_requests", "acme_http_latency_seconds"}) {
+		t.Fatalf("unexpected metrics %v", names)
+	}
+	if len(res.Issues) != 1 || res.Issues[0].RuleID != RuleCounter {
+		t.Fatalf("unexpected issues %+v", res.Issues)
+	}
 }
@@ -0,0 +1,107 @@
+package promlinter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+// Ratchet records the number of issues found in a run, so that later runs
+// only fail when the number of issues increases.
+type Ratchet struct {
+	Total int `json:"total"`
+	// Packages holds the issue count per package directory, if recorded.
+	Packages map[string]int `json:"packages,omitempty"`
+	// Rules holds the issue count per rule ID, if recorded.
+	Rules map[string]int `json:"rules,omitempty"`
+}
+
+// NewRatchet counts issues, optionally per package directory and per rule.
+func NewRatchet(issues []Issue, perPackage, perRule bool) *Ratchet {
+	r := &Ratchet{Total: len(issues)}
+	if perPackage {
+		r.Packages = make(map[string]int)
+		for _, iss := range issues {
+			r.Packages[filepath.Dir(iss.Pos.Filename)]++
+		}
+	}
+	if perRule {
+		r.Rules = make(map[string]int)
+		for _, iss := range issues {
+			r.Rules[iss.RuleID]++
+		}
+	}
+	return r
+}
+
+// ReadRatchet decodes a ratchet previously written by Write.
+func ReadRatchet(r io.Reader) (*Ratchet, error) {
+	ratchet := &Ratchet{}
+	if err := json.NewDecoder(r).Decode(ratchet); err != nil {
+		return nil, err
+	}
+	return ratchet, nil
+}
+
+// Write encodes the ratchet as indented JSON.
+func (r *Ratchet) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Regressions compares the current counts with the recorded ones and
+// describes every count which increased. Per-package and per-rule counts are
+// only compared if they were recorded in r; keys missing from r count as zero
+// issues.
+func (r *Ratchet) Regressions(current *Ratchet) []string {
+	var regressions []string
+	if current.Total > r.Total {
+		regressions = append(regressions, fmt.Sprintf("total issues increased from %d to %d", r.Total, current.Total))
+	}
+
+	regressions = append(regressions, countRegressions("issues in %s increased from %d to %d", r.Packages, current.Packages)...)
+	regressions = append(regressions, countRegressions("issues of rule %s increased from %d to %d", r.Rules, current.Rules)...)
+	return regressions
+}
+
+func countRegressions(format string, recorded, current map[string]int) []string {
+	if recorded == nil {
+		return nil
+	}
+
+	var keys []string
+	for k, count := range current {
+		if count > recorded[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	regressions := make([]string, 0, len(keys))
+	for _, k := range keys {
+		regressions = append(regressions, fmt.Sprintf(format, k, recorded[k], current[k]))
+	}
+	return regressions
+}
+
+// Improved reports whether current has fewer issues than r in any count and no
+// regression, meaning the recorded ratchet can be tightened.
+func (r *Ratchet) Improved(current *Ratchet) bool {
+	if len(r.Regressions(current)) > 0 {
+		return false
+	}
+	if current.Total < r.Total {
+		return true
+	}
+	for _, counts := range [][2]map[string]int{{r.Packages, current.Packages}, {r.Rules, current.Rules}} {
+		for k, count := range counts[0] {
+			if counts[1][k] < count {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package promlinter
+
+import (
+	"go/token"
+	"testing"
+)
+
+func TestRatchet(t *testing.T) {
+	issue := func(file string) Issue {
+		return Issue{Pos: token.Position{Filename: file}}
+	}
+	recorded := NewRatchet([]Issue{issue("a/x.go"), issue("a/y.go"), issue("b/x.go")}, true, false)
+
+	// Moving an issue from one package to another is a regression of b even if
+	// the total is unchanged.
+	current := NewRatchet([]Issue{issue("a/x.go"), issue("b/x.go"), issue("b/y.go")}, true, false)
+	if regressions := recorded.Regressions(current); len(regressions) != 1 || regressions[0] != "issues in b increased from 1 to 2" {
+		t.Fatalf("unexpected regressions %v", regressions)
+	}
+	if recorded.Improved(current) {
+		t.Fatal("expected no improvement")
+	}
+
+	current = NewRatchet([]Issue{issue("a/x.go"), issue("b/x.go")}, true, false)
+	if regressions := recorded.Regressions(current); len(regressions) != 0 {
+		t.Fatalf("unexpected regressions %v", regressions)
+	}
+	if !recorded.Improved(current) {
+		t.Fatal("expected improvement")
+	}
+
+	recorded = NewRatchet([]Issue{{RuleID: RuleHelp}, {RuleID: RuleCounter}}, false, true)
+	current = NewRatchet([]Issue{{RuleID: RuleCounter}, {RuleID: RuleCounter}}, false, true)
+	if regressions := recorded.Regressions(current); len(regressions) != 1 || regressions[0] != "issues of rule PL003 increased from 1 to 2" {
+		t.Fatalf("unexpected regressions %v", regressions)
+	}
+}
@@ -0,0 +1,623 @@
+package promlinter
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ScrapeConfig is the part of a scrape config of Prometheus changing the
+// scraped series: its metric_relabel_configs.
+type ScrapeConfig struct {
+	JobName              string          `yaml:"job_name"`
+	MetricRelabelConfigs []RelabelConfig `yaml:"metric_relabel_configs,omitempty"`
+}
+
+// RelabelConfig is a relabeling rule of Prometheus, with its defaults.
+type RelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels,flow,omitempty"`
+	Separator    string   `yaml:"separator,omitempty"`
+	Regex        string   `yaml:"regex,omitempty"`
+	Modulus      uint64   `yaml:"modulus,omitempty"`
+	TargetLabel  string   `yaml:"target_label,omitempty"`
+	Replacement  string   `yaml:"replacement,omitempty"`
+	Action       string   `yaml:"action,omitempty"`
+
+	regex *regexp.Regexp
+	// matchesAll is true if the regex matches any value, e.g. the default
+	// (.*), so that the rule applies whatever the values of the labels.
+	matchesAll bool
+}
+
+// UnmarshalYAML sets the defaults of Prometheus for the fields not given.
+func (c *RelabelConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	*c = RelabelConfig{Separator: ";", Regex: "(.*)", Replacement: "$1", Action: "replace"}
+	type plain RelabelConfig
+	return unmarshal((*plain)(c))
+}
+
+// relabelActions are the actions of Prometheus, and whether they need a
+// target label.
+var relabelActions = map[string]bool{
+	"replace":   true,
+	"keep":      false,
+	"drop":      false,
+	"keepequal": true,
+	"dropequal": true,
+	"hashmod":   true,
+	"labelmap":  false,
+	"labeldrop": false,
+	"labelkeep": false,
+	"lowercase": true,
+	"uppercase": true,
+}
+
+func (c *RelabelConfig) compile() error {
+	c.Action = strings.ToLower(c.Action)
+	needsTarget, ok := relabelActions[c.Action]
+	if !ok {
+		return fmt.Errorf("unknown relabel action %q", c.Action)
+	}
+	if needsTarget && c.TargetLabel == "" {
+		return fmt.Errorf("relabel action %s requires a target_label", c.Action)
+	}
+	if c.Action == "hashmod" && c.Modulus == 0 {
+		return fmt.Errorf("relabel action hashmod requires a modulus")
+	}
+	re, err := regexp.Compile("^(?s:" + c.Regex + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid regex %q: %w", c.Regex, err)
+	}
+	c.regex = re
+	c.matchesAll = matchesAll(c.Regex)
+	return nil
+}
+
+// matchesAll reports whether the regex expr matches any string, e.g. (.*).
+func matchesAll(expr string) bool {
+	re, err := syntax.Parse(expr, syntax.Perl|syntax.DotNL)
+	if err != nil {
+		return false
+	}
+	re = re.Simplify()
+	for re.Op == syntax.OpCapture {
+		re = re.Sub[0]
+	}
+	return re.Op == syntax.OpStar && re.Sub[0].Op == syntax.OpAnyChar
+}
+
+// LoadScrapeConfigs reads the scrape configs of the Prometheus configuration
+// file at path. Only their job names and metric_relabel_configs are read.
+func LoadScrapeConfigs(path string) ([]ScrapeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		ScrapeConfigs []ScrapeConfig `yaml:"scrape_configs"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for _, sc := range config.ScrapeConfigs {
+		if sc.JobName == "" {
+			return nil, fmt.Errorf("%s: scrape config without a job_name", path)
+		}
+		for i := range sc.MetricRelabelConfigs {
+			if err := sc.MetricRelabelConfigs[i].compile(); err != nil {
+				return nil, fmt.Errorf("%s: job %s: metric_relabel_configs rule %d: %w", path, sc.JobName, i+1, err)
+			}
+		}
+	}
+	return config.ScrapeConfigs, nil
+}
+
+// maxRelabelCombinations is the largest number of combinations of the
+// enumerated label values of a metric simulated; the values of the labels
+// beyond it are assumed unknown.
+const maxRelabelCombinations = 64
+
+// relabelValue is the value of a label during the simulation, which is not
+// known for the labels whose values could not be enumerated.
+type relabelValue struct {
+	value string
+	known bool
+}
+
+// relabelRun is the simulation of the metric_relabel_configs of a job on a
+// series.
+type relabelRun struct {
+	job    string
+	labels map[string]relabelValue
+	// origin maps the labels copied by labelmap to the label of the series
+	// they were copied from.
+	origin map[string]string
+	// changed and uncertain map the labels to the last rule setting or
+	// dropping them, and to the last rule which may have changed them
+	// depending on values not known.
+	changed, uncertain map[string]int
+
+	dropped, mayDrop int
+	mayDropLabels    []string
+}
+
+func newRelabelRun(job string, labels map[string]relabelValue) *relabelRun {
+	return &relabelRun{
+		job:       job,
+		labels:    labels,
+		origin:    make(map[string]string),
+		changed:   make(map[string]int),
+		uncertain: make(map[string]int),
+		dropped:   -1,
+		mayDrop:   -1,
+	}
+}
+
+// get returns the value of a label, the empty string if it is not set.
+func (r *relabelRun) get(name string) relabelValue {
+	if v, ok := r.labels[name]; ok {
+		return v
+	}
+	return relabelValue{known: true}
+}
+
+func (r *relabelRun) set(name string, v relabelValue, rule int) {
+	if v.known && v.value == "" {
+		delete(r.labels, name)
+	} else {
+		r.labels[name] = v
+	}
+	delete(r.origin, name)
+	r.changed[name] = rule
+}
+
+// source returns the values of the source labels of c joined by its
+// separator, and the source labels whose values are not known.
+func (r *relabelRun) source(c *RelabelConfig) (relabelValue, []string) {
+	values := make([]string, len(c.SourceLabels))
+	var unknown []string
+	for i, name := range c.SourceLabels {
+		v := r.get(name)
+		if !v.known {
+			unknown = append(unknown, name)
+		}
+		values[i] = v.value
+	}
+	return relabelValue{value: strings.Join(values, c.Separator), known: len(unknown) == 0}, unknown
+}
+
+// maybeDrop records that rule i may drop the series depending on the values
+// of the labels unknown.
+func (r *relabelRun) maybeDrop(i int, unknown []string) {
+	if r.mayDrop < 0 {
+		r.mayDrop, r.mayDropLabels = i, unknown
+	}
+}
+
+// apply simulates the rule i, returning false if it drops the series.
+func (r *relabelRun) apply(i int, c *RelabelConfig) bool {
+	val, unknown := r.source(c)
+	// The regex matches when the values are known and match it, or when it
+	// matches any value; otherwise whether it matches is not certain.
+	certain, matched := val.known || c.matchesAll, c.matchesAll
+	if val.known {
+		matched = c.regex.MatchString(val.value)
+	}
+
+	switch c.Action {
+	case "keep", "drop":
+		switch {
+		case !certain:
+			r.maybeDrop(i, unknown)
+		case matched == (c.Action == "drop"):
+			r.dropped = i
+			return false
+		}
+
+	case "keepequal", "dropequal":
+		target := r.get(c.TargetLabel)
+		if !target.known {
+			unknown = append(unknown, c.TargetLabel)
+		}
+		switch {
+		case !val.known || !target.known:
+			r.maybeDrop(i, unknown)
+		case (val.value == target.value) == (c.Action == "dropequal"):
+			r.dropped = i
+			return false
+		}
+
+	case "replace":
+		target := c.TargetLabel
+		if !certain || strings.Contains(target, "$") && !val.known {
+			if !strings.Contains(target, "$") {
+				r.uncertain[target] = i
+				if _, ok := r.labels[target]; ok {
+					r.labels[target] = relabelValue{}
+				}
+			}
+			break
+		}
+		if !matched {
+			break
+		}
+		res := relabelValue{value: c.Replacement, known: !strings.Contains(c.Replacement, "$")}
+		if val.known {
+			indexes := c.regex.FindStringSubmatchIndex(val.value)
+			target = string(c.regex.ExpandString(nil, target, val.value, indexes))
+			res = relabelValue{value: string(c.regex.ExpandString(nil, c.Replacement, val.value, indexes)), known: true}
+		}
+		if isLegacyName(target, true) {
+			r.set(target, res, i)
+		}
+
+	case "lowercase", "uppercase":
+		res := relabelValue{}
+		if val.known {
+			res = relabelValue{value: strings.ToLower(val.value), known: true}
+			if c.Action == "uppercase" {
+				res.value = strings.ToUpper(val.value)
+			}
+		}
+		r.set(c.TargetLabel, res, i)
+
+	case "hashmod":
+		res := relabelValue{}
+		if val.known {
+			sum := md5.Sum([]byte(val.value))
+			res = relabelValue{value: fmt.Sprint(binary.BigEndian.Uint64(sum[8:]) % c.Modulus), known: true}
+		}
+		r.set(c.TargetLabel, res, i)
+
+	case "labelmap":
+		for _, name := range sortedLabelNames(r.labels) {
+			if !c.regex.MatchString(name) {
+				continue
+			}
+			v, from := r.labels[name], r.origin[name]
+			if from == "" {
+				from = name
+			}
+			target := c.regex.ReplaceAllString(name, c.Replacement)
+			r.set(target, v, i)
+			r.origin[target] = from
+		}
+
+	case "labeldrop", "labelkeep":
+		for _, name := range sortedLabelNames(r.labels) {
+			if c.regex.MatchString(name) == (c.Action == "labeldrop") {
+				delete(r.labels, name)
+				delete(r.origin, name)
+				r.changed[name] = i
+			}
+		}
+	}
+	return true
+}
+
+func sortedLabelNames(labels map[string]relabelValue) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// relabelFinding is a change of a series by the simulated rules. text is
+// about the series and metricText the same about its metric, which differ
+// for renames, e.g. renamed to foo_bucket and to foo. dropped is true if the
+// rules drop the series.
+type relabelFinding struct {
+	severity         Severity
+	text, metricText string
+	dropped          bool
+}
+
+// relabelSeries describes a series of a metric to simulate.
+type relabelSeries struct {
+	// index is the index of the series in exposedNames.
+	index  int
+	name   string
+	labels []string
+}
+
+// relabelContext holds what the simulation needs to describe its findings.
+type relabelContext struct {
+	metric InventoryMetric
+	// series maps the series names of the inventory to their metric, to
+	// report the renames colliding with them.
+	series map[string]InventoryMetric
+	rules  []RelabelConfig
+}
+
+func (ctx *relabelContext) rule(r *relabelRun, i int) string {
+	return fmt.Sprintf("rule %d (%s) of the metric_relabel_configs of the job %s", i+1, ctx.rules[i].Action, r.job)
+}
+
+// findings compares the series s once relabeled by r with the original.
+func (ctx *relabelContext) findings(s relabelSeries, r *relabelRun, original map[string]relabelValue) []relabelFinding {
+	var findings []relabelFinding
+	add := func(severity Severity, text string) {
+		findings = append(findings, relabelFinding{severity, text, text, false})
+	}
+	if r.dropped >= 0 {
+		text := "is dropped by " + ctx.rule(r, r.dropped)
+		return []relabelFinding{{ruleSeverity(RuleRelabeling), text, text, true}}
+	}
+	if r.mayDrop >= 0 {
+		labels := "the label " + r.mayDropLabels[0]
+		if len(r.mayDropLabels) > 1 {
+			labels = "the labels " + strings.Join(r.mayDropLabels, ", ")
+		}
+		add(SeverityInfo, fmt.Sprintf("may be dropped by %s, depending on the values of %s", ctx.rule(r, r.mayDrop), labels))
+	}
+
+	name, ok := r.labels["__name__"]
+	switch {
+	case !ok:
+		add(ruleSeverity(RuleRelabeling), "loses its name, removed by "+ctx.rule(r, r.changed["__name__"]))
+	case name.known && name.value != s.name:
+		f := relabelFinding{severity: ruleSeverity(RuleRelabeling), text: fmt.Sprintf("is renamed to %s by %s", name.value, ctx.rule(r, r.changed["__name__"]))}
+		if other, ok := ctx.series[name.value]; ok && (other.Name != ctx.metric.Name || other.Position != ctx.metric.Position) {
+			f.severity = SeverityError
+			f.text += fmt.Sprintf(", a series of the %s %s at %s", other.Type, other.Name, other.Position)
+		}
+		f.metricText = f.text
+		if base, ok := relabeledBase(ctx.metric, s.index, name.value); ok {
+			f.metricText = strings.Replace(f.text, name.value, base, 1)
+		}
+		findings = append(findings, f)
+	case !name.known:
+		if i, ok := r.uncertain["__name__"]; ok {
+			add(SeverityInfo, "may be renamed by "+ctx.rule(r, i))
+		} else {
+			add(ruleSeverity(RuleRelabeling), "is renamed, depending on the values of its labels, by "+ctx.rule(r, r.changed["__name__"]))
+		}
+	}
+
+	for _, label := range s.labels {
+		v, ok := r.labels[label]
+		if !ok {
+			renamed := ""
+			for _, target := range sortedLabelNames(r.labels) {
+				if r.origin[target] == label {
+					renamed = target
+					break
+				}
+			}
+			if renamed != "" {
+				add(ruleSeverity(RuleRelabeling), fmt.Sprintf("has the label %s renamed to %s by %s", label, renamed, ctx.rule(r, r.changed[renamed])))
+			} else {
+				add(ruleSeverity(RuleRelabeling), fmt.Sprintf("loses the label %s, dropped by %s", label, ctx.rule(r, r.changed[label])))
+			}
+			continue
+		}
+		i, changed := r.changed[label]
+		switch o := original[label]; {
+		case changed && v.known && (!o.known || o.value != v.value):
+			add(ruleSeverity(RuleRelabeling), fmt.Sprintf("has the label %s set to %q by %s", label, v.value, ctx.rule(r, i)))
+		case changed && !v.known && r.origin[label] == "":
+			add(ruleSeverity(RuleRelabeling), fmt.Sprintf("has the label %s overwritten by %s", label, ctx.rule(r, i)))
+		case !changed:
+			if i, ok := r.uncertain[label]; ok {
+				add(SeverityInfo, fmt.Sprintf("may have the label %s overwritten by %s", label, ctx.rule(r, i)))
+			}
+		}
+	}
+	return findings
+}
+
+// relabeledBase returns the name of the metric whose series index is named
+// name, e.g. foo for foo_bucket, if it exposes its other series the same way
+// as m.
+func relabeledBase(m InventoryMetric, index int, name string) (string, bool) {
+	series := exposedNames(m)[index]
+	suffix := ""
+	switch {
+	case strings.HasPrefix(series, m.Name):
+		suffix = series[len(m.Name):]
+	case strings.HasSuffix(series, "_created"):
+		suffix = "_created"
+	}
+	if !strings.HasSuffix(name, suffix) {
+		return "", false
+	}
+	base := strings.TrimSuffix(name, suffix)
+	if suffix == "_created" {
+		base += strings.TrimPrefix(m.Name, strings.TrimSuffix(m.Name, "_total"))
+	}
+	names := exposedNames(InventoryMetric{Name: base, Type: m.Type})
+	return base, index < len(names) && names[index] == name
+}
+
+// labelCombinations returns the combinations of the enumerated values of the
+// labels of m, at most maxRelabelCombinations.
+func labelCombinations(m InventoryMetric) []map[string]string {
+	combinations := []map[string]string{{}}
+	for _, label := range m.Labels {
+		values := m.LabelValues[label]
+		if len(values) == 0 || len(combinations)*len(values) > maxRelabelCombinations {
+			continue
+		}
+		var next []map[string]string
+		for _, c := range combinations {
+			for _, v := range values {
+				combination := map[string]string{label: v}
+				for k, v := range c {
+					combination[k] = v
+				}
+				next = append(next, combination)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+func formatCombination(labels []string, combination map[string]string) string {
+	var parts []string
+	for _, label := range labels {
+		if v, ok := combination[label]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%q", label, v))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SimulateRelabeling simulates the metric_relabel_configs of each scrape
+// config on the series of the metrics of inv, as when Prometheus scrapes
+// them, and reports the series dropped, renamed or whose labels are dropped
+// or overwritten. The labels of the series take the values enumerated in the
+// inventory, in every combination; the changes depending on the other
+// values, which are not known, are reported with the info severity.
+func SimulateRelabeling(inv *Inventory, configs []ScrapeConfig) []Issue {
+	series := make(map[string]InventoryMetric)
+	for _, m := range inv.Metrics {
+		for _, name := range exposedNames(m) {
+			if _, ok := series[name]; !ok {
+				series[name] = m
+			}
+		}
+	}
+
+	var issues []Issue
+	for _, sc := range configs {
+		if len(sc.MetricRelabelConfigs) == 0 {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, m := range inv.Metrics {
+			if seen[m.Name+"\x00"+m.Position] {
+				continue
+			}
+			seen[m.Name+"\x00"+m.Position] = true
+			ctx := &relabelContext{metric: m, series: series, rules: sc.MetricRelabelConfigs}
+			for _, text := range ctx.simulate(sc.JobName) {
+				issue := inventoryIssue(m, RuleRelabeling)
+				issue.Text, issue.Severity = text.text, text.severity
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues
+}
+
+// simulate returns the findings of the rules on the series of the metric,
+// which are reported once for the metric when all its series have them.
+func (ctx *relabelContext) simulate(job string) []relabelFinding {
+	m := ctx.metric
+	combinations := labelCombinations(m)
+
+	var (
+		perSeries [][]relabelFinding
+		// live is the number of series which are not dropped for every
+		// combination of the label values.
+		live int
+	)
+	for i, name := range exposedNames(m) {
+		s := relabelSeries{index: i, name: name, labels: m.Labels}
+		switch {
+		case m.Type == "histogram" && name == m.Name+"_bucket":
+			s.labels = append(append([]string(nil), m.Labels...), "le")
+		case m.Type == "summary" && name == m.Name:
+			s.labels = append(append([]string(nil), m.Labels...), "quantile")
+		}
+
+		var (
+			keys     []string
+			byText   = make(map[string]relabelFinding)
+			counts   = make(map[string]int)
+			examples = make(map[string]map[string]string)
+			// kept is the number of combinations not dropped, to which
+			// the other findings are compared.
+			kept = len(combinations)
+		)
+		for _, combination := range combinations {
+			labels := map[string]relabelValue{
+				"__name__": {value: name, known: true},
+				"job":      {value: job, known: true},
+				"instance": {},
+			}
+			for _, label := range s.labels {
+				v, ok := combination[label]
+				labels[label] = relabelValue{value: v, known: ok}
+			}
+			original := make(map[string]relabelValue, len(labels))
+			for k, v := range labels {
+				original[k] = v
+			}
+
+			r := newRelabelRun(job, labels)
+			for j := range ctx.rules {
+				if !r.apply(j, &ctx.rules[j]) {
+					break
+				}
+			}
+			for _, f := range ctx.findings(s, r, original) {
+				if f.dropped {
+					kept--
+				}
+				if _, ok := byText[f.text]; !ok {
+					keys = append(keys, f.text)
+					byText[f.text], examples[f.text] = f, combination
+				}
+				counts[f.text]++
+			}
+		}
+
+		var findings []relabelFinding
+		for _, key := range keys {
+			f, total := byText[key], kept
+			if f.dropped {
+				total = len(combinations)
+			}
+			if n := counts[key]; n < total {
+				suffix := fmt.Sprintf(" for %d of the %d combinations of the enumerated label values, e.g. %s", n, total, formatCombination(m.Labels, examples[key]))
+				f.text += suffix
+				f.metricText += suffix
+			}
+			findings = append(findings, f)
+		}
+		perSeries = append(perSeries, findings)
+		if kept > 0 {
+			live++
+		}
+	}
+
+	// The findings of every series are reported for the metric, the others
+	// for their series. The series always dropped are not compared, e.g. the
+	// labels of a histogram are dropped if they are from all the series but
+	// its buckets, which are dropped.
+	all := make(map[string]int)
+	for _, findings := range perSeries {
+		for _, f := range findings {
+			all[f.metricText]++
+		}
+	}
+	var (
+		result   []relabelFinding
+		reported = make(map[string]bool)
+	)
+	names := exposedNames(m)
+	for i, findings := range perSeries {
+		for _, f := range findings {
+			n := all[f.metricText]
+			switch {
+			case n < len(perSeries) && (f.dropped || n < live):
+				result = append(result, relabelFinding{severity: f.severity, text: "series " + names[i] + " " + f.text})
+			case !reported[f.metricText]:
+				reported[f.metricText] = true
+				result = append(result, relabelFinding{severity: f.severity, text: "metric " + f.metricText})
+			}
+		}
+	}
+	return result
+}
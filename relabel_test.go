@@ -0,0 +1,54 @@
+package promlinter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSimulateRelabeling(t *testing.T) {
+	dir := filepath.Join("testdata", "relabel")
+	configs, err := LoadScrapeConfigs(filepath.Join(dir, "prometheus.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(dir, "inventory.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	inv, err := ReadInventory(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range SimulateRelabeling(inv, configs) {
+		got = append(got, iss.Pos.String()+" "+string(iss.Severity)+" "+iss.Text)
+	}
+	expected := []string{
+		`server.go:10:2 warning metric has the label method renamed to http_method by rule 7 (labelmap) of the metric_relabel_configs of the job api`,
+		`server.go:10:2 warning series http_requests_total is dropped by rule 3 (drop) of the metric_relabel_configs of the job api for 2 of the 4 combinations of the enumerated label values, e.g. code="500", method="GET"`,
+		`server.go:15:2 warning metric loses the label path, dropped by rule 4 (labeldrop) of the metric_relabel_configs of the job api`,
+		`server.go:15:2 warning series request_duration_seconds_bucket is dropped by rule 1 (drop) of the metric_relabel_configs of the job api`,
+		`jobs.go:8:2 warning metric is dropped by rule 2 (drop) of the metric_relabel_configs of the job api`,
+		`jobs.go:12:2 info metric may be dropped by rule 6 (drop) of the metric_relabel_configs of the job api, depending on the values of the label queue`,
+		`jobs.go:18:2 error metric is renamed to jobs_processed_total by rule 5 (replace) of the metric_relabel_configs of the job api, a series of the counter jobs_processed_total at jobs.go:12:2`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestLoadScrapeConfigsError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prometheus.yml")
+	config := "scrape_configs:\n  - job_name: api\n    metric_relabel_configs:\n      - source_labels: [__name__]\n        action: hashmod\n        target_label: shard\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadScrapeConfigs(path); err == nil || !strings.Contains(err.Error(), "job api: metric_relabel_configs rule 1: relabel action hashmod requires a modulus") {
+		t.Errorf("expected the invalid rule to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package promlinter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RunReport is the batch of findings of an analysis sent to the reporters at
+// the end of a run, along with its metadata.
+type RunReport struct {
+	Start time.Time `json:"start"`
+	// Duration is the duration of the run in seconds.
+	Duration float64  `json:"duration"`
+	Paths    []string `json:"paths"`
+	// Labels describe the run, e.g. repo=acme/api or commit=abc123.
+	Labels  map[string]string `json:"labels,omitempty"`
+	Summary *Summary          `json:"summary,omitempty"`
+	Issues  []Issue           `json:"issues"`
+}
+
+// Reporter sends the findings of a run to an external sink, such as a chat
+// channel, an issue tracker or an internal service.
+type Reporter interface {
+	Report(run *RunReport) error
+}
+
+// NewReporter returns the built-in reporter of the kind sending to target:
+// "webhook" and "slack" post to the URL target, "file" appends to the file
+// target.
+func NewReporter(kind, target string) (Reporter, error) {
+	switch kind {
+	case "webhook":
+		return &WebhookReporter{URL: target}, nil
+	case "slack":
+		return &SlackReporter{URL: target}, nil
+	case "file":
+		return &FileReporter{Path: target}, nil
+	}
+	return nil, fmt.Errorf("unknown reporter %q, expected webhook, slack or file", kind)
+}
+
+// WebhookReporter posts the run as JSON to URL.
+type WebhookReporter struct {
+	URL     string
+	Headers map[string]string
+	// Client defaults to a client with a timeout of 30 seconds.
+	Client *http.Client
+}
+
+// Report posts the run to the webhook.
+func (r *WebhookReporter) Report(run *RunReport) error {
+	body, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	return postJSON(r.Client, r.URL, r.Headers, body)
+}
+
+// SlackReporter posts a message listing the issues of the run to the Slack
+// incoming webhook at URL. At most MaxIssues issues are listed, 20 if it is
+// zero, followed by the number of the others.
+type SlackReporter struct {
+	URL       string
+	MaxIssues int
+	Client    *http.Client
+}
+
+// Report posts the message of the run to Slack.
+func (r *SlackReporter) Report(run *RunReport) error {
+	body, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{slackText(run, r.MaxIssues)})
+	if err != nil {
+		return err
+	}
+	return postJSON(r.Client, r.URL, nil, body)
+}
+
+// slackText formats the message of a run, e.g.
+//
+//	promlinter found 2 issues (repo=acme/api)
+//	`main.go:10:2` PL001 requests_total: no help text
+func slackText(run *RunReport, max int) string {
+	if max == 0 {
+		max = 20
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "promlinter found %d issues", len(run.Issues))
+	if len(run.Labels) > 0 {
+		names := make([]string, 0, len(run.Labels))
+		for name := range run.Labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			names[i] = name + "=" + run.Labels[name]
+		}
+		fmt.Fprintf(&b, " (%s)", strings.Join(names, ", "))
+	}
+	for i, iss := range run.Issues {
+		if i == max {
+			fmt.Fprintf(&b, "\nand %d more", len(run.Issues)-max)
+			break
+		}
+		fmt.Fprintf(&b, "\n`%s` %s %s: %s", iss.Pos, iss.RuleID, iss.Metric, iss.Text)
+	}
+	return b.String()
+}
+
+func postJSON(client *http.Client, url string, headers map[string]string, body []byte) error {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s: %s", url, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// FileReporter appends the run to the file at Path as a line of JSON, so
+// that the file keeps the history of the runs.
+type FileReporter struct {
+	Path string
+}
+
+// Report appends the run to the file.
+func (r *FileReporter) Report(run *RunReport) error {
+	f, err := os.OpenFile(r.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(run); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
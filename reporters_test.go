@@ -0,0 +1,105 @@
+package promlinter
+
+import (
+	"encoding/json"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testRunReport() *RunReport {
+	return &RunReport{
+		Paths:  []string{"."},
+		Labels: map[string]string{"repo": "acme/api", "commit": "abc123"},
+		Issues: []Issue{
+			{Pos: token.Position{Filename: "main.go", Line: 10, Column: 2}, Metric: "requests_total", RuleID: RuleHelp, Text: "no help text"},
+			{Pos: token.Position{Filename: "main.go", Line: 20, Column: 2}, Metric: "errors", RuleID: RuleCounter, Text: "counter metrics should have \"_total\" suffix"},
+		},
+	}
+}
+
+func TestWebhookReporter(t *testing.T) {
+	var (
+		header string
+		body   []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Authorization")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	r := &WebhookReporter{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}
+	if err := r.Report(testRunReport()); err != nil {
+		t.Fatal(err)
+	}
+	if header != "Bearer token" {
+		t.Errorf("expected the Authorization header to be sent, got %q", header)
+	}
+	var got RunReport
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Issues) != 2 || got.Labels["repo"] != "acme/api" {
+		t.Errorf("expected the issues and labels of the run, got %s", body)
+	}
+}
+
+func TestWebhookReporterError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	err := (&WebhookReporter{URL: server.URL}).Report(testRunReport())
+	if err == nil || !strings.Contains(err.Error(), "401 Unauthorized: invalid token") {
+		t.Fatalf("expected the status and body of the response, got %v", err)
+	}
+}
+
+func TestSlackReporter(t *testing.T) {
+	var msg struct{ Text string }
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&msg)
+	}))
+	defer server.Close()
+
+	if err := (&SlackReporter{URL: server.URL, MaxIssues: 1}).Report(testRunReport()); err != nil {
+		t.Fatal(err)
+	}
+	expected := "promlinter found 2 issues (commit=abc123, repo=acme/api)\n" +
+		"`main.go:10:2` PL001 requests_total: no help text\n" +
+		"and 1 more"
+	if msg.Text != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, msg.Text)
+	}
+}
+
+func TestFileReporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs.jsonl")
+	r, err := NewReporter("file", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := r.Report(testRunReport()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 {
+		t.Errorf("expected a line per run, got:\n%s", data)
+	}
+
+	if _, err := NewReporter("jira", "https://jira.example.com"); err == nil {
+		t.Error("expected an unknown reporter to fail")
+	}
+}
@@ -0,0 +1,650 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the severity of an issue.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+var severityRanks = map[Severity]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// AtLeast reports whether s is as severe as other or more.
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRanks[s] >= severityRanks[other]
+}
+
+// Rule describes a check performed by promlinter. Rule IDs are stable and are
+// never reused for a different check.
+type Rule struct {
+	ID        string
+	Name      string
+	Severity  Severity
+	Summary   string
+	Rationale string
+	Example   string
+	Fix       string
+	// OptIn rules are only reported when enabled, see Setting.EnabledRules.
+	OptIn bool
+}
+
+// Rule IDs of the checks performed by promlinter. The first ones map to the
+// promlint validation of the same name.
+const (
+	RuleHelp                     = "PL001"
+	RuleMetricUnits              = "PL002"
+	RuleCounter                  = "PL003"
+	RuleHistogramSummaryReserved = "PL004"
+	RuleMetricTypeInName         = "PL005"
+	RuleReservedChars            = "PL006"
+	RuleCamelCase                = "PL007"
+	RuleUnitAbbreviations        = "PL008"
+	RuleConstructorArgs          = "PL009"
+	RuleUnsupportedExpr          = "PL010"
+	RuleCrossModuleDuplicate     = "PL011"
+	RuleConflictingDefinitions   = "PL012"
+	RuleLoopDynamicName          = "PL013"
+	RuleDynamicName              = "PL014"
+	RuleDeadMetric               = "PL015"
+	RuleTestOnlyMetric           = "PL016"
+	RuleNegativeCounterAdd       = "PL017"
+	RuleSuspiciousObservation    = "PL018"
+	RuleUnitMismatch             = "PL019"
+	RuleTimerMisuse              = "PL020"
+	RuleConstLabelCandidate      = "PL021"
+	RuleSeriesBudget             = "PL022"
+	RuleHelpRestatesName         = "PL023"
+	RuleHelpUnit                 = "PL024"
+	RuleHelpSpelling             = "PL025"
+	RuleWhitespace               = "PL026"
+	RuleReservedConstLabel       = "PL027"
+	RuleConstLabelNotAllowed     = "PL028"
+	RuleUndeclaredLabelValue     = "PL029"
+	RuleMisplacedMetric          = "PL030"
+	RuleInvalidName              = "PL031"
+	RuleEscapingCollision        = "PL032"
+	RulePromlintProblem          = "PL033"
+	RuleEcosystemName            = "PL034"
+	RuleTestDefinitionDrift      = "PL035"
+	RuleNearDuplicate            = "PL036"
+	RuleInconsistentPrefix       = "PL037"
+	RuleInfoMetric               = "PL038"
+	RuleSeriesCollision          = "PL039"
+	RuleImplicitSuffix           = "PL040"
+	RuleMixedUnits               = "PL041"
+	RuleReservedPrefix           = "PL042"
+	RuleCrossRepoCollision       = "PL043"
+	RuleNamespaceOwnership       = "PL044"
+	RuleMetadataConflict         = "PL045"
+	RuleExemplarType             = "PL046"
+	RuleExemplarLabels           = "PL047"
+	RuleCreatedTimestamp         = "PL048"
+	RuleRelabeling               = "PL049"
+	RuleOTelCollision            = "PL050"
+)
+
+// Rules lists every check performed by promlinter, ordered by ID.
+var Rules = []Rule{
+	{
+		ID:        RuleHelp,
+		Name:      "Help",
+		Severity:  SeverityWarning,
+		Summary:   "Metrics should have help text.",
+		Rationale: "Help text is exposed along with the metric and is the first thing users see in tools like Grafana. Metrics without help are hard to interpret.",
+		Example:   `prometheus.CounterOpts{Name: "http_requests_total"}`,
+		Fix:       `Set the Help field: prometheus.CounterOpts{Name: "http_requests_total", Help: "Total number of HTTP requests."}`,
+	},
+	{
+		ID:        RuleMetricUnits,
+		Name:      "MetricUnits",
+		Severity:  SeverityWarning,
+		Summary:   "Metrics should use base units.",
+		Rationale: "Prometheus conventions use base units (seconds, bytes, ...) so that metrics can be combined without conversions.",
+		Example:   `prometheus.HistogramOpts{Name: "request_duration_milliseconds"}`,
+		Fix:       `Rename the metric to the base unit, e.g. "request_duration_seconds", and convert the observed values.`,
+	},
+	{
+		ID:        RuleCounter,
+		Name:      "Counter",
+		Severity:  SeverityError,
+		Summary:   `Counters should have the "_total" suffix, and only counters should have it.`,
+		Rationale: `The "_total" suffix tells users and tools that rate() can be applied to the metric.`,
+		Example:   `prometheus.CounterOpts{Name: "http_requests"}`,
+		Fix:       `Rename the counter to "http_requests_total", or drop the suffix from non-counter metrics.`,
+	},
+	{
+		ID:        RuleHistogramSummaryReserved,
+		Name:      "HistogramSummaryReserved",
+		Severity:  SeverityError,
+		Summary:   `Only histograms and summaries should use the "_bucket", "_count" and "_sum" suffixes and the "le" and "quantile" labels.`,
+		Rationale: "These suffixes and labels are generated for histograms and summaries; using them elsewhere produces confusing or colliding series.",
+		Example:   `prometheus.GaugeOpts{Name: "queue_count"}`,
+		Fix:       `Rename the metric or label, e.g. "queue_length".`,
+	},
+	{
+		ID:        RuleMetricTypeInName,
+		Name:      "MetricTypeInName",
+		Severity:  SeverityWarning,
+		Summary:   "Metric names should not include the metric type.",
+		Rationale: "The type is already part of the metric metadata; repeating it in the name adds noise.",
+		Example:   `prometheus.GaugeOpts{Name: "temperature_gauge"}`,
+		Fix:       `Remove the type from the name, e.g. "temperature_celsius".`,
+	},
+	{
+		ID:        RuleReservedChars,
+		Name:      "ReservedChars",
+		Severity:  SeverityError,
+		Summary:   "Metric names should not contain ':'.",
+		Rationale: "Colons are reserved for recording rules.",
+		Example:   `prometheus.CounterOpts{Name: "job:http_requests_total"}`,
+		Fix:       `Use underscores instead, e.g. "job_http_requests_total".`,
+	},
+	{
+		ID:        RuleCamelCase,
+		Name:      "CamelCase",
+		Severity:  SeverityError,
+		Summary:   "Metric and label names should be written in snake_case.",
+		Rationale: "Prometheus conventions use snake_case names.",
+		Example:   `prometheus.CounterOpts{Name: "httpRequestsTotal"}`,
+		Fix:       `Rename to snake_case, e.g. "http_requests_total".`,
+	},
+	{
+		ID:        RuleUnitAbbreviations,
+		Name:      "UnitAbbreviations",
+		Severity:  SeverityWarning,
+		Summary:   "Metric names should not contain abbreviated units.",
+		Rationale: "Spelled out units are unambiguous.",
+		Example:   `prometheus.HistogramOpts{Name: "request_duration_s"}`,
+		Fix:       `Spell out the unit, e.g. "request_duration_seconds".`,
+	},
+	{
+		ID:        RuleConstructorArgs,
+		Name:      "ConstructorArgs",
+		Severity:  SeverityError,
+		Summary:   "Metric constructors should be called with the expected number of arguments (strict mode only).",
+		Rationale: "promlinter cannot discover a metric if the constructor arguments are missing.",
+		Example:   `prometheus.NewDesc("foo_total", "Foo.")`,
+		Fix:       "Pass all the arguments of the constructor.",
+	},
+	{
+		ID:        RuleUnsupportedExpr,
+		Name:      "UnsupportedExpr",
+		Severity:  SeverityInfo,
+		Summary:   "The metric name, help or desc could not be resolved statically (strict mode only).",
+		Rationale: "promlinter only lints metrics whose name can be resolved from the source code, so these metrics are not checked.",
+		Example:   `prometheus.CounterOpts{Name: getName()}`,
+		Fix:       "Use string literals or constants for the metric name and help.",
+	},
+	{
+		ID:        RuleCrossModuleDuplicate,
+		Name:      "CrossModuleDuplicate",
+		Severity:  SeverityWarning,
+		Summary:   "A metric name should be defined in a single module of a go.work workspace (workspace mode only).",
+		Rationale: "Modules of a workspace are usually deployed together; a name defined in several of them collides when they are linked into the same binary, and the definitions drift apart over time.",
+		Example:   `prometheus.CounterOpts{Name: "jobs_processed_total"} in both example.com/api and example.com/worker`,
+		Fix:       "Define the metric in a shared module, or prefix each definition with the name of its component.",
+	},
+	{
+		ID:        RuleConflictingDefinitions,
+		Name:      "ConflictingDefinitions",
+		Severity:  SeverityWarning,
+		Summary:   "A metric name or help should not depend on the code path reaching the constructor.",
+		Rationale: "When a variable is assigned different values on different paths, the exposed metric changes with the path taken, and promlinter cannot tell which one is linted.",
+		Example:   `name := "queue_length"; if verbose { name = "queue_size" }; prometheus.NewGauge(prometheus.GaugeOpts{Name: name})`,
+		Fix:       "Assign the variable once, or create one metric per name.",
+	},
+	{
+		ID:        RuleLoopDynamicName,
+		Name:      "LoopDynamicName",
+		Severity:  SeverityWarning,
+		Summary:   "Metric names should not be built from the variable of a loop.",
+		Rationale: "A constructor called in a loop with a name depending on the loop variable creates one metric family per iteration. Their number grows with the input, the families cannot be aggregated with each other, and promlinter cannot lint their names.",
+		Example:   `for _, q := range queues { prometheus.NewGauge(prometheus.GaugeOpts{Name: q + "_length"}) }`,
+		Fix:       `Create a single metric with a label instead, e.g. prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "queue_length"}, []string{"queue"}).`,
+	},
+	{
+		ID:        RuleDynamicName,
+		Name:      "DynamicName",
+		Severity:  SeverityInfo,
+		Summary:   "The metric name is only partly resolved statically; the issue carries the resolved prefix (warning in strict mode).",
+		Rationale: "Names computed at runtime cannot be linted, and make the metrics of a service hard to discover. The resolved prefix, e.g. the namespace, tells which metrics are affected.",
+		Example:   `prometheus.CounterOpts{Namespace: "acme", Name: kind + "_total"}`,
+		Fix:       "Use constants for every part of the name, and a label for the part which varies.",
+	},
+	{
+		ID:        RuleDeadMetric,
+		Name:      "DeadMetric",
+		Severity:  SeverityWarning,
+		Summary:   "Registered metrics should be updated somewhere in the analyzed packages.",
+		Rationale: "A metric which is registered but whose variable or field is never referenced again is exported with a constant value. It is usually a leftover which still costs series and confuses dashboards.",
+		Example:   `var requests = promauto.NewCounter(prometheus.CounterOpts{Name: "requests_total"}) // requests is never used`,
+		Fix:       "Update the metric, e.g. requests.Inc(), or remove it.",
+	},
+	{
+		ID:        RuleTestOnlyMetric,
+		Name:      "TestOnlyMetric",
+		Severity:  SeverityWarning,
+		Summary:   "Metrics should be updated by production code, not only by tests (requires --tests).",
+		Rationale: "A metric whose only Inc or Observe calls are in _test.go files is exported with a constant value in production, usually because the call was lost in a refactoring while the tests kept exercising it.",
+		Example:   `requests.Inc() only called in handler_test.go`,
+		Fix:       "Update the metric where the instrumented event happens, or remove it.",
+	},
+	{
+		ID:        RuleNegativeCounterAdd,
+		Name:      "NegativeCounterAdd",
+		Severity:  SeverityError,
+		Summary:   "Counters should only be increased; Add with a negative value panics.",
+		Rationale: "Counter.Add panics when its argument is negative. Adding a negated value, e.g. Add(-n), usually means that the metric tracks a value which goes down and should be a gauge (warning).",
+		Example:   `requests.Add(-1)`,
+		Fix:       "Only add positive values, or turn the metric into a gauge and call Sub or Dec.",
+	},
+	{
+		ID:        RuleSuspiciousObservation,
+		Name:      "SuspiciousObservation",
+		Severity:  SeverityWarning,
+		Summary:   "Histograms and summaries should not observe negative constants, or constants outside of their buckets.",
+		Rationale: "Durations and sizes are never negative. When every constant observed by a histogram is above its largest bucket, or far below its smallest one, the values and the buckets are most likely in different units, e.g. milliseconds observed in buckets meant for seconds.",
+		Example:   `prometheus.NewHistogram(prometheus.HistogramOpts{Name: "request_duration_seconds"}).Observe(250)`,
+		Fix:       "Convert the observed values to the unit of the buckets, or fix the buckets.",
+	},
+	{
+		ID:        RuleUnitMismatch,
+		Name:      "UnitMismatch",
+		Severity:  SeverityError,
+		Summary:   "Durations should be recorded in the unit declared by the metric name.",
+		Rationale: "Observing time.Since(start).Milliseconds() in a _seconds histogram records values a thousand times too large, which silently breaks the buckets, the dashboards and the alerts.",
+		Example:   `requestDurationSeconds.Observe(float64(time.Since(start).Milliseconds()))`,
+		Fix:       "Convert the duration to the unit of the name, e.g. with time.Since(start).Seconds(), or rename the metric.",
+	},
+	{
+		ID:        RuleTimerMisuse,
+		Name:      "TimerMisuse",
+		Severity:  SeverityWarning,
+		Summary:   "Timers should be stopped when the operation ends, and observe metrics named and bucketed in seconds.",
+		Rationale: "prometheus.NewTimer measures the time until ObserveDuration is called, and observes it in seconds. A discarded timer observes nothing, a timer stopped at once observes zero, and a metric named like a counter or with buckets in milliseconds misrepresents the durations.",
+		Example:   `prometheus.NewTimer(requestsTotal).ObserveDuration()`,
+		Fix:       "Use defer prometheus.NewTimer(requestDurationSeconds).ObserveDuration() on a histogram or summary with a _seconds suffix.",
+	},
+	{
+		ID:        RuleConstLabelCandidate,
+		Name:      "ConstLabelCandidate",
+		Severity:  SeverityInfo,
+		Summary:   "Labels of Vecs should not have the same value at every call site.",
+		Rationale: "A label which always has the same value does not partition the series. It makes queries longer, and invites passing other values later without considering the cardinality.",
+		Example:   `requests.WithLabelValues("api", code).Inc() where "api" is the only service`,
+		Fix:       "Move the label to the ConstLabels of the Opts, or drop it.",
+	},
+	{
+		ID:        RuleSeriesBudget,
+		Name:      "SeriesBudget",
+		Severity:  SeverityWarning,
+		Summary:   "Metric families should not exceed the series budget (requires --series-budget).",
+		Rationale: "Each combination of label values, and each bucket of a histogram, is a series stored by Prometheus. The number of series of a family is estimated from the label values enumerated at its call sites; a family above the budget is expensive to scrape, store and query.",
+		Example:   `a histogram with 11 buckets and the labels method (5 values), path (40 values) and code (8 values): 22400 series`,
+		Fix:       "Drop labels, merge their values into fewer classes, or reduce the buckets.",
+	},
+	{
+		ID:        RuleHelpRestatesName,
+		Name:      "HelpRestatesName",
+		Severity:  SeverityWarning,
+		Summary:   "Help text should describe the metric, not restate its name (opt-in).",
+		Rationale: "Help like \"foo_total counts foo total\" passes the Help rule but tells nothing the name does not: what is counted, when it is updated, what the labels mean.",
+		Example:   `prometheus.CounterOpts{Name: "jobs_processed_total", Help: "Total number of processed jobs."}`,
+		Fix:       `Describe what the metric measures, e.g. "Jobs taken from the queue whose handler returned, whatever the outcome."`,
+		OptIn:     true,
+	},
+	{
+		ID:        RuleHelpUnit,
+		Name:      "HelpUnit",
+		Severity:  SeverityWarning,
+		Summary:   "Help text should state the unit of metrics with a unit suffix (opt-in).",
+		Rationale: "Dashboards are often built from the help text shown in tooltips. Stating the unit next to what is measured avoids panels which assume milliseconds for a _seconds metric, or bits for a _bytes one.",
+		Example:   `prometheus.HistogramOpts{Name: "request_duration_seconds", Help: "Duration of the requests."}`,
+		Fix:       `Mention the unit, e.g. "Duration of the requests in seconds, from the first byte read to the last byte written."`,
+		OptIn:     true,
+	},
+	{
+		ID:        RuleHelpSpelling,
+		Name:      "HelpSpelling",
+		Severity:  SeverityInfo,
+		Summary:   "Help text should be spelled correctly (requires --spell-dictionary).",
+		Rationale: "Help text is exposed with every scrape and shown in Grafana tooltips; a typo stays visible as long as the metric exists, since fixing it later changes the metadata of a stable metric.",
+		Example:   `prometheus.CounterOpts{Name: "messages_received_total", Help: "Messages recieved from the broker."}`,
+		Fix:       "Fix the typo, or add the word to the custom word list of the repository.",
+	},
+	{
+		ID:        RuleWhitespace,
+		Name:      "Whitespace",
+		Severity:  SeverityWarning,
+		Summary:   "Names, help and labels should not have leading or trailing whitespace, newlines or control characters.",
+		Rationale: "These characters survive the concatenation of the namespace, subsystem and name, and produce invalid names or exposition output which is confusing to read, e.g. a help text spanning several lines.",
+		Example:   `prometheus.CounterOpts{Namespace: "acme ", Name: "requests_total", Help: "Requests.\n"}`,
+		Fix:       "Trim the strings, and write the help text on a single line.",
+	},
+	{
+		ID:        RuleReservedConstLabel,
+		Name:      "ReservedConstLabel",
+		Severity:  SeverityWarning,
+		Summary:   `ConstLabels should not use the target labels "job" and "instance", or the configured reserved labels.`,
+		Rationale: "Prometheus attaches job and instance to every scraped series. A ConstLabel with the same name is renamed to exported_job, or, with honor_labels, overrides the identity of the target, so that the series seem to come from another job.",
+		Example:   `prometheus.CounterOpts{Name: "requests_total", ConstLabels: prometheus.Labels{"job": "api"}}`,
+		Fix:       `Use another name, e.g. "component", or let the scrape configuration set the label.`,
+	},
+	{
+		ID:        RuleConstLabelNotAllowed,
+		Name:      "ConstLabelNotAllowed",
+		Severity:  SeverityWarning,
+		Summary:   "ConstLabels keys should be among the allowed ones (requires --allowed-const-label).",
+		Rationale: "Static labels are joined across services in queries and dashboards. When each team picks its own names, e.g. svc, service and component for the same thing, the joins break.",
+		Example:   `prometheus.CounterOpts{Name: "requests_total", ConstLabels: prometheus.Labels{"svc": "api"}} when only component and version are allowed`,
+		Fix:       "Rename the key to an allowed one, or extend the list of the organization.",
+	},
+	{
+		ID:        RuleUndeclaredLabelValue,
+		Name:      "UndeclaredLabelValue",
+		Severity:  SeverityError,
+		Summary:   "Label values should be among those declared by the promlinter:label-values directive of the Vec.",
+		Rationale: "A typo in a label value, e.g. \"eror\" for \"error\", creates a series which no query or alert selects, so the events it counts are silently missed.",
+		Example:   `// promlinter:label-values status=ok,error,timeout above the Vec, and requests.WithLabelValues("eror").Inc()`,
+		Fix:       "Fix the value, or add it to the directive.",
+	},
+	{
+		ID:        RuleMisplacedMetric,
+		Name:      "MisplacedMetric",
+		Severity:  SeverityWarning,
+		Summary:   "Metric names should start with a namespace and subsystem of the hierarchy policy (requires --hierarchy).",
+		Rationale: "A shared hierarchy of namespaces and subsystems tells who owns a metric from its name, and keeps the metrics of a component together in autocompletion and dashboards.",
+		Example:   `prometheus.CounterOpts{Namespace: "acme", Subsystem: "cache", Name: "hits_total"} when acme only has the subsystems http and db`,
+		Fix:       "Move the metric to one of the expected prefixes, or add its subsystem to the hierarchy.",
+	},
+	{
+		ID:        RuleInvalidName,
+		Name:      "InvalidName",
+		Severity:  SeverityError,
+		Summary:   "Metric and label names should be valid in the name validation scheme (legacy by default, or UTF-8 with --name-validation=utf8).",
+		Rationale: "client_golang panics when registering a metric whose name is invalid in its validation scheme. The legacy scheme only allows letters, digits, underscores and colons; Prometheus 3.x accepts any UTF-8 name, but older servers and clients do not.",
+		Example:   `prometheus.CounterOpts{Name: "http.requests_total"} in the legacy scheme`,
+		Fix:       "Use the legacy character set, or opt in to UTF-8 names if every consumer of the metrics supports them.",
+	},
+	{
+		ID:        RuleEscapingCollision,
+		Name:      "EscapingCollision",
+		Severity:  SeverityWarning,
+		Summary:   "UTF-8 metric names should not collide with other names once escaped (UTF-8 validation only).",
+		Rationale: "Scrapers and queriers without UTF-8 support receive the names escaped, e.g. http.requests_total as http_requests_total with the underscores scheme. If another metric already has the escaped name, their series are mixed up.",
+		Example:   `prometheus.CounterOpts{Name: "http.requests_total"} and prometheus.CounterOpts{Name: "http_requests_total"}`,
+		Fix:       "Rename one of the metrics, or use an escaping scheme which keeps them apart, such as values.",
+	},
+	{
+		ID:        RulePromlintProblem,
+		Name:      "PromlintProblem",
+		Severity:  SeverityWarning,
+		Summary:   "Problems of the promlint validations unknown to promlinter, e.g. those of a newer client_golang.",
+		Rationale: "promlinter maps the problems of promlint to the rule of their validation from their text; problems whose text is not known are reported under this rule rather than dropped.",
+		Example:   "A problem of a validation added to promlint after this version of promlinter.",
+		Fix:       "Follow the text of the problem.",
+	},
+	{
+		ID:        RuleEcosystemName,
+		Name:      "EcosystemName",
+		Severity:  SeverityWarning,
+		Summary:   "Metrics should not use the names and prefixes of the metrics registered by the libraries of the ecosystem (profiles only).",
+		Rationale: "Libraries such as controller-runtime or go-grpc-prometheus register metrics of their own; a metric with the same name fails to register, and one with the same prefix is mistaken for theirs in dashboards.",
+		Example:   `prometheus.CounterOpts{Name: "workqueue_adds_total"} with --profile=kubernetes-operator`,
+		Fix:       `Prefix the metric with the namespace of the service, e.g. "acme_operator_queue_adds_total".`,
+	},
+	{
+		ID:        RuleTestDefinitionDrift,
+		Name:      "TestDefinitionDrift",
+		Severity:  SeverityWarning,
+		Summary:   "Metrics redeclared in tests should have the type, help and labels of their production definition (requires --tests).",
+		Rationale: "Tests comparing the output of a registry with testutil often redeclare the metric under test. When the fixture drifts from the production definition, e.g. after a label was added, the test checks a metric which no longer exists and masks the regression.",
+		Example:   `prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total"}, []string{"code"}) in handler_test.go while handler.go declares the labels code and method`,
+		Fix:       "Use the production metric in the test, or update the fixture to match its definition.",
+	},
+	{
+		ID:        RuleNearDuplicate,
+		Name:      "NearDuplicate",
+		Severity:  SeverityWarning,
+		OptIn:     true,
+		Summary:   "Metric names should not only differ by a namespace, a subsystem or a trivial suffix (opt-in).",
+		Rationale: "Two metrics which only differ by a prefix or a suffix such as _total, e.g. http_requests_total and api_http_requests_total, usually count the same thing twice: one of them was copied or renamed without removing the other, and dashboards split between them.",
+		Example:   `prometheus.CounterOpts{Name: "api_http_requests_total"} next to prometheus.CounterOpts{Name: "http_requests_total"}`,
+		Fix:       "Keep one of the metrics, or rename them so that their names tell what distinguishes them. Tune the similarity rules with --similarity-rules.",
+	},
+	{
+		ID:        RuleInconsistentPrefix,
+		Name:      "InconsistentPrefix",
+		Severity:  SeverityInfo,
+		OptIn:     true,
+		Summary:   "Metrics defined in the same file, or Vecs of a package with the same labels, should share the prefix of most of them (opt-in).",
+		Rationale: "Related metrics such as foo_requests_total, foo_request_duration_seconds and foo_requests_in_flight are found together by their prefix; an outlier such as requests_in_flight is missed by the queries and dashboards of the group.",
+		Example:   `prometheus.GaugeOpts{Name: "requests_in_flight"} next to prometheus.CounterOpts{Name: "foo_requests_total"} and prometheus.HistogramOpts{Name: "foo_request_duration_seconds"}`,
+		Fix:       `Use the prefix of the group, e.g. "foo_requests_in_flight", or the Namespace of the other metrics.`,
+	},
+	{
+		ID:        RuleInfoMetric,
+		Name:      "InfoMetric",
+		Severity:  SeverityWarning,
+		Summary:   "Metrics named *_info should be gauges with labels, only ever set to 1.",
+		Rationale: "Info metrics such as build_info carry the properties of a target in their labels, and are joined to other series with a multiplication, e.g. up * on(instance) group_left(version) build_info; any value other than 1 changes the result of the join, and without labels they carry no information.",
+		Example:   `prometheus.NewCounter(prometheus.CounterOpts{Name: "build_info"}).Inc()`,
+		Fix:       `Use a GaugeVec with the identifying labels, e.g. version and revision, and call WithLabelValues(version, revision).Set(1).`,
+	},
+	{
+		ID:        RuleSeriesCollision,
+		Name:      "SeriesCollision",
+		Severity:  SeverityError,
+		Summary:   "Metric names should not be the names of the _bucket, _sum and _count series of a histogram or summary.",
+		Rationale: "A histogram named latency_seconds exposes the series latency_seconds_bucket, latency_seconds_sum and latency_seconds_count; a counter named latency_seconds_count exposes the same series, so the registry fails to gather them both, or the scrape holds duplicate series.",
+		Example:   `prometheus.CounterOpts{Name: "latency_seconds_count"} next to prometheus.HistogramOpts{Name: "latency_seconds"}`,
+		Fix:       "Drop the metric if it duplicates the series of the histogram or summary, or rename it.",
+	},
+	{
+		ID:        RuleImplicitSuffix,
+		Name:      "ImplicitSuffix",
+		Severity:  SeverityWarning,
+		Summary:   `Histograms, summaries and untyped metrics should not be named with the "_bucket", "_sum" and "_count" suffixes.`,
+		Rationale: "The series of a histogram named latency_count are latency_count_bucket, latency_count_sum and latency_count_count, and an untyped latency_count looks like the series of a histogram; queries such as sum(rate(latency_count[5m])) then aggregate another series than intended. The HistogramSummaryReserved validation of promlint only reports counters and gauges.",
+		Example:   `prometheus.HistogramOpts{Name: "latency_count"}`,
+		Fix:       `Drop the suffix, e.g. "latency_seconds".`,
+	},
+	{
+		ID:        RuleMixedUnits,
+		Name:      "MixedUnits",
+		Severity:  SeverityInfo,
+		Summary:   "Metrics measuring the same quantity should use the same unit as the other metrics of the analyzed packages.",
+		Rationale: "When some durations are in milliseconds and the others in seconds, or some sizes in kilobytes and the others in bytes, every query combining them needs a conversion, and dashboards end up mislabeled. The MetricUnits validation of promlint checks each name against the base units; this rule reports the minority convention of the codebase.",
+		Example:   `prometheus.HistogramOpts{Name: "cache_latency_ms"} next to prometheus.HistogramOpts{Name: "request_duration_seconds"} and prometheus.HistogramOpts{Name: "db_query_duration_seconds"}`,
+		Fix:       `Rename the metric with the unit of the others, e.g. "cache_latency_seconds", and convert the observed values.`,
+	},
+	{
+		ID:        RuleReservedPrefix,
+		Name:      "ReservedPrefix",
+		Severity:  SeverityWarning,
+		Summary:   "Application metrics should not use the go_, process_ and promhttp_ prefixes of the standard collectors.",
+		Rationale: "The default registry holds the Go and process collectors, and promhttp.Handler instruments itself with promhttp_ metrics. A metric named like one of theirs fails to register, and the other metrics with their prefixes are mistaken for runtime metrics in dashboards and by the recording rules written for them.",
+		Example:   `prometheus.GaugeOpts{Name: "go_workers"}`,
+		Fix:       `Prefix the metric with the namespace of the application, e.g. "acme_workers".`,
+	},
+	{
+		ID:        RuleCrossRepoCollision,
+		Name:      "CrossRepoCollision",
+		Severity:  SeverityWarning,
+		Summary:   "A series name should be exposed by the metrics of a single repository (aggregated inventories only).",
+		Rationale: "Services built from different repositories share the Prometheus servers and the dashboards of the platform; a name defined by two of them mixes unrelated series in every query not filtered by job, and a different type or label set breaks the queries written for the other. A metric exposing the _count series of a histogram of another repository collides the same way.",
+		Example:   `prometheus.CounterOpts{Name: "jobs_processed_total"} in both acme/api and acme/worker`,
+		Fix:       "Prefix each metric with the namespace of its service, or define the metric in a shared library.",
+	},
+	{
+		ID:        RuleNamespaceOwnership,
+		Name:      "NamespaceOwnership",
+		Severity:  SeverityWarning,
+		Summary:   "Metrics should only be defined by the repositories allowed to use their namespace or subsystem by the hierarchy (aggregated inventories only).",
+		Rationale: "The repos of a namespace of the hierarchy reserve it to the services of its team, so that its names, alerts and dashboards stay consistent; a metric of another repository in the namespace is either misnamed or bypasses the review of the owning team.",
+		Example:   `prometheus.CounterOpts{Name: "acme_billing_invoices_total"} in acme/api, when the subsystem billing lists the repository acme/billing`,
+		Fix:       "Rename the metric into a namespace of the repository, or add the repository to the repos of the namespace in agreement with its team.",
+	},
+	{
+		ID:        RuleMetadataConflict,
+		Name:      "MetadataConflict",
+		Severity:  SeverityWarning,
+		Summary:   "A metric name should not be exposed by the other jobs of a Prometheus server with another type or help text (metadata checks only).",
+		Rationale: "Prometheus keeps the type and help of each metric name per target; when jobs disagree, the metadata API returns several entries for the name, and the tools relying on it, such as the query editor, federation or remote write receivers, show or keep an arbitrary one. A name reused with another type also mixes unrelated series in the queries not filtered by job.",
+		Example:   `prometheus.GaugeOpts{Name: "jobs_processed_total"} while another job exposes jobs_processed_total as a counter`,
+		Fix:       "Rename the metric with the namespace of the application, or align its type and help with those of the other jobs if it measures the same thing.",
+	},
+	{
+		ID:        RuleExemplarType,
+		Name:      "ExemplarType",
+		Severity:  SeverityError,
+		Summary:   "AddWithExemplar should only be called on counters, and ObserveWithExemplar on histograms.",
+		Rationale: "Only the counters of client_golang implement prometheus.ExemplarAdder, and only its histograms prometheus.ExemplarObserver; the type assertion to call them on a gauge or a summary panics.",
+		Example:   `latency.(prometheus.ExemplarObserver).ObserveWithExemplar(d, prometheus.Labels{"trace_id": id}) on a summary`,
+		Fix:       "Make the metric a histogram or a counter, or update it without exemplar.",
+	},
+	{
+		ID:        RuleExemplarLabels,
+		Name:      "ExemplarLabels",
+		Severity:  SeverityWarning,
+		Summary:   "The labels of an exemplar should be valid label names totaling at most 128 runes with their values.",
+		Rationale: "AddWithExemplar and ObserveWithExemplar panic if a label name is invalid or if the names and values of the labels are longer than prometheus.ExemplarMaxRunes, 128 runes. Label sets whose constant part leaves less than a trace ID, 32 runes, to each value which is not constant panic when the values are long; invalid names and label sets too long whatever their values are errors.",
+		Example:   `requests.(prometheus.ExemplarAdder).AddWithExemplar(1, prometheus.Labels{"trace_id": traceID, "span_id": spanID, "user_agent": ua, "request_path": path})`,
+		Fix:       `Keep the exemplar to the IDs of the trace, e.g. prometheus.Labels{"trace_id": traceID}.`,
+	},
+	{
+		ID:        RuleCreatedTimestamp,
+		Name:      "CreatedTimestamp",
+		Severity:  SeverityWarning,
+		OptIn:     true,
+		Summary:   "The const counters of a namespace should all have a created timestamp, or none should, as required by the policy.",
+		Rationale: "The created timestamp of a counter tells Prometheus when it started from zero, so that resets and the first increase are accounted for; the counters of the constructors track it themselves, while the collectors choose between MustNewConstMetric and MustNewConstMetricWithCreatedTimestamp. When only some counters of a namespace have one, the rates of the others are underestimated after restarts and the _created series are missing from some of them. MustNewConstMetricWithCreatedTimestamp panics on the other types.",
+		Example:   `ch <- prometheus.MustNewConstMetric(jobsDesc, prometheus.CounterValue, jobs) next to prometheus.MustNewConstMetricWithCreatedTimestamp(errorsDesc, prometheus.CounterValue, errors, start)`,
+		Fix:       "Use the constructor of the other counters of the namespace, or of the policy given with --created-timestamps.",
+	},
+	{
+		ID:        RuleRelabeling,
+		Name:      "Relabeling",
+		Severity:  SeverityWarning,
+		Summary:   "The metric_relabel_configs of the jobs scraping a metric should not drop or rename its series or labels unexpectedly (relabel checks only).",
+		Rationale: "The metric_relabel_configs of a scrape config apply to every scraped series before it is stored: a keep rule written for other metrics, a drop regex broader than intended or a labeldrop of a label used by the dashboards make the series missing or different in Prometheus while the code and its tests look right, which is only noticed after the deploy.",
+		Example:   `metric_relabel_configs: [{source_labels: [__name__], regex: "http_.*", action: keep}] on the job of a service defining jobs_processed_total`,
+		Fix:       "Adapt the regex of the rule to the metrics of the code, or rename the metric or label so that it is kept as intended.",
+	},
+	{
+		ID:        RuleOTelCollision,
+		Name:      "OTelCollision",
+		Severity:  SeverityError,
+		Summary:   "The Prometheus name of an OpenTelemetry instrument should not collide with a metric of client_golang or with another instrument.",
+		Rationale: "The Prometheus exporter of OpenTelemetry translates the instrument names, replacing the dots with underscores and adding the unit and the _total suffix of counters, and is usually registered on the same registry as the client_golang metrics of the binary. When the translated name, or one of the series of a histogram, is also exposed by a metric of client_golang, the registry fails the scrape or the samples of both are mixed under one name.",
+		Example:   `meter.Int64Counter("http.requests") next to prometheus.NewCounter(prometheus.CounterOpts{Name: "http_requests_total"})`,
+		Fix:       "Rename the instrument or the metric, or keep one of them if they measure the same thing.",
+	},
+}
+
+// promlintRules are the IDs of the rules of the promlint validations.
+var promlintRules = []string{
+	RuleHelp, RuleMetricUnits, RuleCounter, RuleHistogramSummaryReserved,
+	RuleMetricTypeInName, RuleReservedChars, RuleCamelCase, RuleUnitAbbreviations,
+}
+
+// LookupRule returns the rule with the given ID.
+func LookupRule(id string) (Rule, bool) {
+	for _, r := range Rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// ResolveRule returns the rule with the given ID or name, ignoring case. The
+// rules of the promlint validations can also be given the name of the
+// validation, e.g. lintCamelCase.
+func ResolveRule(s string) (Rule, bool) {
+	for _, r := range Rules {
+		if strings.EqualFold(r.ID, s) || strings.EqualFold(r.Name, s) {
+			return r, true
+		}
+	}
+	for _, id := range promlintRules {
+		if r, ok := LookupRule(id); ok && strings.EqualFold("lint"+r.Name, s) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// listsRule reports whether list holds the ID or a name of the rule id, see
+// ResolveRule.
+func listsRule(list []string, id string) bool {
+	for _, s := range list {
+		if s == id {
+			return true
+		}
+		if r, ok := ResolveRule(s); ok && r.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleSeverity returns the default severity of the rule with the given ID.
+func ruleSeverity(id string) Severity {
+	if r, ok := LookupRule(id); ok {
+		return r.Severity
+	}
+	return SeverityWarning
+}
+
+// Explain returns a description of the rule, how to fix its issues and how
+// to suppress it.
+func (r Rule) Explain() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s (%s): %s\n\n", r.ID, r.Name, r.Severity, r.Summary)
+	fmt.Fprintf(&sb, "%s\n\n", r.Rationale)
+	fmt.Fprintf(&sb, "Example:\n\n    %s\n\n", r.Example)
+	fmt.Fprintf(&sb, "How to fix:\n\n    %s\n\n", r.Fix)
+	if r.OptIn {
+		fmt.Fprintf(&sb, "How to enable:\n\n    promlinter lint --enable=%s\n", r.ID)
+	} else {
+		fmt.Fprintf(&sb, "How to suppress, for the metrics of a prefix, in the file given to --rule-overrides:\n\n    overrides:\n      - prefix: legacy_\n        disable: [%s]\n", r.ID)
+	}
+	return sb.String()
+}
+
+// promlintRuleID maps the text of a promlint problem to the ID of the
+// validation which produced it, or to RulePromlintProblem if the text is not
+// known.
+func promlintRuleID(text string) string {
+	switch {
+	case text == "no help text":
+		return RuleHelp
+	case strings.HasPrefix(text, "use base unit"):
+		return RuleMetricUnits
+	case strings.Contains(text, `"_total" suffix`):
+		return RuleCounter
+	case strings.HasPrefix(text, "non-histogram"), strings.HasPrefix(text, "non-summary"):
+		return RuleHistogramSummaryReserved
+	case strings.HasPrefix(text, "metric name should not include type"):
+		return RuleMetricTypeInName
+	case strings.Contains(text, "should not contain ':'"):
+		return RuleReservedChars
+	case strings.Contains(text, "'snake_case' not 'camelCase'"):
+		return RuleCamelCase
+	case strings.Contains(text, "abbreviated units"):
+		return RuleUnitAbbreviations
+	}
+	return RulePromlintProblem
+}
@@ -0,0 +1,82 @@
+package promlinter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, r := range Rules {
+		if seen[r.ID] {
+			t.Fatalf("duplicate rule ID %s", r.ID)
+		}
+		seen[r.ID] = true
+	}
+
+	for text, id := range map[string]string{
+		"no help text": RuleHelp,
+		`use base unit "seconds" instead of "minutes"`:                  RuleMetricUnits,
+		`non-counter metrics should not have "_total" suffix`:           RuleCounter,
+		`non-histogram metrics should not have "le" label`:              RuleHistogramSummaryReserved,
+		"metric name should not include type 'gauge'":                   RuleMetricTypeInName,
+		"metric names should not contain ':'":                           RuleReservedChars,
+		"label names should be written in 'snake_case' not 'camelCase'": RuleCamelCase,
+		"metric names should not contain abbreviated units":             RuleUnitAbbreviations,
+	} {
+		if got := promlintRuleID(text); got != id {
+			t.Fatalf("expected rule %s for %q, got %s", id, text, got)
+		}
+		if _, ok := LookupRule(id); !ok {
+			t.Fatalf("rule %s is not registered", id)
+		}
+	}
+	if got := promlintRuleID("a problem of a future validation"); got != RulePromlintProblem {
+		t.Fatalf("expected unknown problems to be reported as %s, got %s", RulePromlintProblem, got)
+	}
+}
+
+func TestResolveRule(t *testing.T) {
+	for _, s := range []string{"PL007", "pl007", "CamelCase", "camelcase", "lintCamelCase", "LintCamelCase"} {
+		if r, ok := ResolveRule(s); !ok || r.ID != RuleCamelCase {
+			t.Errorf("expected %s to resolve to %s, got %v", s, RuleCamelCase, r.ID)
+		}
+	}
+	for _, s := range []string{"lintDeadMetric", "Camel"} {
+		if r, ok := ResolveRule(s); ok {
+			t.Errorf("expected %s not to resolve, got %s", s, r.ID)
+		}
+	}
+
+	setting := Setting{DisabledRules: []string{"lintHelp"}, EnabledRules: []string{"HelpUnit"}}
+	if setting.reports(RuleHelp) || !setting.reports(RuleHelpUnit) || !setting.reports(RuleCounter) {
+		t.Errorf("expected the rules to be disabled and enabled by name")
+	}
+}
+
+func TestExplainSuppression(t *testing.T) {
+	r, _ := LookupRule(RuleCounter)
+	_, snippet, ok := strings.Cut(r.Explain(), "--rule-overrides:\n\n")
+	if !ok {
+		t.Fatalf("expected the explanation to suggest --rule-overrides, got %q", r.Explain())
+	}
+	path := filepath.Join(t.TempDir(), "overrides.yml")
+	if err := os.WriteFile(path, []byte(snippet), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	overrides, err := LoadRuleOverrides(path)
+	if err != nil {
+		t.Fatalf("expected the suggested overrides to load: %v", err)
+	}
+	if len(overrides) != 1 || len(overrides[0].Disable) != 1 || overrides[0].Disable[0] != RuleCounter {
+		t.Fatalf("expected the overrides to disable %s, got %+v", RuleCounter, overrides)
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	if !SeverityError.AtLeast(SeverityWarning) || !SeverityWarning.AtLeast(SeverityWarning) || SeverityInfo.AtLeast(SeverityWarning) {
+		t.Fatal("unexpected severity order")
+	}
+}
@@ -0,0 +1,112 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// runtimeCollector is a standard collector of client_golang and the metrics
+// it registers.
+type runtimeCollector struct {
+	prefix, name string
+	metrics      []string
+}
+
+// runtimeCollectors are the standard collectors of client_golang, registered
+// by the default registry, whose prefixes are reserved.
+var runtimeCollectors = []runtimeCollector{
+	{
+		prefix: "go_",
+		name:   "the Go collector",
+		metrics: []string{
+			"go_gc_duration_seconds",
+			"go_goroutines",
+			"go_info",
+			"go_memstats_alloc_bytes",
+			"go_memstats_alloc_bytes_total",
+			"go_memstats_buck_hash_sys_bytes",
+			"go_memstats_frees_total",
+			"go_memstats_gc_cpu_fraction",
+			"go_memstats_gc_sys_bytes",
+			"go_memstats_heap_alloc_bytes",
+			"go_memstats_heap_idle_bytes",
+			"go_memstats_heap_inuse_bytes",
+			"go_memstats_heap_objects",
+			"go_memstats_heap_released_bytes",
+			"go_memstats_heap_sys_bytes",
+			"go_memstats_last_gc_time_seconds",
+			"go_memstats_lookups_total",
+			"go_memstats_mallocs_total",
+			"go_memstats_mcache_inuse_bytes",
+			"go_memstats_mcache_sys_bytes",
+			"go_memstats_mspan_inuse_bytes",
+			"go_memstats_mspan_sys_bytes",
+			"go_memstats_next_gc_bytes",
+			"go_memstats_other_sys_bytes",
+			"go_memstats_stack_inuse_bytes",
+			"go_memstats_stack_sys_bytes",
+			"go_memstats_sys_bytes",
+			"go_threads",
+		},
+	},
+	{
+		prefix: "process_",
+		name:   "the process collector",
+		metrics: []string{
+			"process_cpu_seconds_total",
+			"process_max_fds",
+			"process_open_fds",
+			"process_resident_memory_bytes",
+			"process_start_time_seconds",
+			"process_virtual_memory_bytes",
+			"process_virtual_memory_max_bytes",
+		},
+	},
+	{
+		prefix: "promhttp_",
+		name:   "promhttp.Handler",
+		metrics: []string{
+			"promhttp_metric_handler_errors_total",
+			"promhttp_metric_handler_requests_in_flight",
+			"promhttp_metric_handler_requests_total",
+		},
+	},
+}
+
+// lintRuntimePrefix reports the metrics named with the prefix of a standard
+// collector: those it registers fail to register next to it, and the others
+// are mistaken for runtime metrics in dashboards. The prefixes of the
+// selected profiles are reported by lintProfiles.
+func (v *visitor) lintRuntimePrefix(metric MetricFamilyWithPos) {
+	name := metric.MetricFamily.GetName()
+	for _, c := range runtimeCollectors {
+		if !strings.HasPrefix(name, c.prefix) || v.profilePrefix(c.prefix) {
+			continue
+		}
+		text, severity := fmt.Sprintf("metric uses the prefix %s reserved for the metrics of %s", c.prefix, c.name), ruleSeverity(RuleReservedPrefix)
+		if contains(c.metrics, name) {
+			text, severity = fmt.Sprintf("metric %s is already registered by %s", name, c.name), SeverityError
+		}
+		v.addIssue(Issue{
+			Pos:        metric.Pos,
+			End:        metric.End,
+			Metric:     name,
+			Text:       text,
+			RuleID:     RuleReservedPrefix,
+			Severity:   severity,
+			MetricType: metricTypeName(metric.MetricFamily.GetType()),
+			Labels:     metric.Labels(),
+		})
+		return
+	}
+}
+
+// profilePrefix reports whether one of the selected profiles reserves prefix.
+func (v *visitor) profilePrefix(prefix string) bool {
+	for _, p := range v.setting.Profiles {
+		if contains(p.Prefixes, prefix) {
+			return true
+		}
+	}
+	return false
+}
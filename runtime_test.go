@@ -0,0 +1,48 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReservedPrefixes(t *testing.T) {
+	path := filepath.Join("testdata", "runtime", "runtime.go")
+	httpService, _ := LookupProfile("http-service")
+
+	for _, tc := range []struct {
+		setting  Setting
+		expected []string
+	}{
+		{
+			expected: []string{
+				path + ":6:35: go_goroutines metric go_goroutines is already registered by the Go collector (error)",
+				path + ":10:32: process_workers metric uses the prefix process_ reserved for the metrics of the process collector (warning)",
+				path + ":15:34: promhttp_scrapes_total metric uses the prefix promhttp_ reserved for the metrics of promhttp.Handler (warning)",
+			},
+		},
+		{
+			// The prefix of the profile is reported by EcosystemName.
+			setting: Setting{Profiles: []Profile{httpService}},
+			expected: []string{
+				path + ":6:35: go_goroutines metric go_goroutines is already registered by the Go collector (error)",
+				path + ":10:32: process_workers metric uses the prefix process_ reserved for the metrics of the process collector (warning)",
+			},
+		},
+	} {
+		res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, tc.setting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, iss := range res.Issues {
+			if iss.RuleID == RuleReservedPrefix {
+				got = append(got, iss.Pos.String()+": "+iss.Metric+" "+iss.Text+" ("+string(iss.Severity)+")")
+			}
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("expected %v, got %v", tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package promlinter
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// scaffoldTypes map the metric types to the Go types of the metric and of
+// its Vec, and to the name of the constructor and of its Opts.
+var scaffoldTypes = map[string]struct{ metric, vec, constructor, opts string }{
+	"counter":   {"prometheus.Counter", "*prometheus.CounterVec", "Counter", "CounterOpts"},
+	"gauge":     {"prometheus.Gauge", "*prometheus.GaugeVec", "Gauge", "GaugeOpts"},
+	"histogram": {"prometheus.Histogram", "*prometheus.HistogramVec", "Histogram", "HistogramOpts"},
+	"summary":   {"prometheus.Summary", "*prometheus.SummaryVec", "Summary", "SummaryOpts"},
+}
+
+// initialisms are the words of metric names written in upper case in Go
+// identifiers.
+var initialisms = map[string]bool{
+	"api": true, "cpu": true, "dns": true, "grpc": true, "http": true, "id": true, "io": true, "ip": true,
+	"json": true, "rpc": true, "sql": true, "tcp": true, "tls": true, "udp": true, "uri": true, "url": true,
+}
+
+// WriteScaffold writes the Go declarations of the metrics of inv, the spec,
+// as a file of package pkg: a Metrics struct with a field per metric, and a
+// NewMetrics function creating them with promauto and registering them with
+// the given prometheus.Registerer. The file is meant to be generated again
+// when the spec changes, so that the code stays in sync with it.
+func WriteScaffold(w io.Writer, inv *Inventory, pkg string) error {
+	var fields, values bytes.Buffer
+	seen := make(map[string]bool)
+	for _, m := range inv.Metrics {
+		if seen[m.Name] {
+			continue
+		}
+		seen[m.Name] = true
+
+		t, ok := scaffoldTypes[m.Type]
+		if !ok {
+			return fmt.Errorf("metric %s: cannot scaffold %s metrics", m.Name, m.Type)
+		}
+		name := goName(m.Name)
+		typ, constructor := t.metric, "New"+t.constructor
+		if len(m.Labels) > 0 {
+			typ, constructor = t.vec, constructor+"Vec"
+		}
+
+		fmt.Fprintf(&fields, "%s %s\n", name, typ)
+
+		fmt.Fprintf(&values, "%s: factory.%s(prometheus.%s{\nName: %s,\nHelp: %s,\n}", name, constructor, t.opts, strconv.Quote(m.Name), strconv.Quote(m.Help))
+		if len(m.Labels) > 0 {
+			quoted := make([]string, 0, len(m.Labels))
+			for _, l := range m.Labels {
+				quoted = append(quoted, strconv.Quote(l))
+			}
+			fmt.Fprintf(&values, ", []string{%s}", strings.Join(quoted, ", "))
+		}
+		fmt.Fprint(&values, "),\n")
+	}
+
+	src := fmt.Sprintf(`// Code generated by promlinter scaffold. DO NOT EDIT.
+
+package %s
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics holds the metrics of the package.
+type Metrics struct {
+%s}
+
+// NewMetrics creates the metrics and registers them with reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	factory := promauto.With(reg)
+	return &Metrics{
+%s}
+}
+`, pkg, fields.String(), values.String())
+
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return fmt.Errorf("formatting the declarations: %w", err)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+// goName turns a metric name into an exported Go identifier, e.g.
+// http_requests_total into HTTPRequestsTotal.
+func goName(metric string) string {
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(metric, func(r rune) bool { return r == '_' || r == ':' }) {
+		if initialisms[part] {
+			sb.WriteString(strings.ToUpper(part))
+		} else {
+			sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,57 @@
+package promlinter
+
+import (
+	"bytes"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScaffold(t *testing.T) {
+	spec := &Inventory{Metrics: []InventoryMetric{
+		{Name: "http_requests_total", Type: "counter", Help: "HTTP requests.", Labels: []string{"code", "method"}},
+		{Name: "queue_length", Type: "gauge", Help: "Length of the \"default\" queue."},
+		{Name: "rpc_duration_seconds", Type: "histogram", Help: "Duration of RPCs.", Labels: []string{"service"}},
+		{Name: "batch_size_bytes", Type: "summary", Help: "Size of batches."},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteScaffold(&buf, spec, "metrics"); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"\tHTTPRequestsTotal  *prometheus.CounterVec\n",
+		"\tQueueLength        prometheus.Gauge\n",
+		`RPCDurationSeconds: factory.NewHistogramVec(prometheus.HistogramOpts{`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected the declarations to contain %q, got:\n%s", expected, buf.String())
+		}
+	}
+
+	// Discovering the metrics of the generated code gives the spec back.
+	path := filepath.Join(t.TempDir(), "metrics.go")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := NewInventory(res.Metrics)
+	for i := range inv.Metrics {
+		inv.Metrics[i].Position, inv.Metrics[i].Series = "", 0
+	}
+	expected := NewInventory(nil)
+	expected.Metrics = append(expected.Metrics, spec.Metrics[3], spec.Metrics[0], spec.Metrics[1], spec.Metrics[2])
+	if !reflect.DeepEqual(inv, expected) {
+		t.Fatalf("expected the inventory of the generated code to be\n%+v\ngot\n%+v", expected, inv)
+	}
+
+	if err := WriteScaffold(&bytes.Buffer{}, &Inventory{Metrics: []InventoryMetric{{Name: "foo", Type: "untyped"}}}, "metrics"); err == nil {
+		t.Fatal("expected an error for an untyped metric")
+	}
+}
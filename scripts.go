@@ -0,0 +1,150 @@
+package promlinter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ScriptRule is a custom rule written as text/template expressions evaluated
+// against each discovered metric, so that policy teams can add checks
+// without writing Go, e.g.
+//
+//	rules:
+//	  - id: ACME001
+//	    name: CounterLabels
+//	    severity: warning
+//	    summary: Counters should have at most 3 labels.
+//	    when: '{{and (eq .Type "counter") (gt (len .Labels) 3)}}'
+//	    message: 'counter has {{len .Labels}} labels: {{join .Labels ", "}}'
+//
+// When must render "true" for the metric to be reported, with the text
+// rendered by Message. The expressions receive a ScriptMetric, and may call
+// the functions of text/template and hasPrefix, hasSuffix, contains, has (a
+// list contains a string), matches (a regular expression matches a string),
+// lower, upper and join.
+type ScriptRule struct {
+	ID       string   `yaml:"id" json:"id"`
+	Name     string   `yaml:"name" json:"name"`
+	Severity Severity `yaml:"severity,omitempty" json:"severity,omitempty"`
+	Summary  string   `yaml:"summary,omitempty" json:"summary,omitempty"`
+	When     string   `yaml:"when" json:"when"`
+	Message  string   `yaml:"message" json:"message"`
+
+	when, message *template.Template
+}
+
+// ScriptMetric is the metric given to the expressions of a ScriptRule.
+type ScriptMetric struct {
+	Name        string
+	Type        string
+	Help        string
+	Labels      []string
+	Constructor string
+	File        string
+	Line        int
+}
+
+var scriptFuncs = template.FuncMap{
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"contains":  strings.Contains,
+	"has":       contains,
+	"matches": func(pattern, s string) (bool, error) {
+		return regexp.MatchString(pattern, s)
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+}
+
+// LoadScriptRules reads and compiles the script rules of the YAML file at
+// path. The severity of the rules defaults to warning.
+func LoadScriptRules(path string) ([]*ScriptRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Rules []*ScriptRule `yaml:"rules"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	seen := make(map[string]bool)
+	for _, r := range file.Rules {
+		if r.ID == "" || r.When == "" || r.Message == "" {
+			return nil, fmt.Errorf("%s: rules need an id, a when and a message", path)
+		}
+		if _, ok := LookupRule(r.ID); ok || seen[r.ID] {
+			return nil, fmt.Errorf("%s: rule ID %s is already used", path, r.ID)
+		}
+		seen[r.ID] = true
+		if r.Severity == "" {
+			r.Severity = SeverityWarning
+		}
+		if _, ok := severityRanks[r.Severity]; !ok {
+			return nil, fmt.Errorf("%s: invalid severity %q for rule %s", path, r.Severity, r.ID)
+		}
+		if r.when, err = template.New(r.ID).Funcs(scriptFuncs).Parse(r.When); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if r.message, err = template.New(r.ID).Funcs(scriptFuncs).Parse(r.Message); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+// eval returns the issue text of m, if the rule reports it. An expression
+// failing at runtime, e.g. with an invalid regular expression, is reported
+// as the issue.
+func (r *ScriptRule) eval(m ScriptMetric) (string, bool) {
+	var buf bytes.Buffer
+	if err := r.when.Execute(&buf, m); err != nil {
+		return fmt.Sprintf("script rule failed: %v", err), true
+	}
+	if strings.TrimSpace(buf.String()) != "true" {
+		return "", false
+	}
+	buf.Reset()
+	if err := r.message.Execute(&buf, m); err != nil {
+		return fmt.Sprintf("script rule failed: %v", err), true
+	}
+	return buf.String(), true
+}
+
+// runScriptRules evaluates the script rules of the setting on metric.
+func (v *visitor) runScriptRules(metric MetricFamilyWithPos) {
+	if len(v.setting.ScriptRules) == 0 {
+		return
+	}
+	m := ScriptMetric{
+		Name:        metric.MetricFamily.GetName(),
+		Type:        metricTypeName(metric.MetricFamily.GetType()),
+		Help:        metric.MetricFamily.GetHelp(),
+		Labels:      metric.Labels(),
+		Constructor: metric.Constructor,
+		File:        metric.Pos.Filename,
+		Line:        metric.Pos.Line,
+	}
+	for _, r := range v.setting.ScriptRules {
+		if text, ok := r.eval(m); ok {
+			v.addIssue(Issue{
+				Pos:        metric.Pos,
+				End:        metric.End,
+				Metric:     m.Name,
+				Text:       text,
+				RuleID:     r.ID,
+				Severity:   r.Severity,
+				MetricType: m.Type,
+				Labels:     m.Labels,
+			})
+		}
+	}
+}
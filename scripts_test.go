@@ -0,0 +1,48 @@
+package promlinter
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScriptRules(t *testing.T) {
+	rules, err := LoadScriptRules(filepath.Join("testdata", "scripts", "rules.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "scripts", "scripts.go")}, Setting{ScriptRules: rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		got = append(got, iss.Metric+" "+iss.RuleID+" "+string(iss.Severity)+": "+iss.Text)
+	}
+	expected := []string{
+		"acme_requests_total ACME001 warning: counter has 3 labels: method, path, code",
+		"queue_length ACME002 error: metric name should start with acme_",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestLoadScriptRulesErrors(t *testing.T) {
+	for _, content := range []string{
+		"rules:\n  - id: ACME001\n    when: 'true'\n",
+		"rules:\n  - id: PL001\n    when: 'true'\n    message: m\n",
+		"rules:\n  - id: ACME001\n    when: '{{if}}'\n    message: m\n",
+	} {
+		path := filepath.Join(t.TempDir(), "rules.yml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadScriptRules(path); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package promlinter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"go/token"
+	"net"
+	"sync"
+)
+
+var errConnectionClosed = errors.New("connection closed by the server")
+
+// LintRequest asks a Server to lint files.
+type LintRequest struct {
+	// Paths are the Go files to lint. They should be absolute, since the
+	// server may run in another directory.
+	Paths         []string `json:"paths"`
+	Strict        bool     `json:"strict,omitempty"`
+	DisabledRules []string `json:"disabled_rules,omitempty"`
+	EnabledRules  []string `json:"enabled_rules,omitempty"`
+	// Severities, Profiles, ReservedLabels, AllowedConstLabels,
+	// NameValidation, NameEscaping, SeriesBudget, UnresolvedLabelValues,
+	// GoVersion, Deduplicate, CreatedTimestamps and Generated are the fields
+	// of Setting.
+	Severities            map[string]Severity    `json:"severities,omitempty"`
+	Profiles              []Profile              `json:"profiles,omitempty"`
+	ReservedLabels        []string               `json:"reserved_labels,omitempty"`
+	AllowedConstLabels    []string               `json:"allowed_const_labels,omitempty"`
+	NameValidation        NameValidation         `json:"name_validation,omitempty"`
+	NameEscaping          NameEscaping           `json:"name_escaping,omitempty"`
+	SeriesBudget          int                    `json:"series_budget,omitempty"`
+	UnresolvedLabelValues int                    `json:"unresolved_label_values,omitempty"`
+	GoVersion             string                 `json:"go_version,omitempty"`
+	Deduplicate           bool                   `json:"deduplicate,omitempty"`
+	CreatedTimestamps     CreatedTimestampPolicy `json:"created_timestamps,omitempty"`
+	Generated             GeneratedPolicy        `json:"generated,omitempty"`
+}
+
+// LintResponse is the answer of a Server to a LintRequest.
+type LintResponse struct {
+	Issues       []Issue       `json:"issues"`
+	Summary      *Summary      `json:"summary,omitempty"`
+	SyntaxErrors []SyntaxError `json:"syntax_errors,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// Server answers lint requests, keeping the analysis of unchanged packages in
+// memory between requests.
+//
+// The protocol is line-delimited JSON: clients write one LintRequest per line
+// and read one LintResponse per line, on as many requests as they want per
+// connection.
+type Server struct {
+	setting Setting
+	wg      sync.WaitGroup
+}
+
+// NewServer returns a server linting files with setting. The Strict,
+// DisabledRules, EnabledRules, Severities, Profiles, ReservedLabels,
+// AllowedConstLabels, NameValidation, NameEscaping, SeriesBudget,
+// UnresolvedLabelValues, GoVersion, Deduplicate, CreatedTimestamps and
+// Generated fields are overridden by each request.
+func NewServer(setting Setting) *Server {
+	setting.Cache = NewMemoryCache()
+	return &Server{setting: setting}
+}
+
+// Serve accepts connections on l until it is closed.
+func (s *Server) Serve(l net.Listener) error {
+	defer s.wg.Wait()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var (
+			req  LintRequest
+			resp LintResponse
+		)
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp = s.Lint(req)
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			s.logError("writing response failed", err)
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		s.logError("reading request failed", err)
+	}
+}
+
+// Lint answers a single request.
+func (s *Server) Lint(req LintRequest) LintResponse {
+	setting := s.setting
+	setting.Strict = req.Strict
+	setting.DisabledRules = req.DisabledRules
+	setting.EnabledRules = req.EnabledRules
+	setting.Severities = req.Severities
+	setting.Profiles = req.Profiles
+	setting.ReservedLabels = req.ReservedLabels
+	setting.AllowedConstLabels = req.AllowedConstLabels
+	setting.NameValidation = req.NameValidation
+	setting.NameEscaping = req.NameEscaping
+	setting.SeriesBudget = req.SeriesBudget
+	setting.UnresolvedLabelValues = req.UnresolvedLabelValues
+	setting.GoVersion = req.GoVersion
+	setting.Deduplicate = req.Deduplicate
+	setting.CreatedTimestamps = req.CreatedTimestamps
+	setting.Generated = req.Generated
+
+	res, err := AnalyzeFiles(token.NewFileSet(), req.Paths, setting)
+	if err != nil {
+		return LintResponse{Issues: make([]Issue, 0), Error: err.Error()}
+	}
+	return LintResponse{Issues: res.Issues, Summary: Summarize(res, res.Issues), SyntaxErrors: res.SyntaxErrors}
+}
+
+func (s *Server) logError(msg string, err error) {
+	if s.setting.Logger != nil {
+		s.setting.Logger.Error(msg, "err", err)
+	}
+}
+
+// Client sends lint requests to a Server.
+type Client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+// Dial connects to the server listening on the unix socket at path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	return &Client{conn: conn, scanner: scanner}, nil
+}
+
+// Lint sends a request and waits for its response.
+func (c *Client) Lint(req LintRequest) (*LintResponse, error) {
+	if err := json.NewEncoder(c.conn).Encode(req); err != nil {
+		return nil, err
+	}
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errConnectionClosed
+	}
+
+	resp := &LintResponse{}
+	if err := json.Unmarshal(c.scanner.Bytes(), resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
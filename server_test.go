@@ -0,0 +1,48 @@
+package promlinter
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "promlinter.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go NewServer(Setting{}).Serve(l)
+
+	c, err := Dial(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	path, err := filepath.Abs("testdata/testdata.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second request is answered from the warm cache.
+	for i := 0; i < 2; i++ {
+		resp, err := c.Lint(LintRequest{Paths: []string{path}, DisabledRules: []string{RuleHelp}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Error != "" || len(resp.Issues) != 1 || resp.Issues[0].RuleID != RuleCounter || resp.Summary.Metrics != 5 {
+			t.Fatalf("unexpected response %+v", resp)
+		}
+	}
+
+	resp, err := c.Lint(LintRequest{Paths: []string{"/does/not/exist.go"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error == "" {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,92 @@
+package promlinter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ShardBy tells how to split the reports of a monorepo into shards, e.g. one
+// per team.
+type ShardBy string
+
+const (
+	// ShardByModule makes a shard per Go module.
+	ShardByModule ShardBy = "module"
+	// ShardByDir makes a shard per top-level directory, relative to the
+	// current directory.
+	ShardByDir ShardBy = "dir"
+)
+
+// Sharder assigns files to shards.
+type Sharder struct {
+	by ShardBy
+	// ws gives the modules of a workspace; without it, the modules are found
+	// from the go.mod files enclosing the files.
+	ws      *Workspace
+	modules moduleFinder
+}
+
+// NewSharder returns a sharder splitting files by, using the modules of ws
+// if it is not nil.
+func NewSharder(by ShardBy, ws *Workspace) *Sharder {
+	return &Sharder{by: by, ws: ws, modules: moduleFinder{dirs: make(map[string]moduleDir)}}
+}
+
+// Shard returns the key of the shard of filename: the module path, or the
+// top-level directory, "." for the files of the current directory. It is
+// empty if the module of the file is unknown.
+func (s *Sharder) Shard(filename string) string {
+	if s.by == ShardByDir {
+		rel := filepath.Clean(filename)
+		if filepath.IsAbs(rel) {
+			if wd, err := os.Getwd(); err == nil {
+				if r, err := filepath.Rel(wd, rel); err == nil {
+					rel = r
+				}
+			}
+		}
+		dir := filepath.Dir(rel)
+		if dir == "." {
+			return "."
+		}
+		return strings.SplitN(filepath.ToSlash(dir), "/", 2)[0]
+	}
+
+	if s.ws != nil {
+		return s.ws.ModuleOf(filename)
+	}
+	return s.modules.find(filepath.Dir(filename)).path
+}
+
+// ShardIssues splits issues by the shard of their file.
+func (s *Sharder) ShardIssues(issues []Issue) map[string][]Issue {
+	shards := make(map[string][]Issue)
+	for _, iss := range issues {
+		key := s.Shard(iss.Pos.Filename)
+		shards[key] = append(shards[key], iss)
+	}
+	return shards
+}
+
+// ShardMetrics splits metrics by the shard of the file defining them.
+func (s *Sharder) ShardMetrics(metrics []MetricFamilyWithPos) map[string][]MetricFamilyWithPos {
+	shards := make(map[string][]MetricFamilyWithPos)
+	for _, m := range metrics {
+		key := s.Shard(m.Pos.Filename)
+		shards[key] = append(shards[key], m)
+	}
+	return shards
+}
+
+// ShardFilename returns the base name of the file of a shard, without
+// extension, e.g. github.com_acme_api for the module github.com/acme/api.
+func ShardFilename(key string) string {
+	switch key {
+	case "":
+		return "_unknown"
+	case ".":
+		return "_root"
+	}
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(key)
+}
@@ -0,0 +1,59 @@
+package promlinter
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSharder(t *testing.T) {
+	api := filepath.Join("testdata", "workspace", "api", "metrics.go")
+	worker := filepath.Join("testdata", "workspace", "worker", "metrics.go")
+	issues := []Issue{{Metric: "a"}, {Metric: "b"}, {Metric: "c"}, {Metric: "d"}}
+	issues[0].Pos.Filename = api
+	issues[1].Pos.Filename = worker
+	issues[2].Pos.Filename = api
+	issues[3].Pos.Filename = "main.go"
+
+	ws, err := LoadWorkspace(filepath.Join("testdata", "workspace", "go.work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name     string
+		sharder  *Sharder
+		expected map[string][]string
+	}{
+		{
+			name:     "workspace modules",
+			sharder:  NewSharder(ShardByModule, ws),
+			expected: map[string][]string{"example.com/api": {"a", "c"}, "example.com/worker": {"b"}, "": {"d"}},
+		},
+		{
+			name:     "go.mod files",
+			sharder:  NewSharder(ShardByModule, nil),
+			expected: map[string][]string{"example.com/api": {"a", "c"}, "example.com/worker": {"b"}, "github.com/yeya24/promlinter": {"d"}},
+		},
+		{
+			name:     "top-level directories",
+			sharder:  NewSharder(ShardByDir, nil),
+			expected: map[string][]string{"testdata": {"a", "b", "c"}, ".": {"d"}},
+		},
+	} {
+		got := make(map[string][]string)
+		for key, shard := range tc.sharder.ShardIssues(issues) {
+			for _, iss := range shard {
+				got[key] = append(got[key], iss.Metric)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%s: expected shards %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+
+	for key, expected := range map[string]string{"example.com/api": "example.com_api", ".": "_root", "": "_unknown"} {
+		if got := ShardFilename(key); got != expected {
+			t.Errorf("expected the file of shard %q to be %q, got %q", key, expected, got)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package promlinter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SimilarityRules tell which metric names are near-duplicates, reported by
+// the NearDuplicate rule, e.g.
+//
+//	prefix_components: 2
+//	suffixes: [_total, _count, _num]
+type SimilarityRules struct {
+	// PrefixComponents is the largest number of leading components, such
+	// as a namespace and a subsystem, by which two names may differ, e.g. 1
+	// for http_requests_total and api_http_requests_total. Zero disables
+	// the comparison of prefixes.
+	PrefixComponents int `yaml:"prefix_components"`
+	// Suffixes are the trivial suffixes by which two names may differ, e.g.
+	// _total for jobs_processed and jobs_processed_total.
+	Suffixes []string `yaml:"suffixes"`
+}
+
+// DefaultSimilarityRules are the similarity rules used if
+// Setting.SimilarityRules is nil.
+var DefaultSimilarityRules = SimilarityRules{
+	PrefixComponents: 2,
+	Suffixes:         []string{"_total", "_count", "_counter", "_num", "_number", "_value", "_metric"},
+}
+
+// LoadSimilarityRules reads the YAML similarity rules at path.
+func LoadSimilarityRules(path string) (*SimilarityRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &SimilarityRules{}
+	if err := yaml.UnmarshalStrict(data, r); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if r.PrefixComponents < 0 {
+		return nil, fmt.Errorf("%s: negative prefix_components %d", path, r.PrefixComponents)
+	}
+	for _, suffix := range r.Suffixes {
+		if strings.Trim(suffix, "_") == "" {
+			return nil, fmt.Errorf("%s: empty suffix %q", path, suffix)
+		}
+	}
+	return r, nil
+}
+
+// similar returns how name differs from one of the other names, and that
+// name, if it is a near-duplicate of it: the other name is name without a
+// trivial suffix, or without its leading components. The name without its
+// suffix must keep two components, and without its prefix three, so that
+// generic names such as errors_total are not compared.
+func (r SimilarityRules) similar(name string, names map[string]bool) (other, diff string) {
+	for _, suffix := range r.Suffixes {
+		if !strings.HasPrefix(suffix, "_") {
+			suffix = "_" + suffix
+		}
+		if base := strings.TrimSuffix(name, suffix); base != name && names[base] && strings.Contains(base, "_") {
+			return base, "the suffix " + suffix
+		}
+	}
+	components := strings.Split(name, "_")
+	for k := 1; k <= r.PrefixComponents && k+3 <= len(components); k++ {
+		if rest := strings.Join(components[k:], "_"); names[rest] {
+			return rest, "the prefix " + strings.Join(components[:k], "_") + "_"
+		}
+	}
+	return "", ""
+}
+
+// nearDuplicates reports the metrics whose name is a near-duplicate of the
+// name of another metric, at their first definition.
+func nearDuplicates(res *partialResult, rules SimilarityRules) []Issue {
+	first := make(map[string]MetricFamilyWithPos)
+	for _, m := range res.metrics {
+		name := m.MetricFamily.GetName()
+		if prev, ok := first[name]; !ok || positionLess(m.Pos, prev.Pos) {
+			first[name] = m
+		}
+	}
+	names := make(map[string]bool, len(first))
+	for name := range first {
+		names[name] = true
+	}
+
+	var issues []Issue
+	for _, name := range sortedSet(names) {
+		other, diff := rules.similar(name, names)
+		if other == "" {
+			continue
+		}
+		m := first[name]
+		issues = append(issues, Issue{
+			Pos:        m.Pos,
+			Metric:     name,
+			Text:       fmt.Sprintf("metric name only differs by %s from %s defined at %s, likely an accidental duplicate", diff, other, first[other].Pos),
+			RuleID:     RuleNearDuplicate,
+			Severity:   ruleSeverity(RuleNearDuplicate),
+			End:        m.End,
+			MetricType: metricTypeName(m.MetricFamily.GetType()),
+			Labels:     m.Labels(),
+		})
+	}
+	return issues
+}
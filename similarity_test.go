@@ -0,0 +1,66 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNearDuplicates(t *testing.T) {
+	paths := []string{filepath.Join("testdata", "similarity", "similarity.go")}
+	rules, err := LoadSimilarityRules(filepath.Join("testdata", "similarity", "rules.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		setting  Setting
+		expected []string
+	}{
+		// The rule is opt-in.
+		{setting: Setting{}},
+		{
+			setting: Setting{EnabledRules: []string{"NearDuplicate"}},
+			expected: []string{
+				"api_http_requests_total metric name only differs by the prefix api_ from http_requests_total defined at " + paths[0] + ":6:35, likely an accidental duplicate",
+				"jobs_processed_count metric name only differs by the suffix _count from jobs_processed defined at " + paths[0] + ":15:29, likely an accidental duplicate",
+				// errors_total is too short to compare with
+				// acme_db_errors_total.
+			},
+		},
+		{
+			setting: Setting{EnabledRules: []string{"NearDuplicate"}, SimilarityRules: rules},
+			expected: []string{
+				"jobs_processed_count metric name only differs by the suffix _count from jobs_processed defined at " + paths[0] + ":15:29, likely an accidental duplicate",
+			},
+		},
+	} {
+		res, err := AnalyzeFiles(token.NewFileSet(), paths, tc.setting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, iss := range res.Issues {
+			if iss.RuleID == RuleNearDuplicate {
+				got = append(got, iss.Metric+" "+iss.Text)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("expected %v, got %v", tc.expected, got)
+		}
+	}
+}
+
+func TestLoadSimilarityRules(t *testing.T) {
+	rules, err := LoadSimilarityRules(filepath.Join("testdata", "similarity", "rules.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (&SimilarityRules{Suffixes: []string{"_count"}}); !reflect.DeepEqual(rules, expected) {
+		t.Errorf("expected %+v, got %+v", expected, rules)
+	}
+	if _, err := LoadSimilarityRules(filepath.Join("testdata", "similarity", "similarity.go")); err == nil {
+		t.Error("expected an error for invalid rules")
+	}
+}
@@ -0,0 +1,150 @@
+package promlinter
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Dictionary is a set of correctly spelled words, checked by the
+// HelpSpelling rule against the words of help texts.
+type Dictionary struct {
+	words map[string]bool
+	// digest identifies the words in the cache keys.
+	digest string
+}
+
+// NewDictionary returns a dictionary of words. The case of the words is
+// ignored.
+func NewDictionary(words []string) *Dictionary {
+	d := &Dictionary{words: make(map[string]bool, len(words))}
+	for _, w := range words {
+		d.words[strings.ToLower(w)] = true
+	}
+	sorted := sortedSet(d.words)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	d.digest = hex.EncodeToString(sum[:])
+	return d
+}
+
+// LoadDictionary reads the word lists at paths into a single dictionary, e.g.
+// a system dictionary and the custom words of a repository. Lists have one
+// word per line; empty lines and lines starting with # are ignored. The affix
+// flags of Hunspell .dic files, e.g. "request/MS", are dropped, as is their
+// first line holding the number of words.
+func LoadDictionary(paths ...string) (*Dictionary, error) {
+	var words []string
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		for first := true; scanner.Scan(); first = false {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") || (first && isNumber(line)) {
+				continue
+			}
+			word, _, _ := strings.Cut(line, "/")
+			words = append(words, word)
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+	return NewDictionary(words), nil
+}
+
+func isNumber(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+}
+
+// misspelled returns the words of help which are neither in the dictionary
+// nor words of the metric name or labels. Words which look like identifiers,
+// acronyms, numbers or paths are not checked.
+func (d *Dictionary) misspelled(metric MetricFamilyWithPos, help string) []string {
+	known := make(map[string]bool)
+	for _, w := range helpWords(metric.MetricFamily.GetName()) {
+		known[w] = true
+	}
+	for _, l := range metric.Labels() {
+		known[strings.ToLower(l)] = true
+	}
+
+	var words []string
+	for _, field := range strings.Fields(help) {
+		word := strings.TrimFunc(field, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		word = strings.TrimSuffix(strings.TrimSuffix(word, "'s"), "’s")
+		if len([]rune(word)) < 3 || !isPlainWord(word) {
+			continue
+		}
+		lower := strings.ToLower(word)
+		if !d.words[lower] && !known[lower] && !known[strings.TrimSuffix(lower, "s")] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// isPlainWord reports whether word only has letters, or an hyphen or an
+// apostrophe, and is not in upper case past its first letter, e.g. HTTP or
+// ObserveDuration.
+func isPlainWord(word string) bool {
+	for i, r := range word {
+		switch {
+		case r == '-' || r == '\'' || r == '’':
+		case !unicode.IsLetter(r):
+			return false
+		case i > 0 && unicode.IsUpper(r):
+			return false
+		}
+	}
+	return true
+}
+
+// suggest returns a word of the dictionary at one edit of word, deleting,
+// inserting, replacing or transposing a letter, or an empty string. The
+// first one in alphabetical order is returned if there are several.
+func (d *Dictionary) suggest(word string) string {
+	w := []rune(strings.ToLower(word))
+	candidates := make(map[string]bool)
+	add := func(r []rune) {
+		if s := string(r); d.words[s] {
+			candidates[s] = true
+		}
+	}
+	for i := 0; i <= len(w); i++ {
+		if i < len(w) {
+			add(append(append([]rune{}, w[:i]...), w[i+1:]...))
+		}
+		if i+1 < len(w) {
+			t := append([]rune{}, w...)
+			t[i], t[i+1] = t[i+1], t[i]
+			add(t)
+		}
+		for c := 'a'; c <= 'z'; c++ {
+			if i < len(w) {
+				t := append([]rune{}, w...)
+				t[i] = c
+				add(t)
+			}
+			add(append(append(append([]rune{}, w[:i]...), c), w[i:]...))
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sorted := make([]string, 0, len(candidates))
+	for c := range candidates {
+		sorted = append(sorted, c)
+	}
+	sort.Strings(sorted)
+	return sorted[0]
+}
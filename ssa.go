@@ -0,0 +1,263 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// maxSSADepth bounds the number of calls followed to resolve a value.
+const maxSSADepth = 8
+
+// ssaResolver resolves the string values of expressions by constant
+// propagation on the SSA form of the packages.
+type ssaResolver struct {
+	fset  *token.FileSet
+	files map[string]*ssaFile
+	prog  *ssa.Program
+	// stores holds the values stored to each package variable, computed
+	// on first use.
+	storesOnce sync.Once
+	stores     map[*ssa.Global][]ssa.Value
+}
+
+// ssaFile is a file of a package loaded with types and SSA.
+type ssaFile struct {
+	file *ast.File
+	info *types.Info
+	pkg  *ssa.Package
+	// cgo is true if file is the output of cgo for a file importing "C",
+	// whose offsets differ from those of the source: its nodes are found by
+	// their line and column, which //line directives map to the source.
+	cgo bool
+}
+
+// loadSSA loads the packages of the files at paths, with their dependencies,
+// and builds their SSA form. Packages with errors are left out: their values
+// are resolved syntactically.
+func loadSSA(paths []string, setting Setting) (*ssaResolver, error) {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, path := range paths {
+		dir, err := filepath.Abs(filepath.Dir(path))
+		if err != nil {
+			return nil, err
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	// Dependencies are loaded from source rather than from export data, so
+	// that the SSA builder sees fully typed packages.
+	cfg := &packages.Config{
+		Fset: token.NewFileSet(),
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+	}
+	pkgs, err := packages.Load(cfg, dirs...)
+	if err != nil {
+		return nil, err
+	}
+
+	prog, ssaPkgs := ssautil.Packages(pkgs, ssa.GlobalDebug)
+	r := &ssaResolver{fset: cfg.Fset, files: make(map[string]*ssaFile), prog: prog}
+	for i, pkg := range pkgs {
+		if ssaPkgs[i] == nil {
+			if setting.Logger != nil {
+				setting.Logger.Debug("package not loaded for SSA", "package", pkg.PkgPath, "errors", len(pkg.Errors))
+			}
+			continue
+		}
+		ssaPkgs[i].Build()
+		for _, file := range pkg.Syntax {
+			pos := cfg.Fset.Position(file.Pos())
+			cgo := pos.Filename != cfg.Fset.PositionFor(file.Pos(), false).Filename
+			r.files[pos.Filename] = &ssaFile{file: file, info: pkg.TypesInfo, pkg: ssaPkgs[i], cgo: cgo}
+		}
+	}
+	return r, nil
+}
+
+// resolve returns the string value of the expression at the position of n,
+// which belongs to another parse of a loaded file. If handled is false, the
+// expression is not part of the SSA form, e.g. because its package failed to
+// load, and it should be resolved syntactically.
+func (r *ssaResolver) resolve(pos token.Position, n ast.Node) (value string, ok, handled bool) {
+	filename, err := filepath.Abs(pos.Filename)
+	if err != nil {
+		return "", false, false
+	}
+	f, ok := r.files[filename]
+	if !ok {
+		return "", false, false
+	}
+
+	var start, end token.Pos
+	if f.cgo {
+		if start, end, ok = r.locate(f.file, pos, n); !ok {
+			return "", false, false
+		}
+	} else {
+		tokFile := r.fset.File(f.file.Pos())
+		if pos.Offset >= tokFile.Size() {
+			return "", false, false
+		}
+		start = tokFile.Pos(pos.Offset)
+		end = start + (n.End() - n.Pos())
+	}
+	path, exact := astutil.PathEnclosingInterval(f.file, start, end)
+	expr, ok := path[0].(ast.Expr)
+	if !exact || !ok || expr.Pos() != start || expr.End() != end {
+		return "", false, false
+	}
+
+	if tv, ok := f.info.Types[expr]; ok && tv.Value != nil {
+		value, ok = stringConstant(tv.Value)
+		return value, ok, true
+	}
+	fn := ssa.EnclosingFunction(f.pkg, path)
+	if fn == nil {
+		return "", false, false
+	}
+	v, _ := fn.ValueForExpr(expr)
+	if v == nil {
+		return "", false, false
+	}
+	value, ok = r.value(v, make(map[ssa.Value]bool), 0)
+	return value, ok, true
+}
+
+// locate returns the interval of the outermost node of file of the same type
+// as n at the line and column of pos, both mapped to the source by the //line
+// directives of cgo. The offsets of the source and of the cgo output differ,
+// and so may the lengths of the nodes referring to C.
+func (r *ssaResolver) locate(file *ast.File, pos token.Position, n ast.Node) (start, end token.Pos, ok bool) {
+	ast.Inspect(file, func(m ast.Node) bool {
+		if ok || m == nil {
+			return false
+		}
+		if p := r.fset.Position(m.Pos()); p.Line == pos.Line && p.Column == pos.Column && reflect.TypeOf(m) == reflect.TypeOf(n) {
+			start, end, ok = m.Pos(), m.End(), true
+			return false
+		}
+		return true
+	})
+	return start, end, ok
+}
+
+// value propagates string constants through v. A value is only resolved if
+// every flow path leads to the same string.
+func (r *ssaResolver) value(v ssa.Value, visiting map[ssa.Value]bool, depth int) (string, bool) {
+	if visiting[v] {
+		return "", false
+	}
+	visiting[v] = true
+	defer delete(visiting, v)
+
+	switch t := v.(type) {
+	case *ssa.Const:
+		if t.Value == nil {
+			return "", false
+		}
+		return stringConstant(t.Value)
+
+	case *ssa.BinOp:
+		if t.Op != token.ADD {
+			return "", false
+		}
+		x, ok := r.value(t.X, visiting, depth)
+		if !ok {
+			return "", false
+		}
+		y, ok := r.value(t.Y, visiting, depth)
+		if !ok {
+			return "", false
+		}
+		return x + y, true
+
+	case *ssa.ChangeType:
+		return r.value(t.X, visiting, depth)
+
+	case *ssa.Phi:
+		return r.values(t.Edges, visiting, depth)
+
+	case *ssa.Call:
+		// Follow calls to functions which always return the same string,
+		// whatever their arguments.
+		callee := t.Call.StaticCallee()
+		if callee == nil || depth >= maxSSADepth || len(callee.Blocks) == 0 {
+			return "", false
+		}
+		var results []ssa.Value
+		for _, b := range callee.Blocks {
+			if ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok && len(ret.Results) == 1 {
+				results = append(results, ret.Results[0])
+			}
+		}
+		return r.values(results, visiting, depth+1)
+
+	case *ssa.UnOp:
+		// Loads of package variables whose only store is their initializer.
+		global, ok := t.X.(*ssa.Global)
+		if t.Op != token.MUL || !ok {
+			return "", false
+		}
+		return r.values(r.globalStores(global), visiting, depth)
+	}
+
+	return "", false
+}
+
+// values resolves values which must all be the same string.
+func (r *ssaResolver) values(values []ssa.Value, visiting map[ssa.Value]bool, depth int) (string, bool) {
+	if len(values) == 0 {
+		return "", false
+	}
+	var s string
+	for i, v := range values {
+		x, ok := r.value(v, visiting, depth)
+		if !ok || i > 0 && x != s {
+			return "", false
+		}
+		s = x
+	}
+	return s, true
+}
+
+// globalStores returns the values stored to a package variable by the
+// functions of the loaded packages.
+func (r *ssaResolver) globalStores(global *ssa.Global) []ssa.Value {
+	r.storesOnce.Do(func() {
+		r.stores = make(map[*ssa.Global][]ssa.Value)
+		for fn := range ssautil.AllFunctions(r.prog) {
+			for _, b := range fn.Blocks {
+				for _, instr := range b.Instrs {
+					if store, ok := instr.(*ssa.Store); ok {
+						if g, ok := store.Addr.(*ssa.Global); ok {
+							r.stores[g] = append(r.stores[g], store.Val)
+						}
+					}
+				}
+			}
+		}
+	})
+	return r.stores[global]
+}
+
+func stringConstant(v constant.Value) (string, bool) {
+	if v.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(v), true
+}
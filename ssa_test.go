@@ -0,0 +1,79 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSSA(t *testing.T) {
+	paths := []string{filepath.Join("testdata", "ssa", "ssa.go")}
+
+	for _, tc := range []struct {
+		ssa      bool
+		expected []string
+		skipped  int
+	}{
+		// Without constant propagation, the function call is not resolved.
+		// Constant propagation does not resolve the package variable, read
+		// before init reassigns it, which is then resolved syntactically.
+		{ssa: false, expected: []string{"acme_requests_count", "batches_total"}, skipped: 2},
+		{ssa: true, expected: []string{"acme_requests_count", "jobs", "batches_total"}, skipped: 1},
+	} {
+		res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{SSA: tc.ssa})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, m := range res.Metrics {
+			names = append(names, m.MetricFamily.GetName())
+		}
+		if !reflect.DeepEqual(names, tc.expected) || res.Skipped != tc.skipped {
+			t.Fatalf("expected metrics %v and %d skipped with ssa=%v, got %v and %d skipped", tc.expected, tc.skipped, tc.ssa, names, res.Skipped)
+		}
+	}
+}
+
+// bothResolvers runs test with the syntactic resolution of the values and with
+// constant propagation, which must resolve at least the same values.
+func bothResolvers(t *testing.T, test func(t *testing.T, ssa bool)) {
+	for _, ssa := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ssa=%v", ssa), func(t *testing.T) { test(t, ssa) })
+	}
+}
+
+func TestSSACgo(t *testing.T) {
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("no C compiler for cgo")
+	}
+	t.Setenv("CGO_ENABLED", "1")
+	paths := []string{filepath.Join("testdata", "cgo", "cgo.go"), filepath.Join("testdata", "cgo", "pure.go")}
+
+	for _, tc := range []struct {
+		ssa      bool
+		expected []string
+	}{
+		{ssa: false, expected: []string{"sensor_reads"}},
+		// The name built by a function is resolved in the output of cgo,
+		// and the positions are those of the source.
+		{ssa: true, expected: []string{"sensor_temperature_celsius_total", "sensor_reads"}},
+	} {
+		res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{SSA: tc.ssa})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, m := range res.Metrics {
+			names = append(names, m.MetricFamily.GetName())
+			if m.Pos.Filename != paths[0] {
+				t.Errorf("expected %s in %s, got %s", m.MetricFamily.GetName(), paths[0], m.Pos)
+			}
+		}
+		if !reflect.DeepEqual(names, tc.expected) {
+			t.Errorf("expected metrics %v with ssa=%v, got %v", tc.expected, tc.ssa, names)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package promlinter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// StagedTree is a copy of the staged version of the packages with staged
+// changes of a git repository, so that a pre-commit hook lints exactly what
+// will be committed, including the files which are only partly staged.
+type StagedTree struct {
+	// Dir is the temporary directory holding the copy.
+	Dir string
+	// Changed holds the paths of the staged Go files, relative to the root
+	// of the repository.
+	Changed []string
+
+	root  string
+	files []string
+}
+
+// CheckoutStaged copies the staged version of the Go files of the packages
+// with staged changes of the git repository of dir to a temporary directory.
+// Deleted files are not copied. Close removes the copy.
+func CheckoutStaged(dir string) (*StagedTree, error) {
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	t := &StagedTree{root: strings.TrimSpace(string(root))}
+
+	changed, err := git(t.root, "diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z", "--", "*.go")
+	if err != nil {
+		return nil, err
+	}
+	t.Changed = splitNull(changed)
+	dirs := make(map[string]bool)
+	for _, f := range t.Changed {
+		dirs[path.Dir(f)] = true
+	}
+
+	// The other files of the packages are copied from the index too, since
+	// the analysis needs whole packages.
+	indexed, err := git(t.root, "ls-files", "-z", "--", "*.go")
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range splitNull(indexed) {
+		if dirs[path.Dir(f)] {
+			t.files = append(t.files, f)
+		}
+	}
+
+	if t.Dir, err = os.MkdirTemp("", "promlinter-staged-"); err != nil {
+		return nil, err
+	}
+	if len(t.files) == 0 {
+		return t, nil
+	}
+	cmd := exec.Command("git", "checkout-index", "-z", "--stdin", "--prefix="+t.Dir+string(filepath.Separator))
+	cmd.Dir = t.root
+	cmd.Stdin = strings.NewReader(strings.Join(t.files, "\x00"))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Close()
+		return nil, fmt.Errorf("git checkout-index: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return t, nil
+}
+
+// Paths returns the paths of the copies of the files of the packages.
+func (t *StagedTree) Paths() []string {
+	paths := make([]string, 0, len(t.files))
+	for _, f := range t.files {
+		paths = append(paths, filepath.Join(t.Dir, filepath.FromSlash(f)))
+	}
+	return paths
+}
+
+// Restore returns the issues of the staged files, with the paths of the
+// copies replaced by those of the files in the working tree, see
+// RestorePath. The issues of the other files of the packages are dropped.
+func (t *StagedTree) Restore(issues []Issue) []Issue {
+	restore := func(filename string) string {
+		path, _ := t.RestorePath(filename)
+		return path
+	}
+	res := make([]Issue, 0, len(issues))
+	for _, iss := range issues {
+		if _, staged := t.RestorePath(iss.Pos.Filename); staged {
+			res = append(res, iss.MapFilenames(restore))
+		}
+	}
+	return res
+}
+
+// RestorePath returns the path of the file in the working tree of which
+// filename is the copy, relative to the working directory if possible, and
+// whether the file is staged. The paths outside of the copy are returned
+// unchanged.
+func (t *StagedTree) RestorePath(filename string) (string, bool) {
+	rel, err := filepath.Rel(t.Dir, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filename, false
+	}
+	staged := false
+	for _, f := range t.Changed {
+		staged = staged || f == filepath.ToSlash(rel)
+	}
+	abs := filepath.Join(t.root, rel)
+	if wd, err := os.Getwd(); err == nil {
+		if r, err := filepath.Rel(wd, abs); err == nil {
+			return r, staged
+		}
+	}
+	return abs, staged
+}
+
+// Close removes the copy.
+func (t *StagedTree) Close() error {
+	return os.RemoveAll(t.Dir)
+}
+
+// git runs git with args in dir and returns its output.
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return out, nil
+}
+
+func splitNull(out []byte) []string {
+	var fields []string
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,84 @@
+package promlinter
+
+import (
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckoutStaged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(name, src string) {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	counter := func(name string) string {
+		return "package app\n\nimport \"github.com/prometheus/client_golang/prometheus\"\n\nvar c = prometheus.NewCounter(prometheus.CounterOpts{Name: \"" + name + "\", Help: \"Help.\"})\n"
+	}
+
+	run("init", "-q")
+	write("app/a.go", counter("a_total"))
+	write("app/b.go", counter("b_total"))
+	write("other/c.go", "package other\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+
+	// The staged version of a.go has an issue which the working tree fixes,
+	// and the change of b.go is not staged.
+	write("app/a.go", counter("a_requests"))
+	run("add", "app/a.go")
+	write("app/a.go", counter("a_requests_total"))
+	write("app/b.go", counter("b_requests"))
+
+	tree, err := CheckoutStaged(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+
+	if expected := []string{"app/a.go"}; !reflect.DeepEqual(tree.Changed, expected) {
+		t.Errorf("expected the staged files %v, got %v", expected, tree.Changed)
+	}
+	if paths := tree.Paths(); len(paths) != 2 {
+		t.Errorf("expected the files of the package of the staged file, got %v", paths)
+	}
+
+	res, err := AnalyzeFiles(token.NewFileSet(), tree.Paths(), Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, iss := range tree.Restore(res.Issues) {
+		rel, err := filepath.Rel(repo, iss.Pos.Filename)
+		if !filepath.IsAbs(iss.Pos.Filename) {
+			wd, _ := os.Getwd()
+			rel, err = filepath.Rel(repo, filepath.Join(wd, iss.Pos.Filename))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, filepath.ToSlash(rel)+" "+iss.RuleID+" "+iss.Metric)
+	}
+	if expected := []string{"app/a.go " + RuleCounter + " a_requests"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the issues of the staged version of a.go, got %v", got)
+	}
+}
@@ -0,0 +1,100 @@
+package promlinter
+
+import (
+	"go/token"
+)
+
+// StreamResult is the outcome of AnalyzeStream.
+type StreamResult struct {
+	Files     int
+	Skipped   int
+	Truncated bool
+	// SyntaxErrors holds the syntax errors of files which were analyzed
+	// partially.
+	SyntaxErrors []SyntaxError
+	// Inventory holds the compact description of every metric discovered.
+	Inventory *Inventory
+}
+
+// AnalyzeStream analyzes the Go files at paths one package at a time and
+// calls fn with the issues of each package as soon as it is analyzed, then
+// once more with the issues of the checks of the whole module, such as dead
+// metrics, unless the analysis stopped early.
+//
+// Unlike AnalyzeFiles, ASTs and file sets are released after each package;
+// only the metrics and the sites using them are kept for the module checks,
+// which keeps the memory usage low on large monorepos. Setting.Concurrency
+// and Setting.Deduplicate are ignored.
+func AnalyzeStream(paths []string, setting Setting, fn func(issues []Issue) error) (*StreamResult, error) {
+	var (
+		res    = &StreamResult{}
+		state  = &partialResult{metrics: make([]MetricFamilyWithPos, 0), references: make(map[string]bool), registrations: make(map[string][]token.Position), generated: make(map[string]bool)}
+		issues = 0
+	)
+	// emit passes the issues to fn, truncated to Setting.MaxIssues.
+	emit := func(pkgIssues []Issue) error {
+		setting.formatMessages(pkgIssues)
+		sortIssues(pkgIssues)
+		if limit := setting.MaxIssues; limit > 0 && issues+len(pkgIssues) >= limit {
+			pkgIssues = pkgIssues[:limit-issues]
+			res.Truncated = true
+		}
+		issues += len(pkgIssues)
+		return fn(pkgIssues)
+	}
+
+	for _, pkg := range groupByPackage(len(paths), func(i int) string { return paths[i] }) {
+		pkgPaths := make([]string, 0, len(pkg))
+		for _, f := range pkg {
+			pkgPaths = append(pkgPaths, paths[f])
+		}
+
+		var resolver *ssaResolver
+		if setting.SSA {
+			var err error
+			if resolver, err = loadSSA(pkgPaths, setting); err != nil {
+				return nil, err
+			}
+		}
+
+		merged, err := analyzePackage(token.NewFileSet(), pkgPaths, setting, resolver)
+		if err != nil {
+			return nil, err
+		}
+
+		if setting.Workspace != nil {
+			setting.Workspace.attribute(merged.issues)
+		}
+		if err := emit(merged.issues); err != nil {
+			return nil, err
+		}
+		merged.issues = nil
+		state.merge(merged)
+		if res.Truncated {
+			break
+		}
+	}
+
+	if !res.Truncated {
+		moduleIssues := runModuleChecks(state, setting)
+		if ws := setting.Workspace; ws != nil {
+			ws.attribute(moduleIssues)
+			for _, iss := range ws.duplicates(state.metrics) {
+				if setting.apply(&iss) && setting.applyGenerated(&iss, state.generated) {
+					moduleIssues = append(moduleIssues, iss)
+				}
+			}
+		}
+		if len(moduleIssues) > 0 {
+			if err := emit(moduleIssues); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	res.Files = state.files
+	res.Skipped = state.skipped
+	res.SyntaxErrors = state.syntaxErrors
+	res.Inventory = NewInventory(state.metrics)
+	return res, nil
+}
@@ -0,0 +1,90 @@
+package promlinter
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeStream(t *testing.T) {
+	var issues []Issue
+	res, err := AnalyzeStream([]string{"testdata/testdata.go"}, Setting{}, func(pkgIssues []Issue) error {
+		issues = append(issues, pkgIssues...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(issues) != 2 || issues[0].RuleID != RuleCounter || issues[1].RuleID != RuleHelp {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	if res.Files != 1 || len(res.Inventory.Metrics) != 5 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	issues = nil
+	res, err = AnalyzeStream([]string{"testdata/testdata.go"}, Setting{MaxIssues: 1}, func(pkgIssues []Issue) error {
+		issues = append(issues, pkgIssues...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || !res.Truncated {
+		t.Fatalf("expected the analysis to stop after 1 issue, got %+v", issues)
+	}
+}
+
+// TestAnalyzeStreamParity checks that the streamed analysis of each directory
+// of testdata reports the same issues as AnalyzeFiles, including those of the
+// module checks.
+func TestAnalyzeStreamParity(t *testing.T) {
+	entries, err := os.ReadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join("testdata", e.Name())
+		t.Run(e.Name(), func(t *testing.T) {
+			paths, err := goFiles(dir, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(paths) == 0 {
+				t.Skip("no Go files")
+			}
+			setting := Setting{SeriesBudget: 10}
+			if err := setting.ApplyPreset("all"); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := AnalyzeFiles(token.NewFileSet(), paths, setting)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var streamed []Issue
+			if _, err := AnalyzeStream(paths, setting, func(pkgIssues []Issue) error {
+				streamed = append(streamed, pkgIssues...)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			sortIssues(streamed)
+
+			if len(streamed) != len(res.Issues) {
+				t.Fatalf("expected the %d issues of AnalyzeFiles, got %d:\n%v\n%v", len(res.Issues), len(streamed), res.Issues, streamed)
+			}
+			for i := range streamed {
+				if !reflect.DeepEqual(streamed[i], res.Issues[i]) {
+					t.Fatalf("expected the issue %+v, got %+v", res.Issues[i], streamed[i])
+				}
+			}
+		})
+	}
+}
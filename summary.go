@@ -0,0 +1,111 @@
+package promlinter
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+// maxSummaryPackages is the number of worst offending packages kept in a
+// Summary.
+const maxSummaryPackages = 5
+
+// Summary holds statistics about a run.
+type Summary struct {
+	Metrics        int            `json:"metrics"`
+	MetricsByType  map[string]int `json:"metrics_by_type"`
+	Issues         int            `json:"issues"`
+	IssuesByRule   map[string]int `json:"issues_by_rule"`
+	WorstPackages  []PackageCount `json:"worst_packages"`
+	SkippedMetrics int            `json:"skipped_metrics"`
+}
+
+// PackageCount is the number of issues found in a package directory.
+type PackageCount struct {
+	Package string `json:"package"`
+	Issues  int    `json:"issues"`
+}
+
+// Summarize computes the statistics of a result. Issues can be a filtered
+// subset of the result issues.
+func Summarize(res *Result, issues []Issue) *Summary {
+	types := make([]string, 0, len(res.Metrics))
+	for _, m := range res.Metrics {
+		types = append(types, metricTypeName(m.MetricFamily.GetType()))
+	}
+	return newSummary(types, res.Skipped, issues)
+}
+
+// Summarize computes the statistics of a streamed analysis. Issues are the
+// issues passed to the callback of AnalyzeStream.
+func (res *StreamResult) Summarize(issues []Issue) *Summary {
+	types := make([]string, 0, len(res.Inventory.Metrics))
+	for _, m := range res.Inventory.Metrics {
+		types = append(types, m.Type)
+	}
+	return newSummary(types, res.Skipped, issues)
+}
+
+func newSummary(metricTypes []string, skipped int, issues []Issue) *Summary {
+	s := &Summary{
+		Metrics:        len(metricTypes),
+		MetricsByType:  make(map[string]int),
+		Issues:         len(issues),
+		IssuesByRule:   make(map[string]int),
+		WorstPackages:  make([]PackageCount, 0),
+		SkippedMetrics: skipped,
+	}
+
+	for _, t := range metricTypes {
+		s.MetricsByType[t]++
+	}
+
+	packages := make(map[string]int)
+	for _, iss := range issues {
+		s.IssuesByRule[iss.RuleID]++
+		packages[filepath.Dir(iss.Pos.Filename)]++
+	}
+	for pkg, count := range packages {
+		s.WorstPackages = append(s.WorstPackages, PackageCount{Package: pkg, Issues: count})
+	}
+	sort.Slice(s.WorstPackages, func(i, j int) bool {
+		if s.WorstPackages[i].Issues == s.WorstPackages[j].Issues {
+			return s.WorstPackages[i].Package < s.WorstPackages[j].Package
+		}
+		return s.WorstPackages[i].Issues > s.WorstPackages[j].Issues
+	})
+	if len(s.WorstPackages) > maxSummaryPackages {
+		s.WorstPackages = s.WorstPackages[:maxSummaryPackages]
+	}
+
+	return s
+}
+
+// Print writes the summary in a human-readable form.
+func (s *Summary) Print(w io.Writer) {
+	fmt.Fprintf(w, "metrics discovered: %d\n", s.Metrics)
+	for _, k := range sortedKeys(s.MetricsByType) {
+		fmt.Fprintf(w, "  %s: %d\n", k, s.MetricsByType[k])
+	}
+	fmt.Fprintf(w, "metrics skipped: %d\n", s.SkippedMetrics)
+	fmt.Fprintf(w, "issues: %d\n", s.Issues)
+	for _, k := range sortedKeys(s.IssuesByRule) {
+		fmt.Fprintf(w, "  %s: %d\n", k, s.IssuesByRule[k])
+	}
+	if len(s.WorstPackages) > 0 {
+		fmt.Fprintf(w, "worst packages:\n")
+		for _, p := range s.WorstPackages {
+			fmt.Fprintf(w, "  %s: %d\n", p.Package, p.Issues)
+		}
+	}
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,28 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "./testdata/testdata.go", nil, parser.AllErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := Analyze(fs, []*ast.File{file}, Setting{})
+	s := Summarize(res, res.Issues)
+	if s.Metrics != 5 || s.MetricsByType["counter"] != 4 || s.MetricsByType["gauge"] != 1 {
+		t.Fatalf("unexpected metrics summary %+v", s)
+	}
+	if s.Issues != 2 || s.IssuesByRule[RuleHelp] != 1 || s.IssuesByRule[RuleCounter] != 1 {
+		t.Fatalf("unexpected issues summary %+v", s)
+	}
+	if len(s.WorstPackages) != 1 || s.WorstPackages[0] != (PackageCount{Package: "testdata", Issues: 2}) {
+		t.Fatalf("unexpected packages summary %+v", s.WorstPackages)
+	}
+}
@@ -0,0 +1,29 @@
+package annotations
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	// promlinter:label-values status=ok,error,timeout
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Requests.",
+	}, []string{"method", "status"})
+
+	jobs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jobs_total",
+		Help: "Jobs.",
+	}, []string{"queue"}) // promlinter:label-values queue=default,batch kind=a,b
+)
+
+func handle(method string, err error) {
+	status := "ok"
+	if err != nil {
+		status = "eror"
+	}
+	requests.WithLabelValues(method, status).Inc()
+	requests.With(prometheus.Labels{"method": method, "status": "timeout"}).Inc()
+	requests.WithLabelValues(method, label()).Inc()
+	jobs.WithLabelValues("default").Inc()
+}
+
+func label() string { return "ok" }
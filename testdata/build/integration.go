@@ -0,0 +1,13 @@
+//go:build integration
+
+package build
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var integrationRuns = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "integration_runs_total",
+	Help: "Number of integration runs.",
+})
@@ -0,0 +1,11 @@
+package build
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var windowsMetric = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "windows_open_handles",
+	Help: "Number of open handles.",
+})
@@ -0,0 +1,29 @@
+package cgo
+
+/*
+#include <stdlib.h>
+
+static int temperature(void) { return 42; }
+*/
+import "C"
+
+import "github.com/prometheus/client_golang/prometheus"
+
+func name() string {
+	prefix := "sensor"
+	return prefix + "_temperature_celsius_total"
+}
+
+var temperature = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: name(),
+	Help: "Temperature of the sensor.",
+})
+
+func update() {
+	temperature.Set(float64(C.temperature()))
+}
+
+var reads = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "sensor_reads",
+	Help: "Reads of the sensor.",
+})
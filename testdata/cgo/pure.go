@@ -0,0 +1,5 @@
+package cgo
+
+func init() {
+	reads.Inc()
+}
@@ -0,0 +1,31 @@
+package collisions
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	latency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "latency_seconds",
+		Help: "Latency.",
+	})
+	latencyCount = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "latency_seconds_count",
+		Help: "Observed latencies.",
+	})
+	latencyBuckets = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "latency_seconds_bucket",
+		Help: "Buckets.",
+	})
+	rpc = prometheus.NewSummary(prometheus.SummaryOpts{
+		Name: "rpc_duration_seconds",
+		Help: "Duration of the RPCs.",
+	})
+	rpcSum = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "rpc_duration_seconds_sum",
+		Help: "Total duration of the RPCs.",
+	})
+	// Summaries have no buckets.
+	rpcBuckets = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "rpc_duration_seconds_bucket",
+		Help: "Buckets.",
+	})
+)
@@ -0,0 +1,24 @@
+package collisions
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	queued = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "jobs_queued_count",
+		Help: "Queued jobs.",
+	})
+	rpcSize = prometheus.NewSummary(prometheus.SummaryOpts{
+		Name: "rpc_size_sum",
+		Help: "Size of the RPCs.",
+	})
+	// Reported by promlint.
+	requests = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "requests_count",
+		Help: "Requests.",
+	})
+	legacyDesc = prometheus.NewDesc("legacy_latency_bucket", "Legacy buckets.", nil, nil)
+)
+
+func collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(legacyDesc, prometheus.UntypedValue, 1)
+}
@@ -0,0 +1,31 @@
+package constlabels
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var labels = prometheus.Labels{"instance": "primary", "component": "api"}
+
+var (
+	job = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "requests_total",
+		Help:        "Requests.",
+		ConstLabels: prometheus.Labels{"job": "api"},
+	})
+	variable = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "queue_length",
+		Help:        "Length of the queue.",
+		ConstLabels: labels,
+	})
+	region = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "up",
+		Help:        "Up.",
+		ConstLabels: map[string]string{"region": "eu"},
+	})
+)
+
+var desc = prometheus.NewDesc("build_info", "Build.", nil, prometheus.Labels{"instance": "x", "version": "1.0"})
+
+type collector struct{}
+
+func (collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1)
+}
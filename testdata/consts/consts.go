@@ -0,0 +1,38 @@
+package consts
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type unit string
+
+const (
+	namespace = "acme"
+	subsystem = "api"
+	// Implicitly repeats "api".
+	component
+	seconds unit = "seconds"
+	name         = "request_duration_" + string(seconds)
+)
+
+type kind int
+
+const (
+	kindRead kind = iota
+	kindWrite
+)
+
+var kindNames = [...]string{kindRead: "read", kindWrite: "write"}
+
+var _ = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Subsystem: component,
+	Name:      name,
+	Help:      "Duration of requests.",
+})
+
+var _ = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      kindNames[kindWrite] + "s_total",
+	Help:      "Number of writes.",
+})
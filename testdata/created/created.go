@@ -0,0 +1,36 @@
+package created
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	jobsDesc     = prometheus.NewDesc("acme_jobs_total", "Jobs run.", nil, nil)
+	errorsDesc   = prometheus.NewDesc("acme_errors_total", "Jobs failed.", nil, nil)
+	retriesDesc  = prometheus.NewDesc("acme_retries_total", "Jobs retried.", nil, nil)
+	queueDesc    = prometheus.NewDesc("acme_queue_length", "Jobs queued.", nil, nil)
+	scrapesDesc  = prometheus.NewDesc("exporter_scrapes_total", "Scrapes.", nil, nil)
+	failuresDesc = prometheus.NewDesc("exporter_failures_total", "Scrapes failed.", nil, nil)
+)
+
+// requests is a counter of the constructors, which tracks its created
+// timestamp itself.
+var requests = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "acme_requests_total",
+	Help: "Requests.",
+})
+
+type collector struct {
+	start time.Time
+}
+
+func (c collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetricWithCreatedTimestamp(jobsDesc, prometheus.CounterValue, 10, c.start)
+	ch <- prometheus.MustNewConstMetricWithCreatedTimestamp(errorsDesc, prometheus.CounterValue, 1, c.start)
+	ch <- prometheus.MustNewConstMetric(retriesDesc, prometheus.CounterValue, 2)
+	ch <- prometheus.MustNewConstMetricWithCreatedTimestamp(queueDesc, prometheus.GaugeValue, 3, c.start)
+	ch <- prometheus.MustNewConstMetric(scrapesDesc, prometheus.CounterValue, 5)
+	ch <- prometheus.MustNewConstMetricWithCreatedTimestamp(failuresDesc, prometheus.CounterValue, 0, c.start)
+}
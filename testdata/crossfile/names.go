@@ -0,0 +1,8 @@
+package crossfile
+
+const (
+	namespace = "acme"
+	help      = "Number of requests."
+)
+
+var labels = []string{"code"}
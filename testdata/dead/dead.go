@@ -0,0 +1,36 @@
+package dead
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requests = promauto.NewCounter(prometheus.CounterOpts{Name: "requests_total", Help: "Number of requests."})
+	retries  = promauto.NewCounter(prometheus.CounterOpts{Name: "retries_total", Help: "Number of retries."})
+	errors   = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "errors_total", Help: "Number of errors."}, []string{"code"})
+	latency  = prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_seconds", Help: "Latency."})
+	pending  = prometheus.NewGauge(prometheus.GaugeOpts{Name: "pending", Help: "Not registered."})
+
+	// Exported holders may be used by packages which were not analyzed.
+	Exported = promauto.NewCounter(prometheus.CounterOpts{Name: "exported_total", Help: "Exported."})
+)
+
+type metrics struct {
+	inflight prometheus.Gauge
+	queued   prometheus.Gauge
+}
+
+func init() {
+	prometheus.MustRegister(errors, latency)
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		inflight: promauto.With(reg).NewGauge(prometheus.GaugeOpts{Name: "inflight", Help: "In-flight requests."}),
+		queued:   promauto.With(reg).NewGauge(prometheus.GaugeOpts{Name: "queued", Help: "Queued requests."}),
+	}
+	local := prometheus.NewCounter(prometheus.CounterOpts{Name: "local_total", Help: "Local."})
+	reg.MustRegister(local)
+	return m
+}
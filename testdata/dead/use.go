@@ -0,0 +1,13 @@
+package dead
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func handle(m *metrics, code string) {
+	requests.Inc()
+	errors.WithLabelValues(code).Inc()
+	timer := prometheus.NewTimer(latency)
+	defer timer.ObserveDuration()
+	m.inflight.Inc()
+}
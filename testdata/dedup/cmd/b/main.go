@@ -0,0 +1,18 @@
+package main
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var jobs = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "dedup_jobs",
+	Help: "Jobs processed.",
+})
+
+var tasks = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "dedup_tasks",
+	Help: "Tasks of b.",
+})
+
+func main() {
+	jobs.Inc()
+	tasks.Inc()
+}
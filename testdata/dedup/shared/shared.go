@@ -0,0 +1,10 @@
+package shared
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var opts = prometheus.CounterOpts{Name: "dedup_completed", Help: "Completed tasks."}
+
+var (
+	primary   = prometheus.NewCounter(opts)
+	secondary = prometheus.NewCounter(opts)
+)
@@ -0,0 +1,12 @@
+package auto
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	. "github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requests = NewCounter(prometheus.CounterOpts{Name: "auto_requests_total", Help: "Number of requests."})
+	factory  = With(prometheus.NewRegistry())
+	jobs     = factory.NewCounterVec(prometheus.CounterOpts{Name: "auto_jobs_total", Help: "Number of jobs."}, []string{"queue"})
+)
@@ -0,0 +1,15 @@
+package prom
+
+import (
+	. "github.com/prometheus/client_golang/prometheus"
+)
+
+// NewGauge shadows the constructor of the dot-imported package.
+func NewGauge(name string) Gauge {
+	return nil
+}
+
+var (
+	requests = NewCounter(CounterOpts{Name: "prom_requests_total", Help: "Number of requests."})
+	queue    = NewGauge("queue_length")
+)
@@ -0,0 +1,13 @@
+package drift
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "drift_requests_total",
+	Help: "Requests by code and method.",
+}, []string{"code", "method"})
+
+var inflight = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "drift_inflight_requests",
+	Help: "Requests in flight.",
+})
@@ -0,0 +1,21 @@
+package drift
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRequests(t *testing.T) {
+	// The fixture lost the method label.
+	expected := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "drift_requests_total",
+		Help: "Requests by code.",
+	}, []string{"code"})
+	// Same definition as in production.
+	same := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "drift_inflight_requests",
+		Help: "Requests in flight.",
+	})
+	_, _ = expected, same
+}
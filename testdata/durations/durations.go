@@ -0,0 +1,37 @@
+package durations
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name: "request_duration_seconds",
+		Help: "Duration of requests.",
+	})
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "query_duration_milliseconds",
+		Help: "Duration of queries.",
+	}, []string{"table"})
+	waitTime = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wait_seconds_total",
+		Help: "Time spent waiting.",
+	})
+	lockDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name: "lock_duration_seconds",
+		Help: "Duration of locks.",
+	})
+)
+
+func handle(start time.Time, wait time.Duration) {
+	requestDuration.Observe(float64(time.Since(start).Milliseconds()))
+	queryDuration.WithLabelValues("users").Observe(time.Since(start).Seconds())
+
+	ms := wait.Milliseconds()
+	waitTime.Add(float64(ms))
+
+	lockDuration.Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,38 @@
+package exemplars
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "acme_requests_total",
+		Help: "Requests served.",
+	}, []string{"code"})
+	latency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "acme_request_duration_seconds",
+		Help: "Duration of the requests.",
+	})
+	queueLatency = prometheus.NewSummary(prometheus.SummaryOpts{
+		Name: "acme_queue_duration_seconds",
+		Help: "Time spent in the queue.",
+	})
+	inFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "acme_requests_in_flight",
+		Help: "Requests in flight.",
+	})
+)
+
+func record(code, traceID, spanID, userAgent, path string, start time.Time) {
+	requests.WithLabelValues(code).(prometheus.ExemplarAdder).AddWithExemplar(1, prometheus.Labels{"trace_id": traceID})
+	latency.(prometheus.ExemplarObserver).ObserveWithExemplar(time.Since(start).Seconds(), prometheus.Labels{"trace_id": traceID, "span_id": spanID})
+	queueLatency.(prometheus.ExemplarObserver).ObserveWithExemplar(time.Since(start).Seconds(), nil)
+	inFlight.(prometheus.ExemplarAdder).AddWithExemplar(1, nil)
+
+	labels := prometheus.Labels{"trace_id": traceID, "span_id": spanID, "user_agent": userAgent, "request_path": path}
+	latency.(prometheus.ExemplarObserver).ObserveWithExemplar(-1, labels)
+	requests.WithLabelValues(code).(prometheus.ExemplarAdder).AddWithExemplar(1, prometheus.Labels{"trace-id": traceID})
+	requests.WithLabelValues(code).(prometheus.ExemplarAdder).AddWithExemplar(1, prometheus.Labels{"note": "a constant exemplar value much longer than the one hundred and twenty eight runes allowed by client_golang for the names and the values of the labels of an exemplar"})
+}
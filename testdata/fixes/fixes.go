@@ -0,0 +1,35 @@
+package fixes
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	latency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "request_latency_milliseconds",
+		Help: "Latency of the requests.",
+	})
+	cacheSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "acme",
+		Name:      "cache_size_kilobytes",
+		Help:      "Size of the cache.",
+	})
+	sent = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "sent_kilobytes_total",
+		Help: "Sent data.",
+	})
+	uptime = prometheus.NewDesc("uptime_hours", "Uptime.", nil, nil)
+)
+
+func observe(start time.Time, size float64) {
+	latency.Observe(float64(time.Since(start).Milliseconds()))
+	latency.Observe(12)
+	cacheSize.Set(size)
+	sent.Inc()
+}
+
+func collect(ch chan<- prometheus.Metric, hours float64) {
+	ch <- prometheus.MustNewConstMetric(uptime, prometheus.GaugeValue, hours)
+}
@@ -0,0 +1,57 @@
+package flow
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func reassigned() {
+	name := "requests"
+	name = "requests_total"
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: name, Help: "Number of requests."})
+}
+
+func appended() {
+	name := "retries"
+	name += "_total"
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: name, Help: "Number of retries."})
+}
+
+func shadowed() {
+	name := "jobs_total"
+	if true {
+		name := "ignored_total"
+		_ = name
+	}
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: name, Help: "Number of jobs."})
+}
+
+func fields() {
+	opts := prometheus.GaugeOpts{Name: "temperature"}
+	opts.Name = "temperature_celsius"
+	opts.Help = "Temperature."
+	_ = prometheus.NewGauge(opts)
+}
+
+func conflicting(verbose bool) {
+	name := "queue_length"
+	if verbose {
+		name = "queue_size"
+	}
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: "Length of the queue."})
+}
+
+func conflictingFields(verbose bool) {
+	opts := prometheus.GaugeOpts{Name: "pool_size", Help: "Size of the pool."}
+	if verbose {
+		opts.Name = "pool_capacity"
+	}
+	_ = prometheus.NewGauge(opts)
+}
+
+func loop(names []string) {
+	name := "batches_total"
+	for range names {
+		_ = prometheus.NewCounter(prometheus.CounterOpts{Name: name, Help: "Number of batches."})
+		name = "batches_count"
+	}
+}
@@ -0,0 +1,22 @@
+package goversion
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type registry[T prometheus.Collector] struct {
+	collectors []T
+}
+
+var requests = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "goversion_requests_total",
+	Help: "Requests.",
+})
+
+func each(yield func(int) bool) {
+	yield(1)
+}
+
+func count() {
+	for range each {
+		requests.Inc()
+	}
+}
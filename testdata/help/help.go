@@ -0,0 +1,37 @@
+package help
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	restated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "foo_total",
+		Help: "foo_total counts foo total",
+	})
+	restatedPlural = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests.",
+	})
+	described = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "jobs_processed_total",
+		Help: "Jobs taken from the queue whose handler returned, whatever the outcome.",
+	})
+)
+
+var (
+	withoutUnit = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "request_duration_seconds",
+		Help: "Duration of the requests.",
+	})
+	withUnit = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "read_bytes_total",
+		Help: "Bytes read from the disk.",
+	})
+	celsius = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "temperature_celsius",
+		Help: "Temperature of the CPU in degrees Celsius.",
+	})
+	percent = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "disk_usage_percent",
+		Help: "Used space of the disk, in %.",
+	})
+)
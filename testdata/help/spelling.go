@@ -0,0 +1,14 @@
+package help
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	typo = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "messages_received_total",
+		Help: "Messages recieved from the broker's queue.",
+	})
+	jargon = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_handled_total",
+		Help: "Requests handled by the HTTP kubelet, per ObserveDuration code.",
+	}, []string{"code"})
+)
@@ -0,0 +1,26 @@
+package hierarchy
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	placed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "acme",
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Requests.",
+	})
+	wrongSubsystem = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "acme",
+		Subsystem: "cache",
+		Name:      "hits_total",
+		Help:      "Hits.",
+	})
+	noNamespace = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "queue_length",
+		Help: "Length of the queue.",
+	})
+	anySubsystem = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "legacy_jobs_running",
+		Help: "Running jobs.",
+	})
+)
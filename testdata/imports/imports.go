@@ -0,0 +1,33 @@
+package imports
+
+import (
+	"example.com/other/metrics"
+	prom "example.com/platform/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// A fork of client_golang, only linted if configured.
+	forked = prom.NewCounter(prom.CounterOpts{
+		Name: "forked_requests",
+		Help: "Number of requests.",
+	})
+
+	// Another metrics library, never linted.
+	other = metrics.NewCounter(metrics.CounterOpts{
+		Name: "other_requests",
+	})
+
+	// A wrapper taking prometheus options, always linted.
+	wrapped = metrics.NewCounter(prometheus.CounterOpts{
+		Name: "wrapped_requests",
+		Help: "Number of requests.",
+	})
+)
+
+// NewGauge is not a prometheus constructor.
+func NewGauge(opts prometheus.GaugeOpts) {}
+
+func init() {
+	NewGauge(prometheus.GaugeOpts{Name: "local_gauge_total"})
+}
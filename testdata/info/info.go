@@ -0,0 +1,31 @@
+package info
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	buildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "acme_build_info",
+		Help: "Build information.",
+	}, []string{"version", "revision"})
+	versionInfo = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "acme_version_info",
+		Help:        "Version information.",
+		ConstLabels: prometheus.Labels{"version": "1.0"},
+	})
+	configInfo = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "acme_config_info",
+		Help: "Configuration information.",
+	})
+	featureInfo = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "acme_feature_info",
+		Help: "Feature information.",
+	})
+)
+
+func record(version, revision string, enabled float64) {
+	buildInfo.WithLabelValues(version, revision).Set(1)
+	versionInfo.Set(1)
+	configInfo.Inc()
+	featureInfo.Set(2)
+	featureInfo.Set(enabled)
+}
@@ -0,0 +1,60 @@
+package labels
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type status string
+
+const (
+	statusOK     status = "ok"
+	statusFailed status = "failed"
+)
+
+var (
+	queues = []string{"default", "batch"}
+
+	jobsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "jobs_processed_total",
+		Help: "Processed jobs.",
+	}, []string{"queue", "status"})
+	responses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "responses_total",
+		Help: "Responses.",
+	}, []string{"code", "path"})
+	latency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "job_latency_seconds",
+		Help:    "Latency of jobs.",
+		Buckets: []float64{0.1, 1, 10},
+	}, []string{"queue"})
+	cacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_hits_total",
+		Help: "Cache hits.",
+	}, []string{"cache"})
+)
+
+func finish(queue string, s status) {
+	for _, q := range queues {
+		jobsProcessed.WithLabelValues(q, string(s)).Add(0)
+		latency.WithLabelValues(q).Observe(1)
+	}
+}
+
+func respond(code int, path string) {
+	label := "other"
+	switch code {
+	case 200:
+		label = "ok"
+	case 404:
+		label = "not_found"
+	}
+	responses.WithLabelValues(label, path).Inc()
+}
+
+func hit(cache string) {
+	switch cache {
+	case "users", "groups":
+		cacheHits.WithLabelValues(cache).Inc()
+	}
+}
@@ -0,0 +1,41 @@
+package labels
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const service = "api"
+
+var (
+	requests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Requests.",
+	}, []string{"service", "code"})
+	queries = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "query_duration_seconds",
+		Help: "Duration of queries.",
+	}, []string{"db", "table"})
+	jobs = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jobs",
+		Help: "Jobs.",
+	}, []string{"queue"})
+	errors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "errors_total",
+		Help: "Errors.",
+	}, []string{"kind"})
+)
+
+func handle(code, table string, labels prometheus.Labels) {
+	requests.WithLabelValues(service, code).Inc()
+	requests.WithLabelValues("api", "500").Inc()
+
+	queries.With(prometheus.Labels{"db": "main", "table": table}).Observe(1)
+	queries.WithLabelValues("main", "users").Observe(1)
+
+	jobs.WithLabelValues("default").Set(1)
+	jobs.With(labels).Set(2)
+
+	errors.WithLabelValues("timeout").Inc()
+	errors.WithLabelValues("canceled").Inc()
+}
@@ -0,0 +1,45 @@
+package loops
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func queues(names []string) {
+	for _, queue := range names {
+		_ = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: queue + "_length",
+			Help: "Length of the queue.",
+		})
+	}
+}
+
+func shards(n int) {
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("shard_%d_requests_total", i)
+		opts := prometheus.CounterOpts{Help: "Number of requests."}
+		opts.Name = name
+		_ = prometheus.NewCounter(opts)
+	}
+}
+
+func descs(ch chan<- prometheus.Metric, names []string) {
+	for _, name := range names {
+		desc := prometheus.NewDesc(name+"_up", "Whether the target is up.", nil, nil)
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1)
+	}
+}
+
+func static(names []string) {
+	for range names {
+		_ = prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "loops_total",
+			Help: "Number of loops.",
+		})
+	}
+}
+
+func unresolved(name func() string) {
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: name(), Help: "Unresolved."})
+}
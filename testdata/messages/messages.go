@@ -0,0 +1,17 @@
+package messages
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requests = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "message_requests",
+		Help: "Requests.",
+	})
+	queueLength = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "messageQueueLength",
+		Help: "Length of the queue.",
+	})
+	errors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "message_errors_total",
+	})
+)
@@ -0,0 +1,41 @@
+package methods
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type Config struct {
+	Namespace string
+	Subsystem string
+}
+
+func (c *Config) counterOpts(name string) prometheus.CounterOpts {
+	return prometheus.CounterOpts{
+		Namespace: c.Namespace,
+		Subsystem: c.Subsystem,
+		Name:      name + "_total",
+		Help:      "Number of " + name + ".",
+	}
+}
+
+func (c Config) gaugeOpts() prometheus.GaugeOpts {
+	opts := prometheus.GaugeOpts{Namespace: c.Namespace, Help: "Size of the pool."}
+	opts.Name = "pool_size"
+	return opts
+}
+
+func newMetrics() {
+	cfg := &Config{Namespace: "acme", Subsystem: "api"}
+	_ = prometheus.NewCounter(cfg.counterOpts("requests"))
+
+	worker := Config{Namespace: "worker"}
+	worker.Subsystem = "queue"
+	_ = prometheus.NewCounter(worker.counterOpts("jobs"))
+	_ = prometheus.NewGauge(worker.gaugeOpts())
+
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: cfg.Namespace,
+		Name:      "up",
+		Help:      "Whether the service is up.",
+	})
+}
@@ -0,0 +1,18 @@
+package names
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	dotted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "http.requests_total",
+		Help: "Requests.",
+	})
+	legacy = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Requests.",
+	})
+	dashed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "queue_length",
+		Help: "Length of the queue.",
+	}, []string{"queue-name"})
+)
@@ -0,0 +1,37 @@
+package otel
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_server_requests_total",
+		Help: "Requests served.",
+	}, []string{"code"})
+	duration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "rpc_duration_milliseconds",
+		Help: "Duration of the calls.",
+	})
+	queue = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "jobs_queue_length_count",
+		Help: "Jobs queued.",
+	})
+)
+
+const durationUnit = "ms"
+
+var meter = otel.Meter("example")
+
+func instruments() {
+	meter.Int64Counter("http.server.requests", metric.WithDescription("Requests served."))
+	meter.Float64Histogram("rpc.duration", metric.WithUnit(durationUnit))
+	meter.Int64Histogram("jobs.queue.length")
+	meter.Int64UpDownCounter("jobs.in_flight", metric.WithUnit("{job}"))
+	meter.Float64ObservableGauge("jobs-in-flight")
+	meter.Float64Gauge("cpu.utilization", metric.WithUnit("1"))
+	meter.Int64Counter("network.io", metric.WithUnit("By"))
+	meter.Int64Counter("network.io_bytes_total")
+}
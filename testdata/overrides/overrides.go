@@ -0,0 +1,21 @@
+package overrides
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	public = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "public_requests_total",
+		Help: "Public requests total.",
+	})
+	publicNoHelp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "public_up",
+	})
+	legacy = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "legacy_requests",
+		Help: "Requests.",
+	})
+	other = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "other_requests",
+		Help: "Other requests total.",
+	})
+)
@@ -0,0 +1,33 @@
+package partial
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "acme"
+
+func newMetrics(kind string, id int, ch chan<- prometheus.Metric) {
+	_ = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      kind + "_total",
+		Help:      "Number of events.",
+	})
+
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "pool",
+		Name:      "size_" + kind,
+		Help:      "Size of the pool.",
+	})
+
+	name := fmt.Sprintf("worker_%d_busy", id)
+	desc := prometheus.NewDesc(name, "Whether the worker is busy.", nil, nil)
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1)
+
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: kind,
+		Help: "Nothing is resolved.",
+	})
+}
@@ -0,0 +1,8 @@
+package a
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var requests = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "pattern_requests",
+	Help: "Requests.",
+})
@@ -0,0 +1,7 @@
+package a
+
+import "testing"
+
+func TestRequests(t *testing.T) {
+	requests.Inc()
+}
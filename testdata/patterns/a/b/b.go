@@ -0,0 +1,8 @@
+package b
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var errors = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "pattern_errors",
+	Help: "Errors.",
+})
@@ -0,0 +1,10 @@
+//go:build windows
+
+package a
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var services = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "pattern_windows_services",
+	Help: "Windows services.",
+})
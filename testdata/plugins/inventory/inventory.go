@@ -0,0 +1,22 @@
+package inventory
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	buildInfo = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "acme_build_info",
+		Help: "Build of the acme service.",
+	})
+	acmeRequests = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "acme_requests_total",
+		Help: "Requests handled by the acme service.",
+	})
+	billingRequests = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "billing_requests_total",
+		Help: "Requests handled by the billing service.",
+	})
+	billingErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "billing_errors_total",
+		Help: "Errors of the billing service.",
+	})
+)
@@ -0,0 +1,17 @@
+package plugins
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const team = "storage"
+
+var (
+	owned = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "plugin_owned_total",
+		Help:        "Owned.",
+		ConstLabels: prometheus.Labels{"team": team},
+	})
+	unowned = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "plugin_unowned_total",
+		Help: "Unowned.",
+	})
+)
@@ -0,0 +1,29 @@
+package positions
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requests = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "position_requests",
+		Help: "Requests.",
+	})
+	noHelp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "position_queue_length",
+	})
+	labels = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "position_workers",
+		Help: "Workers.",
+	}, []string{"poolName"})
+)
+
+var opts = prometheus.CounterOpts{Name: "position_errors", Help: "Errors."}
+
+var errorsTotal = prometheus.NewCounter(opts)
+
+type collector struct{}
+
+var desc = prometheus.NewDesc("position_jobs", "Jobs.", nil, nil)
+
+func (collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, 1)
+}
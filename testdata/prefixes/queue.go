@@ -0,0 +1,14 @@
+package prefixes
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	latency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "jobs_queue_latency_seconds",
+		Help: "Latency of the queues.",
+	}, []string{"queue"})
+	queueErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "queue_errors_total",
+		Help: "Errors of the queues.",
+	}, []string{"queue"})
+)
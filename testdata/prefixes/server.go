@@ -0,0 +1,19 @@
+package prefixes
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requests = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "foo_requests_total",
+		Help: "Requests.",
+	})
+	duration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "foo",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the requests.",
+	})
+	inFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "requests_in_flight",
+		Help: "Requests in flight.",
+	})
+)
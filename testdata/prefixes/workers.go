@@ -0,0 +1,19 @@
+package prefixes
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	depth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jobs_queue_depth",
+		Help: "Depth of the queues.",
+	}, []string{"queue"})
+	// No prefix is shared by most of the metrics of the file.
+	workers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "workers_busy",
+		Help: "Busy workers.",
+	})
+	uptime = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "process_uptime_seconds",
+		Help: "Uptime.",
+	})
+)
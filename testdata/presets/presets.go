@@ -0,0 +1,17 @@
+package presets
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requests = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "preset_requests",
+		Help: "Requests.",
+	})
+	queueLength = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "presetQueueLength",
+		Help: "Length of the queue.",
+	})
+	errors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "preset_errors_total",
+	})
+)
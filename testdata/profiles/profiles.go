@@ -0,0 +1,20 @@
+package profiles
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	adds = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "workqueue_adds_total",
+		Help: "Items added to the queue.",
+	})
+	retries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "workqueue",
+		Name:      "backoffs_total",
+		Help:      "Items retried with a backoff.",
+	})
+	reconciles = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "acme_operator",
+		Name:      "reconciles_total",
+		Help:      "Reconciliations of the acme resources.",
+	})
+)
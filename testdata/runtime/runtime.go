@@ -0,0 +1,23 @@
+package runtime
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	goroutines = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "go_goroutines",
+		Help: "Goroutines.",
+	})
+	workers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "process",
+		Name:      "workers",
+		Help:      "Workers.",
+	})
+	scrapes = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "promhttp_scrapes_total",
+		Help: "Scrapes.",
+	})
+	jobs = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "acme_go_jobs",
+		Help: "Jobs.",
+	})
+)
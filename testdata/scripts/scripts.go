@@ -0,0 +1,14 @@
+package scripts
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "acme_requests_total",
+		Help: "Requests.",
+	}, []string{"method", "path", "code"})
+	queue = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "queue_length",
+		Help: "Length of the queue.",
+	})
+)
@@ -0,0 +1,31 @@
+package similarity
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requests = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Requests.",
+	})
+	apiRequests = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "api",
+		Name:      "http_requests_total",
+		Help:      "Requests.",
+	})
+	jobs = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "jobs_processed",
+		Help: "Jobs.",
+	})
+	jobsCount = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "jobs_processed_count",
+		Help: "Jobs.",
+	})
+	errors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "errors_total",
+		Help: "Errors.",
+	})
+	dbErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "acme_db_errors_total",
+		Help: "Errors of the database.",
+	})
+)
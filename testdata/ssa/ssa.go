@@ -0,0 +1,46 @@
+package ssa
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "acme"
+
+func metricName() string {
+	return "jobs"
+}
+
+func newMetrics(verbose bool) {
+	name := "requests"
+	name = name + "_count"
+	_ = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      "Number of requests.",
+	})
+
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: metricName(),
+		Help: "Number of jobs.",
+	})
+
+	kind := "queue_length"
+	if verbose {
+		kind = "queue_size"
+	}
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: kind,
+		Help: "Length of the queue.",
+	})
+}
+
+var suffix = "total"
+
+func init() {
+	suffix = "count"
+}
+
+var batches = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "batches_" + suffix,
+	Help: "Number of batches.",
+})
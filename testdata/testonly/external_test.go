@@ -0,0 +1,11 @@
+package testonly_test
+
+import (
+	"testing"
+
+	"example.com/testonly"
+)
+
+func TestRequests(t *testing.T) {
+	testonly.Requests.Inc()
+}
@@ -0,0 +1,16 @@
+package testonly
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	Requests = promauto.NewCounter(prometheus.CounterOpts{Name: "requests_total", Help: "Number of requests."})
+	retries  = promauto.NewCounter(prometheus.CounterOpts{Name: "retries_total", Help: "Number of retries."})
+	failures = promauto.NewCounter(prometheus.CounterOpts{Name: "failures_total", Help: "Number of failures."})
+)
+
+func handle() {
+	failures.Inc()
+}
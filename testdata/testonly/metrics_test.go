@@ -0,0 +1,8 @@
+package testonly
+
+import "testing"
+
+func TestRetries(t *testing.T) {
+	retries.Inc()
+	failures.Inc()
+}
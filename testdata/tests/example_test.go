@@ -0,0 +1,10 @@
+package tests_test
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var example = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "example_requests_total",
+	Help: "Number of example requests.",
+})
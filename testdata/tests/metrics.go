@@ -0,0 +1,11 @@
+package tests
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var Requests = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "requests_total",
+	Help: "Number of requests.",
+})
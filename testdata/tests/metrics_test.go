@@ -0,0 +1,10 @@
+package tests
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var fixture = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "fixture_requests_total",
+	Help: "Number of requests in tests.",
+})
@@ -0,0 +1,43 @@
+package timers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "request_duration_seconds",
+		Help: "Duration of requests.",
+	}, []string{"method"})
+	queryDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name: "query_duration_milliseconds",
+		Help: "Duration of queries.",
+	})
+	batches = promauto.NewSummary(prometheus.SummaryOpts{
+		Name: "batches_total",
+		Help: "Processed batches.",
+	})
+	flushes = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name: "flush_latency",
+		Help: "Latency of flushes.",
+	})
+	compactions = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "compaction_duration_seconds",
+		Help:    "Duration of compactions.",
+		Buckets: []float64{10, 100, 1000, 10000},
+	})
+)
+
+func handle() {
+	defer prometheus.NewTimer(requestDuration.WithLabelValues("GET")).ObserveDuration()
+
+	timer := prometheus.NewTimer(queryDuration)
+	defer timer.ObserveDuration()
+}
+
+func process() {
+	prometheus.NewTimer(batches)
+	prometheus.NewTimer(flushes).ObserveDuration()
+	defer prometheus.NewTimer(compactions).ObserveDuration()
+}
@@ -0,0 +1,31 @@
+package units
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requestDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "request_duration_seconds",
+		Help: "Duration of the requests.",
+	})
+	queryDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "db_query_duration_seconds",
+		Help: "Duration of the queries.",
+	})
+	cacheLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "cache_latency_ms",
+		Help: "Latency of the cache in milliseconds.",
+	})
+	gcPauses = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gc_pause_microseconds_total",
+		Help: "Total GC pauses in microseconds.",
+	})
+	// As many sizes in kilobytes as in megabytes: no convention.
+	responseSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "response_size_kb",
+		Help: "Size of the responses in kilobytes.",
+	})
+	heapSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "heap_size_megabytes",
+		Help: "Size of the heap in megabytes.",
+	})
+)
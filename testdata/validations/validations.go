@@ -0,0 +1,15 @@
+package validations
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	queueLength = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "validation_queue_length",
+		Help: "Length of the queue.",
+	})
+
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "validation_requests_total",
+		Help: "Requests handled.",
+	}, []string{"code", "method", "path", "user_agent"})
+)
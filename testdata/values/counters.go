@@ -0,0 +1,24 @@
+package values
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const refund = -5
+
+var (
+	payments = promauto.NewCounter(prometheus.CounterOpts{Name: "payments_total", Help: "Number of payments."})
+	balance  = promauto.NewGauge(prometheus.GaugeOpts{Name: "balance", Help: "Balance."})
+	sessions = promauto.NewCounterVec(prometheus.CounterOpts{Name: "sessions_total", Help: "Number of sessions."}, []string{"kind"})
+)
+
+func pay(amount float64, closed int) {
+	payments.Add(amount)
+	payments.Add(refund)
+	balance.Add(-amount)
+
+	delta := -1.0
+	sessions.WithLabelValues("web").Add(delta)
+	sessions.WithLabelValues("web").Add(-float64(closed))
+}
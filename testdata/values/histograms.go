@@ -0,0 +1,38 @@
+package values
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	latency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name: "latency_seconds",
+		Help: "Latency.",
+	})
+	sizes = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "response_size_bytes",
+		Help:    "Size of responses.",
+		Buckets: prometheus.ExponentialBuckets(100, 10, 5),
+	})
+	ratios = promauto.NewSummary(prometheus.SummaryOpts{Name: "ratio", Help: "Ratio."})
+	delays = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "delay_seconds",
+		Help:    "Delay.",
+		Buckets: []float64{0.1, 1, 10},
+	})
+)
+
+func observe(start time.Time) {
+	latency.Observe(250)
+	latency.Observe(1500)
+
+	sizes.Observe(0.01)
+
+	ratios.Observe(-1)
+
+	delays.Observe(0.5)
+	delays.Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,27 @@
+package whitespace
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const namespace = "acme "
+
+var (
+	trailing = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "requests_total",
+		Help:      "Requests.",
+	})
+	multiline = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "queue_length",
+		Help: "Length of the queue.\nUpdated every second.",
+	}, []string{" queue", "shard\t"})
+	clean = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "jobs_total",
+		Help: "Jobs processed.",
+	})
+)
+
+type collector struct{}
+
+func (collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(prometheus.NewDesc("up", "Up\x00.", nil, nil), prometheus.GaugeValue, 1)
+}
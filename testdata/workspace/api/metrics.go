@@ -0,0 +1,15 @@
+package api
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var jobs = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "jobs_processed_total",
+	Help: "Number of processed jobs.",
+})
+
+func process() {
+	jobs.Inc()
+}
@@ -0,0 +1,22 @@
+package worker
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	jobs = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "jobs_processed_total",
+		Help: "Number of processed jobs.",
+	})
+
+	queue = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "queue_length",
+	})
+)
+
+func process(length int) {
+	jobs.Inc()
+	queue.Set(float64(length))
+}
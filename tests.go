@@ -0,0 +1,100 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// TestFilePolicy selects the _test.go files to analyze. Files which are not
+// tests are always selected.
+type TestFilePolicy struct {
+	// Internal selects the test files of the package under test.
+	Internal bool
+	// External selects the test files of external test packages, i.e.
+	// packages named with the _test suffix.
+	External bool
+}
+
+func isTestFile(path string) bool {
+	return strings.HasSuffix(path, "_test.go")
+}
+
+// FilterTestFiles returns the paths selected by the policy.
+func FilterTestFiles(paths []string, policy TestFilePolicy) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		if !isTestFile(path) {
+			files = append(files, path)
+			continue
+		}
+		if !policy.Internal && !policy.External {
+			continue
+		}
+
+		external, err := isExternalTest(path)
+		if err != nil {
+			return nil, err
+		}
+		if external && policy.External || !external && policy.Internal {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// isExternalTest reports whether the file at path belongs to an external test
+// package.
+func isExternalTest(path string) (bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return false, err
+	}
+	return strings.HasSuffix(file.Name.Name, "_test"), nil
+}
+
+// testDefinitionDrift reports the metrics redeclared in test files with
+// another type, help text or labels than their definition in production
+// code.
+func testDefinitionDrift(res *partialResult) []Issue {
+	prod := make(map[string]MetricFamilyWithPos)
+	for _, m := range res.metrics {
+		name := m.MetricFamily.GetName()
+		if _, ok := prod[name]; !ok && !isTestFile(m.Pos.Filename) {
+			prod[name] = m
+		}
+	}
+
+	var issues []Issue
+	for _, m := range res.metrics {
+		p, ok := prod[m.MetricFamily.GetName()]
+		if !ok || !isTestFile(m.Pos.Filename) {
+			continue
+		}
+		var diffs []string
+		if t, pt := metricTypeName(m.MetricFamily.GetType()), metricTypeName(p.MetricFamily.GetType()); t != pt {
+			diffs = append(diffs, fmt.Sprintf("type %s instead of %s", t, pt))
+		}
+		if h, ph := m.MetricFamily.GetHelp(), p.MetricFamily.GetHelp(); h != ph {
+			diffs = append(diffs, fmt.Sprintf("help %q instead of %q", h, ph))
+		}
+		if l, pl := m.Labels(), p.Labels(); strings.Join(l, ",") != strings.Join(pl, ",") {
+			diffs = append(diffs, fmt.Sprintf("labels [%s] instead of [%s]", strings.Join(l, " "), strings.Join(pl, " ")))
+		}
+		if len(diffs) == 0 {
+			continue
+		}
+		issues = append(issues, Issue{
+			Pos:        m.Pos,
+			Metric:     m.MetricFamily.GetName(),
+			Text:       fmt.Sprintf("metric is redeclared in tests with %s of its definition at %s", strings.Join(diffs, ", "), p.Pos),
+			RuleID:     RuleTestDefinitionDrift,
+			Severity:   ruleSeverity(RuleTestDefinitionDrift),
+			End:        m.End,
+			MetricType: metricTypeName(m.MetricFamily.GetType()),
+			Labels:     m.Labels(),
+		})
+	}
+	return issues
+}
@@ -0,0 +1,56 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFilterTestFiles(t *testing.T) {
+	var (
+		external = filepath.Join("testdata", "tests", "example_test.go")
+		source   = filepath.Join("testdata", "tests", "metrics.go")
+		internal = filepath.Join("testdata", "tests", "metrics_test.go")
+		paths    = []string{external, source, internal}
+	)
+
+	for _, tc := range []struct {
+		policy   TestFilePolicy
+		expected []string
+	}{
+		{policy: TestFilePolicy{}, expected: []string{source}},
+		{policy: TestFilePolicy{Internal: true}, expected: []string{source, internal}},
+		{policy: TestFilePolicy{External: true}, expected: []string{external, source}},
+		{policy: TestFilePolicy{Internal: true, External: true}, expected: paths},
+	} {
+		files, err := FilterTestFiles(paths, tc.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(files, tc.expected) {
+			t.Fatalf("expected %v with %+v, got %v", tc.expected, tc.policy, files)
+		}
+	}
+}
+
+func TestTestDefinitionDrift(t *testing.T) {
+	paths := []string{filepath.Join("testdata", "drift", "handler.go"), filepath.Join("testdata", "drift", "handler_test.go")}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleTestDefinitionDrift {
+			got = append(got, fmt.Sprintf("%d %s %s", iss.Pos.Line, iss.Metric, iss.Text))
+		}
+	}
+	expected := []string{
+		`11 drift_requests_total metric is redeclared in tests with help "Requests by code." instead of "Requests by code and method.", labels [code] instead of [code method] of its definition at ` + paths[0] + ":5:41",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,108 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// isNewTimer reports whether call is prometheus.NewTimer(observer).
+func (v *visitor) isNewTimer(call *ast.CallExpr) bool {
+	if len(call.Args) != 1 {
+		return false
+	}
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name == "NewTimer" && v.isDotImported(fun)
+	case *ast.SelectorExpr:
+		return fun.Sel.Name == "NewTimer" && v.isPrometheusSelector(fun)
+	}
+	return false
+}
+
+// parseTimer records a call to prometheus.NewTimer as a write of durations in
+// seconds to its observer, which ObserveDuration does, and reports the timers
+// which cannot measure anything:
+//
+//	prometheus.NewTimer(latency)                  // discarded
+//	prometheus.NewTimer(latency).ObserveDuration() // stopped at once
+//
+// The timer is meant to be stopped when the measured operation ends, usually
+// with defer prometheus.NewTimer(latency).ObserveDuration().
+func (v *visitor) parseTimer(call *ast.CallExpr) {
+	if !v.isNewTimer(call) {
+		return
+	}
+
+	// The path starts with the innermost node.
+	path := v.types.enclosing(call.Pos())
+	for i, n := range path {
+		if n != call {
+			continue
+		}
+		parents := path[i+1:]
+		if len(parents) > 0 {
+			if _, ok := parents[0].(*ast.ExprStmt); ok {
+				v.report(call, RuleTimerMisuse, "the timer is discarded, so no duration is observed; call ObserveDuration when the operation ends")
+			}
+		}
+		if len(parents) > 2 {
+			sel, ok := parents[0].(*ast.SelectorExpr)
+			_, stmt := parents[2].(*ast.ExprStmt)
+			if ok && stmt && strings.HasPrefix(sel.Sel.Name, "ObserveDuration") {
+				v.report(parents[1], RuleTimerMisuse, "the timer is stopped right after it is started, so it observes a duration close to zero; use defer")
+			}
+		}
+		break
+	}
+
+	key := v.metricKey(call.Args[0])
+	if key == "" {
+		return
+	}
+	v.writes = append(v.writes, writeSite{
+		Holder:    key,
+		WriteSite: WriteSite{Pos: v.fs.Position(call.Pos()), Method: "NewTimer"},
+		Unit:      "seconds",
+	})
+}
+
+// timerTargets reports the metrics observed by timers, which observe seconds,
+// whose name has a counter suffix or no unit, or whose buckets look like they
+// are in milliseconds. Names with another time unit are reported by
+// unitMismatches.
+func timerTargets(res *partialResult) []Issue {
+	byHolder := metricsByHolder(res)
+
+	var issues []Issue
+	for _, w := range res.writes {
+		if w.Method != "NewTimer" {
+			continue
+		}
+		for _, m := range byHolder[w.Holder] {
+			name := m.MetricFamily.GetName()
+			var text string
+			switch {
+			case strings.HasSuffix(name, "_total") || strings.HasSuffix(name, "_count"):
+				text = "a timer observes durations, but the metric name has a counter suffix; name it with a _seconds suffix"
+			case nameUnit(name) == "":
+				text = "a timer observes durations in seconds, but the metric name does not have a _seconds suffix"
+			case nameUnit(name) == "seconds" && len(m.buckets) > 0 && m.buckets[0] >= 1 && m.buckets[len(m.buckets)-1] >= 1000:
+				text = fmt.Sprintf("a timer observes durations in seconds, but the buckets from %v to %v look like milliseconds", m.buckets[0], m.buckets[len(m.buckets)-1])
+			default:
+				continue
+			}
+			issues = append(issues, Issue{
+				Pos:        w.Pos,
+				Metric:     name,
+				Text:       text,
+				RuleID:     RuleTimerMisuse,
+				Severity:   ruleSeverity(RuleTimerMisuse),
+				End:        w.Pos,
+				MetricType: metricTypeName(m.MetricFamily.GetType()),
+				Labels:     m.Labels(),
+			})
+		}
+	}
+	return issues
+}
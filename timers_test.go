@@ -0,0 +1,35 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTimers(t *testing.T) {
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "timers", "timers.go")}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		switch iss.RuleID {
+		case RuleTimerMisuse, RuleUnitMismatch:
+			got = append(got, iss.Pos.String()+" "+iss.RuleID+" "+iss.Metric)
+		}
+	}
+	file := filepath.Join("testdata", "timers", "timers.go")
+	expected := []string{
+		file + ":35:11 PL019 query_duration_milliseconds",
+		file + ":40:2 PL020 batches_total",
+		file + ":40:2 PL020 ",
+		file + ":41:2 PL020 flush_latency",
+		file + ":41:2 PL020 ",
+		file + ":42:8 PL020 compaction_duration_seconds",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected timer issues\n%v\ngot\n%v", expected, got)
+	}
+}
@@ -0,0 +1,134 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path"
+)
+
+// typesInfo resolves identifiers with go/types rather than with the
+// deprecated ast.Object, which is unset when files are parsed with
+// parser.SkipObjectResolution.
+type typesInfo struct {
+	info *types.Info
+	// defs holds the declarations and assignments of each variable and
+	// constant, in source order.
+	defs  map[types.Object][]*definition
+	files []*ast.File
+	// funcs holds the declarations of the functions and methods.
+	funcs map[types.Object]*ast.FuncDecl
+	// versionErrors are the uses of the features of a later Go version than
+	// the one of the files.
+	versionErrors []SyntaxError
+}
+
+// fakeImporter returns empty packages: only the identifiers declared in the
+// checked files need to be resolved, so dependencies are not loaded.
+type fakeImporter struct {
+	packages map[string]*types.Package
+}
+
+func (imp *fakeImporter) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := imp.packages[importPath]; ok {
+		return pkg, nil
+	}
+	pkg := types.NewPackage(importPath, path.Base(importPath))
+	pkg.MarkComplete()
+	imp.packages[importPath] = pkg
+	return pkg, nil
+}
+
+// checkFiles type-checks the files of a package for the Go language version
+// goVersion, empty for the version of the toolchain. Type errors, such as uses
+// of the empty imported packages, are ignored, apart from the uses of the
+// features of a later version. Files with different package names, as
+// external test packages, are checked separately.
+func checkFiles(fs *token.FileSet, files []*ast.File, goVersion string) *typesInfo {
+	t := &typesInfo{
+		info: &types.Info{
+			Defs:  make(map[*ast.Ident]types.Object),
+			Uses:  make(map[*ast.Ident]types.Object),
+			Types: make(map[ast.Expr]types.TypeAndValue),
+		},
+		defs:  make(map[types.Object][]*definition),
+		files: files,
+		funcs: make(map[types.Object]*ast.FuncDecl),
+	}
+
+	var (
+		names []string
+		pkgs  = make(map[string][]*ast.File)
+	)
+	for _, f := range files {
+		if _, ok := pkgs[f.Name.Name]; !ok {
+			names = append(names, f.Name.Name)
+		}
+		pkgs[f.Name.Name] = append(pkgs[f.Name.Name], f)
+	}
+
+	var errs []error
+	conf := types.Config{
+		Importer:    &fakeImporter{packages: make(map[string]*types.Package)},
+		Error:       func(err error) { errs = append(errs, err) },
+		FakeImportC: true,
+		GoVersion:   checkVersion(goVersion),
+	}
+	for _, name := range names {
+		// The returned error is the first type error, reported to Error too.
+		_, _ = conf.Check(name, fs, pkgs[name], t.info)
+		for _, f := range pkgs[name] {
+			t.collectDefinitions(f)
+		}
+	}
+	t.versionErrors = versionErrors(errs, goVersion)
+	return t
+}
+
+// constant returns the value of the string constant referred to by ident, as
+// evaluated by the type checker. It covers the constants repeating the
+// previous expression of a const block, and those built with iota or
+// conversions from other constants.
+func (t *typesInfo) constant(ident *ast.Ident) (string, bool) {
+	c, ok := t.info.Uses[ident].(*types.Const)
+	if !ok || c.Val().Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(c.Val()), true
+}
+
+// element returns the element of the composite literal lit, an array, slice
+// or map, at the constant index, e.g. the element "write" of
+// [...]string{kindRead: "read", kindWrite: "write"} at kindWrite.
+func (t *typesInfo) element(lit *ast.CompositeLit, index ast.Expr) ast.Expr {
+	idx := t.info.Types[index].Value
+	if idx == nil {
+		return nil
+	}
+
+	pos := constant.MakeInt64(0)
+	for _, elt := range lit.Elts {
+		value := elt
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			key := t.info.Types[kv.Key].Value
+			if key == nil {
+				return nil
+			}
+			pos, value = key, kv.Value
+		}
+		if pos.Kind() == idx.Kind() && constant.Compare(pos, token.EQL, idx) {
+			return value
+		}
+		if pos.Kind() == constant.Int {
+			pos = constant.BinaryOp(pos, token.ADD, constant.MakeInt64(1))
+		}
+	}
+	return nil
+}
+
+// isPackage reports whether ident refers to an imported package.
+func (t *typesInfo) isPackage(ident *ast.Ident) bool {
+	_, ok := t.info.Uses[ident].(*types.PkgName)
+	return ok
+}
@@ -0,0 +1,57 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCrossFileResolution(t *testing.T) {
+	paths := []string{
+		filepath.Join("testdata", "crossfile", "metrics.go"),
+		filepath.Join("testdata", "crossfile", "names.go"),
+	}
+
+	// Files parsed without object resolution, as modern loaders do.
+	fs := token.NewFileSet()
+	var files []*ast.File
+	for _, path := range paths {
+		f, err := parser.ParseFile(fs, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+
+	fromFiles, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range []*Result{Analyze(fs, files, Setting{}), fromFiles} {
+		if len(res.Metrics) != 1 || len(res.Issues) != 0 {
+			t.Fatalf("unexpected result %+v", res)
+		}
+		m := res.Metrics[0]
+		if m.MetricFamily.GetName() != "acme_requests_total" || m.MetricFamily.GetHelp() != "Number of requests." || !reflect.DeepEqual(m.Labels(), []string{"code"}) {
+			t.Fatalf("unexpected metric %+v", m)
+		}
+	}
+}
+
+func TestConstBlock(t *testing.T) {
+	bothResolvers(t, func(t *testing.T, ssa bool) {
+		res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "consts", "consts.go")}, Setting{Strict: true, SSA: ssa})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Metrics) != 2 || res.Metrics[0].MetricFamily.GetName() != "acme_api_request_duration_seconds" || res.Metrics[1].MetricFamily.GetName() != "acme_writes_total" {
+			t.Fatalf("unexpected metrics %v", res.Metrics)
+		}
+		if len(res.Issues) != 0 {
+			t.Fatalf("unexpected issues in strict mode: %v", res.Issues)
+		}
+	})
+}
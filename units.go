@@ -0,0 +1,164 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// quantityUnit is a unit declared by the suffix of a metric name.
+type quantityUnit struct {
+	suffix, quantity, unit string
+}
+
+// quantityUnits map the suffixes of metric names to the quantity they
+// measure and to their unit.
+var quantityUnits = []quantityUnit{
+	{"_seconds", "duration", "seconds"},
+	{"_hours", "duration", "hours"},
+	{"_minutes", "duration", "minutes"},
+	{"_milliseconds", "duration", "milliseconds"},
+	{"_ms", "duration", "milliseconds"},
+	{"_microseconds", "duration", "microseconds"},
+	{"_us", "duration", "microseconds"},
+	{"_nanoseconds", "duration", "nanoseconds"},
+	{"_ns", "duration", "nanoseconds"},
+	{"_bytes", "size", "bytes"},
+	{"_bits", "size", "bits"},
+	{"_kilobytes", "size", "kilobytes"},
+	{"_kb", "size", "kilobytes"},
+	{"_kibibytes", "size", "kibibytes"},
+	{"_kib", "size", "kibibytes"},
+	{"_megabytes", "size", "megabytes"},
+	{"_mb", "size", "megabytes"},
+	{"_mebibytes", "size", "mebibytes"},
+	{"_mib", "size", "mebibytes"},
+	{"_gigabytes", "size", "gigabytes"},
+	{"_gb", "size", "gigabytes"},
+	{"_gibibytes", "size", "gibibytes"},
+	{"_gib", "size", "gibibytes"},
+}
+
+// baseUnits are the base units of the quantities.
+var baseUnits = map[string]string{"duration": "seconds", "size": "bytes"}
+
+// unitOf returns the unit declared by the suffix of a metric name, ignoring
+// the _total suffix of counters.
+func unitOf(name string) (quantityUnit, bool) {
+	name = strings.TrimSuffix(name, "_total")
+	for _, u := range quantityUnits {
+		if strings.HasSuffix(name, u.suffix) {
+			return u, true
+		}
+	}
+	return quantityUnit{}, false
+}
+
+// withUnit returns name with its unit suffix replaced by suffix, e.g.
+// request_latency_seconds for request_latency_ms and _seconds.
+func withUnit(name string, u quantityUnit, suffix string) string {
+	total := strings.HasSuffix(name, "_total")
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "_total"), u.suffix) + suffix
+	if total {
+		name += "_total"
+	}
+	return name
+}
+
+// mixedUnits reports the metrics whose unit is not the one of most of the
+// metrics of the analyzed packages measuring the same quantity, e.g. a
+// duration in milliseconds when the other durations are in seconds, with the
+// name they would have in that unit. When several units are used by as many
+// metrics, the base unit wins, or nothing is reported.
+func mixedUnits(res *partialResult) []Issue {
+	type unitCount struct {
+		names    map[string]bool
+		suffixes map[string]int
+	}
+	byQuantity := make(map[string]map[string]*unitCount)
+	for _, m := range res.metrics {
+		u, ok := unitOf(m.MetricFamily.GetName())
+		if !ok {
+			continue
+		}
+		units := byQuantity[u.quantity]
+		if units == nil {
+			units = make(map[string]*unitCount)
+			byQuantity[u.quantity] = units
+		}
+		c := units[u.unit]
+		if c == nil {
+			c = &unitCount{names: make(map[string]bool), suffixes: make(map[string]int)}
+			units[u.unit] = c
+		}
+		if !c.names[m.MetricFamily.GetName()] {
+			c.names[m.MetricFamily.GetName()] = true
+			c.suffixes[u.suffix]++
+		}
+	}
+
+	// convention holds the unit of most metrics of each quantity, and the
+	// most used suffix for it.
+	type convention struct {
+		unit, suffix string
+		n, total     int
+	}
+	conventions := make(map[string]convention)
+	for quantity, units := range byQuantity {
+		if len(units) < 2 {
+			continue
+		}
+		var (
+			best  convention
+			tie   bool
+			total int
+		)
+		for unit, c := range units {
+			total += len(c.names)
+			switch n := len(c.names); {
+			case n > best.n:
+				best, tie = convention{unit: unit, n: n}, false
+			case n == best.n:
+				if baseUnits[quantity] == unit {
+					best, tie = convention{unit: unit, n: n}, false
+				} else if baseUnits[quantity] != best.unit {
+					tie = true
+				}
+			}
+		}
+		if tie {
+			continue
+		}
+		suffixes := units[best.unit].suffixes
+		for suffix, n := range suffixes {
+			if n > suffixes[best.suffix] || n == suffixes[best.suffix] && suffix < best.suffix {
+				best.suffix = suffix
+			}
+		}
+		best.total = total
+		conventions[quantity] = best
+	}
+
+	var issues []Issue
+	for _, m := range res.metrics {
+		name := m.MetricFamily.GetName()
+		u, ok := unitOf(name)
+		if !ok {
+			continue
+		}
+		c, ok := conventions[u.quantity]
+		if !ok || c.unit == u.unit {
+			continue
+		}
+		issues = append(issues, Issue{
+			Pos:        m.Pos,
+			Metric:     name,
+			Text:       fmt.Sprintf("metric is in %s while %d of the %d %s metrics are in %s, rename it to %s", u.unit, c.n, c.total, u.quantity, c.unit, withUnit(name, u, c.suffix)),
+			RuleID:     RuleMixedUnits,
+			Severity:   ruleSeverity(RuleMixedUnits),
+			End:        m.End,
+			MetricType: metricTypeName(m.MetricFamily.GetType()),
+			Labels:     m.Labels(),
+		})
+	}
+	return issues
+}
@@ -0,0 +1,47 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMixedUnits(t *testing.T) {
+	path := filepath.Join("testdata", "units", "units.go")
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{path}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		path + ":14:41: cache_latency_ms metric is in milliseconds while 2 of the 4 duration metrics are in seconds, rename it to cache_latency_seconds",
+		path + ":18:35: gc_pause_microseconds_total metric is in microseconds while 2 of the 4 duration metrics are in seconds, rename it to gc_pause_seconds_total",
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleMixedUnits {
+			got = append(got, iss.Pos.String()+": "+iss.Metric+" "+iss.Text)
+		}
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestWithUnit(t *testing.T) {
+	for _, tc := range []struct {
+		name, suffix, expected string
+	}{
+		{"request_latency_ms", "_seconds", "request_latency_seconds"},
+		{"sent_kb_total", "_bytes", "sent_bytes_total"},
+	} {
+		u, ok := unitOf(tc.name)
+		if !ok {
+			t.Fatalf("no unit found in %s", tc.name)
+		}
+		if got := withUnit(tc.name, u, tc.suffix); got != tc.expected {
+			t.Errorf("expected %s, got %s", tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package promlinter
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"sort"
+)
+
+// writeMethods are the methods of the metric types updating their value.
+var writeMethods = map[string]bool{
+	"Inc":              true,
+	"Dec":              true,
+	"Add":              true,
+	"Sub":              true,
+	"Set":              true,
+	"SetToCurrentTime": true,
+	"Observe":          true,
+
+	"AddWithExemplar":     true,
+	"ObserveWithExemplar": true,
+}
+
+// labelMethods are the methods of the Vec types returning a child metric or
+// a curried Vec.
+var labelMethods = map[string]bool{
+	"WithLabelValues":          true,
+	"With":                     true,
+	"GetMetricWith":            true,
+	"GetMetricWithLabelValues": true,
+	"CurryWith":                true,
+	"MustCurryWith":            true,
+}
+
+// WriteSite is a call updating the value of a metric.
+type WriteSite struct {
+	Pos    token.Position `json:"pos"`
+	Method string         `json:"method"`
+}
+
+// writeSite is a write call on the variable or field with the holder key.
+type writeSite struct {
+	Holder string `json:"holder"`
+	WriteSite
+	// Value is the value of the argument if it is a constant, and Negated
+	// is true if the argument is negated, e.g. Add(-n). Unit is the time unit
+	// of the argument if it is a converted duration.
+	Value   *float64 `json:"value,omitempty"`
+	Negated bool     `json:"negated,omitempty"`
+	Unit    string   `json:"unit,omitempty"`
+	// Exemplar is the label set of the exemplar of AddWithExemplar and
+	// ObserveWithExemplar.
+	Exemplar *exemplarLabels `json:"exemplar,omitempty"`
+}
+
+// parseWrite records call if it updates a metric, e.g. requests.Inc() or
+// errors.WithLabelValues(code).Inc().
+func (v *visitor) parseWrite(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !writeMethods[sel.Sel.Name] {
+		return
+	}
+
+	key := v.metricKey(sel.X)
+	if key == "" {
+		return
+	}
+	w := writeSite{
+		Holder:    key,
+		WriteSite: WriteSite{Pos: v.fs.Position(call.Pos()), Method: sel.Sel.Name},
+	}
+	if _, ok := exemplarMethods[sel.Sel.Name]; ok && len(call.Args) == 2 {
+		w.Exemplar = v.exemplarLabels(call.Args[1], 0)
+	}
+	if len(call.Args) == 1 || w.Exemplar != nil {
+		w.Value = v.constantValue(call.Args[0])
+		if u, ok := ast.Unparen(call.Args[0]).(*ast.UnaryExpr); ok && u.Op == token.SUB && w.Value == nil {
+			w.Negated = true
+		}
+		w.Unit = v.durationUnit(call.Args[0])
+	}
+	v.writes = append(v.writes, w)
+}
+
+// metricKey returns the holder key of the metric expr refers to, directly or
+// through the child metrics of a Vec, e.g. errors.WithLabelValues(code), and
+// the type assertions to the exemplar interfaces, e.g.
+// latency.(prometheus.ExemplarObserver).
+func (v *visitor) metricKey(expr ast.Expr) string {
+	for {
+		if assert, ok := ast.Unparen(expr).(*ast.TypeAssertExpr); ok {
+			if !v.isExemplarAssertion(assert) {
+				return ""
+			}
+			expr = assert.X
+			continue
+		}
+		inner, ok := ast.Unparen(expr).(*ast.CallExpr)
+		if !ok {
+			break
+		}
+		fun, ok := inner.Fun.(*ast.SelectorExpr)
+		if !ok || !labelMethods[fun.Sel.Name] {
+			return ""
+		}
+		expr = fun.X
+	}
+	return v.referenceKey(expr, false)
+}
+
+// isExemplarAssertion reports whether assert is a type assertion to one of the
+// exemplar interfaces of an imported prometheus package. The type checker does
+// not resolve the asserted type when the type of the operand is unknown, e.g.
+// the metrics of the empty packages of the fake importer.
+func (v *visitor) isExemplarAssertion(assert *ast.TypeAssertExpr) bool {
+	sel, ok := assert.Type.(*ast.SelectorExpr)
+	if !ok || !isExemplarInterface(sel.Sel.Name) {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, resolved := v.types.info.Uses[x]
+	return v.isPrometheusSelector(sel) || !resolved && v.imports.prometheus[x.Name]
+}
+
+// MetricUsage lists the calls updating a metric.
+type MetricUsage struct {
+	Name string         `json:"name"`
+	Type string         `json:"type"`
+	Pos  token.Position `json:"pos"`
+	// Writes is the number of call sites in Sites.
+	Writes int         `json:"writes"`
+	Sites  []WriteSite `json:"sites"`
+}
+
+// UsageReport lists the call sites updating each discovered metric, to audit
+// the coverage of the instrumentation. Only the calls on the variable or
+// field to which a constructor is assigned are found.
+type UsageReport struct {
+	Metrics []MetricUsage `json:"metrics"`
+}
+
+// NewUsageReport builds the usage report of the metrics of res, in the order
+// of res.Metrics.
+func NewUsageReport(res *Result) *UsageReport {
+	byHolder := writesByHolder(res.writes)
+	report := &UsageReport{Metrics: make([]MetricUsage, 0, len(res.Metrics))}
+	for _, m := range res.Metrics {
+		sites := make([]WriteSite, 0)
+		if m.holder != "" {
+			sites = append(sites, byHolder[m.holder]...)
+		}
+		sort.Slice(sites, func(i, j int) bool { return positionLess(sites[i].Pos, sites[j].Pos) })
+		report.Metrics = append(report.Metrics, MetricUsage{
+			Name:   m.MetricFamily.GetName(),
+			Type:   metricTypeName(m.MetricFamily.GetType()),
+			Pos:    m.Pos,
+			Writes: len(sites),
+			Sites:  sites,
+		})
+	}
+	return report
+}
+
+func writesByHolder(writes []writeSite) map[string][]WriteSite {
+	byHolder := make(map[string][]WriteSite)
+	for _, w := range writes {
+		byHolder[w.Holder] = append(byHolder[w.Holder], w.WriteSite)
+	}
+	return byHolder
+}
+
+// testOnlyMetrics reports the metrics defined outside of test files whose
+// values are only updated in _test.go files, which usually means that the
+// production code was not wired after a refactoring.
+func testOnlyMetrics(res *partialResult) []Issue {
+	byHolder := writesByHolder(res.writes)
+
+	var issues []Issue
+	for _, m := range res.metrics {
+		sites := byHolder[m.holder]
+		if m.holder == "" || len(sites) == 0 || isTestFile(m.Pos.Filename) {
+			continue
+		}
+		testOnly := true
+		for _, site := range sites {
+			testOnly = testOnly && isTestFile(site.Pos.Filename)
+		}
+		if !testOnly {
+			continue
+		}
+		issues = append(issues, Issue{
+			Pos:        m.Pos,
+			Metric:     m.MetricFamily.GetName(),
+			Text:       fmt.Sprintf("metric is only updated in tests, e.g. at %s", sites[0].Pos),
+			RuleID:     RuleTestOnlyMetric,
+			Severity:   ruleSeverity(RuleTestOnlyMetric),
+			End:        m.End,
+			MetricType: metricTypeName(m.MetricFamily.GetType()),
+			Labels:     m.Labels(),
+		})
+	}
+	return issues
+}
+
+// Write encodes the report as indented JSON.
+func (r *UsageReport) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,63 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUsageReport(t *testing.T) {
+	paths := []string{
+		filepath.Join("testdata", "dead", "dead.go"),
+		filepath.Join("testdata", "dead", "use.go"),
+	}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writes := make(map[string][]WriteSite)
+	for _, m := range NewUsageReport(res).Metrics {
+		if m.Writes != len(m.Sites) {
+			t.Fatalf("%s: %d writes but %d sites", m.Name, m.Writes, len(m.Sites))
+		}
+		writes[m.Name] = m.Sites
+	}
+	for name, expected := range map[string]int{
+		"requests_total": 1,
+		"errors_total":   1,
+		"inflight":       1,
+		"retries_total":  0,
+		"pending":        0,
+	} {
+		if len(writes[name]) != expected {
+			t.Errorf("expected %d writes of %s, got %v", expected, name, writes[name])
+		}
+	}
+	if site := writes["errors_total"][0]; site.Method != "Inc" || site.Pos.Line != 9 || filepath.Base(site.Pos.Filename) != "use.go" {
+		t.Errorf("unexpected write site %+v", site)
+	}
+}
+
+func TestTestOnlyMetrics(t *testing.T) {
+	paths := []string{
+		filepath.Join("testdata", "testonly", "metrics.go"),
+		filepath.Join("testdata", "testonly", "metrics_test.go"),
+		filepath.Join("testdata", "testonly", "external_test.go"),
+	}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var metrics []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleTestOnlyMetric {
+			metrics = append(metrics, iss.Metric)
+		}
+	}
+	if expected := []string{"requests_total", "retries_total"}; !reflect.DeepEqual(metrics, expected) {
+		t.Fatalf("expected test-only metrics %v, got %v", expected, metrics)
+	}
+}
@@ -0,0 +1,66 @@
+package promlinter
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Validation is a custom validation of a metric family, run along with the
+// validations of promlint. It has the signature of promlint.Validation in the
+// versions of client_golang accepting custom validations, to which it can be
+// passed through once client_golang is upgraded; the version in use does not
+// accept them, so promlinter runs them itself.
+type Validation func(mf *dto.MetricFamily) []promlint.Problem
+
+type ruleValidation struct {
+	rule     Rule
+	validate Validation
+}
+
+// validations are the registered custom validations.
+var validations []ruleValidation
+
+// RegisterValidation registers a custom validation run on every discovered
+// metric family, and adds its rule to Rules. Its problems are reported with
+// the ID and the severity of the rule. Like RegisterCheck, it should be
+// called from an init function and panics if the ID is already used.
+func RegisterValidation(r Rule, fn Validation) {
+	if r.ID == "" {
+		panic("promlinter: validation without a rule ID")
+	}
+	if _, ok := LookupRule(r.ID); ok {
+		panic(fmt.Sprintf("promlinter: rule %s is already registered", r.ID))
+	}
+	Rules = append(Rules, r)
+	validations = append(validations, ruleValidation{r, fn})
+}
+
+// problem is a problem of promlint or of a custom validation, with the ID
+// of its rule.
+type problem struct {
+	promlint.Problem
+	ruleID string
+}
+
+// promlintProblems lints mf via promlint and the registered validations.
+func promlintProblems(mf *dto.MetricFamily) []problem {
+	problems, err := promlint.NewWithMetricFamilies([]*dto.MetricFamily{mf}).Lint()
+	if err != nil {
+		panic(err)
+	}
+	res := make([]problem, 0, len(problems))
+	for _, p := range problems {
+		res = append(res, problem{p, promlintRuleID(p.Text)})
+	}
+	for _, v := range validations {
+		for _, p := range v.validate(mf) {
+			if p.Metric == "" {
+				p.Metric = mf.GetName()
+			}
+			res = append(res, problem{p, v.rule.ID})
+		}
+	}
+	return res
+}
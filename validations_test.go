@@ -0,0 +1,53 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func init() {
+	RegisterValidation(Rule{ID: "TEST002", Name: "MaxLabels", Severity: SeverityError, Summary: "Metrics should have at most 3 labels."}, func(mf *dto.MetricFamily) []promlint.Problem {
+		if len(mf.GetMetric()) == 0 || len(mf.GetMetric()[0].GetLabel()) <= 3 {
+			return nil
+		}
+		return []promlint.Problem{{Text: fmt.Sprintf("metric has %d labels", len(mf.GetMetric()[0].GetLabel()))}}
+	})
+}
+
+func TestRegisterValidation(t *testing.T) {
+	if r, ok := LookupRule("TEST002"); !ok || r.Name != "MaxLabels" {
+		t.Fatalf("expected the rule of the validation to be registered, got %v", r)
+	}
+
+	paths := []string{filepath.Join("testdata", "validations", "validations.go")}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == "TEST002" {
+			got = append(got, iss.Metric+" "+string(iss.Severity)+": "+iss.Text)
+		}
+	}
+	expected := []string{"validation_requests_total error: metric has 4 labels"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	res, err = AnalyzeFiles(token.NewFileSet(), paths, Setting{DisabledRules: []string{"TEST002"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iss := range res.Issues {
+		if iss.RuleID == "TEST002" {
+			t.Errorf("expected the disabled validation not to be reported, got %v", iss)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"math"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// constantValue returns the numeric value of expr if it is a constant, or a
+// variable whose single reaching definition is one, e.g. d in
+//
+//	d := -1.0
+//	counter.Add(d)
+func (v *visitor) constantValue(expr ast.Expr) *float64 {
+	if tv, ok := v.types.info.Types[expr]; ok && tv.Value != nil {
+		if val := constant.ToFloat(tv.Value); val.Kind() == constant.Float {
+			f, _ := constant.Float64Val(val)
+			return &f
+		}
+		return nil
+	}
+
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if value := v.reachingValue(expr); value != nil {
+			return v.constantValue(value)
+		}
+	case *ast.CallExpr:
+		// Conversions, e.g. float64(d).
+		if len(expr.Args) == 1 && v.types.info.Types[expr.Fun].IsType() {
+			return v.constantValue(expr.Args[0])
+		}
+	}
+	return nil
+}
+
+// metricsByHolder returns the metrics of res by holder key.
+func metricsByHolder(res *partialResult) map[string][]MetricFamilyWithPos {
+	byHolder := make(map[string][]MetricFamilyWithPos)
+	for _, m := range res.metrics {
+		if m.holder != "" {
+			byHolder[m.holder] = append(byHolder[m.holder], m)
+		}
+	}
+	return byHolder
+}
+
+// negativeCounterAdds reports the calls adding a negative value to a counter,
+// which panic at runtime, and those adding a negated value, which suggest that
+// the counter should be a gauge.
+func negativeCounterAdds(res *partialResult) []Issue {
+	byHolder := metricsByHolder(res)
+
+	var issues []Issue
+	for _, w := range res.writes {
+		if plainMethod(w.Method) != "Add" {
+			continue
+		}
+		for _, m := range byHolder[w.Holder] {
+			if m.MetricFamily.GetType() != dto.MetricType_COUNTER {
+				continue
+			}
+
+			var (
+				text     string
+				severity = ruleSeverity(RuleNegativeCounterAdd)
+			)
+			switch {
+			case w.Value != nil && *w.Value < 0:
+				text = fmt.Sprintf("adding the negative value %v to a counter panics; use a gauge for values which decrease", *w.Value)
+			case w.Negated:
+				text = "adding a negated value to a counter panics if the value is positive; use a gauge for values which decrease"
+				severity = SeverityWarning
+			default:
+				continue
+			}
+			issues = append(issues, Issue{
+				Pos:        w.Pos,
+				Metric:     m.MetricFamily.GetName(),
+				Text:       text,
+				RuleID:     RuleNegativeCounterAdd,
+				Severity:   severity,
+				End:        w.Pos,
+				MetricType: metricTypeName(m.MetricFamily.GetType()),
+				Labels:     m.Labels(),
+			})
+		}
+	}
+	return issues
+}
+
+// defBuckets are the values of prometheus.DefBuckets.
+var defBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// histogramBuckets resolves the buckets of the histogram Opts arg, or returns
+// nil if the metric is not a histogram or they could not be resolved.
+func (v *visitor) histogramBuckets(metricType dto.MetricType, arg ast.Expr) []float64 {
+	if metricType != dto.MetricType_HISTOGRAM {
+		return nil
+	}
+	values := v.fieldExprs(arg, "Buckets")
+	switch {
+	case len(values) == 0:
+		if _, lit := arg.(*ast.CompositeLit); lit {
+			return defBuckets
+		}
+		return nil
+	case len(values) > 1:
+		return nil
+	}
+	// fieldExprs represents the fields missing from a variable's literal
+	// as empty strings.
+	if lit, ok := values[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		return defBuckets
+	}
+	return v.parseBuckets(values[0])
+}
+
+// parseBuckets resolves a literal slice of bucket bounds, prometheus.DefBuckets
+// or a call to a bucket generator with constant arguments.
+func (v *visitor) parseBuckets(expr ast.Expr) []float64 {
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.CompositeLit:
+		buckets := make([]float64, 0, len(expr.Elts))
+		for _, elt := range expr.Elts {
+			value := v.constantValue(elt)
+			if value == nil {
+				return nil
+			}
+			buckets = append(buckets, *value)
+		}
+		return buckets
+
+	case *ast.SelectorExpr:
+		if expr.Sel.Name == "DefBuckets" && v.isPrometheusSelector(expr) {
+			return defBuckets
+		}
+
+	case *ast.Ident:
+		if value := v.reachingValue(expr); value != nil {
+			return v.parseBuckets(value)
+		}
+
+	case *ast.CallExpr:
+		sel, ok := expr.Fun.(*ast.SelectorExpr)
+		if !ok || !v.isPrometheusSelector(sel) || len(expr.Args) != 3 {
+			return nil
+		}
+		var args [3]float64
+		for i, arg := range expr.Args {
+			value := v.constantValue(arg)
+			if value == nil {
+				return nil
+			}
+			args[i] = *value
+		}
+		count := int(args[2])
+		if count < 1 || count > 1000 {
+			return nil
+		}
+		switch sel.Sel.Name {
+		case "LinearBuckets":
+			return prometheus.LinearBuckets(args[0], args[1], count)
+		case "ExponentialBuckets":
+			if args[0] > 0 && args[1] > 1 {
+				return prometheus.ExponentialBuckets(args[0], args[1], count)
+			}
+		case "ExponentialBucketsRange":
+			// Not available in the version of client_golang used here.
+			if args[0] > 0 && args[1] > args[0] && count > 1 {
+				factor := math.Pow(args[1]/args[0], 1/float64(count-1))
+				return prometheus.ExponentialBuckets(args[0], factor, count)
+			}
+		}
+	}
+	return nil
+}
+
+// isPrometheusSelector reports whether sel selects a declaration of an
+// imported prometheus package.
+func (v *visitor) isPrometheusSelector(sel *ast.SelectorExpr) bool {
+	x, ok := sel.X.(*ast.Ident)
+	return ok && v.types.isPackage(x) && v.imports.prometheus[x.Name]
+}
+
+// magnitudeFactor is how far below the smallest bucket all the observed
+// constants must be to be reported.
+const magnitudeFactor = 1000
+
+// suspiciousObservations reports the negative constants observed by
+// histograms and summaries, and the histograms observing only constants
+// above their largest bucket or far below their smallest one.
+func suspiciousObservations(res *partialResult) []Issue {
+	byHolder := metricsByHolder(res)
+	observed := make(map[string][]writeSite)
+
+	var issues []Issue
+	report := func(m MetricFamilyWithPos, pos token.Position, text string) {
+		issues = append(issues, Issue{
+			Pos:        pos,
+			Metric:     m.MetricFamily.GetName(),
+			Text:       text,
+			RuleID:     RuleSuspiciousObservation,
+			Severity:   ruleSeverity(RuleSuspiciousObservation),
+			End:        pos,
+			MetricType: metricTypeName(m.MetricFamily.GetType()),
+			Labels:     m.Labels(),
+		})
+	}
+
+	for _, w := range res.writes {
+		if plainMethod(w.Method) != "Observe" {
+			continue
+		}
+		observed[w.Holder] = append(observed[w.Holder], w)
+		if w.Value == nil || *w.Value >= 0 {
+			continue
+		}
+		for _, m := range byHolder[w.Holder] {
+			switch m.MetricFamily.GetType() {
+			case dto.MetricType_HISTOGRAM, dto.MetricType_SUMMARY:
+				report(m, w.Pos, fmt.Sprintf("observing the negative value %v", *w.Value))
+			}
+		}
+	}
+
+	for holder, writes := range observed {
+		for _, m := range byHolder[holder] {
+			if len(m.buckets) == 0 {
+				continue
+			}
+			lowest, highest := m.buckets[0], m.buckets[len(m.buckets)-1]
+			above, below := true, true
+			for _, w := range writes {
+				if w.Value == nil || *w.Value < 0 {
+					above, below = false, false
+					break
+				}
+				above = above && *w.Value > highest
+				below = below && *w.Value*magnitudeFactor < lowest
+			}
+			switch {
+			case above:
+				report(m, writes[0].Pos, fmt.Sprintf("every value observed is above the largest bucket %v, e.g. %v; are the values and the buckets in the same unit?", highest, *writes[0].Value))
+			case below:
+				report(m, writes[0].Pos, fmt.Sprintf("every value observed is far below the smallest bucket %v, e.g. %v; are the values and the buckets in the same unit?", lowest, *writes[0].Value))
+			}
+		}
+	}
+	return issues
+}
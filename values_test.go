@@ -0,0 +1,43 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNegativeCounterAdd(t *testing.T) {
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "values", "counters.go")}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleNegativeCounterAdd {
+			got = append(got, iss.Metric+" "+string(iss.Severity))
+		}
+	}
+	expected := []string{"payments_total error", "sessions_total error", "sessions_total warning"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestSuspiciousObservations(t *testing.T) {
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "values", "histograms.go")}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleSuspiciousObservation {
+			got = append(got, iss.Metric)
+		}
+	}
+	if expected := []string{"latency_seconds", "response_size_bytes", "ratio"}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected suspicious observations of %v, got %v: %v", expected, got, res.Issues)
+	}
+}
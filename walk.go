@@ -0,0 +1,130 @@
+package promlinter
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// WalkOptions override the rules of WalkGoFiles. By default, the directories
+// are walked like the ./... pattern of the go command.
+type WalkOptions struct {
+	// FollowSymlinks follows the symbolic links to files and directories,
+	// which are skipped otherwise. A directory reached twice, e.g. through a
+	// link to one of its parents, is only walked once.
+	FollowSymlinks bool
+	// NestedModules walks the subdirectories with a go.mod file, which
+	// belong to other modules, skipped otherwise.
+	NestedModules bool
+	// Testdata walks the testdata directories, skipped otherwise.
+	Testdata bool
+	// Hidden walks the directories whose name starts with . or _, skipped
+	// otherwise.
+	Hidden bool
+}
+
+// WalkGoFiles returns the Go files of the directory root and of its
+// subdirectories, in lexical order. The vendor directories are always
+// skipped; the symbolic links, the nested modules, the testdata directories
+// and the hidden directories are skipped unless opts says otherwise. root
+// itself is walked whatever its name, and returned if it is a file.
+func WalkGoFiles(root string, opts WalkOptions) ([]string, error) {
+	return walkGoFiles(root, opts, true)
+}
+
+// goFiles returns the Go files of the directory root and, if recursive, of
+// its subdirectories, with the default rules of WalkGoFiles.
+func goFiles(root string, recursive bool) ([]string, error) {
+	return walkGoFiles(root, WalkOptions{}, recursive)
+}
+
+func walkGoFiles(root string, opts WalkOptions, recursive bool) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{root}, nil
+	}
+	w := &walker{opts: opts, recursive: recursive, visited: make(map[string]bool)}
+	if err := w.walk(root); err != nil {
+		return nil, err
+	}
+	return w.files, nil
+}
+
+type walker struct {
+	opts      WalkOptions
+	recursive bool
+	// visited holds the real paths of the walked directories, to walk each
+	// once when links are followed.
+	visited map[string]bool
+	files   []string
+}
+
+func (w *walker) walk(dir string) error {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if w.visited[real] {
+		return nil
+	}
+	w.visited[real] = true
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		isDir := e.IsDir()
+		if e.Type()&os.ModeSymlink != 0 {
+			if !w.opts.FollowSymlinks {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				// Dangling link.
+				continue
+			}
+			isDir = info.IsDir()
+		} else if !isDir && !e.Type().IsRegular() {
+			continue
+		}
+
+		if !isDir {
+			if strings.HasSuffix(e.Name(), ".go") {
+				w.files = append(w.files, path)
+			}
+			continue
+		}
+		if !w.recursive || w.skipDir(path, e.Name()) {
+			continue
+		}
+		if err := w.walk(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipDir reports whether the subdirectory name at path is skipped.
+func (w *walker) skipDir(path, name string) bool {
+	switch {
+	case name == "vendor":
+		return true
+	case name == "testdata":
+		return !w.opts.Testdata
+	case strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_"):
+		return !w.opts.Hidden
+	}
+	if !w.opts.NestedModules {
+		if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+			return true
+		}
+	}
+	return false
+}
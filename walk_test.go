@@ -0,0 +1,89 @@
+package promlinter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWalkGoFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"main.go",
+		"README.md",
+		"pkg/b.go",
+		"pkg/a.go",
+		"pkg/testdata/fixture.go",
+		"vendor/lib/lib.go",
+		".git/hooks/hook.go",
+		"_examples/example.go",
+		"tools/go.mod",
+		"tools/tools.go",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "linked.go"), []byte("package x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{
+		"linked":       outside,
+		"pkg/loop":     root,
+		"pkg/c.go":     filepath.Join(root, "main.go"),
+		"pkg/dangling": filepath.Join(root, "missing"),
+	} {
+		if err := os.Symlink(target, filepath.Join(root, filepath.FromSlash(link))); err != nil {
+			t.Skipf("symbolic links are not supported: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		opts     WalkOptions
+		expected []string
+	}{
+		{
+			expected: []string{"main.go", "pkg/a.go", "pkg/b.go"},
+		},
+		{
+			opts:     WalkOptions{Testdata: true, Hidden: true, NestedModules: true},
+			expected: []string{".git/hooks/hook.go", "_examples/example.go", "main.go", "pkg/a.go", "pkg/b.go", "pkg/testdata/fixture.go", "tools/tools.go"},
+		},
+		{
+			// The link to the root is not walked again.
+			opts:     WalkOptions{FollowSymlinks: true},
+			expected: []string{"linked/linked.go", "main.go", "pkg/a.go", "pkg/b.go", "pkg/c.go"},
+		},
+	} {
+		files, err := WalkGoFiles(root, tc.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0, len(files))
+		for _, f := range files {
+			rel, err := filepath.Rel(root, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, filepath.ToSlash(rel))
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%+v: expected %v, got %v", tc.opts, tc.expected, got)
+		}
+	}
+
+	// A directory given explicitly is walked whatever its name.
+	files, err := WalkGoFiles(filepath.Join(root, "pkg", "testdata"), WalkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected the fixture, got %v", files)
+	}
+}
@@ -0,0 +1,65 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// textField is a string of a metric definition, such as its Help, named by
+// the field or argument defining it.
+type textField struct {
+	name, value string
+}
+
+// reportWhitespace reports the leading and trailing whitespace, newlines and
+// control characters of the strings defining a metric, between pos and end.
+// They survive the concatenation of the namespace, subsystem and name, and
+// break or garble the exposition.
+func (v *visitor) reportWhitespace(pos, end token.Position, metric, metricType string, fields []textField) {
+	for _, f := range fields {
+		for _, problem := range whitespaceProblems(f.value) {
+			v.addIssue(Issue{
+				Pos:        pos,
+				End:        end,
+				Metric:     metric,
+				Text:       fmt.Sprintf("%s %s", f.name, problem),
+				RuleID:     RuleWhitespace,
+				Severity:   ruleSeverity(RuleWhitespace),
+				MetricType: metricType,
+			})
+		}
+	}
+}
+
+// whitespaceProblems describes what is wrong with s, if anything.
+func whitespaceProblems(s string) []string {
+	var problems []string
+	if strings.TrimLeftFunc(s, unicode.IsSpace) != s {
+		problems = append(problems, "has leading whitespace")
+	}
+	if strings.TrimRightFunc(s, unicode.IsSpace) != s {
+		problems = append(problems, "has trailing whitespace")
+	}
+	inner := strings.TrimFunc(s, unicode.IsSpace)
+	if strings.ContainsAny(inner, "\n\r") {
+		problems = append(problems, "contains a newline")
+	}
+	for _, r := range inner {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' {
+			problems = append(problems, fmt.Sprintf("contains the control character %q", r))
+			break
+		}
+	}
+	return problems
+}
+
+// labelFields returns the label names as fields to check.
+func labelFields(labels []string) []textField {
+	fields := make([]textField, 0, len(labels))
+	for _, l := range labels {
+		fields = append(fields, textField{fmt.Sprintf("label %q", l), l})
+	}
+	return fields
+}
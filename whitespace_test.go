@@ -0,0 +1,32 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWhitespace(t *testing.T) {
+	res, err := AnalyzeFiles(token.NewFileSet(), []string{filepath.Join("testdata", "whitespace", "whitespace.go")}, Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, iss := range res.Issues {
+		if iss.RuleID == RuleWhitespace {
+			got = append(got, iss.Metric+": "+iss.Text)
+		}
+	}
+	expected := []string{
+		"acme _requests_total: Namespace has trailing whitespace",
+		"queue_length: Help contains a newline",
+		`queue_length: label " queue" has leading whitespace`,
+		`queue_length: label "shard\t" has trailing whitespace`,
+		`up: help contains the control character '\x00'`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,136 @@
+package promlinter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Workspace describes the modules of a go.work workspace.
+type Workspace struct {
+	// Dir is the directory of the go.work file.
+	Dir     string
+	Modules []Module
+}
+
+// Module is a module of a workspace.
+type Module struct {
+	// Path is the module path declared in go.mod.
+	Path string
+	// Dir is the absolute directory of the module.
+	Dir string
+}
+
+// LoadWorkspace reads the go.work file at path and the go.mod file of every
+// module it uses.
+func LoadWorkspace(path string) (*Workspace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	work, err := modfile.ParseWork(path, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	ws := &Workspace{Dir: dir}
+	for _, use := range work.Use {
+		modDir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(modDir) {
+			modDir = filepath.Join(dir, modDir)
+		}
+		gomod, err := os.ReadFile(filepath.Join(modDir, "go.mod"))
+		if err != nil {
+			return nil, err
+		}
+		modPath := modfile.ModulePath(gomod)
+		if modPath == "" {
+			return nil, fmt.Errorf("%s: no module path", filepath.Join(modDir, "go.mod"))
+		}
+		ws.Modules = append(ws.Modules, Module{Path: modPath, Dir: modDir})
+	}
+	return ws, nil
+}
+
+// Dirs returns the directories of the modules.
+func (w *Workspace) Dirs() []string {
+	dirs := make([]string, 0, len(w.Modules))
+	for _, m := range w.Modules {
+		dirs = append(dirs, m.Dir)
+	}
+	return dirs
+}
+
+// ModuleOf returns the path of the module containing filename, or "" if it is
+// not part of the workspace. With nested modules, the innermost one wins.
+func (w *Workspace) ModuleOf(filename string) string {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return ""
+	}
+
+	var module, moduleDir string
+	for _, m := range w.Modules {
+		if (abs == m.Dir || strings.HasPrefix(abs, m.Dir+string(filepath.Separator))) && len(m.Dir) > len(moduleDir) {
+			module, moduleDir = m.Path, m.Dir
+		}
+	}
+	return module
+}
+
+// attribute sets the module of each issue.
+func (w *Workspace) attribute(issues []Issue) {
+	for i := range issues {
+		issues[i].Module = w.ModuleOf(issues[i].Pos.Filename)
+	}
+}
+
+// duplicates reports the metrics defined in several modules. The first
+// definition, by module path then position, is kept; the others are reported.
+func (w *Workspace) duplicates(metrics []MetricFamilyWithPos) []Issue {
+	type definition struct {
+		module string
+		metric MetricFamilyWithPos
+	}
+	byName := make(map[string][]definition)
+	for _, m := range metrics {
+		name := m.MetricFamily.GetName()
+		byName[name] = append(byName[name], definition{module: w.ModuleOf(m.Pos.Filename), metric: m})
+	}
+
+	var issues []Issue
+	for name, defs := range byName {
+		sort.SliceStable(defs, func(i, j int) bool {
+			if defs[i].module != defs[j].module {
+				return defs[i].module < defs[j].module
+			}
+			return positionLess(defs[i].metric.Pos, defs[j].metric.Pos)
+		})
+		first := defs[0]
+		for _, d := range defs[1:] {
+			if d.module == first.module {
+				continue
+			}
+			issues = append(issues, Issue{
+				Pos:        d.metric.Pos,
+				Metric:     name,
+				Text:       fmt.Sprintf("metric is also defined in module %s at %s", first.module, first.metric.Pos),
+				RuleID:     RuleCrossModuleDuplicate,
+				Severity:   ruleSeverity(RuleCrossModuleDuplicate),
+				End:        d.metric.End,
+				MetricType: metricTypeName(d.metric.MetricFamily.GetType()),
+				Labels:     d.metric.Labels(),
+				Module:     d.module,
+			})
+		}
+	}
+	return issues
+}
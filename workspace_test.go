@@ -0,0 +1,51 @@
+package promlinter
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkspace(t *testing.T) {
+	ws, err := LoadWorkspace("testdata/workspace/go.work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ws.Modules) != 2 || ws.Modules[0].Path != "example.com/api" || ws.Modules[1].Path != "example.com/worker" {
+		t.Fatalf("unexpected modules %+v", ws.Modules)
+	}
+	if got := ws.ModuleOf("testdata/workspace/worker/metrics.go"); got != "example.com/worker" {
+		t.Fatalf("expected module example.com/worker, got %q", got)
+	}
+	if got := ws.ModuleOf("testdata/testdata.go"); got != "" {
+		t.Fatalf("expected no module, got %q", got)
+	}
+
+	paths := []string{
+		filepath.Join("testdata", "workspace", "api", "metrics.go"),
+		filepath.Join("testdata", "workspace", "worker", "metrics.go"),
+	}
+	res, err := AnalyzeFiles(token.NewFileSet(), paths, Setting{Workspace: ws})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := make(map[string]string)
+	for _, iss := range res.Issues {
+		if iss.Module != "example.com/worker" {
+			t.Fatalf("issue %+v attributed to the wrong module", iss)
+		}
+		rules[iss.RuleID] = iss.Metric
+	}
+	if len(res.Issues) != 2 || rules[RuleCrossModuleDuplicate] != "jobs_processed_total" || rules[RuleHelp] != "queue_length" {
+		t.Fatalf("unexpected issues %+v", res.Issues)
+	}
+
+	res, err = AnalyzeFiles(token.NewFileSet(), paths, Setting{Workspace: ws, DisabledRules: []string{RuleCrossModuleDuplicate}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Issues) != 1 {
+		t.Fatalf("expected the duplicate to be disabled, got %+v", res.Issues)
+	}
+}